@@ -15,6 +15,7 @@ import (
 	goEthereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/sirupsen/logrus"
@@ -176,9 +177,13 @@ func (co *Connection) WatchTransaction(ctx context.Context, tx *types.Transactio
 			"txHash": tx.Hash().Hex(),
 		}
 		if err != nil {
+			err = DecodeRevertError(err)
 			logFields["error"] = err.Error()
-			jsonErr, ok := err.(JsonError)
-			if ok {
+			var revertErr *RevertError
+			if errors.As(err, &revertErr) {
+				logFields["revert"] = revertErr.Error()
+				logFields["code"] = hexutil.Encode(revertErr.Data)
+			} else if jsonErr, ok := err.(JsonError); ok {
 				errorCode := fmt.Sprintf("%v", jsonErr.ErrorData())
 				logFields["code"] = errorCode
 			}
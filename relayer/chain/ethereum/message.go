@@ -32,17 +32,8 @@ func MakeMessageFromEvent(event *etypes.Log, receiptsTrie *etrie.Trie) (*paracha
 		return nil, err
 	}
 
-	var convertedTopics []types.H256
-	for _, topic := range event.Topics {
-		convertedTopics = append(convertedTopics, types.H256(topic))
-	}
-
 	m := parachain.Message{
-		EventLog: parachain.EventLog{
-			Address: types.H160(event.Address),
-			Topics:  convertedTopics,
-			Data:    event.Data,
-		},
+		EventLog: MakeEventLog(event),
 		Proof: parachain.Proof{
 			ReceiptProof: proof,
 		},
@@ -50,3 +41,17 @@ func MakeMessageFromEvent(event *etypes.Log, receiptsTrie *etrie.Trie) (*paracha
 
 	return &m, nil
 }
+
+// MakeEventLog converts an event log into the event log of an inbound message
+func MakeEventLog(event *etypes.Log) parachain.EventLog {
+	var convertedTopics []types.H256
+	for _, topic := range event.Topics {
+		convertedTopics = append(convertedTopics, types.H256(topic))
+	}
+
+	return parachain.EventLog{
+		Address: types.H160(event.Address),
+		Topics:  convertedTopics,
+		Data:    event.Data,
+	}
+}
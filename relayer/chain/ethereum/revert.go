@@ -0,0 +1,270 @@
+// Copyright 2020 Snowfork
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package ethereum
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/snowfork/snowbridge/relayer/contracts"
+)
+
+var (
+	// Selector for the builtin Error(string) revert reason
+	revertReasonSelector = [4]byte(crypto.Keccak256([]byte("Error(string)"))[:4])
+	// Selector for the builtin Panic(uint256) revert reason
+	revertPanicSelector = [4]byte(crypto.Keccak256([]byte("Panic(uint256)"))[:4])
+)
+
+var ErrUnknownRevert = errors.New("unknown revert selector")
+
+// RevertError is a decoded revert from one of the bridge contracts. Callers can branch on the
+// error name using errors.As or the IsRevert helper.
+type RevertError struct {
+	// Name of the contract declaring the error, empty for builtin Error(string) and Panic(uint256)
+	Contract string
+	// Name of the Solidity error, e.g. InvalidTicket
+	Name string
+	// Decoded error arguments, by argument name
+	Args map[string]interface{}
+	// Raw revert data
+	Data []byte
+	// Error returned by the node
+	Cause error
+}
+
+func (e *RevertError) Error() string {
+	var b strings.Builder
+	if e.Contract != "" {
+		b.WriteString(e.Contract)
+		b.WriteString("::")
+	}
+	b.WriteString(e.Name)
+	b.WriteString("(")
+	b.WriteString(formatRevertArgs(e.Args))
+	b.WriteString(")")
+	return b.String()
+}
+
+func (e *RevertError) Unwrap() error {
+	return e.Cause
+}
+
+// Is matches another RevertError with the same contract and error name. An empty contract
+// in the target matches any contract.
+func (e *RevertError) Is(target error) bool {
+	t, ok := target.(*RevertError)
+	if !ok {
+		return false
+	}
+	return t.Name == e.Name && (t.Contract == "" || t.Contract == e.Contract)
+}
+
+// IsRevert returns true if err is a RevertError for the Solidity error with the given name.
+func IsRevert(err error, name string) bool {
+	var revertErr *RevertError
+	if !errors.As(err, &revertErr) {
+		return false
+	}
+	return revertErr.Name == name
+}
+
+type revertEntry struct {
+	contract string
+	err      abi.Error
+}
+
+// RevertDecoder resolves selector-prefixed revert data into named custom errors declared in the
+// bound contract ABIs.
+type RevertDecoder struct {
+	errors map[[4]byte]revertEntry
+}
+
+// NewRevertDecoder creates a decoder for the custom errors of the Gateway and BeefyClient contracts.
+func NewRevertDecoder() (*RevertDecoder, error) {
+	decoder := RevertDecoder{
+		errors: make(map[[4]byte]revertEntry),
+	}
+
+	bindings := []struct {
+		name     string
+		metadata *bind.MetaData
+	}{
+		{"Gateway", contracts.GatewayMetaData},
+		{"BeefyClient", contracts.BeefyClientMetaData},
+	}
+
+	for _, binding := range bindings {
+		parsed, err := binding.metadata.GetAbi()
+		if err != nil {
+			return nil, fmt.Errorf("parse %s ABI: %w", binding.name, err)
+		}
+		decoder.Add(binding.name, parsed)
+	}
+
+	return &decoder, nil
+}
+
+// Add registers the custom errors declared in an ABI. Errors already registered under the same
+// selector are kept, since identical signatures decode identically.
+func (d *RevertDecoder) Add(contract string, parsed *abi.ABI) {
+	for _, abiErr := range parsed.Errors {
+		selector := [4]byte(abiErr.ID[:4])
+		if _, ok := d.errors[selector]; ok {
+			continue
+		}
+		d.errors[selector] = revertEntry{
+			contract: contract,
+			err:      abiErr,
+		}
+	}
+}
+
+// Decode turns raw revert data into a RevertError.
+func (d *RevertDecoder) Decode(data []byte) (*RevertError, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("revert data too short: %d bytes", len(data))
+	}
+	selector := [4]byte(data[:4])
+
+	switch selector {
+	case revertReasonSelector, revertPanicSelector:
+		reason, err := abi.UnpackRevert(data)
+		if err != nil {
+			return nil, fmt.Errorf("unpack revert reason: %w", err)
+		}
+		name := "Error"
+		if selector == revertPanicSelector {
+			name = "Panic"
+		}
+		return &RevertError{
+			Name: name,
+			Args: map[string]interface{}{"reason": reason},
+			Data: data,
+		}, nil
+	}
+
+	entry, ok := d.errors[selector]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRevert, hexutil.Encode(selector[:]))
+	}
+
+	args := make(map[string]interface{}, len(entry.err.Inputs))
+	if len(entry.err.Inputs) > 0 {
+		err := entry.err.Inputs.UnpackIntoMap(args, data[4:])
+		if err != nil {
+			return nil, fmt.Errorf("unpack arguments of %s: %w", entry.err.Name, err)
+		}
+	}
+
+	return &RevertError{
+		Contract: entry.contract,
+		Name:     entry.err.Name,
+		Args:     args,
+		Data:     data,
+	}, nil
+}
+
+// DecodeError extracts revert data from an error returned by the node and decodes it. If the error
+// does not carry revert data, or the data cannot be decoded, the original error is returned.
+func (d *RevertDecoder) DecodeError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	data, ok := revertData(err)
+	if !ok {
+		return err
+	}
+
+	revertErr, decodeErr := d.Decode(data)
+	if decodeErr != nil {
+		return err
+	}
+	revertErr.Cause = err
+
+	return revertErr
+}
+
+// revertData extracts the revert data from a JSON-RPC error
+func revertData(err error) ([]byte, bool) {
+	var jsonErr JsonError
+	if !errors.As(err, &jsonErr) {
+		return nil, false
+	}
+
+	switch data := jsonErr.ErrorData().(type) {
+	case string:
+		decoded, err := hexutil.Decode(data)
+		if err != nil {
+			return nil, false
+		}
+		return decoded, true
+	case []byte:
+		return data, true
+	}
+
+	return nil, false
+}
+
+var defaultRevertDecoder = sync.OnceValues(NewRevertDecoder)
+
+// DecodeRevert decodes revert data using the custom errors of the bridge contracts.
+func DecodeRevert(data []byte) (*RevertError, error) {
+	decoder, err := defaultRevertDecoder()
+	if err != nil {
+		return nil, err
+	}
+	return decoder.Decode(data)
+}
+
+// DecodeRevertError decodes the revert data carried by an error returned by the node, using the
+// custom errors of the bridge contracts. See RevertDecoder.DecodeError.
+func DecodeRevertError(err error) error {
+	decoder, decoderErr := defaultRevertDecoder()
+	if decoderErr != nil {
+		return err
+	}
+	return decoder.DecodeError(err)
+}
+
+func formatRevertArgs(args map[string]interface{}) string {
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s: %s", name, formatRevertArg(args[name]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatRevertArg(arg interface{}) string {
+	switch value := arg.(type) {
+	case *big.Int:
+		return value.String()
+	case common.Address:
+		return value.Hex()
+	case [32]byte:
+		return hexutil.Encode(value[:])
+	case []byte:
+		return hexutil.Encode(value)
+	case string:
+		return fmt.Sprintf("%q", value)
+	}
+	return fmt.Sprintf("%v", arg)
+}
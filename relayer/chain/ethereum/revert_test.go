@@ -0,0 +1,66 @@
+package ethereum_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/snowfork/snowbridge/relayer/chain/ethereum"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testJsonError struct {
+	data interface{}
+}
+
+func (e testJsonError) Error() string          { return "execution reverted" }
+func (e testJsonError) ErrorCode() int         { return 3 }
+func (e testJsonError) ErrorData() interface{} { return e.data }
+
+func TestDecodeRevert_CustomError(t *testing.T) {
+	data := crypto.Keccak256([]byte("InvalidTicket()"))[:4]
+
+	revertErr, err := ethereum.DecodeRevert(data)
+	require.NoError(t, err)
+	assert.Equal(t, "BeefyClient", revertErr.Contract)
+	assert.Equal(t, "InvalidTicket", revertErr.Name)
+	assert.Equal(t, "BeefyClient::InvalidTicket()", revertErr.Error())
+}
+
+func TestDecodeRevert_ErrorString(t *testing.T) {
+	stringTy, err := abi.NewType("string", "", nil)
+	require.NoError(t, err)
+	encoded, err := abi.Arguments{{Type: stringTy}}.Pack("out of gas")
+	require.NoError(t, err)
+	data := append(crypto.Keccak256([]byte("Error(string)"))[:4], encoded...)
+
+	revertErr, err := ethereum.DecodeRevert(data)
+	require.NoError(t, err)
+	assert.Equal(t, "Error", revertErr.Name)
+	assert.Equal(t, `Error(reason: "out of gas")`, revertErr.Error())
+}
+
+func TestDecodeRevert_Unknown(t *testing.T) {
+	_, err := ethereum.DecodeRevert([]byte{0xde, 0xad, 0xbe, 0xef})
+	assert.ErrorIs(t, err, ethereum.ErrUnknownRevert)
+}
+
+func TestDecodeRevertError(t *testing.T) {
+	data := crypto.Keccak256([]byte("StaleCommitment()"))[:4]
+	nodeErr := fmt.Errorf("send transaction: %w", testJsonError{data: hexutil.Encode(data)})
+
+	err := ethereum.DecodeRevertError(nodeErr)
+	assert.True(t, ethereum.IsRevert(err, "StaleCommitment"))
+	assert.True(t, errors.Is(err, &ethereum.RevertError{Name: "StaleCommitment"}))
+	assert.False(t, errors.Is(err, &ethereum.RevertError{Contract: "Gateway", Name: "StaleCommitment"}))
+
+	var jsonErr ethereum.JsonError
+	assert.True(t, errors.As(err, &jsonErr))
+
+	plainErr := errors.New("connection refused")
+	assert.Equal(t, plainErr, ethereum.DecodeRevertError(plainErr))
+}
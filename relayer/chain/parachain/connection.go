@@ -5,6 +5,7 @@ package parachain
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -130,3 +131,22 @@ func (co *Connection) GetLatestBlockNumber() (*types.BlockNumber, error) {
 
 	return &latestBlock.Block.Header.Number, nil
 }
+
+// GetBlockTimestamp returns the time a block was authored, as recorded by the Timestamp pallet
+func (co *Connection) GetBlockTimestamp(blockHash types.Hash) (time.Time, error) {
+	key, err := types.CreateStorageKey(co.Metadata(), "Timestamp", "Now", nil, nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("create storage key for Timestamp.Now: %w", err)
+	}
+
+	var now types.U64
+	ok, err := co.API().RPC.State.GetStorage(key, &now, blockHash)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("fetch storage Timestamp.Now at block %v: %w", blockHash.Hex(), err)
+	}
+	if !ok {
+		return time.Time{}, fmt.Errorf("timestamp is not set at block %v", blockHash.Hex())
+	}
+
+	return time.UnixMilli(int64(now)), nil
+}
@@ -0,0 +1,487 @@
+// Copyright 2020 Snowfork
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package parachain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/snowfork/go-substrate-rpc-client/v4/scale"
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+)
+
+var ErrExtrinsicNotFound = errors.New("extrinsic not found in block")
+
+// DispatchError is the outcome of an extrinsic which was included in a finalized block but failed
+// to dispatch. Module errors are resolved to the name of the pallet and error using the metadata.
+type DispatchError struct {
+	// Pallet declaring the error, empty if this is not a module error
+	Pallet string
+	// Name of the error, e.g. InvalidSyncCommitteeMerkleProof or BadOrigin
+	Name string
+	// Block in which the extrinsic was included
+	BlockHash types.Hash
+	// Index of the extrinsic in the block
+	ExtrinsicIndex uint32
+}
+
+func (e *DispatchError) Error() string {
+	if e.Pallet == "" {
+		return fmt.Sprintf("DispatchError::%s", e.Name)
+	}
+	return fmt.Sprintf("%s::%s", e.Pallet, e.Name)
+}
+
+// Is matches another DispatchError with the same pallet and error name. An empty pallet in the
+// target matches any pallet.
+func (e *DispatchError) Is(target error) bool {
+	t, ok := target.(*DispatchError)
+	if !ok {
+		return false
+	}
+	return t.Name == e.Name && (t.Pallet == "" || t.Pallet == e.Pallet)
+}
+
+// IsDispatchError returns true if err is a DispatchError for the given pallet and error name.
+func IsDispatchError(err error, pallet, name string) bool {
+	return errors.Is(err, &DispatchError{Pallet: pallet, Name: name})
+}
+
+// IsAlreadyDelivered returns true if err is a DispatchError showing that another relayer delivered the message or
+// update first. Callers should treat these failures as benign, since relayers race each other for deliveries.
+func IsAlreadyDelivered(err error) bool {
+	return IsDispatchError(err, "EthereumInboundQueue", "InvalidNonce") ||
+		IsDispatchError(err, "EthereumBeaconClient", "IrrelevantUpdate")
+}
+
+// FetchDispatchError looks up the outcome of an extrinsic in a finalized block. It returns a
+// DispatchError if the block contains a System.ExtrinsicFailed event for the extrinsic, and nil
+// if the extrinsic was dispatched successfully.
+func (co *Connection) FetchDispatchError(blockHash types.Hash, ext *types.Extrinsic) error {
+	index, err := co.findExtrinsicIndex(blockHash, ext)
+	if err != nil {
+		return err
+	}
+
+	eventsKey, err := types.CreateStorageKey(co.Metadata(), "System", "Events", nil, nil)
+	if err != nil {
+		return fmt.Errorf("create storage key for System.Events: %w", err)
+	}
+
+	raw, err := co.API().RPC.State.GetStorageRaw(eventsKey, blockHash)
+	if err != nil {
+		return fmt.Errorf("fetch System.Events at block %v: %w", blockHash.Hex(), err)
+	}
+
+	failures, err := decodeExtrinsicFailures(co.Metadata(), *raw)
+	if err != nil {
+		return fmt.Errorf("decode System.Events at block %v: %w", blockHash.Hex(), err)
+	}
+
+	dispatchErr, ok := failures[index]
+	if !ok {
+		return nil
+	}
+	dispatchErr.BlockHash = blockHash
+	dispatchErr.ExtrinsicIndex = index
+
+	return dispatchErr
+}
+
+func (co *Connection) findExtrinsicIndex(blockHash types.Hash, ext *types.Extrinsic) (uint32, error) {
+	encoded, err := types.EncodeToBytes(ext)
+	if err != nil {
+		return 0, fmt.Errorf("encode extrinsic: %w", err)
+	}
+
+	block, err := co.API().RPC.Chain.GetBlock(blockHash)
+	if err != nil {
+		return 0, fmt.Errorf("fetch block %v: %w", blockHash.Hex(), err)
+	}
+
+	for i, blockExt := range block.Block.Extrinsics {
+		blockExtEncoded, err := types.EncodeToBytes(blockExt)
+		if err != nil {
+			return 0, fmt.Errorf("encode extrinsic %d in block %v: %w", i, blockHash.Hex(), err)
+		}
+		if bytes.Equal(encoded, blockExtEncoded) {
+			return uint32(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %v", ErrExtrinsicNotFound, blockHash.Hex())
+}
+
+// decodeExtrinsicFailures decodes the System.Events storage value and returns the dispatch errors
+// of all System.ExtrinsicFailed events, keyed by extrinsic index.
+func decodeExtrinsicFailures(meta *types.Metadata, raw []byte) (map[uint32]*DispatchError, error) {
+	d, recordType, count, err := newEventsDecoder(meta, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	failures := make(map[uint32]*DispatchError)
+	for i := uint64(0); i < count; i++ {
+		var phase []byte
+		var dispatchErr *DispatchError
+		for _, field := range recordType.Def.Composite.Fields {
+			switch string(field.Name) {
+			case "phase":
+				phase, err = d.capture(field.Type.Int64())
+			case "event":
+				dispatchErr, err = d.decodeExtrinsicFailed(field.Type.Int64())
+			default:
+				err = d.skip(field.Type.Int64())
+			}
+			if err != nil {
+				return nil, fmt.Errorf("decode event record %d: %w", i, err)
+			}
+		}
+
+		// Phase::ApplyExtrinsic(u32) is the first variant
+		if dispatchErr != nil && len(phase) == 5 && phase[0] == 0 {
+			failures[binary.LittleEndian.Uint32(phase[1:])] = dispatchErr
+		}
+	}
+
+	return failures, nil
+}
+
+// typeDecoder walks SCALE encoded values using the portable type registry in the V14 metadata
+type typeDecoder struct {
+	meta    *types.MetadataV14
+	data    []byte
+	reader  *bytes.Reader
+	decoder *scale.Decoder
+}
+
+func newTypeDecoder(meta *types.MetadataV14, data []byte) *typeDecoder {
+	reader := bytes.NewReader(data)
+	return &typeDecoder{
+		meta:    meta,
+		data:    data,
+		reader:  reader,
+		decoder: scale.NewDecoder(reader),
+	}
+}
+
+func (d *typeDecoder) lookup(id int64) (*types.Si1Type, error) {
+	typ, ok := d.meta.EfficientLookup[id]
+	if !ok {
+		return nil, fmt.Errorf("type %d not found in metadata", id)
+	}
+	return typ, nil
+}
+
+func (d *typeDecoder) offset() int {
+	return len(d.data) - d.reader.Len()
+}
+
+// capture skips a value of the given type and returns its encoding
+func (d *typeDecoder) capture(id int64) ([]byte, error) {
+	start := d.offset()
+	err := d.skip(id)
+	if err != nil {
+		return nil, err
+	}
+	return d.data[start:d.offset()], nil
+}
+
+func (d *typeDecoder) skip(id int64) error {
+	typ, err := d.lookup(id)
+	if err != nil {
+		return err
+	}
+	def := typ.Def
+
+	switch {
+	case def.IsComposite:
+		for _, field := range def.Composite.Fields {
+			err := d.skip(field.Type.Int64())
+			if err != nil {
+				return err
+			}
+		}
+	case def.IsVariant:
+		variant, err := d.readVariant(def.Variant)
+		if err != nil {
+			return err
+		}
+		for _, field := range variant.Fields {
+			err := d.skip(field.Type.Int64())
+			if err != nil {
+				return err
+			}
+		}
+	case def.IsSequence:
+		count, err := d.decoder.DecodeUintCompact()
+		if err != nil {
+			return err
+		}
+		for i := uint64(0); i < count.Uint64(); i++ {
+			err := d.skip(def.Sequence.Type.Int64())
+			if err != nil {
+				return err
+			}
+		}
+	case def.IsArray:
+		for i := uint32(0); i < uint32(def.Array.Len); i++ {
+			err := d.skip(def.Array.Type.Int64())
+			if err != nil {
+				return err
+			}
+		}
+	case def.IsTuple:
+		for _, elem := range def.Tuple {
+			err := d.skip(elem.Int64())
+			if err != nil {
+				return err
+			}
+		}
+	case def.IsPrimitive:
+		return d.skipPrimitive(def.Primitive.Si0TypeDefPrimitive)
+	case def.IsCompact:
+		_, err := d.decoder.DecodeUintCompact()
+		return err
+	case def.IsBitSequence:
+		bits, err := d.decoder.DecodeUintCompact()
+		if err != nil {
+			return err
+		}
+		storeType, err := d.lookup(def.BitSequence.BitStoreType.Int64())
+		if err != nil {
+			return err
+		}
+		storeSize, err := primitiveSize(storeType.Def.Primitive.Si0TypeDefPrimitive)
+		if err != nil {
+			return err
+		}
+		storeBits := uint64(storeSize * 8)
+		return d.skipBytes(int((bits.Uint64() + storeBits - 1) / storeBits * uint64(storeSize)))
+	default:
+		return fmt.Errorf("unsupported definition for type %d", id)
+	}
+
+	return nil
+}
+
+func (d *typeDecoder) skipPrimitive(primitive types.Si0TypeDefPrimitive) error {
+	if primitive == types.IsStr {
+		length, err := d.decoder.DecodeUintCompact()
+		if err != nil {
+			return err
+		}
+		return d.skipBytes(int(length.Uint64()))
+	}
+
+	size, err := primitiveSize(primitive)
+	if err != nil {
+		return err
+	}
+	return d.skipBytes(size)
+}
+
+func (d *typeDecoder) skipBytes(n int) error {
+	if n > d.reader.Len() {
+		return fmt.Errorf("unexpected end of input")
+	}
+	_, err := d.reader.Seek(int64(n), 1)
+	return err
+}
+
+func (d *typeDecoder) readVariant(def types.Si1TypeDefVariant) (*types.Si1Variant, error) {
+	index, err := d.decoder.ReadOneByte()
+	if err != nil {
+		return nil, err
+	}
+	return findVariant(def, index)
+}
+
+// decodeExtrinsicFailed decodes a RuntimeEvent and returns the dispatch error if it is a
+// System.ExtrinsicFailed event.
+func (d *typeDecoder) decodeExtrinsicFailed(id int64) (*DispatchError, error) {
+	runtimeEvent, err := d.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	if !runtimeEvent.Def.IsVariant {
+		return nil, fmt.Errorf("runtime event is not a variant")
+	}
+
+	pallet, err := d.readVariant(runtimeEvent.Def.Variant)
+	if err != nil {
+		return nil, err
+	}
+	if string(pallet.Name) != "System" || len(pallet.Fields) != 1 {
+		for _, field := range pallet.Fields {
+			err := d.skip(field.Type.Int64())
+			if err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}
+
+	systemEvent, err := d.lookup(pallet.Fields[0].Type.Int64())
+	if err != nil {
+		return nil, err
+	}
+	event, err := d.readVariant(systemEvent.Def.Variant)
+	if err != nil {
+		return nil, err
+	}
+
+	var dispatchErr *DispatchError
+	for i, field := range event.Fields {
+		if string(event.Name) == "ExtrinsicFailed" && i == 0 {
+			dispatchErr, err = d.decodeDispatchError(field.Type.Int64())
+		} else {
+			err = d.skip(field.Type.Int64())
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return dispatchErr, nil
+}
+
+// decodeDispatchError decodes a sp_runtime::DispatchError. Module errors are resolved to the pallet
+// and error names, other errors are named after their variant, e.g. Token::FundsUnavailable.
+func (d *typeDecoder) decodeDispatchError(id int64) (*DispatchError, error) {
+	typ, err := d.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	if !typ.Def.IsVariant {
+		return nil, fmt.Errorf("dispatch error is not a variant")
+	}
+
+	variant, err := d.readVariant(typ.Def.Variant)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(variant.Fields) != 1 {
+		for _, field := range variant.Fields {
+			err := d.skip(field.Type.Int64())
+			if err != nil {
+				return nil, err
+			}
+		}
+		return &DispatchError{Name: string(variant.Name)}, nil
+	}
+
+	inner, err := d.capture(variant.Fields[0].Type.Int64())
+	if err != nil {
+		return nil, err
+	}
+
+	// ModuleError { index: u8, error: [u8; 4] }, where the first byte of the error is the index of
+	// the error variant in the pallet
+	if string(variant.Name) == "Module" {
+		if len(inner) < 2 {
+			return nil, fmt.Errorf("module error too short")
+		}
+		return resolveModuleError(d.meta, inner[0], inner[1])
+	}
+
+	innerType, err := d.lookup(variant.Fields[0].Type.Int64())
+	if err != nil {
+		return nil, err
+	}
+	if innerType.Def.IsVariant && len(inner) > 0 {
+		innerVariant, err := findVariant(innerType.Def.Variant, inner[0])
+		if err != nil {
+			return nil, err
+		}
+		return &DispatchError{Name: fmt.Sprintf("%s::%s", variant.Name, innerVariant.Name)}, nil
+	}
+
+	return &DispatchError{Name: string(variant.Name)}, nil
+}
+
+func resolveModuleError(meta *types.MetadataV14, palletIndex, errorIndex uint8) (*DispatchError, error) {
+	for _, pallet := range meta.Pallets {
+		if uint8(pallet.Index) != palletIndex {
+			continue
+		}
+		if !pallet.HasErrors {
+			break
+		}
+		typ, ok := meta.EfficientLookup[pallet.Errors.Type.Int64()]
+		if !ok || !typ.Def.IsVariant {
+			break
+		}
+		variant, err := findVariant(typ.Def.Variant, errorIndex)
+		if err != nil {
+			break
+		}
+		return &DispatchError{
+			Pallet: string(pallet.Name),
+			Name:   string(variant.Name),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("module error %d not found for pallet %d", errorIndex, palletIndex)
+}
+
+func findVariant(def types.Si1TypeDefVariant, index uint8) (*types.Si1Variant, error) {
+	for i := range def.Variants {
+		if uint8(def.Variants[i].Index) == index {
+			return &def.Variants[i], nil
+		}
+	}
+	return nil, fmt.Errorf("variant %d not found", index)
+}
+
+func primitiveSize(primitive types.Si0TypeDefPrimitive) (int, error) {
+	switch primitive {
+	case types.IsBool, types.IsU8, types.IsI8:
+		return 1, nil
+	case types.IsU16, types.IsI16:
+		return 2, nil
+	case types.IsChar, types.IsU32, types.IsI32:
+		return 4, nil
+	case types.IsU64, types.IsI64:
+		return 8, nil
+	case types.IsU128, types.IsI128:
+		return 16, nil
+	case types.IsU256, types.IsI256:
+		return 32, nil
+	}
+	return 0, fmt.Errorf("unsupported primitive %d", primitive)
+}
+
+// checkDispatchResult returns a DispatchError if the extrinsic failed to dispatch in the finalized
+// block. Failures to determine the outcome are logged and otherwise ignored, since the extrinsic
+// has already been finalized.
+func (co *Connection) checkDispatchResult(blockHash types.Hash, ext *types.Extrinsic) error {
+	err := co.FetchDispatchError(blockHash, ext)
+	var dispatchErr *DispatchError
+	switch {
+	case errors.As(err, &dispatchErr):
+		logger := log.WithFields(log.Fields{
+			"nonce":          nonce(ext),
+			"block":          blockHash.Hex(),
+			"extrinsicIndex": dispatchErr.ExtrinsicIndex,
+			"error":          dispatchErr.Error(),
+		})
+		if IsAlreadyDelivered(dispatchErr) {
+			logger.Info("Extrinsic failed to dispatch, it was already delivered by another relayer")
+		} else {
+			logger.Error("Extrinsic failed to dispatch")
+		}
+		return dispatchErr
+	case err != nil:
+		log.WithError(err).WithFields(log.Fields{
+			"nonce": nonce(ext),
+			"block": blockHash.Hex(),
+		}).Warn("Unable to determine dispatch result of extrinsic")
+	}
+	return nil
+}
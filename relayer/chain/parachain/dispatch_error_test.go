@@ -0,0 +1,102 @@
+package parachain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	assert "github.com/stretchr/testify/require"
+)
+
+func typeID(id uint64) types.Si1LookupTypeID {
+	return types.NewSi1LookupTypeIDFromUInt(id)
+}
+
+func field(id uint64) types.Si1Field {
+	return types.Si1Field{Type: typeID(id)}
+}
+
+// testDispatchErrorMetadata mirrors the shape of sp_runtime::DispatchError in a BridgeHub runtime
+func testDispatchErrorMetadata() *types.MetadataV14 {
+	return &types.MetadataV14{
+		Pallets: []types.PalletMetadataV14{
+			{
+				Name:      "EthereumBeaconClient",
+				Index:     82,
+				HasErrors: true,
+				Errors:    types.ErrorMetadataV14{Type: typeID(5)},
+			},
+		},
+		EfficientLookup: map[int64]*types.Si1Type{
+			0: {Def: types.Si1TypeDef{IsPrimitive: true, Primitive: types.Si1TypeDefPrimitive{Si0TypeDefPrimitive: types.IsU8}}},
+			1: {Def: types.Si1TypeDef{IsArray: true, Array: types.Si1TypeDefArray{Len: 4, Type: typeID(0)}}},
+			// ModuleError { index: u8, error: [u8; 4] }
+			2: {Def: types.Si1TypeDef{IsComposite: true, Composite: types.Si1TypeDefComposite{Fields: []types.Si1Field{field(0), field(1)}}}},
+			// TokenError
+			3: {Def: types.Si1TypeDef{IsVariant: true, Variant: types.Si1TypeDefVariant{Variants: []types.Si1Variant{
+				{Name: "FundsUnavailable", Index: 0},
+				{Name: "OnlyProvider", Index: 1},
+			}}}},
+			// DispatchError
+			4: {Def: types.Si1TypeDef{IsVariant: true, Variant: types.Si1TypeDefVariant{Variants: []types.Si1Variant{
+				{Name: "Other", Index: 0},
+				{Name: "BadOrigin", Index: 2},
+				{Name: "Module", Index: 3, Fields: []types.Si1Field{field(2)}},
+				{Name: "Token", Index: 7, Fields: []types.Si1Field{field(3)}},
+			}}}},
+			// EthereumBeaconClient errors
+			5: {Def: types.Si1TypeDef{IsVariant: true, Variant: types.Si1TypeDefVariant{Variants: []types.Si1Variant{
+				{Name: "SkippedSyncCommitteePeriod", Index: 0},
+				{Name: "InvalidSyncCommitteeMerkleProof", Index: 7},
+			}}}},
+		},
+	}
+}
+
+func TestDecodeDispatchError(t *testing.T) {
+	meta := testDispatchErrorMetadata()
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected DispatchError
+	}{
+		{"module", []byte{3, 82, 7, 0, 0, 0}, DispatchError{Pallet: "EthereumBeaconClient", Name: "InvalidSyncCommitteeMerkleProof"}},
+		{"bad origin", []byte{2}, DispatchError{Name: "BadOrigin"}},
+		{"token", []byte{7, 1}, DispatchError{Name: "Token::OnlyProvider"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTypeDecoder(meta, tt.data)
+			dispatchErr, err := d.decodeDispatchError(4)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, *dispatchErr)
+			assert.Equal(t, 0, d.reader.Len())
+		})
+	}
+}
+
+func TestDecodeDispatchErrorUnknownModuleError(t *testing.T) {
+	d := newTypeDecoder(testDispatchErrorMetadata(), []byte{3, 82, 9, 0, 0, 0})
+	_, err := d.decodeDispatchError(4)
+	assert.Error(t, err)
+}
+
+func TestIsDispatchError(t *testing.T) {
+	var err error = &DispatchError{Pallet: "EthereumBeaconClient", Name: "InvalidSyncCommitteeMerkleProof"}
+	wrapped := errors.Join(errors.New("submit update"), err)
+
+	assert.True(t, IsDispatchError(wrapped, "EthereumBeaconClient", "InvalidSyncCommitteeMerkleProof"))
+	assert.True(t, IsDispatchError(wrapped, "", "InvalidSyncCommitteeMerkleProof"))
+	assert.False(t, IsDispatchError(wrapped, "EthereumInboundQueue", "InvalidSyncCommitteeMerkleProof"))
+	assert.Equal(t, "EthereumBeaconClient::InvalidSyncCommitteeMerkleProof", err.Error())
+}
+
+func TestIsAlreadyDelivered(t *testing.T) {
+	assert.True(t, IsAlreadyDelivered(fmt.Errorf("submit message: %w", &DispatchError{Pallet: "EthereumInboundQueue", Name: "InvalidNonce"})))
+	assert.True(t, IsAlreadyDelivered(&DispatchError{Pallet: "EthereumBeaconClient", Name: "IrrelevantUpdate"}))
+	assert.False(t, IsAlreadyDelivered(&DispatchError{Pallet: "EthereumBeaconClient", Name: "InvalidNonce"}))
+	assert.False(t, IsAlreadyDelivered(errors.New("extrinsic removed from the transaction pool")))
+}
@@ -0,0 +1,164 @@
+// Copyright 2020 Snowfork
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package parachain
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+)
+
+// EventRecord is an event emitted in a block. The event fields are left SCALE encoded, to be
+// decoded by callers which know their types.
+type EventRecord struct {
+	Pallet string
+	Name   string
+	Fields [][]byte
+	// Whether the event was emitted while applying an extrinsic, and the index of the extrinsic
+	ApplyExtrinsic bool
+	ExtrinsicIndex uint32
+}
+
+// FetchEvents fetches and decodes all events emitted in a block.
+func (co *Connection) FetchEvents(blockHash types.Hash) ([]EventRecord, error) {
+	eventsKey, err := types.CreateStorageKey(co.Metadata(), "System", "Events", nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create storage key for System.Events: %w", err)
+	}
+
+	raw, err := co.API().RPC.State.GetStorageRaw(eventsKey, blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("fetch System.Events at block %v: %w", blockHash.Hex(), err)
+	}
+
+	events, err := decodeEvents(co.Metadata(), *raw)
+	if err != nil {
+		return nil, fmt.Errorf("decode System.Events at block %v: %w", blockHash.Hex(), err)
+	}
+
+	return events, nil
+}
+
+func decodeEvents(meta *types.Metadata, raw []byte) ([]EventRecord, error) {
+	d, recordType, count, err := newEventsDecoder(meta, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]EventRecord, 0, count)
+	for i := uint64(0); i < count; i++ {
+		var event *EventRecord
+		var phase []byte
+		for _, field := range recordType.Def.Composite.Fields {
+			switch string(field.Name) {
+			case "event":
+				event, err = d.decodeEvent(field.Type.Int64())
+			case "phase":
+				phase, err = d.capture(field.Type.Int64())
+			default:
+				err = d.skip(field.Type.Int64())
+			}
+			if err != nil {
+				return nil, fmt.Errorf("decode event record %d: %w", i, err)
+			}
+		}
+		if event != nil {
+			// Phase::ApplyExtrinsic(u32) is the first variant
+			if len(phase) == 5 && phase[0] == 0 {
+				event.ApplyExtrinsic = true
+				event.ExtrinsicIndex = binary.LittleEndian.Uint32(phase[1:])
+			}
+			events = append(events, *event)
+		}
+	}
+
+	return events, nil
+}
+
+// newEventsDecoder creates a decoder for the System.Events storage value, returning the type of
+// the event records and their count.
+func newEventsDecoder(meta *types.Metadata, raw []byte) (*typeDecoder, *types.Si1Type, uint64, error) {
+	if meta.Version != 14 {
+		return nil, nil, 0, fmt.Errorf("unsupported metadata version %d", meta.Version)
+	}
+
+	entry, err := meta.FindStorageEntryMetadata("System", "Events")
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("find storage entry System.Events: %w", err)
+	}
+	entryV14, ok := entry.(types.StorageEntryMetadataV14)
+	if !ok || !entryV14.Type.IsPlainType {
+		return nil, nil, 0, fmt.Errorf("storage entry System.Events is not a plain value")
+	}
+
+	d := newTypeDecoder(&meta.AsMetadataV14, raw)
+
+	vecType, err := d.lookup(entryV14.Type.AsPlainType.Int64())
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if !vecType.Def.IsSequence {
+		return nil, nil, 0, fmt.Errorf("System.Events is not a sequence")
+	}
+	recordType, err := d.lookup(vecType.Def.Sequence.Type.Int64())
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if !recordType.Def.IsComposite {
+		return nil, nil, 0, fmt.Errorf("event record is not a composite")
+	}
+
+	count, err := d.decoder.DecodeUintCompact()
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("decode event count: %w", err)
+	}
+
+	return d, recordType, count.Uint64(), nil
+}
+
+// decodeEvent decodes a RuntimeEvent into the pallet and event names, capturing the encoded fields
+func (d *typeDecoder) decodeEvent(id int64) (*EventRecord, error) {
+	runtimeEvent, err := d.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	if !runtimeEvent.Def.IsVariant {
+		return nil, fmt.Errorf("runtime event is not a variant")
+	}
+
+	pallet, err := d.readVariant(runtimeEvent.Def.Variant)
+	if err != nil {
+		return nil, err
+	}
+	if len(pallet.Fields) != 1 {
+		return nil, fmt.Errorf("event of pallet %s has %d fields", pallet.Name, len(pallet.Fields))
+	}
+
+	palletEvent, err := d.lookup(pallet.Fields[0].Type.Int64())
+	if err != nil {
+		return nil, err
+	}
+	if !palletEvent.Def.IsVariant {
+		return nil, fmt.Errorf("event of pallet %s is not a variant", pallet.Name)
+	}
+	event, err := d.readVariant(palletEvent.Def.Variant)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([][]byte, len(event.Fields))
+	for i, field := range event.Fields {
+		fields[i], err = d.capture(field.Type.Int64())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &EventRecord{
+		Pallet: string(pallet.Name),
+		Name:   string(event.Name),
+		Fields: fields,
+	}, nil
+}
@@ -0,0 +1,171 @@
+package parachain
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+)
+
+// Weight is the two dimensional weight of a call
+type Weight struct {
+	RefTime   types.UCompact
+	ProofSize types.UCompact
+}
+
+// RuntimeDispatchInfo is returned by the TransactionPaymentCallApi runtime API
+type RuntimeDispatchInfo struct {
+	Weight     Weight
+	Class      types.U8
+	PartialFee types.U128
+}
+
+// ChannelInfo is a channel registered in the EthereumSystem pallet
+type ChannelInfo struct {
+	AgentID types.H256
+	ParaID  types.U32
+}
+
+// PricingParameters are the pricing parameters of the EthereumSystem pallet
+type PricingParameters struct {
+	ExchangeRate types.U128
+	Rewards      struct {
+		Local  types.U128
+		Remote types.U256
+	}
+	FeePerGas  types.U256
+	Multiplier types.U128
+}
+
+// QueryCallFee returns the inclusion fee of a call, excluding tips, for an extrinsic of the given
+// encoded length.
+func (co *Connection) QueryCallFee(call types.Call, length uint32) (*big.Int, error) {
+	info, err := co.QueryCallInfo(call, length)
+	if err != nil {
+		return nil, err
+	}
+	return info.PartialFee.Int, nil
+}
+
+// QueryCallInfo returns the weight and inclusion fee of a call, for an extrinsic of the given
+// encoded length.
+func (co *Connection) QueryCallInfo(call types.Call, length uint32) (RuntimeDispatchInfo, error) {
+	params, err := types.EncodeToHexString(struct {
+		Call   types.Call
+		Length types.U32
+	}{call, types.U32(length)})
+	if err != nil {
+		return RuntimeDispatchInfo{}, fmt.Errorf("encode params: %w", err)
+	}
+
+	var infoHex string
+	err = co.API().Client.Call(&infoHex, "state_call", "TransactionPaymentCallApi_query_call_info", params)
+	if err != nil {
+		return RuntimeDispatchInfo{}, fmt.Errorf("call RPC TransactionPaymentCallApi_query_call_info: %w", err)
+	}
+
+	var info RuntimeDispatchInfo
+	err = types.DecodeFromHexString(infoHex, &info)
+	if err != nil {
+		return RuntimeDispatchInfo{}, fmt.Errorf("decode dispatch info: %w", err)
+	}
+
+	return info, nil
+}
+
+// QueryWeightToFee returns the fee charged for the given weight, without the fee multiplier
+func (co *Connection) QueryWeightToFee(weight Weight) (*big.Int, error) {
+	return co.queryFee("TransactionPaymentCallApi_query_weight_to_fee", weight)
+}
+
+// QueryLengthToFee returns the fee charged for the given encoded length
+func (co *Connection) QueryLengthToFee(length uint32) (*big.Int, error) {
+	return co.queryFee("TransactionPaymentCallApi_query_length_to_fee", types.U32(length))
+}
+
+func (co *Connection) queryFee(method string, arg interface{}) (*big.Int, error) {
+	params, err := types.EncodeToHexString(arg)
+	if err != nil {
+		return nil, fmt.Errorf("encode params: %w", err)
+	}
+
+	var feeHex string
+	err = co.API().Client.Call(&feeHex, "state_call", method, params)
+	if err != nil {
+		return nil, fmt.Errorf("call RPC %s: %w", method, err)
+	}
+
+	var fee types.U128
+	err = types.DecodeFromHexString(feeHex, &fee)
+	if err != nil {
+		return nil, fmt.Errorf("decode fee: %w", err)
+	}
+
+	return fee.Int, nil
+}
+
+// GetChannel returns the channel registered in the EthereumSystem pallet for the channel ID
+func (co *Connection) GetChannel(channelID types.H256) (ChannelInfo, error) {
+	key, err := types.CreateStorageKey(co.Metadata(), "EthereumSystem", "Channels", channelID[:], nil)
+	if err != nil {
+		return ChannelInfo{}, fmt.Errorf("create storage key for EthereumSystem.Channels(%v): %w", channelID.Hex(), err)
+	}
+
+	var channel ChannelInfo
+	ok, err := co.API().RPC.State.GetStorageLatest(key, &channel)
+	if err != nil {
+		return ChannelInfo{}, fmt.Errorf("fetch storage EthereumSystem.Channels(%v): %w", channelID.Hex(), err)
+	}
+	if !ok {
+		return ChannelInfo{}, fmt.Errorf("channel %v is not registered", channelID.Hex())
+	}
+
+	return channel, nil
+}
+
+// GetPricingParameters returns the pricing parameters of the EthereumSystem pallet
+func (co *Connection) GetPricingParameters() (PricingParameters, error) {
+	key, err := types.CreateStorageKey(co.Metadata(), "EthereumSystem", "PricingParameters", nil, nil)
+	if err != nil {
+		return PricingParameters{}, fmt.Errorf("create storage key for EthereumSystem.PricingParameters: %w", err)
+	}
+
+	var params PricingParameters
+	ok, err := co.API().RPC.State.GetStorageLatest(key, &params)
+	if err != nil {
+		return PricingParameters{}, fmt.Errorf("fetch storage EthereumSystem.PricingParameters: %w", err)
+	}
+	if !ok {
+		return PricingParameters{}, fmt.Errorf("pricing parameters are not set")
+	}
+
+	return params, nil
+}
+
+// GetFreeBalance returns the free balance of an account
+func (co *Connection) GetFreeBalance(accountID types.AccountID) (*big.Int, error) {
+	key, err := types.CreateStorageKey(co.Metadata(), "System", "Account", accountID[:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("create storage key for System.Account(%v): %w", types.HexEncodeToString(accountID[:]), err)
+	}
+
+	var accountInfo types.AccountInfo
+	ok, err := co.API().RPC.State.GetStorageLatest(key, &accountInfo)
+	if err != nil {
+		return nil, fmt.Errorf("fetch storage System.Account(%v): %w", types.HexEncodeToString(accountID[:]), err)
+	}
+	if !ok {
+		return new(big.Int), nil
+	}
+
+	return accountInfo.Data.Free.Int, nil
+}
+
+// SiblingSovereignAccount returns the sovereign account of a sibling parachain
+func SiblingSovereignAccount(paraID uint32) types.AccountID {
+	var account types.AccountID
+	copy(account[:], "sibl")
+	binary.LittleEndian.PutUint32(account[4:8], paraID)
+	return account
+}
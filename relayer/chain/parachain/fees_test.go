@@ -0,0 +1,25 @@
+package parachain
+
+import (
+	"testing"
+
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSiblingSovereignAccount(t *testing.T) {
+	account := SiblingSovereignAccount(1000)
+	assert.Equal(t, "0x7369626ce8030000000000000000000000000000000000000000000000000000", types.HexEncodeToString(account[:]))
+}
+
+func TestDecodeRuntimeDispatchInfo(t *testing.T) {
+	// ref_time 1_000_000, proof_size 4096, class Normal, partial_fee 123_456_789
+	var info RuntimeDispatchInfo
+	err := types.DecodeFromHexString("0x02093d0001400015cd5b07000000000000000000000000", &info)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1000000), info.Weight.RefTime.Int64())
+	assert.Equal(t, int64(4096), info.Weight.ProofSize.Int64())
+	assert.Equal(t, int64(123456789), info.PartialFee.Int64())
+}
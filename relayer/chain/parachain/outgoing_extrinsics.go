@@ -22,6 +22,10 @@ type ExtrinsicPool struct {
 
 type OnFinalized func(types.Hash) error
 
+// OnFailed is called with the reason an extrinsic was not dispatched successfully, which is either a DispatchError,
+// the extrinsic being removed from the transaction pool or the status subscription failing.
+type OnFailed func(error)
+
 func NewExtrinsicPool(eg *errgroup.Group, conn *Connection, maxWatchedExtrinsics int64) *ExtrinsicPool {
 	ep := ExtrinsicPool{
 		conn: conn,
@@ -35,6 +39,7 @@ func (ep *ExtrinsicPool) WaitForSubmitAndWatch(
 	ctx context.Context,
 	ext *types.Extrinsic,
 	onFinalized OnFinalized,
+	onFailed OnFailed,
 ) error {
 	err := ep.sem.Acquire(ctx, 1)
 	if err != nil {
@@ -56,7 +61,8 @@ func (ep *ExtrinsicPool) WaitForSubmitAndWatch(
 				return nil
 			case err := <-sub.Err():
 				log.WithError(err).WithField("nonce", nonce(ext)).Error("Subscription failed for extrinsic status")
-				return err
+				onFailed(err)
+				return nil
 			case status := <-sub.Chan():
 				// https://github.com/paritytech/substrate/blob/29aca981db5e8bf8b5538e6c7920ded917013ef3/primitives/transaction-pool/src/pool.rs#L56-L127
 				if status.IsDropped || status.IsInvalid || status.IsUsurped || status.IsFinalityTimeout {
@@ -65,9 +71,16 @@ func (ep *ExtrinsicPool) WaitForSubmitAndWatch(
 						"nonce":  nonce(ext),
 						"reason": reason(&status),
 					}).Error("Extrinsic removed from the transaction pool")
-					return fmt.Errorf("extrinsic removed from the transaction pool")
+					onFailed(fmt.Errorf("extrinsic removed from the transaction pool: %s", reason(&status)))
+					return nil
 				} else if status.IsFinalized {
 					sub.Unsubscribe()
+					// A failed extrinsic only affects the caller, so it is not returned to the errgroup
+					err := ep.conn.checkDispatchResult(status.AsFinalized, ext)
+					if err != nil {
+						onFailed(err)
+						return nil
+					}
 					return onFinalized(status.AsFinalized)
 				}
 			}
@@ -0,0 +1,153 @@
+package parachain
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/snowfork/go-substrate-rpc-client/v4/scale"
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	beaconscale "github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer/scale"
+)
+
+// PendingExtrinsicSigners returns the signers of the extrinsics in the transaction pool which deliver
+// the message with the given event log to EthereumInboundQueue.submit, either directly or in a
+// Utility batch. The size of the sync committee is needed to decode the EthereumBeaconClient.submit
+// calls batched with messages. Unsigned extrinsics and extrinsics which cannot be decoded are ignored.
+func (co *Connection) PendingExtrinsicSigners(eventLog EventLog, syncCommitteeSize uint64) ([]types.AccountID, error) {
+	calls, err := newPendingCalls(co.Metadata(), syncCommitteeSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var extrinsics []string
+	err = co.API().Client.Call(&extrinsics, "author_pendingExtrinsics")
+	if err != nil {
+		return nil, fmt.Errorf("call RPC author_pendingExtrinsics: %w", err)
+	}
+
+	var signers []types.AccountID
+	for _, extrinsic := range extrinsics {
+		var ext types.Extrinsic
+		err = types.DecodeFromHexString(extrinsic, &ext)
+		if err != nil || !ext.IsSigned() || !ext.Signature.Signer.IsID {
+			continue
+		}
+		if calls.delivers(ext.Method, eventLog) {
+			signers = append(signers, ext.Signature.Signer.AsID)
+		}
+	}
+
+	return signers, nil
+}
+
+// pendingCalls decodes the calls of pending extrinsics which deliver messages
+type pendingCalls struct {
+	submit            types.CallIndex
+	beaconSubmit      *types.CallIndex
+	batches           []types.CallIndex
+	syncCommitteeSize uint64
+}
+
+func newPendingCalls(meta *types.Metadata, syncCommitteeSize uint64) (*pendingCalls, error) {
+	submit, err := meta.FindCallIndex("EthereumInboundQueue.submit")
+	if err != nil {
+		return nil, fmt.Errorf("find call EthereumInboundQueue.submit: %w", err)
+	}
+
+	calls := pendingCalls{
+		submit:            submit,
+		syncCommitteeSize: syncCommitteeSize,
+	}
+	beaconSubmit, err := meta.FindCallIndex("EthereumBeaconClient.submit")
+	if err == nil {
+		calls.beaconSubmit = &beaconSubmit
+	}
+	for _, batch := range []string{"Utility.batch", "Utility.batch_all", "Utility.force_batch"} {
+		index, err := meta.FindCallIndex(batch)
+		if err == nil {
+			calls.batches = append(calls.batches, index)
+		}
+	}
+
+	return &calls, nil
+}
+
+// delivers returns whether a call submits a message with the given event log
+func (p *pendingCalls) delivers(call types.Call, eventLog EventLog) bool {
+	decoder := scale.NewDecoder(bytes.NewReader(call.Args))
+	delivered, err := p.decodeCall(decoder, call.CallIndex, eventLog)
+	return err == nil && delivered
+}
+
+// decodeCall decodes the arguments of a call and returns whether it submits a message with the
+// given event log. Calls in a batch are decoded until the message is found, so a batch with a call
+// which cannot be decoded before the message is not matched.
+func (p *pendingCalls) decodeCall(decoder *scale.Decoder, index types.CallIndex, eventLog EventLog) (bool, error) {
+	if index == p.submit {
+		return decodeSubmittedMessage(decoder, eventLog)
+	}
+	if p.beaconSubmit != nil && index == *p.beaconSubmit {
+		_, err := beaconscale.DecodeUpdatePayload(*decoder, p.syncCommitteeSize)
+		return false, err
+	}
+	for _, batch := range p.batches {
+		if index != batch {
+			continue
+		}
+
+		count, err := decoder.DecodeUintCompact()
+		if err != nil {
+			return false, fmt.Errorf("decode batch length: %w", err)
+		}
+		for i := uint64(0); i < count.Uint64(); i++ {
+			var inner types.CallIndex
+			err = decoder.Decode(&inner)
+			if err != nil {
+				return false, fmt.Errorf("decode batched call: %w", err)
+			}
+			delivered, err := p.decodeCall(decoder, inner, eventLog)
+			if err != nil || delivered {
+				return delivered, err
+			}
+		}
+		return false, nil
+	}
+
+	return false, fmt.Errorf("call %d.%d cannot be decoded", index.SectionIndex, index.MethodIndex)
+}
+
+// decodeSubmittedMessage decodes the message of an EthereumInboundQueue.submit call and returns
+// whether it has the given event log
+func decodeSubmittedMessage(decoder *scale.Decoder, eventLog EventLog) (bool, error) {
+	var submitted EventLog
+	err := decoder.Decode(&submitted)
+	if err != nil {
+		return false, fmt.Errorf("decode event log: %w", err)
+	}
+	var receiptProof ProofData
+	err = decoder.Decode(&receiptProof)
+	if err != nil {
+		return false, fmt.Errorf("decode receipt proof: %w", err)
+	}
+	var executionProof beaconscale.HeaderUpdatePayload
+	err = decoder.Decode(&executionProof)
+	if err != nil {
+		return false, fmt.Errorf("decode execution proof: %w", err)
+	}
+
+	return submitted.Address == eventLog.Address &&
+		equalTopics(submitted.Topics, eventLog.Topics) &&
+		bytes.Equal(submitted.Data, eventLog.Data), nil
+}
+
+func equalTopics(a, b []types.H256) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+package parachain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer/scale"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testSubmit       = types.CallIndex{SectionIndex: 80, MethodIndex: 0}
+	testBeaconSubmit = types.CallIndex{SectionIndex: 81, MethodIndex: 1}
+	testBatchAll     = types.CallIndex{SectionIndex: 40, MethodIndex: 2}
+)
+
+func testPendingCalls() *pendingCalls {
+	return &pendingCalls{
+		submit:            testSubmit,
+		beaconSubmit:      &testBeaconSubmit,
+		batches:           []types.CallIndex{testBatchAll},
+		syncCommitteeSize: 32,
+	}
+}
+
+func testMessage(data byte) Message {
+	return Message{
+		EventLog: EventLog{
+			Address: types.H160{1},
+			Topics:  []types.H256{{2}, {3}},
+			Data:    types.Bytes{data},
+		},
+		Proof: Proof{
+			ReceiptProof: &ProofData{Keys: []types.Bytes{{4}}, Values: []types.Bytes{{5}}},
+			ExecutionProof: scale.HeaderUpdatePayload{
+				AncestryProof:   scale.OptionAncestryProof{HasValue: true, Value: scale.AncestryProof{HeaderBranch: []types.H256{{6}}}},
+				ExecutionHeader: scale.VersionedExecutionPayloadHeader{Deneb: &scale.ExecutionPayloadHeaderDeneb{LogsBloom: []byte{7}, BaseFeePerGas: types.NewU256(*big.NewInt(1))}},
+				ExecutionBranch: []types.H256{{8}},
+			},
+		},
+	}
+}
+
+func testCall(t *testing.T, index types.CallIndex, args ...interface{}) types.Call {
+	call := types.Call{CallIndex: index}
+	for _, arg := range args {
+		encoded, err := types.EncodeToBytes(arg)
+		require.NoError(t, err)
+		call.Args = append(call.Args, encoded...)
+	}
+	return call
+}
+
+func testBatch(t *testing.T, calls ...types.Call) types.Call {
+	return testCall(t, testBatchAll, calls)
+}
+
+func TestPendingCallsDeliversSubmittedMessage(t *testing.T) {
+	p := testPendingCalls()
+	message := testMessage(1)
+
+	assert.True(t, p.delivers(testCall(t, testSubmit, message), message.EventLog))
+	assert.False(t, p.delivers(testCall(t, testSubmit, testMessage(2)), message.EventLog))
+	// The event log must be the message of a submit call, not any argument which encodes it
+	assert.False(t, p.delivers(testCall(t, types.CallIndex{SectionIndex: 90}, message), message.EventLog))
+}
+
+func TestPendingCallsDeliversBatchedMessage(t *testing.T) {
+	p := testPendingCalls()
+	message := testMessage(1)
+
+	pubkeys := make([][48]byte, 32)
+	pubkeys[0][0] = 9
+	update := scale.UpdatePayload{
+		SyncAggregate: scale.SyncAggregate{SyncCommitteeBits: []byte{1, 2, 3, 4}},
+		NextSyncCommitteeUpdate: scale.OptionNextSyncCommitteeUpdatePayload{
+			HasValue: true,
+			Value: scale.NextSyncCommitteeUpdatePayload{
+				NextSyncCommittee:       scale.SyncCommittee{Pubkeys: pubkeys},
+				NextSyncCommitteeBranch: []types.H256{{10}},
+			},
+		},
+		FinalityBranch:   []types.H256{{11}},
+		BlockRootsBranch: []types.H256{{12}},
+	}
+
+	batch := testBatch(t,
+		testCall(t, testBeaconSubmit, update),
+		testCall(t, testSubmit, testMessage(2)),
+		testCall(t, testSubmit, message),
+	)
+	assert.True(t, p.delivers(batch, message.EventLog))
+	assert.False(t, p.delivers(batch, testMessage(3).EventLog))
+
+	// Calls after an unknown call cannot be decoded
+	batch = testBatch(t,
+		testCall(t, types.CallIndex{SectionIndex: 90}, types.U32(1)),
+		testCall(t, testSubmit, message),
+	)
+	assert.False(t, p.delivers(batch, message.EventLog))
+}
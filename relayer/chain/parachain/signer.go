@@ -0,0 +1,67 @@
+package parachain
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/snowfork/go-substrate-rpc-client/v4/scale"
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+)
+
+// FetchExtrinsicSigner returns the signer of an extrinsic in a block, and false if the extrinsic
+// is unsigned or not signed by an account ID.
+func (co *Connection) FetchExtrinsicSigner(blockHash types.Hash, index uint32) (types.AccountID, bool, error) {
+	var block struct {
+		Block struct {
+			Extrinsics []string `json:"extrinsics"`
+		} `json:"block"`
+	}
+	err := co.API().Client.Call(&block, "chain_getBlock", blockHash.Hex())
+	if err != nil {
+		return types.AccountID{}, false, fmt.Errorf("fetch block %v: %w", blockHash.Hex(), err)
+	}
+	if int(index) >= len(block.Block.Extrinsics) {
+		return types.AccountID{}, false, fmt.Errorf("block %v has no extrinsic %d", blockHash.Hex(), index)
+	}
+
+	encoded, err := types.HexDecodeString(block.Block.Extrinsics[index])
+	if err != nil {
+		return types.AccountID{}, false, fmt.Errorf("decode extrinsic %d: %w", index, err)
+	}
+
+	return decodeExtrinsicSigner(encoded)
+}
+
+// decodeExtrinsicSigner decodes the signer of an encoded extrinsic without decoding its signed
+// extensions, which differ between runtimes.
+func decodeExtrinsicSigner(encoded []byte) (types.AccountID, bool, error) {
+	decoder := scale.NewDecoder(bytes.NewReader(encoded))
+
+	_, err := decoder.DecodeUintCompact()
+	if err != nil {
+		return types.AccountID{}, false, fmt.Errorf("decode extrinsic length: %w", err)
+	}
+	version, err := decoder.ReadOneByte()
+	if err != nil {
+		return types.AccountID{}, false, fmt.Errorf("decode extrinsic version: %w", err)
+	}
+	if version&types.ExtrinsicBitSigned == 0 {
+		return types.AccountID{}, false, nil
+	}
+
+	// MultiAddress::Id is the first variant
+	addressKind, err := decoder.ReadOneByte()
+	if err != nil {
+		return types.AccountID{}, false, fmt.Errorf("decode signer: %w", err)
+	}
+	if addressKind != 0 {
+		return types.AccountID{}, false, nil
+	}
+
+	var signer types.AccountID
+	err = decoder.Read(signer[:])
+	if err != nil {
+		return types.AccountID{}, false, fmt.Errorf("decode signer: %w", err)
+	}
+	return signer, true, nil
+}
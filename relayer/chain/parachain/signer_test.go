@@ -0,0 +1,35 @@
+package parachain
+
+import (
+	"testing"
+
+	"github.com/snowfork/go-substrate-rpc-client/v4/signature"
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeExtrinsicSigner(t *testing.T) {
+	ext := types.NewExtrinsic(types.Call{CallIndex: types.CallIndex{SectionIndex: 80, MethodIndex: 0}, Args: []byte{1, 2, 3}})
+
+	unsigned, err := types.EncodeToBytes(ext)
+	require.NoError(t, err)
+	_, ok, err := decodeExtrinsicSigner(unsigned)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	err = ext.Sign(signature.TestKeyringPairAlice, types.SignatureOptions{
+		Era:         types.ExtrinsicEra{IsImmortalEra: true},
+		Nonce:       types.NewUCompactFromUInt(1),
+		SpecVersion: 1,
+		Tip:         types.NewUCompactFromUInt(0),
+	})
+	require.NoError(t, err)
+	signed, err := types.EncodeToBytes(ext)
+	require.NoError(t, err)
+
+	signer, ok, err := decodeExtrinsicSigner(signed)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, signature.TestKeyringPairAlice.PublicKey, signer[:])
+}
@@ -26,6 +26,8 @@ type ChainWriter interface {
 	GetFinalizedHeaderStateByBlockRoot(blockRoot types.H256) (state.FinalizedHeader, error)
 	GetLastFinalizedStateIndex() (types.U32, error)
 	GetFinalizedBeaconRootByIndex(index uint32) (types.H256, error)
+	GetSyncCommittee(storage string) (scale.SyncCommitteePrepared, error)
+	GetValidatorsRoot() (common.Hash, error)
 }
 
 type ParachainWriter struct {
@@ -101,8 +103,15 @@ func (wr *ParachainWriter) WriteToParachainAndRateLimit(ctx context.Context, ext
 	}
 
 	callback := func(h types.Hash) error { return nil }
+	onFailed := func(err error) {
+		if IsAlreadyDelivered(err) {
+			log.WithError(err).WithField("extrinsic", extrinsicName).Info("extrinsic was already delivered by another relayer")
+			return
+		}
+		log.WithError(err).WithField("extrinsic", extrinsicName).Warn("rate limited extrinsic failed")
+	}
 
-	err = wr.pool.WaitForSubmitAndWatch(ctx, extI, callback)
+	err = wr.pool.WaitForSubmitAndWatch(ctx, extI, callback, onFailed)
 	if err != nil {
 		return err
 	}
@@ -116,7 +125,7 @@ func (wr *ParachainWriter) WriteToParachainAndWatch(ctx context.Context, extrins
 	wr.mu.Lock()
 	defer wr.mu.Unlock()
 
-	sub, err := wr.writeToParachain(ctx, extrinsicName, payload...)
+	sub, ext, err := wr.writeToParachain(ctx, extrinsicName, payload...)
 	if err != nil {
 		return err
 	}
@@ -134,7 +143,7 @@ func (wr *ParachainWriter) WriteToParachainAndWatch(ctx context.Context, extrins
 			if status.IsFinalized {
 				log.WithFields(log.Fields{
 					"extrinsic": extrinsicName, "block": status.AsFinalized}).Debug("extrinsic finalized")
-				return nil
+				return wr.conn.checkDispatchResult(status.AsFinalized, ext)
 			}
 		case err = <-sub.Err():
 			return err
@@ -240,21 +249,22 @@ func (wr *ParachainWriter) GetFinalizedHeaderStateByBlockRoot(blockRoot types.H2
 	return state.FinalizedHeader{
 		BeaconSlot:      uint64(compactBeaconState.Slot.Int64()),
 		BeaconBlockRoot: common.Hash(blockRoot),
+		BlockRootsRoot:  common.Hash(compactBeaconState.BlockRootsRoot),
 	}, nil
 }
 
-func (wr *ParachainWriter) writeToParachain(ctx context.Context, extrinsicName string, payload ...interface{}) (*author.ExtrinsicStatusSubscription, error) {
+func (wr *ParachainWriter) writeToParachain(ctx context.Context, extrinsicName string, payload ...interface{}) (*author.ExtrinsicStatusSubscription, *types.Extrinsic, error) {
 	extI, err := wr.prepExtrinstic(ctx, extrinsicName, payload...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	sub, err := wr.conn.API().RPC.Author.SubmitAndWatchExtrinsic(*extI)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return sub, nil
+	return sub, extI, nil
 }
 
 func (wr *ParachainWriter) queryAccountNonce() (uint32, error) {
@@ -405,3 +415,32 @@ func (wr *ParachainWriter) GetFinalizedBeaconRootByIndex(index uint32) (types.H2
 
 	return beaconRoot, nil
 }
+
+// GetSyncCommittee returns the sync committee held by EthereumBeaconClient in the storage item, either
+// CurrentSyncCommittee or NextSyncCommittee. A committee which is not set has a zero root.
+func (wr *ParachainWriter) GetSyncCommittee(storage string) (scale.SyncCommitteePrepared, error) {
+	key, err := types.CreateStorageKey(wr.conn.Metadata(), "EthereumBeaconClient", storage, nil, nil)
+	if err != nil {
+		return scale.SyncCommitteePrepared{}, fmt.Errorf("create storage key for %s: %w", storage, err)
+	}
+
+	data, err := wr.conn.API().RPC.State.GetStorageRawLatest(key)
+	if err != nil {
+		return scale.SyncCommitteePrepared{}, fmt.Errorf("get storage for %s (err): %w", storage, err)
+	}
+	if data == nil || len(*data) == 0 {
+		return scale.SyncCommitteePrepared{}, nil
+	}
+
+	committee, err := scale.DecodeSyncCommitteePrepared(*data)
+	if err != nil {
+		return scale.SyncCommitteePrepared{}, fmt.Errorf("decode %s: %w", storage, err)
+	}
+
+	return committee, nil
+}
+
+// GetValidatorsRoot returns the genesis validators root with which EthereumBeaconClient verifies signatures
+func (wr *ParachainWriter) GetValidatorsRoot() (common.Hash, error) {
+	return wr.getHashFromParachain("EthereumBeaconClient", "ValidatorsRoot")
+}
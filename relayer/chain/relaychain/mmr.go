@@ -0,0 +1,90 @@
+package relaychain
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/snowfork/go-substrate-rpc-client/v4/client"
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// GenerateMMRBatchProofResponse contains the response of mmr_generateProof for multiple leaves
+type GenerateMMRBatchProofResponse struct {
+	BlockHash types.H256
+	Leaves    []types.MMRLeaf
+	Proof     types.MultiMMRProof
+}
+
+// UnmarshalJSON fills d with the JSON encoded RPC response given by bz
+func (d *GenerateMMRBatchProofResponse) UnmarshalJSON(bz []byte) error {
+	var tmp struct {
+		BlockHash string `json:"blockHash"`
+		Leaves    string `json:"leaves"`
+		Proof     string `json:"proof"`
+	}
+	if err := json.Unmarshal(bz, &tmp); err != nil {
+		return err
+	}
+	err := types.DecodeFromHexString(tmp.BlockHash, &d.BlockHash)
+	if err != nil {
+		return fmt.Errorf("decode block hash: %w", err)
+	}
+
+	var encodedLeaves []types.MMREncodableOpaqueLeaf
+	err = types.DecodeFromHexString(tmp.Leaves, &encodedLeaves)
+	if err != nil {
+		return fmt.Errorf("decode leaves: %w", err)
+	}
+	d.Leaves = make([]types.MMRLeaf, len(encodedLeaves))
+	for i, encodedLeaf := range encodedLeaves {
+		err = types.DecodeFromBytes(encodedLeaf, &d.Leaves[i])
+		if err != nil {
+			return fmt.Errorf("decode leaf %d: %w", i, err)
+		}
+	}
+
+	err = types.DecodeFromHexString(tmp.Proof, &d.Proof)
+	if err != nil {
+		return fmt.Errorf("decode proof: %w", err)
+	}
+	if len(d.Proof.LeafIndices) != len(d.Leaves) {
+		return fmt.Errorf("proof has %d leaf indices for %d leaves", len(d.Proof.LeafIndices), len(d.Leaves))
+	}
+
+	return nil
+}
+
+// GenerateProofForBlocks generates a single MMR proof for the leaves of multiple blocks, against
+// the MMR at the given BEEFY block. Like GenerateProofForBlock, blockNumbers are the numbers passed
+// to mmr_generateProof.
+func (co *Connection) GenerateProofForBlocks(
+	blockNumbers []uint64,
+	latestBeefyBlockHash types.Hash,
+) (GenerateMMRBatchProofResponse, error) {
+	log.WithFields(log.Fields{
+		"blockNumbers": blockNumbers,
+		"blockHash":    latestBeefyBlockHash.Hex(),
+	}).Debug("Getting MMR Leaves for blocks...")
+
+	blocks := make([]uint32, len(blockNumbers))
+	for i, blockNumber := range blockNumbers {
+		blocks[i] = uint32(blockNumber)
+	}
+
+	var proofResponse GenerateMMRBatchProofResponse
+	err := client.CallWithBlockHash(co.API().Client, &proofResponse, "mmr_generateProof", &latestBeefyBlockHash, blocks, nil)
+	if err != nil {
+		return GenerateMMRBatchProofResponse{}, err
+	}
+
+	log.WithFields(log.Fields{
+		"BlockHash":   proofResponse.BlockHash.Hex(),
+		"LeafIndices": proofResponse.Proof.LeafIndices,
+		"LeafCount":   proofResponse.Proof.LeafCount,
+		"ItemCount":   len(proofResponse.Proof.Items),
+	}).Debug("Generated MMR batch proof")
+
+	return proofResponse, nil
+}
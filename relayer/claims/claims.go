@@ -0,0 +1,371 @@
+package claims
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/snowfork/snowbridge/relayer/config"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultLeaseDuration = 300
+	claimsPath           = "/v1/claims"
+	peerTimeout          = 2 * time.Second
+)
+
+// A Claim announces that a relayer is delivering a nonce on a channel, for the duration of its lease
+type Claim struct {
+	ChannelID common.Hash `json:"channelId"`
+	Nonce     uint64      `json:"nonce"`
+	Relayer   string      `json:"relayer"`
+	// Remaining duration of the lease, in seconds. Leases are relative so that clocks of peers
+	// need not be synchronised.
+	Lease uint64 `json:"lease"`
+}
+
+type claimKey struct {
+	channelID common.Hash
+	nonce     uint64
+}
+
+type lease struct {
+	relayer string
+	expiry  time.Time
+	// Set once this relayer won the claim and started delivering the nonce. Such a lease is kept
+	// until it expires or is released, whatever the tie-break.
+	delivering bool
+}
+
+// Claims exchanges claims on nonces with peers over HTTP, so that relayers back off from nonces
+// which another relayer is delivering. Claims are advisory: unreachable peers are ignored, and
+// relayers fall back to the schedule once a lease expires. Peers authenticate with a shared token.
+type Claims struct {
+	enabled       bool
+	listen        string
+	peers         []string
+	leaseDuration time.Duration
+	token         string
+	self          string
+	client        *http.Client
+	now           func() time.Time
+
+	mu     sync.Mutex
+	leases map[claimKey]lease
+}
+
+// New creates the claims of the relayer self, which identifies the relayer to its peers
+func New(config config.ClaimsConfig, self string) *Claims {
+	leaseDuration := config.LeaseDuration
+	if leaseDuration == 0 {
+		leaseDuration = defaultLeaseDuration
+	}
+
+	return &Claims{
+		enabled:       config.Enabled,
+		listen:        config.Listen,
+		peers:         config.Peers,
+		leaseDuration: time.Duration(leaseDuration) * time.Second,
+		token:         config.Token,
+		self:          self,
+		client:        &http.Client{Timeout: peerTimeout},
+		now:           time.Now,
+		leases:        make(map[claimKey]lease),
+	}
+}
+
+// Start serves the claims endpoint for peers
+func (c *Claims) Start(ctx context.Context, eg *errgroup.Group) {
+	if !c.enabled {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(claimsPath, c)
+	server := &http.Server{
+		Addr:              c.listen,
+		Handler:           mux,
+		ReadHeaderTimeout: peerTimeout,
+	}
+
+	eg.Go(func() error {
+		log.WithField("address", c.listen).Info("Serving claims endpoint")
+		err := server.ListenAndServe()
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("serve claims endpoint: %w", err)
+	})
+	eg.Go(func() error {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), peerTimeout)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	})
+}
+
+// TryClaim claims a nonce and announces the claim to peers. It returns false if another relayer
+// holds a lease on the nonce, in which case the relayer should back off until the lease expires.
+// Once TryClaim returns true, the relayer should deliver the nonce and then Release it.
+func (c *Claims) TryClaim(ctx context.Context, channelID [32]byte, nonce uint64) bool {
+	if !c.enabled {
+		return true
+	}
+
+	claim := Claim{
+		ChannelID: channelID,
+		Nonce:     nonce,
+		Relayer:   c.self,
+		Lease:     uint64(c.leaseDuration / time.Second),
+	}
+	holder, ok := c.accept(claim)
+	if !ok {
+		log.WithFields(log.Fields{
+			"nonce":   nonce,
+			"relayer": holder.relayer,
+			"expiry":  holder.expiry,
+		}).Info("Nonce is claimed by another relayer")
+		return false
+	}
+
+	// Announce the claim to all peers, backing off if a peer knows of a winning claim
+	var wg sync.WaitGroup
+	conflicts := make(chan Claim, len(c.peers))
+	for _, peer := range c.peers {
+		peer := peer
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conflict, err := c.announce(ctx, http.MethodPost, peer, claim)
+			if err != nil {
+				log.WithError(err).WithField("peer", peer).Debug("Failed to announce claim")
+				return
+			}
+			if conflict != nil {
+				conflicts <- *conflict
+			}
+		}()
+	}
+	wg.Wait()
+	close(conflicts)
+
+	won := true
+	for conflict := range conflicts {
+		c.record(conflict)
+		won = false
+	}
+	if won {
+		// A claim of another relayer may have won while the claim was announced
+		won = c.startDelivering(claimKey{claim.ChannelID, claim.Nonce})
+	}
+	if !won {
+		log.WithField("nonce", nonce).Info("Claim conflicts with the claim of another relayer")
+	}
+	return won
+}
+
+// Release gives up the claim of this relayer on a nonce once it was delivered, or failed to be
+// delivered, so that peers need not wait for the lease to expire.
+func (c *Claims) Release(ctx context.Context, channelID [32]byte, nonce uint64) {
+	if !c.enabled {
+		return
+	}
+
+	claim := Claim{
+		ChannelID: channelID,
+		Nonce:     nonce,
+		Relayer:   c.self,
+	}
+	if !c.release(claim) {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, peer := range c.peers {
+		peer := peer
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.announce(ctx, http.MethodDelete, peer, claim)
+			if err != nil {
+				log.WithError(err).WithField("peer", peer).Debug("Failed to release claim")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// announce sends a claim, or the release of a claim, to a peer, returning the claim of the peer
+// if it conflicts
+func (c *Claims) announce(ctx context.Context, method, peer string, claim Claim) (*Claim, error) {
+	body, err := json.Marshal(claim)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, peer+claimsPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil, nil
+	case http.StatusConflict:
+		var conflict Claim
+		err = json.NewDecoder(resp.Body).Decode(&conflict)
+		if err != nil {
+			return nil, fmt.Errorf("decode conflicting claim: %w", err)
+		}
+		return &conflict, nil
+	default:
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+}
+
+// ServeHTTP receives the claims of peers, and the releases of their claims. A claim conflicting
+// with an active lease of another relayer is rejected with the lease, if the holder of the lease
+// wins the tie-break or is this relayer delivering the nonce. Requests without the shared token
+// are rejected.
+func (c *Claims) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if !c.authenticated(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var claim Claim
+	err := json.NewDecoder(r.Body).Decode(&claim)
+	if err != nil || claim.Relayer == "" || claim.Relayer == c.self {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		c.release(claim)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	holder, ok := c.accept(claim)
+	if ok {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	_ = json.NewEncoder(w).Encode(Claim{
+		ChannelID: claim.ChannelID,
+		Nonce:     claim.Nonce,
+		Relayer:   holder.relayer,
+		Lease:     uint64(holder.expiry.Sub(c.now()).Seconds()),
+	})
+}
+
+func (c *Claims) authenticated(r *http.Request) bool {
+	expected := []byte("Bearer " + c.token)
+	return subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) == 1
+}
+
+// accept records a claim unless another relayer holds an active lease on the nonce which wins
+// the tie-break, returning that lease. Of two relayers claiming the same nonce, the relayer with
+// the lower identifier wins. Claims of this relayer never win against the lease of another relayer,
+// and claims of other relayers never win against a lease of this relayer delivering the nonce.
+func (c *Claims) accept(claim Claim) (lease, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for key, l := range c.leases {
+		if !now.Before(l.expiry) {
+			delete(c.leases, key)
+		}
+	}
+
+	key := claimKey{claim.ChannelID, claim.Nonce}
+	existing, ok := c.leases[key]
+	if ok && existing.relayer != claim.Relayer {
+		if claim.Relayer == c.self || existing.delivering || existing.relayer < claim.Relayer {
+			return existing, false
+		}
+	}
+
+	c.leases[key] = lease{
+		relayer:    claim.Relayer,
+		expiry:     now.Add(c.leaseOf(claim)),
+		delivering: ok && existing.relayer == claim.Relayer && existing.delivering,
+	}
+	return lease{}, true
+}
+
+// record records the claim of another relayer which won against a claim of this relayer
+func (c *Claims) record(claim Claim) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.leases[claimKey{claim.ChannelID, claim.Nonce}] = lease{
+		relayer: claim.Relayer,
+		expiry:  c.now().Add(c.leaseOf(claim)),
+	}
+}
+
+// startDelivering marks the lease of this relayer on a nonce as being delivered, returning false
+// if the lease is no longer held by this relayer
+func (c *Claims) startDelivering(key claimKey) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l, ok := c.leases[key]
+	if !ok || l.relayer != c.self {
+		return false
+	}
+	l.delivering = true
+	c.leases[key] = l
+	return true
+}
+
+// release removes the lease of the relayer of a claim, returning false if the relayer holds no
+// lease on the nonce
+func (c *Claims) release(claim Claim) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := claimKey{claim.ChannelID, claim.Nonce}
+	l, ok := c.leases[key]
+	if !ok || l.relayer != claim.Relayer {
+		return false
+	}
+	delete(c.leases, key)
+	return true
+}
+
+// leaseOf returns the duration of the lease of a claim, capped to the lease duration of this relayer
+func (c *Claims) leaseOf(claim Claim) time.Duration {
+	lease := time.Duration(claim.Lease) * time.Second
+	if lease > c.leaseDuration {
+		return c.leaseDuration
+	}
+	return lease
+}
@@ -0,0 +1,128 @@
+package claims
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/snowfork/snowbridge/relayer/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClaims(self string, peers ...string) (*Claims, *httptest.Server) {
+	c := New(config.ClaimsConfig{Enabled: true, Peers: peers, LeaseDuration: 60, Token: "secret"}, self)
+	return c, httptest.NewServer(c)
+}
+
+func TestTryClaim(t *testing.T) {
+	alice, aliceServer := newTestClaims("alice")
+	defer aliceServer.Close()
+	bob, bobServer := newTestClaims("bob", aliceServer.URL)
+	defer bobServer.Close()
+	alice.peers = []string{bobServer.URL}
+
+	ctx := context.Background()
+	channelID := [32]byte{1}
+
+	assert.True(t, bob.TryClaim(ctx, channelID, 1))
+	// alice learned of the claim of bob
+	assert.False(t, alice.TryClaim(ctx, channelID, 1))
+	// Claims of a relayer can be renewed
+	assert.True(t, bob.TryClaim(ctx, channelID, 1))
+
+	assert.True(t, alice.TryClaim(ctx, channelID, 2))
+	assert.False(t, bob.TryClaim(ctx, channelID, 2))
+	assert.True(t, alice.TryClaim(ctx, [32]byte{2}, 1))
+}
+
+func TestTryClaimConflict(t *testing.T) {
+	// alice and bob claimed the same nonce before learning of each other's claims
+	alice, aliceServer := newTestClaims("alice")
+	defer aliceServer.Close()
+	bob, bobServer := newTestClaims("bob", aliceServer.URL)
+	defer bobServer.Close()
+
+	ctx := context.Background()
+	channelID := [32]byte{1}
+
+	assert.True(t, alice.TryClaim(ctx, channelID, 1))
+	// alice wins the tie-break
+	assert.False(t, bob.TryClaim(ctx, channelID, 1))
+	assert.False(t, bob.TryClaim(ctx, channelID, 1))
+}
+
+func TestTryClaimUnreachablePeer(t *testing.T) {
+	alice := New(config.ClaimsConfig{Enabled: true, Peers: []string{"http://127.0.0.1:1"}}, "alice")
+
+	assert.True(t, alice.TryClaim(context.Background(), [32]byte{1}, 1))
+}
+
+func TestTryClaimDisabled(t *testing.T) {
+	alice := New(config.ClaimsConfig{}, "alice")
+	alice.accept(Claim{ChannelID: [32]byte{1}, Nonce: 1, Relayer: "bob", Lease: 60})
+
+	assert.True(t, alice.TryClaim(context.Background(), [32]byte{1}, 1))
+}
+
+func TestTryClaimLateConflict(t *testing.T) {
+	// bob is delivering a nonce when alice, who wins the tie-break, claims it
+	alice, aliceServer := newTestClaims("alice")
+	defer aliceServer.Close()
+	bob, bobServer := newTestClaims("bob")
+	defer bobServer.Close()
+	alice.peers = []string{bobServer.URL}
+
+	ctx := context.Background()
+	channelID := [32]byte{1}
+
+	assert.True(t, bob.TryClaim(ctx, channelID, 1))
+	assert.False(t, alice.TryClaim(ctx, channelID, 1))
+	// bob keeps its lease
+	assert.True(t, bob.TryClaim(ctx, channelID, 1))
+}
+
+func TestRelease(t *testing.T) {
+	alice, aliceServer := newTestClaims("alice")
+	defer aliceServer.Close()
+	bob, bobServer := newTestClaims("bob", aliceServer.URL)
+	defer bobServer.Close()
+	alice.peers = []string{bobServer.URL}
+
+	ctx := context.Background()
+	channelID := [32]byte{1}
+
+	assert.True(t, bob.TryClaim(ctx, channelID, 1))
+	assert.False(t, alice.TryClaim(ctx, channelID, 1))
+
+	bob.Release(ctx, channelID, 1)
+	assert.True(t, alice.TryClaim(ctx, channelID, 1))
+}
+
+func TestAcceptCapsLease(t *testing.T) {
+	alice, aliceServer := newTestClaims("alice")
+	defer aliceServer.Close()
+	now := time.Unix(0, 0)
+	alice.now = func() time.Time { return now }
+
+	_, ok := alice.accept(Claim{ChannelID: [32]byte{1}, Nonce: 1, Relayer: "bob", Lease: 1 << 40})
+	assert.True(t, ok)
+
+	now = now.Add(61 * time.Second)
+	assert.True(t, alice.TryClaim(context.Background(), [32]byte{1}, 1))
+}
+
+func TestServeHTTPRejectsUnauthenticatedPeers(t *testing.T) {
+	alice, aliceServer := newTestClaims("alice")
+	defer aliceServer.Close()
+
+	body := []byte(`{"channelId":"0x0100000000000000000000000000000000000000000000000000000000000000","nonce":1,"relayer":"bob","lease":60}`)
+	resp, err := http.Post(aliceServer.URL, "application/json", bytes.NewReader(body))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	assert.True(t, alice.TryClaim(context.Background(), [32]byte{1}, 1))
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/snowfork/snowbridge/relayer/chain/ethereum"
+	"github.com/spf13/cobra"
+)
+
+func decodeRevertCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "decode-revert",
+		Short: "Decode revert data returned by the Gateway or BeefyClient contracts",
+		Args:  cobra.ExactArgs(1),
+		RunE:  DecodeRevertFn,
+	}
+
+	return cmd
+}
+
+func DecodeRevertFn(_ *cobra.Command, args []string) error {
+	data, err := hexutil.Decode(args[0])
+	if err != nil {
+		return fmt.Errorf("decode revert data hex: %w", err)
+	}
+
+	revertErr, err := ethereum.DecodeRevert(data)
+	if err != nil {
+		return fmt.Errorf("decode revert: %w", err)
+	}
+
+	fmt.Println(revertErr.Error())
+
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer/api"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/protocol"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/state"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/store"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -92,13 +93,20 @@ func importBeaconState(cmd *cobra.Command, _ []string) error {
 		return fmt.Errorf("read finalized state data from file: %w", err)
 	}
 
-	afterDenebFork := (conf.Source.Beacon.Spec.DenebForkEpoch + 1) * 32
+	attestedStateSlot, err := state.BeaconStateSlot(attestedData)
+	if err != nil {
+		return fmt.Errorf("read attested beacon state slot: %w", err)
+	}
+	finalizedStateSlot, err := state.BeaconStateSlot(finalizedData)
+	if err != nil {
+		return fmt.Errorf("read finalized beacon state slot: %w", err)
+	}
 
-	attestedState, err := syncer.UnmarshalBeaconState(afterDenebFork, attestedData)
+	attestedState, err := syncer.UnmarshalBeaconState(attestedStateSlot, attestedData)
 	if err != nil {
 		return fmt.Errorf("unmarshal attested beacon state: %w", err)
 	}
-	finalizedState, err := syncer.UnmarshalBeaconState(afterDenebFork, finalizedData)
+	finalizedState, err := syncer.UnmarshalBeaconState(finalizedStateSlot, finalizedData)
 	if err != nil {
 		return fmt.Errorf("unmarshal finalized beacon state: %w", err)
 	}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/config"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/era"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/protocol"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/state"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/store"
+
+	_ "github.com/mattn/go-sqlite3"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func importEraCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import-era",
+		Short: "Import the beacon states of a directory of era files into the datastore.",
+		Args:  cobra.ExactArgs(0),
+		RunE:  importEra,
+	}
+
+	cmd.Flags().String("config", "", "path to the beacon config file to use")
+	err := cmd.MarkFlagRequired("config")
+	if err != nil {
+		return nil
+	}
+	cmd.Flags().String("era-dir", "", "path to the directory of era files")
+	err = cmd.MarkFlagRequired("era-dir")
+	if err != nil {
+		return nil
+	}
+	cmd.Flags().Uint64("from", 0, "Optional first slot of the beacon states to import")
+	cmd.Flags().Uint64("to", 0, "Optional last slot of the beacon states to import, all later states are imported if 0")
+
+	return cmd
+}
+
+func importEra(cmd *cobra.Command, _ []string) error {
+	configFile, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return err
+	}
+
+	viper.SetConfigFile(configFile)
+	if err := viper.ReadInConfig(); err != nil {
+		return err
+	}
+	var conf config.Config
+	err = viper.Unmarshal(&conf)
+	if err != nil {
+		return err
+	}
+
+	eraDir, err := cmd.Flags().GetString("era-dir")
+	if err != nil {
+		return err
+	}
+
+	from, err := cmd.Flags().GetUint64("from")
+	if err != nil {
+		return err
+	}
+	to, err := cmd.Flags().GetUint64("to")
+	if err != nil {
+		return err
+	}
+	if to != 0 && to < from {
+		return fmt.Errorf("last slot %d is before the first slot %d", to, from)
+	}
+
+	p := protocol.New(conf.Source.Beacon.Spec, conf.Sink.Parachain.HeaderRedundancy)
+	archive, err := era.NewArchive(eraDir, p.SlotsPerHistoricalRoot)
+	if err != nil {
+		return fmt.Errorf("open era archive: %w", err)
+	}
+
+	store := store.New(conf.Source.Beacon.DataStore.Location, conf.Source.Beacon.DataStore.MaxEntries, *p)
+	err = store.Connect()
+	if err != nil {
+		return fmt.Errorf("connect to database: %w", err)
+	}
+	defer store.Close()
+
+	for _, eraNumber := range archive.Eras() {
+		// The state of an era is at its first slot
+		stateSlot := eraNumber * p.SlotsPerHistoricalRoot
+		if stateSlot < from || (to != 0 && stateSlot > to) {
+			continue
+		}
+		slot, err := importEraState(archive, &store, eraNumber)
+		if err != nil {
+			return fmt.Errorf("import era %d: %w", eraNumber, err)
+		}
+		log.WithFields(log.Fields{"era": eraNumber, "slot": slot}).Info("imported beacon state from era file")
+	}
+
+	return nil
+}
+
+func importEraState(archive *era.Archive, store *store.Store, eraNumber uint64) (uint64, error) {
+	f, err := archive.Open(eraNumber)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	data, err := f.State()
+	if err != nil {
+		return 0, fmt.Errorf("read beacon state: %w", err)
+	}
+	slot, err := state.BeaconStateSlot(data)
+	if err != nil {
+		return 0, fmt.Errorf("read beacon state slot: %w", err)
+	}
+	if slot != f.StateSlot() {
+		return 0, fmt.Errorf("beacon state has slot %d, but is indexed at slot %d", slot, f.StateSlot())
+	}
+
+	err = store.WriteArchiveState(slot, data)
+	if err != nil {
+		return 0, fmt.Errorf("write beacon state: %w", err)
+	}
+
+	return slot, nil
+}
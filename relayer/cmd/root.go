@@ -36,7 +36,10 @@ func init() {
 	rootCmd.AddCommand(storeBeaconStateCmd())
 	rootCmd.AddCommand(importBeaconStateCmd())
 	rootCmd.AddCommand(listBeaconStateCmd())
+	rootCmd.AddCommand(importEraCmd())
 	rootCmd.AddCommand(syncBeefyCommitmentCmd())
+	rootCmd.AddCommand(decodeRevertCmd())
+	rootCmd.AddCommand(verifyParachainProofCmd())
 }
 
 func Execute() {
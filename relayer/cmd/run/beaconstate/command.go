@@ -0,0 +1,93 @@
+package beaconstate
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+	"github.com/snowfork/snowbridge/relayer/relays/beaconstate"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/sync/errgroup"
+)
+
+var configFile string
+
+func Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "beacon-state-service",
+		Short: "Start the service which stores beacon states and serves them to relays",
+		Args:  cobra.ExactArgs(0),
+		RunE:  run,
+	}
+
+	cmd.Flags().StringVar(&configFile, "config", "", "Path to configuration file")
+	cmd.MarkFlagRequired("config")
+
+	return cmd
+}
+
+func run(_ *cobra.Command, _ []string) error {
+	log.SetOutput(logrus.WithFields(logrus.Fields{"logger": "stdlib"}).WriterLevel(logrus.InfoLevel))
+	logrus.SetLevel(logrus.DebugLevel)
+
+	logrus.Info("Beacon state service started up")
+
+	viper.SetConfigFile(configFile)
+	if err := viper.ReadInConfig(); err != nil {
+		return err
+	}
+
+	var config beaconstate.Config
+	err := viper.UnmarshalExact(&config)
+	if err != nil {
+		return err
+	}
+
+	err = config.Validate()
+	if err != nil {
+		logrus.WithError(err).Fatal("Configuration file validation failed")
+		return err
+	}
+
+	service := beaconstate.New(&config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	eg, ctx := errgroup.WithContext(ctx)
+
+	// Ensure clean termination upon SIGINT, SIGTERM
+	eg.Go(func() error {
+		notify := make(chan os.Signal, 1)
+		signal.Notify(notify, syscall.SIGINT, syscall.SIGTERM)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case sig := <-notify:
+			logrus.WithField("signal", sig.String()).Info("Received signal")
+			cancel()
+		}
+
+		return nil
+	})
+
+	err = service.Start(ctx, eg)
+	if err != nil {
+		logrus.WithError(err).Fatal("Unhandled error")
+		cancel()
+		return err
+	}
+
+	err = eg.Wait()
+	if err != nil {
+		logrus.WithError(err).Fatal("Unhandled error")
+		return err
+	}
+
+	return nil
+}
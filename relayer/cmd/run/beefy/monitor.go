@@ -0,0 +1,97 @@
+package beefy
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+	"github.com/snowfork/snowbridge/relayer/relays/beefy"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	monitorConfigFile string
+	monitorFromBlock  uint64
+)
+
+func MonitorCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "beefy-monitor",
+		Short: "Monitor commitments accepted by the BeefyClient for invalid or conflicting commitments",
+		Args:  cobra.ExactArgs(0),
+		RunE:  runMonitor,
+	}
+
+	cmd.Flags().StringVar(&monitorConfigFile, "config", "", "Path to configuration file")
+	cmd.MarkFlagRequired("config")
+
+	cmd.Flags().Uint64Var(&monitorFromBlock, "from-block", 0, "Ethereum block from which to check historical commitments")
+
+	return cmd
+}
+
+func runMonitor(_ *cobra.Command, _ []string) error {
+	log.SetOutput(logrus.WithFields(logrus.Fields{"logger": "stdlib"}).WriterLevel(logrus.InfoLevel))
+	logrus.SetLevel(logrus.DebugLevel)
+
+	viper.SetConfigFile(monitorConfigFile)
+	if err := viper.ReadInConfig(); err != nil {
+		return err
+	}
+
+	var config beefy.MonitorConfig
+	err := viper.UnmarshalExact(&config)
+	if err != nil {
+		return err
+	}
+
+	err = config.Validate()
+	if err != nil {
+		return fmt.Errorf("config file validation failed: %w", err)
+	}
+
+	monitor, err := beefy.NewMonitor(&config)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	eg, ctx := errgroup.WithContext(ctx)
+
+	// Ensure clean termination upon SIGINT, SIGTERM
+	eg.Go(func() error {
+		notify := make(chan os.Signal, 1)
+		signal.Notify(notify, syscall.SIGINT, syscall.SIGTERM)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case sig := <-notify:
+			logrus.WithField("signal", sig.String()).Info("Received signal")
+			cancel()
+		}
+
+		return nil
+	})
+
+	err = monitor.Start(ctx, eg, monitorFromBlock)
+	if err != nil {
+		logrus.WithError(err).Fatal("Unhandled error")
+		cancel()
+		return err
+	}
+
+	err = eg.Wait()
+	if err != nil {
+		logrus.WithError(err).Fatal("Unhandled error")
+		return err
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package run
 
 import (
 	"github.com/snowfork/snowbridge/relayer/cmd/run/beacon"
+	"github.com/snowfork/snowbridge/relayer/cmd/run/beaconstate"
 	"github.com/snowfork/snowbridge/relayer/cmd/run/beefy"
 	"github.com/snowfork/snowbridge/relayer/cmd/run/execution"
 	"github.com/snowfork/snowbridge/relayer/cmd/run/parachain"
@@ -16,8 +17,10 @@ func Command() *cobra.Command {
 	}
 
 	cmd.AddCommand(beefy.Command())
+	cmd.AddCommand(beefy.MonitorCommand())
 	cmd.AddCommand(parachain.Command())
 	cmd.AddCommand(beacon.Command())
+	cmd.AddCommand(beaconstate.Command())
 	cmd.AddCommand(execution.Command())
 
 	return cmd
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	log "github.com/sirupsen/logrus"
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"github.com/snowfork/snowbridge/relayer/contracts"
+	"github.com/snowfork/snowbridge/relayer/relays/parachain"
+	"github.com/spf13/cobra"
+)
+
+func verifyParachainProofCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify-parachain-proof",
+		Short: "Verify the proofs of a Gateway.submitV1 transaction against the BEEFY light client",
+		Args:  cobra.ExactArgs(0),
+		RunE:  VerifyParachainProofFn,
+	}
+
+	cmd.Flags().StringP("url", "u", "", "Ethereum URL")
+	cmd.MarkFlagRequired("url")
+
+	cmd.Flags().String("tx-hash", "", "Hash of the Gateway.submitV1 transaction")
+	cmd.MarkFlagRequired("tx-hash")
+
+	cmd.Flags().String("beefy-client", "", "Address of the BeefyClient contract")
+	cmd.MarkFlagRequired("beefy-client")
+
+	cmd.Flags().Uint32("parachain-id", 0, "The parachain id of BridgeHub")
+	cmd.MarkFlagRequired("parachain-id")
+
+	cmd.Flags().Uint64(
+		"block-number",
+		0,
+		"Ethereum block at which to read the latest MMR root. Defaults to the block before the transaction was included, or the latest block if it is pending.",
+	)
+	cmd.Flags().BytesHex("mmr-root", []byte{}, "MMR root to verify against, instead of reading it from the BeefyClient")
+
+	return cmd
+}
+
+func VerifyParachainProofFn(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+
+	url, _ := cmd.Flags().GetString("url")
+	txHash, _ := cmd.Flags().GetString("tx-hash")
+	beefyClientAddress, _ := cmd.Flags().GetString("beefy-client")
+	paraID, _ := cmd.Flags().GetUint32("parachain-id")
+	blockNumber, _ := cmd.Flags().GetUint64("block-number")
+	mmrRootHex, _ := cmd.Flags().GetBytesHex("mmr-root")
+
+	client, err := ethclient.DialContext(ctx, url)
+	if err != nil {
+		return fmt.Errorf("connect to ethereum: %w", err)
+	}
+	defer client.Close()
+
+	tx, _, err := client.TransactionByHash(ctx, common.HexToHash(txHash))
+	if err != nil {
+		return fmt.Errorf("fetch transaction: %w", err)
+	}
+
+	message, leafProof, proof, err := decodeSubmitV1(tx.Data())
+	if err != nil {
+		return err
+	}
+
+	var mmrRoot types.H256
+	if len(mmrRootHex) > 0 {
+		if len(mmrRootHex) != 32 {
+			return errors.New("incorrect MMR root length")
+		}
+		copy(mmrRoot[:], mmrRootHex)
+	} else {
+		callOpts := bind.CallOpts{Context: ctx}
+		if blockNumber != 0 {
+			callOpts.BlockNumber = new(big.Int).SetUint64(blockNumber)
+		} else {
+			receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+			if err != nil && !errors.Is(err, ethereum.NotFound) {
+				return fmt.Errorf("fetch transaction receipt: %w", err)
+			}
+			if receipt != nil {
+				callOpts.BlockNumber = new(big.Int).Sub(receipt.BlockNumber, big.NewInt(1))
+			}
+		}
+
+		beefyClient, err := contracts.NewBeefyClient(common.HexToAddress(beefyClientAddress), client)
+		if err != nil {
+			return err
+		}
+		mmrRoot, err = beefyClient.LatestMMRRoot(&callOpts)
+		if err != nil {
+			return fmt.Errorf("fetch BeefyClient.latestMMRRoot: %w", err)
+		}
+		log.WithFields(log.Fields{
+			"blockNumber":   callOpts.BlockNumber,
+			"latestMMRRoot": mmrRoot.Hex(),
+		}).Info("Fetched MMR root of BeefyClient")
+	}
+
+	err = parachain.VerifyMessage(message, leafProof, proof, paraID, mmrRoot)
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"channelID": common.Hash(message.ChannelID).Hex(),
+		"nonce":     message.Nonce,
+		"mmrRoot":   mmrRoot.Hex(),
+	}).Info("Message proof is valid")
+
+	return nil
+}
+
+func decodeSubmitV1(data []byte) (contracts.InboundMessage, [][32]byte, *contracts.VerificationProof, error) {
+	parsed, err := contracts.GatewayMetaData.GetAbi()
+	if err != nil {
+		return contracts.InboundMessage{}, nil, nil, fmt.Errorf("parse Gateway ABI: %w", err)
+	}
+
+	if len(data) < 4 {
+		return contracts.InboundMessage{}, nil, nil, errors.New("transaction has no call data")
+	}
+	method, err := parsed.MethodById(data[:4])
+	if err != nil || method.Name != "submitV1" {
+		return contracts.InboundMessage{}, nil, nil, errors.New("transaction is not a call to Gateway.submitV1")
+	}
+
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return contracts.InboundMessage{}, nil, nil, fmt.Errorf("unpack Gateway.submitV1 arguments: %w", err)
+	}
+
+	message := *abi.ConvertType(args[0], new(contracts.InboundMessage)).(*contracts.InboundMessage)
+	leafProof := *abi.ConvertType(args[1], new([][32]byte)).(*[][32]byte)
+	proof := abi.ConvertType(args[2], new(contracts.VerificationProof)).(*contracts.VerificationProof)
+
+	return message, leafProof, proof, nil
+}
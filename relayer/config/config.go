@@ -1,6 +1,9 @@
 package config
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 type PolkadotConfig struct {
 	Endpoint string `mapstructure:"endpoint"`
@@ -53,3 +56,95 @@ func (o OFACConfig) Validate() error {
 	}
 	return nil
 }
+
+type ProfitabilityConfig struct {
+	// Defer messages whose estimated delivery cost exceeds their reward
+	Enabled bool `mapstructure:"enabled"`
+	// Minimum profit, in the smallest unit of the sink chain's native token, for a message to be
+	// relayed before its deadline
+	MinProfit uint64 `mapstructure:"min-profit"`
+	// Time (in seconds) after which a deferred message is relayed regardless of its profitability
+	Deadline uint64 `mapstructure:"deadline"`
+	// File to which decisions are appended as JSON lines. Decisions are only logged if unset.
+	AuditLog string `mapstructure:"audit-log"`
+}
+
+func (p ProfitabilityConfig) Validate() error {
+	if p.Enabled && p.Deadline == 0 {
+		return errors.New("profitability is enabled but no [deadline] set")
+	}
+	return nil
+}
+
+type MembershipConfig struct {
+	// Source of the relayer set, one of "file", "http" or "contract". The relayer set is
+	// given by the static schedule if unset.
+	Source string `mapstructure:"source"`
+	// File listing the accounts of the relayers as hex, one per line
+	File string `mapstructure:"file"`
+	// URL of a registry returning the accounts of the relayers as a JSON array of hex strings
+	URL string `mapstructure:"url"`
+	// Address of an allowlist contract on Ethereum exposing `relayers() returns (bytes32[])`
+	Contract string `mapstructure:"contract"`
+	// Interval (in seconds) at which the relayer set is refreshed, defaults to 60
+	RefreshInterval uint64 `mapstructure:"refresh-interval"`
+	// Number of consecutive turns a relayer may miss before it is left out of the rotation,
+	// defaults to 3
+	MaxMissedTurns uint64 `mapstructure:"max-missed-turns"`
+}
+
+func (m MembershipConfig) Validate() error {
+	switch m.Source {
+	case "":
+	case "file":
+		if m.File == "" {
+			return errors.New("membership source is file but no [file] set")
+		}
+	case "http":
+		if m.URL == "" {
+			return errors.New("membership source is http but no [url] set")
+		}
+	case "contract":
+		if m.Contract == "" {
+			return errors.New("membership source is contract but no [contract] set")
+		}
+	default:
+		return fmt.Errorf("unknown membership [source] %q", m.Source)
+	}
+	return nil
+}
+
+type ClaimsConfig struct {
+	// Announce the nonces this relayer is delivering to peers, and back off from nonces claimed
+	// by peers
+	Enabled bool `mapstructure:"enabled"`
+	// Address on which claims of peers are received, e.g. ":8090"
+	Listen string `mapstructure:"listen"`
+	// Base URLs of the claim endpoints of peers, e.g. "http://relayer-1:8090"
+	Peers []string `mapstructure:"peers"`
+	// Time (in seconds) for which a claim is held, defaults to 300. Leases announced by peers are
+	// capped to this duration.
+	LeaseDuration uint64 `mapstructure:"lease-duration"`
+	// Token shared by all peers, authenticating the claims they exchange
+	Token string `mapstructure:"token"`
+}
+
+func (c ClaimsConfig) Validate() error {
+	if c.Enabled && c.Listen == "" {
+		return errors.New("claims are enabled but no [listen] address set")
+	}
+	if c.Enabled && c.Token == "" {
+		return errors.New("claims are enabled but no [token] set")
+	}
+	return nil
+}
+
+type OperatingModeConfig struct {
+	// Interval (in seconds) at which the operating modes of the bridge are checked, defaults to 30
+	PollInterval uint64 `mapstructure:"poll-interval"`
+}
+
+type MetricsConfig struct {
+	// Address on which prometheus metrics are served, e.g. ":9100". Metrics are not served if unset.
+	Listen string `mapstructure:"listen"`
+}
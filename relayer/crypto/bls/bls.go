@@ -0,0 +1,78 @@
+package bls
+
+import (
+	"errors"
+	"fmt"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+)
+
+// Domain separation tag of the proof of possession scheme, which Ethereum consensus uses
+var dst = []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+
+var ErrNoPublicKeys = errors.New("no public keys to aggregate")
+
+// FastAggregateVerify checks that signature is the aggregate of the signatures over message by each of the public
+// keys, as defined by the FastAggregateVerify function of the Ethereum consensus specs.
+func FastAggregateVerify(publicKeys [][48]byte, message []byte, signature [96]byte) (bool, error) {
+	if len(publicKeys) == 0 {
+		return false, ErrNoPublicKeys
+	}
+
+	var aggregate bls12381.G1Jac
+	for i, publicKey := range publicKeys {
+		var point bls12381.G1Affine
+		_, err := point.SetBytes(publicKey[:])
+		if err != nil {
+			return false, fmt.Errorf("decode public key %d: %w", i, err)
+		}
+		if point.IsInfinity() {
+			return false, fmt.Errorf("decode public key %d: point at infinity", i)
+		}
+		aggregate.AddMixed(&point)
+	}
+	var aggregatePublicKey bls12381.G1Affine
+	aggregatePublicKey.FromJacobian(&aggregate)
+
+	var signaturePoint bls12381.G2Affine
+	_, err := signaturePoint.SetBytes(signature[:])
+	if err != nil {
+		return false, fmt.Errorf("decode signature: %w", err)
+	}
+
+	messagePoint, err := bls12381.HashToG2(message, dst)
+	if err != nil {
+		return false, fmt.Errorf("hash message to curve: %w", err)
+	}
+
+	// e(aggregatePublicKey, H(message)) == e(g1, signature)
+	_, _, generator, _ := bls12381.Generators()
+	var negatedGenerator bls12381.G1Affine
+	negatedGenerator.Neg(&generator)
+
+	return bls12381.PairingCheck(
+		[]bls12381.G1Affine{aggregatePublicKey, negatedGenerator},
+		[]bls12381.G2Affine{messagePoint, signaturePoint},
+	)
+}
+
+// CompressPublicKey converts a public key from its uncompressed form, in which EthereumBeaconClient stores sync
+// committees, to its compressed form.
+func CompressPublicKey(uncompressed [96]byte) ([48]byte, error) {
+	var point bls12381.G1Affine
+	_, err := point.SetBytes(uncompressed[:])
+	if err != nil {
+		return [48]byte{}, fmt.Errorf("decode public key: %w", err)
+	}
+	return point.Bytes(), nil
+}
+
+// DecompressPublicKey converts a public key from its compressed form to its uncompressed form
+func DecompressPublicKey(compressed [48]byte) ([96]byte, error) {
+	var point bls12381.G1Affine
+	_, err := point.SetBytes(compressed[:])
+	if err != nil {
+		return [96]byte{}, fmt.Errorf("decode public key: %w", err)
+	}
+	return point.RawBytes(), nil
+}
@@ -0,0 +1,75 @@
+package bls
+
+import (
+	"math/big"
+	"testing"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sign(t *testing.T, secretKeys []int64, message []byte) ([][48]byte, [96]byte) {
+	messagePoint, err := bls12381.HashToG2(message, dst)
+	require.NoError(t, err)
+	_, _, generator, _ := bls12381.Generators()
+
+	var publicKeys [][48]byte
+	var aggregate bls12381.G2Jac
+	for _, secretKey := range secretKeys {
+		var publicKey bls12381.G1Affine
+		publicKey.ScalarMultiplication(&generator, big.NewInt(secretKey))
+		publicKeys = append(publicKeys, publicKey.Bytes())
+
+		var signature bls12381.G2Affine
+		signature.ScalarMultiplication(&messagePoint, big.NewInt(secretKey))
+		aggregate.AddMixed(&signature)
+	}
+	var signature bls12381.G2Affine
+	signature.FromJacobian(&aggregate)
+
+	return publicKeys, signature.Bytes()
+}
+
+func TestFastAggregateVerify(t *testing.T) {
+	message := []byte("beacon block root")
+	publicKeys, signature := sign(t, []int64{3, 5, 7}, message)
+
+	valid, err := FastAggregateVerify(publicKeys, message, signature)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	valid, err = FastAggregateVerify(publicKeys, []byte("another block root"), signature)
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	// A signature of a subset of the keys does not verify against all of them
+	_, partialSignature := sign(t, []int64{3, 5}, message)
+	valid, err = FastAggregateVerify(publicKeys, message, partialSignature)
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	_, err = FastAggregateVerify(nil, message, signature)
+	assert.ErrorIs(t, err, ErrNoPublicKeys)
+
+	var invalidKey [48]byte
+	invalidKey[0] = 0xff
+	_, err = FastAggregateVerify([][48]byte{invalidKey}, message, signature)
+	assert.Error(t, err)
+}
+
+func TestCompressPublicKey(t *testing.T) {
+	publicKeys, _ := sign(t, []int64{3}, []byte("beacon block root"))
+
+	uncompressed, err := DecompressPublicKey(publicKeys[0])
+	require.NoError(t, err)
+	assert.NotEqual(t, publicKeys[0][:], uncompressed[:48])
+
+	compressed, err := CompressPublicKey(uncompressed)
+	require.NoError(t, err)
+	assert.Equal(t, publicKeys[0], compressed)
+
+	uncompressed[95] ^= 0x01
+	_, err = CompressPublicKey(uncompressed)
+	assert.Error(t, err)
+}
@@ -0,0 +1,251 @@
+package merkle
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"github.com/snowfork/snowbridge/relayer/crypto/keccak"
+)
+
+type mmrNode struct {
+	position uint64
+	height   uint32
+	hash     types.H256
+}
+
+func mergeHashes(left, right types.H256) types.H256 {
+	return types.NewH256((&keccak.Keccak256{}).Hash(append(left[:], right[:]...)))
+}
+
+// ConvertToSimplifiedMMRProofs converts an MMR proof for multiple leaves, as generated by substrate
+// for several block numbers at once, into a SimplifiedMMRProof for each of the leaves. The proofs
+// are returned in the order of leafIndices.
+//
+// The multi-leaf proof only contains the nodes which cannot be computed from the proven leaves, so
+// all nodes on the paths from the leaves to their peaks are first recomputed, following the
+// verification algorithm of https://github.com/nervosnetwork/merkle-mountain-range. A single-leaf
+// proof is then assembled for each leaf and simplified with ConvertToSimplifiedMMRProof.
+func ConvertToSimplifiedMMRProofs(
+	blockhash types.H256,
+	leafIndices []uint64,
+	leaves []types.MMRLeaf,
+	leafCount uint64,
+	proofItems []types.H256,
+) ([]SimplifiedMMRProof, error) {
+	if len(leafIndices) == 0 {
+		return nil, fmt.Errorf("no leaves to prove")
+	}
+	if len(leafIndices) != len(leaves) {
+		return nil, fmt.Errorf("got %d leaf indices for %d leaves", len(leafIndices), len(leaves))
+	}
+
+	mmrSize := leafCountToMMRSize(leafCount)
+	peaks := getPeaks(mmrSize)
+
+	// Hashes of all known nodes, by position
+	nodes := make(map[uint64]types.H256)
+
+	leafNodes := make([]mmrNode, 0, len(leafIndices))
+	for i, leafIndex := range leafIndices {
+		if leafIndex >= leafCount {
+			return nil, fmt.Errorf("leaf index %d out of range for %d leaves", leafIndex, leafCount)
+		}
+		encodedLeaf, err := types.EncodeToBytes(leaves[i])
+		if err != nil {
+			return nil, fmt.Errorf("encode leaf %d: %w", leafIndex, err)
+		}
+		node := mmrNode{
+			position: leafIndexToPosition(leafIndex),
+			hash:     types.NewH256((&keccak.Keccak256{}).Hash(encodedLeaf)),
+		}
+		if _, ok := nodes[node.position]; ok {
+			continue
+		}
+		nodes[node.position] = node.hash
+		leafNodes = append(leafNodes, node)
+	}
+	sort.Slice(leafNodes, func(i, j int) bool { return leafNodes[i].position < leafNodes[j].position })
+
+	var proofItemPosition int
+	nextProofItem := func() (types.H256, bool) {
+		if proofItemPosition >= len(proofItems) {
+			return types.H256{}, false
+		}
+		item := proofItems[proofItemPosition]
+		proofItemPosition++
+		return item, true
+	}
+
+	// Hashes of the peaks. Peaks to the right of all proven leaves may be bagged into a single item,
+	// in which case the last entry is the bagged hash of the remaining peaks.
+	var peakHashes []types.H256
+	for _, peak := range peaks {
+		var peakLeaves []mmrNode
+		for len(leafNodes) > 0 && leafNodes[0].position <= peak {
+			peakLeaves = append(peakLeaves, leafNodes[0])
+			leafNodes = leafNodes[1:]
+		}
+
+		if len(peakLeaves) == 1 && peakLeaves[0].position == peak {
+			peakHashes = append(peakHashes, peakLeaves[0].hash)
+		} else if len(peakLeaves) == 0 {
+			item, ok := nextProofItem()
+			if !ok {
+				break
+			}
+			peakHashes = append(peakHashes, item)
+		} else {
+			peakHash, err := calculatePeakHash(peakLeaves, peak, nextProofItem, nodes)
+			if err != nil {
+				return nil, err
+			}
+			peakHashes = append(peakHashes, peakHash)
+		}
+	}
+	if len(leafNodes) > 0 {
+		return nil, fmt.Errorf("corrupted proof: leaves beyond the last peak")
+	}
+	if item, ok := nextProofItem(); ok {
+		peakHashes = append(peakHashes, item)
+	}
+	if proofItemPosition != len(proofItems) {
+		return nil, fmt.Errorf("corrupted proof: %d unused proof items", len(proofItems)-proofItemPosition)
+	}
+
+	proofs := make([]SimplifiedMMRProof, len(leafIndices))
+	for i, leafIndex := range leafIndices {
+		items, err := singleLeafProofItems(leafIndexToPosition(leafIndex), peaks, peakHashes, nodes)
+		if err != nil {
+			return nil, fmt.Errorf("proof for leaf %d: %w", leafIndex, err)
+		}
+		proofs[i], err = ConvertToSimplifiedMMRProof(blockhash, leafIndex, leaves[i], leafCount, items)
+		if err != nil {
+			return nil, fmt.Errorf("simplify proof for leaf %d: %w", leafIndex, err)
+		}
+	}
+
+	return proofs, nil
+}
+
+// calculatePeakHash computes the hash of a peak from the leaves below it, taking the siblings which
+// cannot be computed from the proof items. All computed nodes are recorded.
+func calculatePeakHash(
+	leaves []mmrNode,
+	peak uint64,
+	nextProofItem func() (types.H256, bool),
+	nodes map[uint64]types.H256,
+) (types.H256, error) {
+	queue := append([]mmrNode{}, leaves...)
+
+	sibling := func(position uint64) (types.H256, error) {
+		if len(queue) > 0 && queue[0].position == position {
+			node := queue[0]
+			queue = queue[1:]
+			return node.hash, nil
+		}
+		item, ok := nextProofItem()
+		if !ok {
+			return types.H256{}, fmt.Errorf("corrupted proof: missing sibling at position %d", position)
+		}
+		nodes[position] = item
+		return item, nil
+	}
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		if node.position == peak {
+			if len(queue) > 0 {
+				return types.H256{}, fmt.Errorf("corrupted proof: nodes left after reaching peak %d", peak)
+			}
+			return node.hash, nil
+		}
+
+		var parent mmrNode
+		if heightInTree(node.position+1) > node.height {
+			// node is a right sibling
+			siblingHash, err := sibling(node.position - siblingOffset(node.height))
+			if err != nil {
+				return types.H256{}, err
+			}
+			parent = mmrNode{
+				position: node.position + 1,
+				height:   node.height + 1,
+				hash:     mergeHashes(siblingHash, node.hash),
+			}
+		} else {
+			// node is a left sibling
+			siblingHash, err := sibling(node.position + siblingOffset(node.height))
+			if err != nil {
+				return types.H256{}, err
+			}
+			parent = mmrNode{
+				position: node.position + parentOffset(node.height),
+				height:   node.height + 1,
+				hash:     mergeHashes(node.hash, siblingHash),
+			}
+		}
+
+		if parent.position > peak {
+			return types.H256{}, fmt.Errorf("corrupted proof: node %d is above peak %d", parent.position, peak)
+		}
+		nodes[parent.position] = parent.hash
+		queue = append(queue, parent)
+	}
+
+	return types.H256{}, fmt.Errorf("corrupted proof: peak %d not reached", peak)
+}
+
+// singleLeafProofItems assembles the items of a single-leaf MMR proof: the peaks to the left of
+// the leaf, the path from the leaf to its peak and the bagged peaks to the right of the leaf.
+func singleLeafProofItems(
+	leafPosition uint64,
+	peaks []uint64,
+	peakHashes []types.H256,
+	nodes map[uint64]types.H256,
+) ([]types.H256, error) {
+	peakIndex := sort.Search(len(peaks), func(i int) bool { return peaks[i] >= leafPosition })
+	if peakIndex >= len(peakHashes) {
+		return nil, fmt.Errorf("peak of leaf at position %d is unknown", leafPosition)
+	}
+
+	var items []types.H256
+	items = append(items, peakHashes[:peakIndex]...)
+
+	position := leafPosition
+	var height uint32
+	for position != peaks[peakIndex] {
+		var siblingPosition uint64
+		if heightInTree(position+1) > height {
+			siblingPosition = position - siblingOffset(height)
+			position = position + 1
+		} else {
+			siblingPosition = position + siblingOffset(height)
+			position = position + parentOffset(height)
+		}
+		height++
+
+		siblingHash, ok := nodes[siblingPosition]
+		if !ok {
+			return nil, fmt.Errorf("missing node at position %d", siblingPosition)
+		}
+		items = append(items, siblingHash)
+	}
+
+	if peakIndex < len(peaks)-1 {
+		// Bag the peaks to the right, from right to left
+		rightPeaks := append([]types.H256{}, peakHashes[peakIndex+1:]...)
+		if len(rightPeaks) == 0 {
+			return nil, fmt.Errorf("peaks right of leaf at position %d are unknown", leafPosition)
+		}
+		for len(rightPeaks) > 1 {
+			right, left := rightPeaks[len(rightPeaks)-1], rightPeaks[len(rightPeaks)-2]
+			rightPeaks = append(rightPeaks[:len(rightPeaks)-2], mergeHashes(right, left))
+		}
+		items = append(items, rightPeaks[0])
+	}
+
+	return items, nil
+}
@@ -0,0 +1,198 @@
+package merkle
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"github.com/snowfork/snowbridge/relayer/crypto/keccak"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testMMR is a minimal port of the MMR in https://github.com/nervosnetwork/merkle-mountain-range,
+// used to generate multi-leaf proofs in the format returned by substrate.
+type testMMR struct {
+	leaves []types.MMRLeaf
+	nodes  []types.H256
+}
+
+func newTestMMR(t *testing.T, leafCount int) *testMMR {
+	mmr := testMMR{}
+	for i := 0; i < leafCount; i++ {
+		leaf := types.MMRLeaf{
+			ParentNumberAndHash: types.ParentNumberAndHash{ParentNumber: types.U32(i)},
+		}
+		encoded, err := types.EncodeToBytes(leaf)
+		require.NoError(t, err)
+
+		mmr.leaves = append(mmr.leaves, leaf)
+		mmr.nodes = append(mmr.nodes, types.NewH256((&keccak.Keccak256{}).Hash(encoded)))
+
+		position := uint64(len(mmr.nodes) - 1)
+		var height uint32
+		for heightInTree(position+1) > height {
+			position++
+			left := position - parentOffset(height)
+			right := left + siblingOffset(height)
+			mmr.nodes = append(mmr.nodes, mergeHashes(mmr.nodes[left], mmr.nodes[right]))
+			height++
+		}
+	}
+	return &mmr
+}
+
+func (m *testMMR) root() types.H256 {
+	var peakHashes []types.H256
+	for _, peak := range getPeaks(uint64(len(m.nodes))) {
+		peakHashes = append(peakHashes, m.nodes[peak])
+	}
+	return m.bagPeaks(peakHashes)
+}
+
+func (m *testMMR) bagPeaks(peakHashes []types.H256) types.H256 {
+	for len(peakHashes) > 1 {
+		right, left := peakHashes[len(peakHashes)-1], peakHashes[len(peakHashes)-2]
+		peakHashes = append(peakHashes[:len(peakHashes)-2], mergeHashes(right, left))
+	}
+	return peakHashes[0]
+}
+
+func (m *testMMR) generateProof(leafIndices []uint64) []types.H256 {
+	var positions []uint64
+	for _, leafIndex := range leafIndices {
+		positions = append(positions, leafIndexToPosition(leafIndex))
+	}
+	sort.Slice(positions, func(i, j int) bool { return positions[i] < positions[j] })
+
+	var proof []types.H256
+	baggingTrack := 0
+	for _, peak := range getPeaks(uint64(len(m.nodes))) {
+		var peakPositions []uint64
+		for len(positions) > 0 && positions[0] <= peak {
+			peakPositions = append(peakPositions, positions[0])
+			positions = positions[1:]
+		}
+		if len(peakPositions) == 0 {
+			baggingTrack++
+		} else {
+			baggingTrack = 0
+		}
+		proof = m.generatePeakProof(proof, peakPositions, peak)
+	}
+
+	if baggingTrack > 1 {
+		rightPeaks := append([]types.H256{}, proof[len(proof)-baggingTrack:]...)
+		proof = append(proof[:len(proof)-baggingTrack], m.bagPeaks(rightPeaks))
+	}
+
+	return proof
+}
+
+func (m *testMMR) generatePeakProof(proof []types.H256, positions []uint64, peak uint64) []types.H256 {
+	if len(positions) == 1 && positions[0] == peak {
+		return proof
+	}
+	if len(positions) == 0 {
+		return append(proof, m.nodes[peak])
+	}
+
+	type queueItem struct {
+		position uint64
+		height   uint32
+	}
+	var queue []queueItem
+	for _, position := range positions {
+		queue = append(queue, queueItem{position: position})
+	}
+
+	for len(queue) > 0 {
+		item := queue[0]
+		queue = queue[1:]
+		if item.position == peak {
+			break
+		}
+
+		var siblingPosition, parentPosition uint64
+		if heightInTree(item.position+1) > item.height {
+			siblingPosition = item.position - siblingOffset(item.height)
+			parentPosition = item.position + 1
+		} else {
+			siblingPosition = item.position + siblingOffset(item.height)
+			parentPosition = item.position + parentOffset(item.height)
+		}
+
+		if len(queue) > 0 && queue[0].position == siblingPosition {
+			queue = queue[1:]
+		} else {
+			proof = append(proof, m.nodes[siblingPosition])
+		}
+		if parentPosition <= peak {
+			queue = append(queue, queueItem{position: parentPosition, height: item.height + 1})
+		}
+	}
+
+	return proof
+}
+
+func TestConvertToSimplifiedMMRProofs(t *testing.T) {
+	tests := []struct {
+		leafCount   int
+		leafIndices []uint64
+	}{
+		{1, []uint64{0}},
+		{2, []uint64{0, 1}},
+		{7, []uint64{2, 6}},
+		{11, []uint64{0, 5, 9}},
+		{11, []uint64{9, 3}},
+		{64, []uint64{1, 2, 3, 40, 63}},
+		{1049, []uint64{1048}},
+		{1049, []uint64{17, 512, 1000, 1024}},
+		{1049, []uint64{3, 4}},
+		{1500, []uint64{100, 101, 1023, 1024, 1499}},
+	}
+
+	for _, tt := range tests {
+		mmr := newTestMMR(t, tt.leafCount)
+		root := mmr.root()
+
+		var leaves []types.MMRLeaf
+		for _, leafIndex := range tt.leafIndices {
+			leaves = append(leaves, mmr.leaves[leafIndex])
+		}
+		proofItems := mmr.generateProof(tt.leafIndices)
+
+		proofs, err := ConvertToSimplifiedMMRProofs(types.H256{}, tt.leafIndices, leaves, uint64(tt.leafCount), proofItems)
+		require.NoError(t, err, "leaf count %d, leaves %v", tt.leafCount, tt.leafIndices)
+		require.Len(t, proofs, len(tt.leafIndices))
+
+		for i, leafIndex := range tt.leafIndices {
+			encoded, err := types.EncodeToBytes(leaves[i])
+			require.NoError(t, err)
+			leafHash := types.NewH256((&keccak.Keccak256{}).Hash(encoded))
+			assert.Equal(t, root, CalculateMerkleRoot(&proofs[i], leafHash), "leaf count %d, leaf %d", tt.leafCount, leafIndex)
+			assert.Equal(t, leaves[i], proofs[i].Leaf)
+
+			// The simplified proof must be identical to the one converted from a single-leaf proof
+			single, err := ConvertToSimplifiedMMRProof(types.H256{}, leafIndex, leaves[i], uint64(tt.leafCount), mmr.generateProof([]uint64{leafIndex}))
+			require.NoError(t, err)
+			assert.Equal(t, single, proofs[i])
+		}
+	}
+}
+
+func TestConvertToSimplifiedMMRProofsCorrupted(t *testing.T) {
+	mmr := newTestMMR(t, 11)
+	leafIndices := []uint64{0, 5}
+	leaves := []types.MMRLeaf{mmr.leaves[0], mmr.leaves[5]}
+	proofItems := mmr.generateProof(leafIndices)
+
+	_, err := ConvertToSimplifiedMMRProofs(types.H256{}, leafIndices, leaves, 11, proofItems[:len(proofItems)-2])
+	assert.Error(t, err)
+
+	_, err = ConvertToSimplifiedMMRProofs(types.H256{}, leafIndices, leaves, 11, append(proofItems, types.H256{1}, types.H256{2}, types.H256{3}))
+	assert.Error(t, err)
+
+	_, err = ConvertToSimplifiedMMRProofs(types.H256{}, []uint64{0, 11}, leaves, 11, proofItems)
+	assert.Error(t, err)
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/snowfork/snowbridge/relayer/config"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	metricsPath     = "/metrics"
+	shutdownTimeout = 2 * time.Second
+)
+
+// Namespace of the metrics exported by the relays
+const Namespace = "snowbridge"
+
+// Start serves the metrics registered with the default prometheus registry, if a listen address
+// is configured.
+func Start(ctx context.Context, eg *errgroup.Group, config config.MetricsConfig) {
+	if config.Listen == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, promhttp.Handler())
+	server := &http.Server{
+		Addr:              config.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: shutdownTimeout,
+	}
+
+	eg.Go(func() error {
+		log.WithField("address", config.Listen).Info("Serving metrics endpoint")
+		err := server.ListenAndServe()
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("serve metrics endpoint: %w", err)
+	})
+	eg.Go(func() error {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	})
+}
@@ -0,0 +1,325 @@
+package operatingmode
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/snowfork/snowbridge/relayer/chain/ethereum"
+	"github.com/snowfork/snowbridge/relayer/chain/parachain"
+	"github.com/snowfork/snowbridge/relayer/config"
+	"github.com/snowfork/snowbridge/relayer/contracts"
+	"github.com/snowfork/snowbridge/relayer/metrics"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Components of the bridge which governance can halt
+const (
+	// The Gateway contract, which rejects outbound messages while halted. Messages already accepted
+	// can still be delivered, so a halted Gateway does not pause relays.
+	Gateway = "Gateway"
+	// The EthereumInboundQueue pallet on BridgeHub
+	EthereumInboundQueue = "EthereumInboundQueue"
+	// The EthereumBeaconClient pallet on BridgeHub
+	EthereumBeaconClient = "EthereumBeaconClient"
+)
+
+const defaultPollInterval = 30
+
+// Operating mode in which both the Gateway and the pallets accept messages. Any other mode halts them.
+const operatingModeNormal = 0
+
+var haltedGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "operating_mode_halted",
+		Help:      "Whether a bridge component has been halted by governance (1) or operates normally (0)",
+	},
+	[]string{"component"},
+)
+
+func init() {
+	prometheus.MustRegister(haltedGauge)
+}
+
+// Watcher follows the operating modes of the Gateway, of its channels and of the bridge pallets.
+// Relays pause while a pallet they submit to is halted by governance, resuming once it is unhalted.
+// The Gateway and its channels only reject outbound messages while halted, so messages they already
+// accepted can still be delivered: their operating modes are surfaced in logs and metrics, but do
+// not pause relays. A nil Watcher reports every component as operating normally.
+type Watcher struct {
+	pollInterval time.Duration
+	ethConn      *ethereum.Connection
+	gateway      *contracts.Gateway
+	channelIDs   [][32]byte
+	paraConn     *parachain.Connection
+	pallets      []string
+	// Prefix of the component names, distinguishing the pallets of several parachains
+	scope string
+	// Last ethereum block searched for OperatingModeChanged events
+	lastBlock uint64
+
+	mu      sync.Mutex
+	halted  map[string]bool
+	changed chan struct{}
+}
+
+// New creates a watcher for the Gateway and the given channels on it, if gateway is not nil, and
+// for the OperatingMode storage of the given pallets on paraConn. Component names are prefixed
+// with scope, if set.
+func New(
+	config config.OperatingModeConfig,
+	ethConn *ethereum.Connection,
+	gateway *contracts.Gateway,
+	channelIDs [][32]byte,
+	paraConn *parachain.Connection,
+	pallets []string,
+	scope string,
+) *Watcher {
+	pollInterval := config.PollInterval
+	if pollInterval == 0 {
+		pollInterval = defaultPollInterval
+	}
+
+	return &Watcher{
+		pollInterval: time.Duration(pollInterval) * time.Second,
+		ethConn:      ethConn,
+		gateway:      gateway,
+		channelIDs:   channelIDs,
+		paraConn:     paraConn,
+		pallets:      pallets,
+		scope:        scope,
+		halted:       make(map[string]bool),
+		changed:      make(chan struct{}),
+	}
+}
+
+// Start checks the operating modes once, so that relays do not submit to a component which is
+// already halted, and then keeps polling them in the background. Failing to check the operating
+// modes is not fatal, the components are then assumed to operate normally until the next poll.
+func (w *Watcher) Start(ctx context.Context, eg *errgroup.Group) {
+	err := w.poll(ctx)
+	if err != nil {
+		log.WithError(err).Warn("Failed to check operating modes")
+	}
+
+	eg.Go(func() error {
+		ticker := time.NewTicker(w.pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				err := w.poll(ctx)
+				if err != nil {
+					log.WithError(err).Warn("Failed to check operating modes")
+				}
+			}
+		}
+	})
+}
+
+// ChannelComponent returns the name of the component for a channel on the Gateway, which rejects
+// outbound messages on the channel while halted
+func ChannelComponent(channelID [32]byte) string {
+	return fmt.Sprintf("%s channel %#x", Gateway, channelID)
+}
+
+// Halted returns the halted pallets, which pause the relay, in alphabetical order
+func (w *Watcher) Halted() []string {
+	if w == nil {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.haltedLocked()
+}
+
+// Wait blocks while any pallet is halted
+func (w *Watcher) Wait(ctx context.Context) error {
+	if w == nil {
+		return nil
+	}
+
+	for {
+		w.mu.Lock()
+		halted := len(w.haltedLocked()) > 0
+		changed := w.changed
+		w.mu.Unlock()
+
+		if !halted {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+func (w *Watcher) haltedLocked() []string {
+	var halted []string
+	for _, pallet := range w.pallets {
+		if component := w.component(pallet); w.halted[component] {
+			halted = append(halted, component)
+		}
+	}
+	sort.Strings(halted)
+	return halted
+}
+
+func (w *Watcher) isPallet(name string) bool {
+	for _, pallet := range w.pallets {
+		if pallet == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Watcher) component(name string) string {
+	if w.scope == "" {
+		return name
+	}
+	return w.scope + "/" + name
+}
+
+// set records the operating mode of a component, logging and waking up waiters when it changes
+func (w *Watcher) set(name string, halted bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	component := w.component(name)
+	previous, known := w.halted[component]
+	w.halted[component] = halted
+	if halted {
+		haltedGauge.WithLabelValues(component).Set(1)
+	} else {
+		haltedGauge.WithLabelValues(component).Set(0)
+	}
+
+	if known && previous == halted {
+		return
+	}
+	logger := log.WithField("component", component)
+	pausesRelay := w.isPallet(name)
+	switch {
+	case halted && !pausesRelay:
+		logger.Warn("Gateway component halted by governance, rejecting outbound messages")
+	case halted:
+		logger.Warn("Bridge component halted by governance, pausing relay")
+	case known && !pausesRelay:
+		logger.Info("Gateway component resumed normal operation")
+	case known:
+		logger.Info("Bridge component resumed normal operation, resuming relay")
+	}
+
+	close(w.changed)
+	w.changed = make(chan struct{})
+}
+
+func (w *Watcher) poll(ctx context.Context) error {
+	if w.gateway != nil {
+		err := w.pollGateway(ctx)
+		if err != nil {
+			return fmt.Errorf("check gateway operating mode: %w", err)
+		}
+
+		for _, channelID := range w.channelIDs {
+			mode, err := w.gateway.ChannelOperatingModeOf(&bind.CallOpts{Context: ctx}, channelID)
+			if err != nil {
+				return fmt.Errorf("check operating mode of channel %#x: %w", channelID, err)
+			}
+			w.set(ChannelComponent(channelID), mode != operatingModeNormal)
+		}
+	}
+
+	for _, pallet := range w.pallets {
+		halted, err := w.fetchPalletHalted(pallet)
+		if err != nil {
+			return fmt.Errorf("check %s operating mode: %w", pallet, err)
+		}
+		w.set(pallet, halted)
+	}
+
+	return nil
+}
+
+// pollGateway reads the operating mode of the Gateway when first called, and afterwards applies the
+// OperatingModeChanged events emitted since the last poll.
+func (w *Watcher) pollGateway(ctx context.Context) error {
+	latestBlock, err := w.ethConn.Client().BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("get latest block number: %w", err)
+	}
+
+	if w.lastBlock == 0 {
+		mode, err := w.gateway.OperatingMode(&bind.CallOpts{
+			Context:     ctx,
+			BlockNumber: new(big.Int).SetUint64(latestBlock),
+		})
+		if err != nil {
+			return fmt.Errorf("fetch operating mode: %w", err)
+		}
+		w.set(Gateway, mode != operatingModeNormal)
+		w.lastBlock = latestBlock
+		return nil
+	}
+
+	if latestBlock <= w.lastBlock {
+		return nil
+	}
+
+	iter, err := w.gateway.FilterOperatingModeChanged(&bind.FilterOpts{
+		Start:   w.lastBlock + 1,
+		End:     &latestBlock,
+		Context: ctx,
+	})
+	if err != nil {
+		return fmt.Errorf("filter OperatingModeChanged events: %w", err)
+	}
+	defer iter.Close()
+
+	for iter.Next() {
+		log.WithFields(log.Fields{
+			"mode":        iter.Event.Mode,
+			"blockNumber": iter.Event.Raw.BlockNumber,
+		}).Debug("Gateway operating mode changed")
+		w.set(Gateway, iter.Event.Mode != operatingModeNormal)
+	}
+	if iter.Error() != nil {
+		return fmt.Errorf("iterate OperatingModeChanged events: %w", iter.Error())
+	}
+
+	w.lastBlock = latestBlock
+	return nil
+}
+
+// fetchPalletHalted reads the BasicOperatingMode of a pallet, which is Normal if unset
+func (w *Watcher) fetchPalletHalted(pallet string) (bool, error) {
+	key, err := types.CreateStorageKey(w.paraConn.Metadata(), pallet, "OperatingMode", nil, nil)
+	if err != nil {
+		return false, fmt.Errorf("create storage key: %w", err)
+	}
+
+	var mode types.U8
+	_, err = w.paraConn.API().RPC.State.GetStorageLatest(key, &mode)
+	if err != nil {
+		return false, fmt.Errorf("fetch storage: %w", err)
+	}
+
+	return uint8(mode) != operatingModeNormal, nil
+}
@@ -0,0 +1,82 @@
+package operatingmode
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/snowfork/snowbridge/relayer/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHaltAndResume(t *testing.T) {
+	w := New(config.OperatingModeConfig{}, nil, nil, nil, nil, []string{EthereumInboundQueue, EthereumBeaconClient}, "")
+
+	w.set(EthereumInboundQueue, false)
+	assert.Empty(t, w.Halted())
+
+	w.set(EthereumBeaconClient, true)
+	w.set(EthereumInboundQueue, true)
+	assert.Equal(t, []string{EthereumBeaconClient, EthereumInboundQueue}, w.Halted())
+	assert.Equal(t, 1.0, testutil.ToFloat64(haltedGauge.WithLabelValues(EthereumBeaconClient)))
+
+	waited := make(chan error, 1)
+	go func() {
+		waited <- w.Wait(context.Background())
+	}()
+
+	w.set(EthereumBeaconClient, false)
+	select {
+	case <-waited:
+		t.Fatal("wait returned while a component is halted")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	w.set(EthereumInboundQueue, false)
+	select {
+	case err := <-waited:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("wait did not return after the components resumed")
+	}
+	assert.Empty(t, w.Halted())
+	assert.Equal(t, 0.0, testutil.ToFloat64(haltedGauge.WithLabelValues(EthereumBeaconClient)))
+}
+
+func TestHaltedGatewayDoesNotPause(t *testing.T) {
+	w := New(config.OperatingModeConfig{}, nil, nil, nil, nil, []string{EthereumInboundQueue}, "")
+
+	w.set(Gateway, true)
+	assert.Empty(t, w.Halted())
+	assert.NoError(t, w.Wait(context.Background()))
+	assert.Equal(t, 1.0, testutil.ToFloat64(haltedGauge.WithLabelValues(Gateway)))
+}
+
+func TestHaltedChannelDoesNotPause(t *testing.T) {
+	w := New(config.OperatingModeConfig{}, nil, nil, [][32]byte{{1}}, nil, []string{EthereumInboundQueue}, "1000")
+
+	w.set(ChannelComponent([32]byte{1}), true)
+	assert.Empty(t, w.Halted())
+	assert.NoError(t, w.Wait(context.Background()))
+	assert.Equal(t, 1.0, testutil.ToFloat64(haltedGauge.WithLabelValues("1000/"+ChannelComponent([32]byte{1}))))
+
+	w.set(EthereumInboundQueue, true)
+	assert.Equal(t, []string{"1000/" + EthereumInboundQueue}, w.Halted())
+}
+
+func TestWaitCancelled(t *testing.T) {
+	w := New(config.OperatingModeConfig{}, nil, nil, nil, nil, []string{EthereumBeaconClient}, "")
+	w.set(EthereumBeaconClient, true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, w.Wait(ctx), context.Canceled)
+}
+
+func TestNilWatcher(t *testing.T) {
+	var w *Watcher
+	assert.Empty(t, w.Halted())
+	assert.NoError(t, w.Wait(context.Background()))
+}
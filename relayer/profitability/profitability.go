@@ -0,0 +1,186 @@
+package profitability
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"github.com/snowfork/snowbridge/relayer/config"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	ReasonDisabled     = "disabled"
+	ReasonProfitable   = "profitable"
+	ReasonUnprofitable = "unprofitable"
+	ReasonDeadline     = "deadline reached"
+)
+
+// Estimate is the expected reward and delivery cost of relaying a message, both denominated in
+// the native token of the chain the message is delivered to.
+type Estimate struct {
+	Reward *big.Int
+	Cost   *big.Int
+}
+
+func (e Estimate) Profit() *big.Int {
+	return new(big.Int).Sub(e.Reward, e.Cost)
+}
+
+type Decision struct {
+	Relay  bool
+	Reason string
+}
+
+type messageKey struct {
+	channelID [32]byte
+	nonce     uint64
+}
+
+// Policy decides whether messages are relayed based on their estimated profit. Unprofitable
+// messages are deferred until the deadline has passed since they were first seen.
+type Policy struct {
+	enabled   bool
+	minProfit *big.Int
+	deadline  time.Duration
+	auditLog  *os.File
+	now       func() time.Time
+
+	mu        sync.Mutex
+	firstSeen map[messageKey]time.Time
+}
+
+type auditRecord struct {
+	Time      time.Time `json:"time"`
+	ChannelID string    `json:"channelId"`
+	Nonce     uint64    `json:"nonce"`
+	Reward    string    `json:"reward"`
+	Cost      string    `json:"cost"`
+	Profit    string    `json:"profit"`
+	FirstSeen time.Time `json:"firstSeen"`
+	Relay     bool      `json:"relay"`
+	Reason    string    `json:"reason"`
+}
+
+func New(config config.ProfitabilityConfig) (*Policy, error) {
+	policy := Policy{
+		enabled:   config.Enabled,
+		minProfit: new(big.Int).SetUint64(config.MinProfit),
+		deadline:  time.Duration(config.Deadline) * time.Second,
+		now:       time.Now,
+		firstSeen: make(map[messageKey]time.Time),
+	}
+
+	if config.Enabled && config.AuditLog != "" {
+		f, err := os.OpenFile(config.AuditLog, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("open audit log: %w", err)
+		}
+		policy.auditLog = f
+	}
+
+	return &policy, nil
+}
+
+func (p *Policy) Enabled() bool {
+	return p.enabled
+}
+
+// Decide returns whether the message with the given nonce should be relayed now. Every decision is
+// logged, and appended to the audit log if one is configured.
+func (p *Policy) Decide(channelID [32]byte, nonce uint64, estimate Estimate) Decision {
+	if !p.enabled {
+		return Decision{Relay: true, Reason: ReasonDisabled}
+	}
+
+	now := p.now()
+	key := messageKey{channelID, nonce}
+
+	p.mu.Lock()
+	firstSeen, ok := p.firstSeen[key]
+	if !ok {
+		firstSeen = now
+		p.firstSeen[key] = now
+	}
+	p.mu.Unlock()
+
+	profit := estimate.Profit()
+	var decision Decision
+	switch {
+	case profit.Cmp(p.minProfit) >= 0:
+		decision = Decision{Relay: true, Reason: ReasonProfitable}
+	case now.Sub(firstSeen) >= p.deadline:
+		decision = Decision{Relay: true, Reason: ReasonDeadline}
+	default:
+		decision = Decision{Relay: false, Reason: ReasonUnprofitable}
+	}
+
+	p.audit(auditRecord{
+		Time:      now,
+		ChannelID: types.H256(channelID).Hex(),
+		Nonce:     nonce,
+		Reward:    estimate.Reward.String(),
+		Cost:      estimate.Cost.String(),
+		Profit:    profit.String(),
+		FirstSeen: firstSeen,
+		Relay:     decision.Relay,
+		Reason:    decision.Reason,
+	})
+
+	return decision
+}
+
+// Prune forgets the messages of a channel up to and including the given nonce, once they have
+// been delivered.
+func (p *Policy) Prune(channelID [32]byte, nonce uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key := range p.firstSeen {
+		if key.channelID == channelID && key.nonce <= nonce {
+			delete(p.firstSeen, key)
+		}
+	}
+}
+
+func (p *Policy) Close() error {
+	if p.auditLog == nil {
+		return nil
+	}
+	return p.auditLog.Close()
+}
+
+func (p *Policy) audit(record auditRecord) {
+	logger := log.WithFields(log.Fields{
+		"channelId": record.ChannelID,
+		"nonce":     record.Nonce,
+		"reward":    record.Reward,
+		"cost":      record.Cost,
+		"profit":    record.Profit,
+		"firstSeen": record.FirstSeen,
+		"reason":    record.Reason,
+	})
+	if record.Relay {
+		logger.Info("Relaying message")
+	} else {
+		logger.Info("Deferring unprofitable message")
+	}
+
+	if p.auditLog == nil {
+		return
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		log.WithError(err).Error("Failed to encode profitability audit record")
+		return
+	}
+	_, err = p.auditLog.Write(append(line, '\n'))
+	if err != nil {
+		log.WithError(err).Error("Failed to write profitability audit record")
+	}
+}
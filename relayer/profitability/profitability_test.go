@@ -0,0 +1,78 @@
+package profitability
+
+import (
+	"bufio"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/snowfork/snowbridge/relayer/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func estimate(reward, cost int64) Estimate {
+	return Estimate{Reward: big.NewInt(reward), Cost: big.NewInt(cost)}
+}
+
+func TestDecide(t *testing.T) {
+	auditLog := filepath.Join(t.TempDir(), "audit.jsonl")
+	policy, err := New(config.ProfitabilityConfig{
+		Enabled:   true,
+		MinProfit: 10,
+		Deadline:  60,
+		AuditLog:  auditLog,
+	})
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	policy.now = func() time.Time { return now }
+	channelID := [32]byte{1}
+
+	assert.Equal(t, Decision{Relay: true, Reason: ReasonProfitable}, policy.Decide(channelID, 1, estimate(110, 100)))
+	assert.Equal(t, Decision{Relay: false, Reason: ReasonUnprofitable}, policy.Decide(channelID, 2, estimate(105, 100)))
+
+	now = now.Add(59 * time.Second)
+	assert.Equal(t, Decision{Relay: false, Reason: ReasonUnprofitable}, policy.Decide(channelID, 2, estimate(105, 100)))
+	// The deadline of a message only starts when it is first seen
+	assert.Equal(t, Decision{Relay: false, Reason: ReasonUnprofitable}, policy.Decide(channelID, 3, estimate(0, 100)))
+
+	now = now.Add(time.Second)
+	assert.Equal(t, Decision{Relay: true, Reason: ReasonDeadline}, policy.Decide(channelID, 2, estimate(105, 100)))
+	assert.Equal(t, Decision{Relay: false, Reason: ReasonUnprofitable}, policy.Decide(channelID, 3, estimate(0, 100)))
+
+	// Pruned messages are treated as new
+	policy.Prune(channelID, 2)
+	assert.Equal(t, Decision{Relay: false, Reason: ReasonUnprofitable}, policy.Decide(channelID, 2, estimate(105, 100)))
+
+	require.NoError(t, policy.Close())
+
+	f, err := os.Open(auditLog)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var records []auditRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record auditRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+	require.Len(t, records, 7)
+	assert.Equal(t, uint64(2), records[4].Nonce)
+	assert.Equal(t, "5", records[4].Profit)
+	assert.True(t, records[4].Relay)
+	assert.Equal(t, ReasonDeadline, records[4].Reason)
+}
+
+func TestDecideDisabled(t *testing.T) {
+	policy, err := New(config.ProfitabilityConfig{})
+	require.NoError(t, err)
+
+	assert.Equal(t, Decision{Relay: true, Reason: ReasonDisabled}, policy.Decide([32]byte{1}, 1, estimate(0, 100)))
+	assert.NoError(t, policy.Close())
+}
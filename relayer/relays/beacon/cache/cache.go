@@ -75,7 +75,9 @@ func (b *BeaconCache) SetInitialCheckpointSlot(slot uint64) {
 	}
 }
 
-func (b *BeaconCache) AddCheckPoint(finalizedHeaderRoot common.Hash, blockRootsTree *ssz.Node, slot uint64) {
+// AddCheckPoint adds a finalized checkpoint to the cache, and returns the slots of the checkpoints pruned to make room
+// for it
+func (b *BeaconCache) AddCheckPoint(finalizedHeaderRoot common.Hash, blockRootsTree *ssz.Node, slot uint64) []uint64 {
 	b.addSlot(slot)
 	b.Finalized.Checkpoints.Proofs[slot] = Proof{
 		FinalizedBlockRoot: finalizedHeaderRoot,
@@ -83,7 +85,7 @@ func (b *BeaconCache) AddCheckPoint(finalizedHeaderRoot common.Hash, blockRootsT
 		Slot:               slot,
 	}
 
-	b.pruneOldCheckpoints()
+	return b.pruneOldCheckpoints()
 }
 
 func (b *BeaconCache) AddCheckPointSlots(slots []uint64) {
@@ -110,10 +112,10 @@ func (b *BeaconCache) LastFinalizedHeader() common.Hash {
 	return b.Finalized.LastSyncedHash
 }
 
-func (b *BeaconCache) pruneOldCheckpoints() {
+func (b *BeaconCache) pruneOldCheckpoints() []uint64 {
 	checkpointsCount := len(b.Finalized.Checkpoints.Slots)
 	if checkpointsCount <= FinalizedCheckpointsLimit {
-		return
+		return nil
 	}
 
 	slotsToKeep := b.Finalized.Checkpoints.Slots[checkpointsCount-FinalizedCheckpointsLimit : checkpointsCount]
@@ -126,6 +128,8 @@ func (b *BeaconCache) pruneOldCheckpoints() {
 	log.WithField("prunedSlots", slotsToPrune).Info("pruned finalized checkpoint slots from cache")
 
 	b.Finalized.Checkpoints.Slots = slotsToKeep
+
+	return slotsToPrune
 }
 
 func (b *BeaconCache) addSlot(slot uint64) {
@@ -91,3 +91,15 @@ func TestAddPruneOldCheckpoints(t *testing.T) {
 		require.LessOrEqual(t, checkpoint.Slot, uint64(FinalizedCheckpointsLimit+20))
 	}
 }
+
+func TestAddCheckPointReturnsPrunedSlots(t *testing.T) {
+	b := New(8, 8)
+
+	for i := 1; i <= FinalizedCheckpointsLimit; i++ {
+		pruned := b.AddCheckPoint(common.HexToHash("0xe5509a901249bcb4800b644ebb3c666074848ea02d0e85427fff29fe2ec354ec"), nil, uint64(i))
+		require.Empty(t, pruned)
+	}
+
+	pruned := b.AddCheckPoint(common.HexToHash("0xe5509a901249bcb4800b644ebb3c666074848ea02d0e85427fff29fe2ec354ec"), nil, FinalizedCheckpointsLimit+1)
+	require.Equal(t, []uint64{1}, pruned)
+}
@@ -3,18 +3,36 @@ package config
 import (
 	"errors"
 	"fmt"
+
+	"github.com/snowfork/snowbridge/relayer/config"
 )
 
 type Config struct {
 	Source SourceConfig `mapstructure:"source"`
 	Sink   SinkConfig   `mapstructure:"sink"`
+	// Pausing while the EthereumBeaconClient is halted
+	OperatingMode config.OperatingModeConfig `mapstructure:"operating-mode"`
+	Metrics       config.MetricsConfig       `mapstructure:"metrics"`
 }
 
 type SpecSettings struct {
+	// Name of a built-in network preset (mainnet, sepolia, holesky or minimal). Settings which are
+	// set explicitly override the preset.
+	Preset                       string `mapstructure:"preset"`
 	SyncCommitteeSize            uint64 `mapstructure:"syncCommitteeSize"`
 	SlotsInEpoch                 uint64 `mapstructure:"slotsInEpoch"`
 	EpochsPerSyncCommitteePeriod uint64 `mapstructure:"epochsPerSyncCommitteePeriod"`
-	DenebForkEpoch               uint64 `mapstructure:"denebForkedEpoch"`
+	// Capella activation epoch. Capella is the earliest fork supported, so it applies from genesis
+	// if unset or 0.
+	CapellaForkEpoch uint64 `mapstructure:"capellaForkedEpoch"`
+	DenebForkEpoch   uint64 `mapstructure:"denebForkedEpoch"`
+	// Electra activation epoch, Electra is not scheduled if unset or 0
+	ElectraForkEpoch uint64 `mapstructure:"electraForkedEpoch"`
+	// Activation epochs by fork name, overriding the fork epoch settings above. Forks which are not
+	// listed there or here are not scheduled.
+	ForkEpochs map[string]uint64 `mapstructure:"forkEpochs"`
+	// Check the settings against the beacon node's /eth/v1/config/spec at startup
+	CheckSpec bool `mapstructure:"checkSpec"`
 }
 
 type SourceConfig struct {
@@ -24,13 +42,28 @@ type SourceConfig struct {
 type DataStore struct {
 	Location   string `mapstructure:"location"`
 	MaxEntries uint64 `mapstructure:"maxEntries"`
+	// Optional directory of era files, which beacon states are read from when they are not in the datastore, and
+	// blocks when the beacon node does not have them. Era files only hold the states at the first slot of each era.
+	EraDirectory string `mapstructure:"eraDirectory"`
 }
 
+// Sync modes of the beacon relay
+const (
+	// Build updates from beacon states, falling back to the light client API
+	SyncModeState = "state"
+	// Build updates from the light client API, only downloading beacon states for block roots proofs.
+	// The light client API does not serve block roots, so the finalized state of each update is still
+	// downloaded, while the attested state is not.
+	SyncModeLightClient = "lightClient"
+)
+
 type BeaconConfig struct {
 	Endpoint      string       `mapstructure:"endpoint"`
 	StateEndpoint string       `mapstructure:"stateEndpoint"`
 	Spec          SpecSettings `mapstructure:"spec"`
 	DataStore     DataStore    `mapstructure:"datastore"`
+	// Either "state" (the default) or "lightClient"
+	SyncMode string `mapstructure:"syncMode"`
 }
 
 type SinkConfig struct {
@@ -63,14 +96,9 @@ func (c Config) Validate() error {
 
 func (b BeaconConfig) Validate() error {
 	// spec settings
-	if b.Spec.EpochsPerSyncCommitteePeriod == 0 {
-		return errors.New("source beacon setting [epochsPerSyncCommitteePeriod] is not set")
-	}
-	if b.Spec.SlotsInEpoch == 0 {
-		return errors.New("source beacon setting [slotsInEpoch] is not set")
-	}
-	if b.Spec.SyncCommitteeSize == 0 {
-		return errors.New("source beacon setting [syncCommitteeSize] is not set")
+	err := b.Spec.Validate()
+	if err != nil {
+		return fmt.Errorf("source beacon setting %w", err)
 	}
 	// data store
 	if b.DataStore.Location == "" {
@@ -86,6 +114,11 @@ func (b BeaconConfig) Validate() error {
 	if b.StateEndpoint == "" {
 		return errors.New("source beacon setting [stateEndpoint] is not set")
 	}
+	switch b.SyncMode {
+	case "", SyncModeState, SyncModeLightClient:
+	default:
+		return fmt.Errorf("source beacon setting [syncMode] %q is unknown", b.SyncMode)
+	}
 	return nil
 }
 
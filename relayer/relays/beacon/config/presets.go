@@ -0,0 +1,178 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Beacon chain forks supported by the relay, in activation order
+const (
+	Capella = "capella"
+	Deneb   = "deneb"
+	Electra = "electra"
+)
+
+var Forks = []string{Capella, Deneb, Electra}
+
+// Names of the built-in network presets
+const (
+	Mainnet = "mainnet"
+	Sepolia = "sepolia"
+	Holesky = "holesky"
+	Minimal = "minimal"
+)
+
+var presets = map[string]SpecSettings{
+	Mainnet: {
+		SyncCommitteeSize:            512,
+		SlotsInEpoch:                 32,
+		EpochsPerSyncCommitteePeriod: 256,
+		ForkEpochs: map[string]uint64{
+			Capella: 194048,
+			Deneb:   269568,
+			Electra: 364032,
+		},
+	},
+	Sepolia: {
+		SyncCommitteeSize:            512,
+		SlotsInEpoch:                 32,
+		EpochsPerSyncCommitteePeriod: 256,
+		ForkEpochs: map[string]uint64{
+			Capella: 56832,
+			Deneb:   132608,
+			Electra: 222464,
+		},
+	},
+	Holesky: {
+		SyncCommitteeSize:            512,
+		SlotsInEpoch:                 32,
+		EpochsPerSyncCommitteePeriod: 256,
+		ForkEpochs: map[string]uint64{
+			Capella: 256,
+			Deneb:   29696,
+			Electra: 115968,
+		},
+	},
+	// Local devnets, which activate every fork up to Deneb at genesis
+	Minimal: {
+		SyncCommitteeSize:            32,
+		SlotsInEpoch:                 8,
+		EpochsPerSyncCommitteePeriod: 8,
+		ForkEpochs: map[string]uint64{
+			Capella: 0,
+			Deneb:   0,
+		},
+	},
+}
+
+// WithPreset returns the settings of the configured preset, overridden by the settings which are
+// set explicitly. Without a preset, the fork schedule is taken from the Capella, Deneb and Electra
+// fork epochs, overridden by any fork epochs listed explicitly. Capella and Deneb are always
+// scheduled, at genesis if their epoch is 0, while an Electra fork epoch of 0 means the fork is not
+// scheduled; Electra at genesis is configured in the fork epochs.
+func (s SpecSettings) WithPreset() SpecSettings {
+	preset, ok := presets[s.Preset]
+
+	resolved := SpecSettings{
+		Preset:                       s.Preset,
+		SyncCommitteeSize:            preset.SyncCommitteeSize,
+		SlotsInEpoch:                 preset.SlotsInEpoch,
+		EpochsPerSyncCommitteePeriod: preset.EpochsPerSyncCommitteePeriod,
+		ForkEpochs:                   make(map[string]uint64),
+		CheckSpec:                    s.CheckSpec,
+	}
+	if ok {
+		for fork, epoch := range preset.ForkEpochs {
+			resolved.ForkEpochs[fork] = epoch
+		}
+		if s.CapellaForkEpoch != 0 {
+			resolved.ForkEpochs[Capella] = s.CapellaForkEpoch
+		}
+		if s.DenebForkEpoch != 0 {
+			resolved.ForkEpochs[Deneb] = s.DenebForkEpoch
+		}
+		if s.ElectraForkEpoch != 0 {
+			resolved.ForkEpochs[Electra] = s.ElectraForkEpoch
+		}
+	} else {
+		resolved.ForkEpochs[Capella] = s.CapellaForkEpoch
+		resolved.ForkEpochs[Deneb] = s.DenebForkEpoch
+		if s.ElectraForkEpoch != 0 {
+			resolved.ForkEpochs[Electra] = s.ElectraForkEpoch
+		}
+	}
+	if s.SyncCommitteeSize != 0 {
+		resolved.SyncCommitteeSize = s.SyncCommitteeSize
+	}
+	if s.SlotsInEpoch != 0 {
+		resolved.SlotsInEpoch = s.SlotsInEpoch
+	}
+	if s.EpochsPerSyncCommitteePeriod != 0 {
+		resolved.EpochsPerSyncCommitteePeriod = s.EpochsPerSyncCommitteePeriod
+	}
+	for fork, epoch := range s.ForkEpochs {
+		resolved.ForkEpochs[fork] = epoch
+	}
+	resolved.CapellaForkEpoch = resolved.ForkEpochs[Capella]
+	resolved.DenebForkEpoch = resolved.ForkEpochs[Deneb]
+	resolved.ElectraForkEpoch = resolved.ForkEpochs[Electra]
+
+	return resolved
+}
+
+// Validate checks the settings after applying the preset
+func (s SpecSettings) Validate() error {
+	if s.Preset != "" {
+		_, ok := presets[s.Preset]
+		if !ok {
+			return fmt.Errorf("[preset] %q is unknown", s.Preset)
+		}
+	}
+
+	resolved := s.WithPreset()
+	if resolved.EpochsPerSyncCommitteePeriod == 0 {
+		return errors.New("[epochsPerSyncCommitteePeriod] is not set")
+	}
+	if resolved.SlotsInEpoch == 0 {
+		return errors.New("[slotsInEpoch] is not set")
+	}
+	if resolved.SyncCommitteeSize == 0 {
+		return errors.New("[syncCommitteeSize] is not set")
+	}
+
+	for fork := range resolved.ForkEpochs {
+		if !isFork(fork) {
+			return fmt.Errorf("[forkEpochs] fork %q is unknown", fork)
+		}
+	}
+	// Forks must activate in order
+	var previousFork string
+	for _, fork := range Forks {
+		epoch, ok := resolved.ForkEpochs[fork]
+		if !ok {
+			continue
+		}
+		if previousFork != "" && epoch < resolved.ForkEpochs[previousFork] {
+			return fmt.Errorf("[forkEpochs] fork %s activates before %s", fork, previousFork)
+		}
+		previousFork = fork
+	}
+
+	return nil
+}
+
+// MinimalContainers returns whether beacon states and blocks are encoded with the SSZ limits of the
+// minimal preset. It is derived from the sync committee size, so that settings which match the
+// minimal preset without naming it are decoded the same way.
+func (s SpecSettings) MinimalContainers() bool {
+	return s.WithPreset().SyncCommitteeSize == presets[Minimal].SyncCommitteeSize
+}
+
+func isFork(name string) bool {
+	for _, fork := range Forks {
+		if fork == name {
+			return true
+		}
+	}
+	return false
+}
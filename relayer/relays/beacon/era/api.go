@@ -0,0 +1,111 @@
+package era
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/config"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer/api"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/protocol"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/state"
+)
+
+// API is a beacon API which falls back to an era archive for the blocks the beacon node does not have, such as the
+// blocks before the checkpoint the node was synced from. Era files are indexed by slot, so the archive only serves
+// the lookups of blocks by slot, and GetBeaconBlock still needs the beacon node.
+type API struct {
+	api.BeaconAPI
+	archive  *Archive
+	protocol *protocol.Protocol
+}
+
+func NewAPI(beaconAPI api.BeaconAPI, archive *Archive, protocol *protocol.Protocol) *API {
+	return &API{
+		BeaconAPI: beaconAPI,
+		archive:   archive,
+		protocol:  protocol,
+	}
+}
+
+func (a *API) GetBeaconBlockBySlot(slot uint64) (api.BeaconBlockResponse, error) {
+	response, apiErr := a.BeaconAPI.GetBeaconBlockBySlot(slot)
+	if !errors.Is(apiErr, api.ErrNotFound) {
+		return response, apiErr
+	}
+
+	block, err := a.getBeaconBlock(slot)
+	if err != nil {
+		return api.BeaconBlockResponse{}, err
+	}
+	return api.BeaconBlockResponseFromSSZ(block)
+}
+
+func (a *API) GetBeaconBlockRoot(slot uint64) (common.Hash, error) {
+	root, apiErr := a.BeaconAPI.GetBeaconBlockRoot(slot)
+	if !errors.Is(apiErr, api.ErrNotFound) {
+		return root, apiErr
+	}
+
+	block, err := a.getBeaconBlock(slot)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	tree, err := block.GetTree()
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("beacon block tree: %w", err)
+	}
+	return common.BytesToHash(tree.Hash()), nil
+}
+
+// getBeaconBlock reads the block at the slot from the archive. api.ErrNotFound is returned if the archive does not
+// have the era of the slot, or if the slot has no block, as the beacon node would for an empty slot.
+func (a *API) getBeaconBlock(slot uint64) (state.BeaconBlock, error) {
+	data, err := a.archive.GetBeaconBlockData(slot)
+	if errors.Is(err, ErrNotFound) {
+		return nil, api.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read beacon block at slot %d from era archive: %w", slot, err)
+	}
+
+	block, err := decodeSignedBeaconBlock(data, a.protocol.ForkAtSlot(slot), a.protocol.Settings.MinimalContainers())
+	if err != nil {
+		return nil, fmt.Errorf("decode beacon block at slot %d from era archive: %w", slot, err)
+	}
+	return block, nil
+}
+
+// decodeSignedBeaconBlock decodes the block of an SSZ encoded signed beacon block, which is the offset of the block
+// and the signature, followed by the block. As with blocks of the beacon API, blocks of the minimal preset are only
+// supported from Deneb onwards.
+func decodeSignedBeaconBlock(data []byte, fork string, minimal bool) (state.BeaconBlock, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("signed beacon block too short: %d bytes", len(data))
+	}
+	offset := binary.LittleEndian.Uint32(data[:4])
+	if uint64(offset) > uint64(len(data)) {
+		return nil, fmt.Errorf("beacon block offset %d is beyond the signed beacon block", offset)
+	}
+	if minimal && fork != config.Deneb {
+		return nil, fmt.Errorf("%s blocks are not supported with the minimal preset", fork)
+	}
+
+	var block state.BeaconBlock
+	switch {
+	case fork == config.Electra:
+		block = &state.BeaconBlockElectraMainnet{}
+	case fork == config.Deneb && minimal:
+		block = &state.BeaconBlockDenebMinimal{}
+	case fork == config.Deneb:
+		block = &state.BeaconBlockDenebMainnet{}
+	default:
+		block = &state.BeaconBlockCapellaMainnet{}
+	}
+	err := block.UnmarshalSSZ(data[offset:])
+	if err != nil {
+		return nil, err
+	}
+	return block, nil
+}
@@ -0,0 +1,171 @@
+package era
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/store"
+)
+
+// Archive is a directory of era files, named <config-name>-<era-number>-<short-historical-root>.era
+type Archive struct {
+	dir                    string
+	slotsPerHistoricalRoot uint64
+	// Era file paths by era number
+	files map[uint64]string
+}
+
+func NewArchive(dir string, slotsPerHistoricalRoot uint64) (*Archive, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read era directory: %w", err)
+	}
+
+	files := make(map[uint64]string)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".era" {
+			continue
+		}
+		parts := strings.Split(strings.TrimSuffix(entry.Name(), ".era"), "-")
+		if len(parts) < 3 {
+			return nil, fmt.Errorf("era file name %s is not <config-name>-<era-number>-<short-historical-root>.era", entry.Name())
+		}
+		era, err := strconv.ParseUint(parts[len(parts)-2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("era number of file %s: %w", entry.Name(), err)
+		}
+		if existing, ok := files[era]; ok {
+			return nil, fmt.Errorf("era %d is in both %s and %s", era, filepath.Base(existing), entry.Name())
+		}
+		files[era] = filepath.Join(dir, entry.Name())
+	}
+
+	return &Archive{
+		dir:                    dir,
+		slotsPerHistoricalRoot: slotsPerHistoricalRoot,
+		files:                  files,
+	}, nil
+}
+
+// Eras returns the era numbers in the archive, in ascending order
+func (a *Archive) Eras() []uint64 {
+	eras := make([]uint64, 0, len(a.files))
+	for era := range a.files {
+		eras = append(eras, era)
+	}
+	sort.Slice(eras, func(i, j int) bool { return eras[i] < eras[j] })
+	return eras
+}
+
+func (a *Archive) Open(era uint64) (*File, error) {
+	path, ok := a.files[era]
+	if !ok {
+		return nil, fmt.Errorf("era %d: %w", era, ErrNotFound)
+	}
+	f, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if f.StateSlot() != era*a.slotsPerHistoricalRoot {
+		_ = f.Close()
+		return nil, fmt.Errorf("era file %s has the state of slot %d, expected %d", path, f.StateSlot(), era*a.slotsPerHistoricalRoot)
+	}
+	return f, nil
+}
+
+// GetBeaconStateData returns the beacon state at the slot. Era files only hold the states at the first slot of each
+// era.
+func (a *Archive) GetBeaconStateData(slot uint64) ([]byte, error) {
+	if slot%a.slotsPerHistoricalRoot != 0 {
+		return nil, fmt.Errorf("beacon state at slot %d: %w", slot, ErrNotFound)
+	}
+	f, err := a.Open(slot / a.slotsPerHistoricalRoot)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.State()
+}
+
+// GetBeaconBlockData returns the SSZ encoded signed beacon block at the slot, which is in the file of the next era
+func (a *Archive) GetBeaconBlockData(slot uint64) ([]byte, error) {
+	f, err := a.Open(slot/a.slotsPerHistoricalRoot + 1)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Block(slot)
+}
+
+// OpenBeaconState opens the era file of the beacon state at the slot, which is decompressed while it is read. The
+// returned reader must be closed by the caller.
+func (a *Archive) OpenBeaconState(slot uint64) (io.ReadCloser, error) {
+	if slot%a.slotsPerHistoricalRoot != 0 {
+		return nil, fmt.Errorf("beacon state at slot %d: %w", slot, ErrNotFound)
+	}
+	f, err := a.Open(slot / a.slotsPerHistoricalRoot)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := f.StateReader()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return stateReader{reader, f}, nil
+}
+
+// stateReader reads the beacon state of an era file, closing the file when it is closed
+type stateReader struct {
+	io.Reader
+	file *File
+}
+
+func (r stateReader) Close() error {
+	return r.file.Close()
+}
+
+// Store is a beacon store which falls back to an era archive for the states it does not have. Era files only hold the
+// states at multiples of SlotsPerHistoricalRoot, so the fallback only serves the syncer when it reads a state at the
+// first slot of an era, such as a checkpoint at an era boundary. States at other slots still need the beacon node or
+// the datastore.
+type Store struct {
+	store.BeaconStore
+	archive *Archive
+}
+
+func NewStore(beaconStore store.BeaconStore, archive *Archive) *Store {
+	return &Store{
+		BeaconStore: beaconStore,
+		archive:     archive,
+	}
+}
+
+func (s *Store) GetBeaconStateData(slot uint64) ([]byte, error) {
+	data, storeErr := s.BeaconStore.GetBeaconStateData(slot)
+	if storeErr == nil {
+		return data, nil
+	}
+	data, err := s.archive.GetBeaconStateData(slot)
+	if err != nil {
+		return nil, fmt.Errorf("%w, archive: %w", storeErr, err)
+	}
+	return data, nil
+}
+
+func (s *Store) OpenBeaconState(slot uint64) (io.ReadCloser, error) {
+	reader, storeErr := s.BeaconStore.OpenBeaconState(slot)
+	if storeErr == nil {
+		return reader, nil
+	}
+	reader, err := s.archive.OpenBeaconState(slot)
+	if err != nil {
+		return nil, fmt.Errorf("%w, archive: %w", storeErr, err)
+	}
+	return reader, nil
+}
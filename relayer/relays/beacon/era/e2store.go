@@ -0,0 +1,127 @@
+package era
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/golang/snappy"
+)
+
+// Record types of the e2store format, see https://github.com/status-im/nimbus-eth2/blob/stable/docs/e2store.md
+var (
+	TypeVersion                     = [2]byte{0x65, 0x32}
+	TypeCompressedSignedBeaconBlock = [2]byte{0x01, 0x00}
+	TypeCompressedBeaconState       = [2]byte{0x02, 0x00}
+	TypeSlotIndex                   = [2]byte{0x69, 0x32}
+)
+
+const headerSize = 8
+
+// header of an e2store record, which is followed by length bytes of data
+type header struct {
+	Type   [2]byte
+	Length uint32
+}
+
+func readHeader(r io.ReaderAt, offset int64) (header, error) {
+	var buf [headerSize]byte
+	_, err := r.ReadAt(buf[:], offset)
+	if err != nil {
+		return header{}, fmt.Errorf("read record header at %d: %w", offset, err)
+	}
+	if buf[6] != 0 || buf[7] != 0 {
+		return header{}, fmt.Errorf("record header at %d has a non-zero reserved field", offset)
+	}
+	return header{
+		Type:   [2]byte{buf[0], buf[1]},
+		Length: binary.LittleEndian.Uint32(buf[2:6]),
+	}, nil
+}
+
+// readRecord reads the data of the record at offset, which must be of the expected type
+func readRecord(r io.ReaderAt, offset int64, expected [2]byte) ([]byte, error) {
+	h, err := readHeader(r, offset)
+	if err != nil {
+		return nil, err
+	}
+	if h.Type != expected {
+		return nil, fmt.Errorf("record at %d has type %#x, expected %#x", offset, h.Type, expected)
+	}
+	data := make([]byte, h.Length)
+	_, err = r.ReadAt(data, offset+headerSize)
+	if err != nil {
+		return nil, fmt.Errorf("read record data at %d: %w", offset, err)
+	}
+	return data, nil
+}
+
+// readCompressedRecord reads and decompresses a record, whose data is snappy framed SSZ
+func readCompressedRecord(r io.ReaderAt, offset int64, expected [2]byte) ([]byte, error) {
+	reader, err := openCompressedRecord(r, offset, expected)
+	if err != nil {
+		return nil, err
+	}
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("decompress record at %d: %w", offset, err)
+	}
+	return decompressed, nil
+}
+
+// openCompressedRecord returns a reader which decompresses the data of a record while it is read
+func openCompressedRecord(r io.ReaderAt, offset int64, expected [2]byte) (io.Reader, error) {
+	h, err := readHeader(r, offset)
+	if err != nil {
+		return nil, err
+	}
+	if h.Type != expected {
+		return nil, fmt.Errorf("record at %d has type %#x, expected %#x", offset, h.Type, expected)
+	}
+	return snappy.NewReader(io.NewSectionReader(r, offset+headerSize, int64(h.Length))), nil
+}
+
+// slotIndex maps the slots from startSlot to the offsets of their records. Slots without a block have a zero offset.
+type slotIndex struct {
+	startSlot uint64
+	offsets   []int64
+}
+
+// readSlotIndex reads the slot index record which ends at end. The offsets in the record are relative to its start
+// and are converted to offsets in the file.
+func readSlotIndex(r io.ReaderAt, end int64) (slotIndex, int64, error) {
+	var buf [8]byte
+	if end < headerSize+16 {
+		return slotIndex{}, 0, fmt.Errorf("no slot index before %d", end)
+	}
+	_, err := r.ReadAt(buf[:], end-8)
+	if err != nil {
+		return slotIndex{}, 0, fmt.Errorf("read slot index count: %w", err)
+	}
+	count := binary.LittleEndian.Uint64(buf[:])
+	length := 16 + 8*count
+	if count == 0 || count > uint64(end) || length+headerSize > uint64(end) {
+		return slotIndex{}, 0, fmt.Errorf("slot index count %d is out of bounds", count)
+	}
+	start := end - headerSize - int64(length)
+
+	data, err := readRecord(r, start, TypeSlotIndex)
+	if err != nil {
+		return slotIndex{}, 0, err
+	}
+	if uint64(len(data)) != length {
+		return slotIndex{}, 0, fmt.Errorf("slot index at %d has length %d, expected %d", start, len(data), length)
+	}
+
+	index := slotIndex{
+		startSlot: binary.LittleEndian.Uint64(data[:8]),
+		offsets:   make([]int64, count),
+	}
+	for i := range index.offsets {
+		relative := int64(binary.LittleEndian.Uint64(data[8+8*i:]))
+		if relative != 0 {
+			index.offsets[i] = start + relative
+		}
+	}
+	return index, start, nil
+}
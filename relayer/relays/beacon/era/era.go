@@ -0,0 +1,105 @@
+package era
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+var ErrNotFound = errors.New("not found in era file")
+
+// File is an era file, which holds the blocks of the slots before its era and the beacon state at the first slot of
+// its era, see https://github.com/status-im/nimbus-eth2/blob/stable/docs/e2store.md#era-files
+type File struct {
+	file       *os.File
+	stateIndex slotIndex
+	// The index of the blocks, which is empty for the genesis era
+	blockIndex slotIndex
+}
+
+func Open(path string) (*File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open era file: %w", err)
+	}
+	f, err := newFile(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("read era file %s: %w", path, err)
+	}
+	return f, nil
+}
+
+func newFile(file *os.File) (*File, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := readRecord(file, 0, TypeVersion)
+	if err != nil {
+		return nil, err
+	}
+	if len(version) != 0 {
+		return nil, fmt.Errorf("version record has length %d", len(version))
+	}
+
+	stateIndex, stateIndexStart, err := readSlotIndex(file, info.Size())
+	if err != nil {
+		return nil, fmt.Errorf("read state index: %w", err)
+	}
+	if len(stateIndex.offsets) != 1 {
+		return nil, fmt.Errorf("state index has %d entries, expected 1", len(stateIndex.offsets))
+	}
+
+	f := File{
+		file:       file,
+		stateIndex: stateIndex,
+	}
+	// The genesis era has no blocks, so the state is not preceded by a block index
+	if stateIndex.startSlot > 0 {
+		f.blockIndex, _, err = readSlotIndex(file, stateIndexStart)
+		if err != nil {
+			return nil, fmt.Errorf("read block index: %w", err)
+		}
+		if f.blockIndex.startSlot+uint64(len(f.blockIndex.offsets)) != stateIndex.startSlot {
+			return nil, fmt.Errorf("blocks from slot %d to %d do not precede the state at slot %d", f.blockIndex.startSlot, f.blockIndex.startSlot+uint64(len(f.blockIndex.offsets)), stateIndex.startSlot)
+		}
+	}
+
+	return &f, nil
+}
+
+func (f *File) Close() error {
+	return f.file.Close()
+}
+
+// StateSlot is the slot of the beacon state in the file
+func (f *File) StateSlot() uint64 {
+	return f.stateIndex.startSlot
+}
+
+// State returns the SSZ encoded beacon state
+func (f *File) State() ([]byte, error) {
+	return readCompressedRecord(f.file, f.stateIndex.offsets[0], TypeCompressedBeaconState)
+}
+
+// StateReader returns a reader of the SSZ encoded beacon state, which is decompressed while it is read. The reader
+// is valid until the file is closed.
+func (f *File) StateReader() (io.Reader, error) {
+	return openCompressedRecord(f.file, f.stateIndex.offsets[0], TypeCompressedBeaconState)
+}
+
+// Block returns the SSZ encoded signed beacon block at the slot. ErrNotFound is returned if the slot is not in the
+// file, or if it has no block.
+func (f *File) Block(slot uint64) ([]byte, error) {
+	if slot < f.blockIndex.startSlot || slot-f.blockIndex.startSlot >= uint64(len(f.blockIndex.offsets)) {
+		return nil, fmt.Errorf("block at slot %d: %w", slot, ErrNotFound)
+	}
+	offset := f.blockIndex.offsets[slot-f.blockIndex.startSlot]
+	if offset == 0 {
+		return nil, fmt.Errorf("block at slot %d: %w", slot, ErrNotFound)
+	}
+	return readCompressedRecord(f.file, offset, TypeCompressedSignedBeaconBlock)
+}
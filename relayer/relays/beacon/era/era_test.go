@@ -0,0 +1,279 @@
+package era
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/golang/snappy"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/config"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer/api"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/mock"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/protocol"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/state"
+	"github.com/snowfork/snowbridge/relayer/relays/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const slotsPerHistoricalRoot = 8192
+
+func writeRecord(buf *bytes.Buffer, recordType [2]byte, data []byte) {
+	var h [headerSize]byte
+	copy(h[:2], recordType[:])
+	binary.LittleEndian.PutUint32(h[2:6], uint32(len(data)))
+	buf.Write(h[:])
+	buf.Write(data)
+}
+
+func writeCompressedRecord(t *testing.T, buf *bytes.Buffer, recordType [2]byte, data []byte) {
+	var compressed bytes.Buffer
+	w := snappy.NewBufferedWriter(&compressed)
+	_, err := w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	writeRecord(buf, recordType, compressed.Bytes())
+}
+
+func writeSlotIndex(t *testing.T, buf *bytes.Buffer, startSlot uint64, offsets []int64) {
+	start := int64(buf.Len())
+	data := binary.LittleEndian.AppendUint64(nil, startSlot)
+	for _, offset := range offsets {
+		if offset != 0 {
+			offset -= start
+		}
+		data = binary.LittleEndian.AppendUint64(data, uint64(offset))
+	}
+	data = binary.LittleEndian.AppendUint64(data, uint64(len(offsets)))
+	writeRecord(buf, TypeSlotIndex, data)
+}
+
+// writeEra writes an era file with the blocks of the previous era, and the beacon state at the start of the era
+func writeEra(t *testing.T, dir string, era uint64, blocks map[uint64][]byte, beaconState []byte) string {
+	var buf bytes.Buffer
+	writeRecord(&buf, TypeVersion, nil)
+
+	stateSlot := era * slotsPerHistoricalRoot
+	var blockOffsets []int64
+	if era > 0 {
+		blockOffsets = make([]int64, slotsPerHistoricalRoot)
+		for i := range blockOffsets {
+			block, ok := blocks[stateSlot-slotsPerHistoricalRoot+uint64(i)]
+			if ok {
+				blockOffsets[i] = int64(buf.Len())
+				writeCompressedRecord(t, &buf, TypeCompressedSignedBeaconBlock, block)
+			}
+		}
+	}
+
+	stateOffset := int64(buf.Len())
+	writeCompressedRecord(t, &buf, TypeCompressedBeaconState, beaconState)
+
+	if era > 0 {
+		writeSlotIndex(t, &buf, stateSlot-slotsPerHistoricalRoot, blockOffsets)
+	}
+	writeSlotIndex(t, &buf, stateSlot, []int64{stateOffset})
+
+	path := filepath.Join(dir, fmt.Sprintf("sepolia-%05d-0123abcd.era", era))
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+	return path
+}
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	beaconState, err := testutil.LoadFile("4571136.ssz")
+	require.NoError(t, err)
+
+	firstBlock := []byte("signed beacon block at slot 4562944")
+	lastBlock := []byte("signed beacon block at slot 4571135")
+	path := writeEra(t, dir, 558, map[uint64][]byte{
+		4562944: firstBlock,
+		4571135: lastBlock,
+	}, beaconState)
+
+	f, err := Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	assert.Equal(t, uint64(4571136), f.StateSlot())
+	data, err := f.State()
+	require.NoError(t, err)
+	assert.Equal(t, beaconState, data)
+
+	block, err := f.Block(4562944)
+	require.NoError(t, err)
+	assert.Equal(t, firstBlock, block)
+	block, err = f.Block(4571135)
+	require.NoError(t, err)
+	assert.Equal(t, lastBlock, block)
+
+	// Empty slot
+	_, err = f.Block(4562945)
+	assert.ErrorIs(t, err, ErrNotFound)
+	// Slots of other eras
+	_, err = f.Block(4562943)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = f.Block(4571136)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestGenesisFile(t *testing.T) {
+	dir := t.TempDir()
+	genesisState := []byte("genesis beacon state")
+	path := writeEra(t, dir, 0, nil, genesisState)
+
+	f, err := Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	assert.Equal(t, uint64(0), f.StateSlot())
+	data, err := f.State()
+	require.NoError(t, err)
+	assert.Equal(t, genesisState, data)
+
+	_, err = f.Block(0)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestOpenInvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	path := writeEra(t, dir, 1, nil, []byte("beacon state"))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	truncated := filepath.Join(dir, "truncated.era")
+	require.NoError(t, os.WriteFile(truncated, data[:len(data)-8], 0644))
+	_, err = Open(truncated)
+	assert.Error(t, err)
+
+	noVersion := filepath.Join(dir, "no-version.era")
+	require.NoError(t, os.WriteFile(noVersion, data[headerSize:], 0644))
+	_, err = Open(noVersion)
+	assert.Error(t, err)
+}
+
+func TestArchive(t *testing.T) {
+	dir := t.TempDir()
+	beaconState, err := testutil.LoadFile("4571136.ssz")
+	require.NoError(t, err)
+
+	writeEra(t, dir, 558, nil, beaconState)
+	writeEra(t, dir, 559, nil, []byte("beacon state at slot 4579328"))
+	// Other files are ignored
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "checksums.txt"), nil, 0644))
+
+	archive, err := NewArchive(dir, slotsPerHistoricalRoot)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{558, 559}, archive.Eras())
+
+	data, err := archive.GetBeaconStateData(4571136)
+	require.NoError(t, err)
+	assert.Equal(t, beaconState, data)
+
+	// Only the states at the start of each era are archived
+	_, err = archive.GetBeaconStateData(4571137)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = archive.GetBeaconStateData(560 * slotsPerHistoricalRoot)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// States which are not in the datastore are read from the archive
+	beaconStore := NewStore(&mock.Store{
+		BeaconStateData: map[uint64][]byte{4571072: []byte("stored beacon state")},
+	}, archive)
+	data, err = beaconStore.GetBeaconStateData(4571072)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("stored beacon state"), data)
+	data, err = beaconStore.GetBeaconStateData(4571136)
+	require.NoError(t, err)
+	assert.Equal(t, beaconState, data)
+	_, err = beaconStore.GetBeaconStateData(4571137)
+	assert.Error(t, err)
+
+	// States are decompressed while they are read
+	reader, err := beaconStore.OpenBeaconState(4571136)
+	require.NoError(t, err)
+	data, err = io.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	assert.Equal(t, beaconState, data)
+	_, err = beaconStore.OpenBeaconState(4571137)
+	assert.Error(t, err)
+}
+
+func TestArchiveInvalidFileName(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sepolia.era"), nil, 0644))
+
+	_, err := NewArchive(dir, slotsPerHistoricalRoot)
+	assert.Error(t, err)
+}
+
+// blockRootNotFoundAPI is a beacon node which has no block roots
+type blockRootNotFoundAPI struct {
+	mock.API
+}
+
+func (m *blockRootNotFoundAPI) GetBeaconBlockRoot(slot uint64) (common.Hash, error) {
+	return common.Hash{}, api.ErrNotFound
+}
+
+func TestAPI(t *testing.T) {
+	response, err := testutil.GetBlockAtSlot(4571137)
+	require.NoError(t, err)
+	block, err := response.ToFastSSZ(config.Deneb, false)
+	require.NoError(t, err)
+	message, err := block.(*state.BeaconBlockDenebMainnet).MarshalSSZ()
+	require.NoError(t, err)
+	// A signed beacon block is the offset of the block, the signature and the block
+	signedBlock := binary.LittleEndian.AppendUint32(nil, 100)
+	signedBlock = append(signedBlock, make([]byte, 96)...)
+	signedBlock = append(signedBlock, message...)
+
+	dir := t.TempDir()
+	writeEra(t, dir, 559, map[uint64][]byte{4571137: signedBlock}, []byte("beacon state at slot 4579328"))
+	archive, err := NewArchive(dir, slotsPerHistoricalRoot)
+	require.NoError(t, err)
+
+	p := protocol.New(config.SpecSettings{
+		SlotsInEpoch:                 32,
+		EpochsPerSyncCommitteePeriod: 256,
+		SyncCommitteeSize:            512,
+		DenebForkEpoch:               0,
+	}, 0)
+	beaconAPI := NewAPI(&blockRootNotFoundAPI{mock.API{
+		BlocksAtSlot: map[uint64]api.BeaconBlockResponse{4571072: response},
+	}}, archive, p)
+
+	// Blocks which the beacon node does not have are read from the archive
+	archived, err := beaconAPI.GetBeaconBlockBySlot(4571137)
+	require.NoError(t, err)
+	assert.Equal(t, response.Data.Message.Body.SyncAggregate, archived.Data.Message.Body.SyncAggregate)
+	assert.Equal(t, response.Data.Message.Body.ExecutionPayload.BaseFeePerGas, archived.Data.Message.Body.ExecutionPayload.BaseFeePerGas)
+	archivedBlock, err := archived.ToFastSSZ(config.Deneb, false)
+	require.NoError(t, err)
+	tree, err := block.GetTree()
+	require.NoError(t, err)
+	archivedTree, err := archivedBlock.GetTree()
+	require.NoError(t, err)
+	assert.Equal(t, tree.Hash(), archivedTree.Hash())
+
+	root, err := beaconAPI.GetBeaconBlockRoot(4571137)
+	require.NoError(t, err)
+	assert.Equal(t, common.BytesToHash(tree.Hash()), root)
+
+	// Blocks of the beacon node are not read from the archive
+	fromNode, err := beaconAPI.GetBeaconBlockBySlot(4571072)
+	require.NoError(t, err)
+	assert.Equal(t, response, fromNode)
+
+	// Empty slots and eras which are not archived are not found
+	_, err = beaconAPI.GetBeaconBlockBySlot(4571138)
+	assert.ErrorIs(t, err, api.ErrNotFound)
+	_, err = beaconAPI.GetBeaconBlockBySlot(4579329)
+	assert.ErrorIs(t, err, api.ErrNotFound)
+}
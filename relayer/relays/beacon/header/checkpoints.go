@@ -0,0 +1,107 @@
+package header
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	ssz "github.com/ferranbt/fastssz"
+	log "github.com/sirupsen/logrus"
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/store"
+)
+
+// addCheckpoint adds a finalized checkpoint to the cache, and persists it in the datastore so that ancestry proofs
+// can be built after a restart without downloading its beacon state again.
+func (h *Header) addCheckpoint(blockRoot common.Hash, blockRootsTree *ssz.Node, slot uint64) {
+	prunedSlots := h.cache.AddCheckPoint(blockRoot, blockRootsTree, slot)
+
+	// Persisting is best effort, the checkpoint can be rebuilt from its beacon state
+	if len(prunedSlots) > 0 {
+		err := h.store.DeleteCheckpoints(prunedSlots)
+		if err != nil {
+			log.WithError(err).WithField("slots", prunedSlots).Warn("delete pruned checkpoints from datastore")
+		}
+	}
+	if blockRootsTree == nil {
+		return
+	}
+	blockRoots, err := h.blockRootsFromTree(blockRootsTree)
+	if err != nil {
+		log.WithError(err).WithField("slot", slot).Warn("read block roots of checkpoint")
+		return
+	}
+	err = h.store.WriteCheckpoint(store.Checkpoint{
+		Slot:       slot,
+		BlockRoot:  blockRoot,
+		BlockRoots: blockRoots,
+	})
+	if err != nil {
+		log.WithError(err).WithField("slot", slot).Warn("write checkpoint to datastore")
+	}
+}
+
+// LoadCheckpoints populates the cache with the checkpoints persisted in the datastore. Checkpoints which do not
+// match a finalized header of EthereumBeaconClient are deleted instead.
+func (h *Header) LoadCheckpoints() error {
+	checkpoints, err := h.store.ListCheckpoints()
+	if err != nil {
+		return fmt.Errorf("list checkpoints: %w", err)
+	}
+
+	var deleteSlots []uint64
+	for _, checkpoint := range checkpoints {
+		blockRootsTree, err := ssz.TreeFromChunks(checkpoint.BlockRoots)
+		if err != nil {
+			log.WithError(err).WithField("slot", checkpoint.Slot).Warn("stored checkpoint has invalid block roots")
+			deleteSlots = append(deleteSlots, checkpoint.Slot)
+			continue
+		}
+
+		onChainState, err := h.writer.GetFinalizedHeaderStateByBlockRoot(types.H256(checkpoint.BlockRoot))
+		if err != nil {
+			log.WithError(err).WithField("slot", checkpoint.Slot).Warn("stored checkpoint is not finalized on-chain")
+			deleteSlots = append(deleteSlots, checkpoint.Slot)
+			continue
+		}
+		if onChainState.BeaconSlot != checkpoint.Slot || onChainState.BlockRootsRoot != common.BytesToHash(blockRootsTree.Hash()) {
+			log.WithFields(log.Fields{
+				"slot":                      checkpoint.Slot,
+				"on_chain_slot":             onChainState.BeaconSlot,
+				"block_roots_root":          common.BytesToHash(blockRootsTree.Hash()),
+				"on_chain_block_roots_root": onChainState.BlockRootsRoot,
+			}).Warn("stored checkpoint does not match the finalized header on-chain")
+			deleteSlots = append(deleteSlots, checkpoint.Slot)
+			continue
+		}
+
+		deleteSlots = append(deleteSlots, h.cache.AddCheckPoint(checkpoint.BlockRoot, blockRootsTree, checkpoint.Slot)...)
+	}
+
+	if len(deleteSlots) > 0 {
+		err = h.store.DeleteCheckpoints(deleteSlots)
+		if err != nil {
+			return fmt.Errorf("delete checkpoints: %w", err)
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"loaded":  len(checkpoints) - len(deleteSlots),
+		"deleted": len(deleteSlots),
+	}).Info("loaded checkpoints from datastore")
+
+	return nil
+}
+
+// blockRootsFromTree returns the leaves of a block roots tree
+func (h *Header) blockRootsFromTree(blockRootsTree *ssz.Node) ([][]byte, error) {
+	count := int(h.protocol.SlotsPerHistoricalRoot)
+	blockRoots := make([][]byte, count)
+	for i := range blockRoots {
+		leaf, err := blockRootsTree.Get(count + i)
+		if err != nil {
+			return nil, fmt.Errorf("get block root %d: %w", i, err)
+		}
+		blockRoots[i] = leaf.Hash()
+	}
+	return blockRoots, nil
+}
@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
 	"github.com/snowfork/snowbridge/relayer/chain/parachain"
+	"github.com/snowfork/snowbridge/relayer/crypto/bls"
+	"github.com/snowfork/snowbridge/relayer/operatingmode"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/cache"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/config"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer"
@@ -28,25 +31,36 @@ var ErrSyncCommitteeNotImported = errors.New("sync committee not imported")
 var ErrSyncCommitteeLatency = errors.New("sync committee latency found")
 var ErrExecutionHeaderNotImported = errors.New("execution header not imported")
 var ErrBeaconHeaderNotFinalized = errors.New("beacon header not finalized")
+var ErrUpdateAlreadyImported = errors.New("update already imported by another relayer")
+var ErrSyncCommitteeUnknown = errors.New("sync committee of the signature period is not known on-chain")
 
 type Header struct {
 	cache              *cache.BeaconCache
+	store              store.BeaconStore
 	writer             parachain.ChainWriter
 	syncer             *syncer.Syncer
 	protocol           *protocol.Protocol
 	updateSlotInterval uint64
+	operatingMode      *operatingmode.Watcher
 }
 
-func New(writer parachain.ChainWriter, client api.BeaconAPI, setting config.SpecSettings, store store.BeaconStore, protocol *protocol.Protocol, updateSlotInterval uint64) Header {
+func New(writer parachain.ChainWriter, client api.BeaconAPI, setting config.SpecSettings, store store.BeaconStore, protocol *protocol.Protocol, updateSlotInterval uint64, operatingMode *operatingmode.Watcher) Header {
 	return Header{
 		cache:              cache.New(setting.SlotsInEpoch, setting.EpochsPerSyncCommitteePeriod),
+		store:              store,
 		writer:             writer,
 		syncer:             syncer.New(client, store, protocol),
 		protocol:           protocol,
 		updateSlotInterval: updateSlotInterval,
+		operatingMode:      operatingMode,
 	}
 }
 
+// SetSyncMode sets how updates are built, the state sync mode being the default
+func (h *Header) SetSyncMode(syncMode string) {
+	h.syncer.SetSyncMode(syncMode)
+}
+
 func (h *Header) Sync(ctx context.Context, eg *errgroup.Group) error {
 	lastFinalizedHeaderState, err := h.writer.GetLastFinalizedHeaderState()
 	if err != nil {
@@ -62,6 +76,10 @@ func (h *Header) Sync(ctx context.Context, eg *errgroup.Group) error {
 	h.cache.SetLastSyncedFinalizedState(lastFinalizedHeaderState.BeaconBlockRoot, lastFinalizedHeaderState.BeaconSlot)
 	h.cache.SetInitialCheckpointSlot(lastFinalizedHeaderState.InitialCheckpointSlot)
 	h.cache.AddCheckPointSlots([]uint64{lastFinalizedHeaderState.BeaconSlot})
+	err = h.LoadCheckpoints()
+	if err != nil {
+		log.WithError(err).Warn("load checkpoints from datastore")
+	}
 
 	// Special handling here for the initial checkpoint to sync the next sync committee which is not included in initial
 	// checkpoint.
@@ -79,6 +97,12 @@ func (h *Header) Sync(ctx context.Context, eg *errgroup.Group) error {
 
 	eg.Go(func() error {
 		for {
+			// Headers are imported once the beacon client is unhalted
+			err = h.operatingMode.Wait(ctx)
+			if err != nil {
+				return nil
+			}
+
 			err = h.SyncHeaders(ctx)
 			logFields := log.Fields{
 				"finalized_header": h.cache.Finalized.LastSyncedHash,
@@ -87,6 +111,8 @@ func (h *Header) Sync(ctx context.Context, eg *errgroup.Group) error {
 			switch {
 			case errors.Is(err, ErrFinalizedHeaderUnchanged):
 				log.WithFields(logFields).Info("not importing unchanged header")
+			case errors.Is(err, ErrUpdateAlreadyImported):
+				log.WithFields(logFields).Info("update already imported by another relayer, synced with the on-chain finalized header")
 			case errors.Is(err, ErrFinalizedHeaderNotImported):
 				log.WithFields(logFields).WithError(err).Warn("Not importing header this cycle")
 			case errors.Is(err, ErrSyncCommitteeNotImported):
@@ -99,6 +125,10 @@ func (h *Header) Sync(ctx context.Context, eg *errgroup.Group) error {
 				log.WithFields(logFields).WithError(err).Warn("beacon state not available for finalized state yet")
 			case errors.Is(err, syncer.ErrSyncCommitteeNotSuperMajority):
 				log.WithFields(logFields).WithError(err).Warn("update received was not signed by supermajority")
+			case errors.Is(err, syncer.ErrLightClientUpdateUnavailable):
+				log.WithFields(logFields).WithError(err).Warn("light client update not available for the slot range yet")
+			case errors.Is(err, syncer.ErrInvalidSyncCommitteeSignature):
+				log.WithFields(logFields).WithError(err).Warn("not submitting update with an invalid sync committee signature")
 			case err != nil:
 				return err
 			}
@@ -162,11 +192,18 @@ func (h *Header) SyncCommitteePeriodUpdate(ctx context.Context, period uint64) e
 		"period":                period,
 	}).Info("syncing sync committee for period")
 
-	err = h.writer.WriteToParachainAndWatch(ctx, "EthereumBeaconClient.submit", update.Payload)
+	err = h.verifyUpdate(update)
 	if err != nil {
 		return err
 	}
 
+	err = h.writer.WriteToParachainAndWatch(ctx, "EthereumBeaconClient.submit", update.Payload)
+	if parachain.IsAlreadyDelivered(err) {
+		return h.syncOnchainFinalizedState()
+	} else if err != nil {
+		return err
+	}
+
 	// Only update cache when SyncCommitteeUpdate import succeeded and period updated as expected
 	lastFinalizedHeaderState, err := h.writer.GetLastFinalizedHeaderState()
 	if err != nil {
@@ -177,7 +214,7 @@ func (h *Header) SyncCommitteePeriodUpdate(ctx context.Context, period uint64) e
 		return ErrSyncCommitteeNotImported
 	}
 	h.cache.SetLastSyncedFinalizedState(update.FinalizedHeaderBlockRoot, uint64(update.Payload.FinalizedHeader.Slot))
-	h.cache.AddCheckPoint(update.FinalizedHeaderBlockRoot, update.BlockRootsTree, uint64(update.Payload.FinalizedHeader.Slot))
+	h.addCheckpoint(update.FinalizedHeaderBlockRoot, update.BlockRootsTree, uint64(update.Payload.FinalizedHeader.Slot))
 
 	return nil
 }
@@ -221,8 +258,15 @@ func (h *Header) SyncFinalizedHeader(ctx context.Context) error {
 // Write the provided finalized header update (possibly containing a sync committee) on-chain and check if it was
 // imported successfully. Update the cache if it has and add the finalized header to the checkpoint cache.
 func (h *Header) updateFinalizedHeaderOnchain(ctx context.Context, update scale.Update) error {
-	err := h.writer.WriteToParachainAndWatch(ctx, "EthereumBeaconClient.submit", update.Payload)
+	err := h.verifyUpdate(update)
 	if err != nil {
+		return err
+	}
+
+	err = h.writer.WriteToParachainAndWatch(ctx, "EthereumBeaconClient.submit", update.Payload)
+	if parachain.IsAlreadyDelivered(err) {
+		return h.syncOnchainFinalizedState()
+	} else if err != nil {
 		return fmt.Errorf("write to parachain: %w", err)
 	}
 
@@ -239,10 +283,92 @@ func (h *Header) updateFinalizedHeaderOnchain(ctx context.Context, update scale.
 
 	// If the finalized header import succeeded, we add it to this cache.
 	h.cache.SetLastSyncedFinalizedState(update.FinalizedHeaderBlockRoot, uint64(update.Payload.FinalizedHeader.Slot))
-	h.cache.AddCheckPoint(update.FinalizedHeaderBlockRoot, update.BlockRootsTree, uint64(update.Payload.FinalizedHeader.Slot))
+	h.addCheckpoint(update.FinalizedHeaderBlockRoot, update.BlockRootsTree, uint64(update.Payload.FinalizedHeader.Slot))
+	return nil
+}
+
+// syncOnchainFinalizedState updates the cache with the finalized header on-chain, after another relayer imported an
+// update first. The next sync continues from that header instead of resubmitting updates which are not needed anymore.
+func (h *Header) syncOnchainFinalizedState() error {
+	lastFinalizedHeaderState, err := h.writer.GetLastFinalizedHeaderState()
+	if err != nil {
+		return fmt.Errorf("fetch last finalized header state: %w", err)
+	}
+	if lastFinalizedHeaderState.BeaconSlot > h.cache.Finalized.LastSyncedSlot {
+		h.cache.SetLastSyncedFinalizedState(lastFinalizedHeaderState.BeaconBlockRoot, lastFinalizedHeaderState.BeaconSlot)
+		h.cache.AddCheckPointSlots([]uint64{lastFinalizedHeaderState.BeaconSlot})
+	}
+	return ErrUpdateAlreadyImported
+}
+
+// verifyUpdate checks the sync committee signature of an update against the sync committee held by
+// EthereumBeaconClient, since it only rejects an update with a bad signature after the submission fees are paid. If
+// what the signature is verified with cannot be fetched, the update is submitted unverified.
+func (h *Header) verifyUpdate(update scale.Update) error {
+	committee, err := h.signingSyncCommittee(uint64(update.Payload.SignatureSlot))
+	if errors.Is(err, ErrSyncCommitteeUnknown) {
+		return fmt.Errorf("verify update with finalized header at slot %d: %w", update.Payload.FinalizedHeader.Slot, err)
+	}
+	if err != nil {
+		log.WithError(err).Warn("unable to fetch the sync committee, submitting the update without verifying its signature")
+		return nil
+	}
+	validatorsRoot, err := h.writer.GetValidatorsRoot()
+	if err != nil {
+		log.WithError(err).Warn("unable to fetch the validators root, submitting the update without verifying its signature")
+		return nil
+	}
+
+	err = h.syncer.VerifySyncAggregate(update.Payload, committee, validatorsRoot)
+	if errors.Is(err, syncer.ErrInvalidSyncCommitteeSignature) {
+		return fmt.Errorf("verify update with finalized header at slot %d: %w", update.Payload.FinalizedHeader.Slot, err)
+	}
+	if err != nil {
+		log.WithError(err).Warn("unable to verify the sync committee signature, submitting the update unverified")
+	}
 	return nil
 }
 
+// signingSyncCommittee returns the public keys of the sync committee which EthereumBeaconClient verifies the
+// signature at the slot with. It is the current committee in the period of the last finalized header, or the next
+// committee in the period after it.
+func (h *Header) signingSyncCommittee(signatureSlot uint64) ([][48]byte, error) {
+	lastFinalizedHeaderState, err := h.writer.GetLastFinalizedHeaderState()
+	if err != nil {
+		return nil, fmt.Errorf("fetch last finalized header state: %w", err)
+	}
+	storePeriod := h.protocol.ComputeSyncPeriodAtSlot(lastFinalizedHeaderState.BeaconSlot)
+	signaturePeriod := h.protocol.ComputeSyncPeriodAtSlot(signatureSlot)
+
+	var storage string
+	switch signaturePeriod {
+	case storePeriod:
+		storage = "CurrentSyncCommittee"
+	case storePeriod + 1:
+		storage = "NextSyncCommittee"
+	default:
+		return nil, fmt.Errorf("%w: signature period %d, finalized period %d", ErrSyncCommitteeUnknown, signaturePeriod, storePeriod)
+	}
+
+	committee, err := h.writer.GetSyncCommittee(storage)
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", storage, err)
+	}
+	if committee.Root == (types.H256{}) {
+		return nil, fmt.Errorf("%w: %s is not set", ErrSyncCommitteeUnknown, storage)
+	}
+
+	publicKeys := make([][48]byte, len(committee.Pubkeys))
+	for i, pubkey := range committee.Pubkeys {
+		publicKeys[i], err = bls.CompressPublicKey(pubkey)
+		if err != nil {
+			return nil, fmt.Errorf("public key %d of %s: %w", i, storage, err)
+		}
+	}
+
+	return publicKeys, nil
+}
+
 func (h *Header) SyncHeaders(ctx context.Context) error {
 	finalizedUpdate, err := h.syncer.Client.GetLatestFinalizedUpdate()
 	if err != nil {
@@ -354,7 +480,7 @@ func (h *Header) populateFinalizedCheckpoint(slot uint64) error {
 		return fmt.Errorf("fetch block roots for slot %d: %w", slot, err)
 	}
 
-	h.cache.AddCheckPoint(blockRoot, blockRootsProof.Tree, slot)
+	h.addCheckpoint(blockRoot, blockRootsProof.Tree, slot)
 
 	return nil
 }
@@ -1,11 +1,18 @@
 package header
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"github.com/snowfork/snowbridge/relayer/crypto/bls"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/config"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer/api"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer/scale"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/mock"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/protocol"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/state"
@@ -13,6 +20,8 @@ import (
 	"github.com/snowfork/snowbridge/relayer/relays/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"math/big"
+	"strconv"
 	"testing"
 )
 
@@ -37,6 +46,10 @@ func TestSyncInterimFinalizedUpdate_WithDataFromAPI(t *testing.T) {
 	require.NoError(t, err)
 	blockAtSlot4571137, err := testutil.GetBlockAtSlot(4571137)
 	require.NoError(t, err)
+	// The fixture block at slot 4571137 does not sign the attested header, so it is signed by a test committee,
+	// which becomes the next sync committee of the period of the last finalized header
+	committee := newTestSyncCommittee()
+	blockAtSlot4571137.Data.Message.Body.SyncAggregate = committee.sign(t, headerAtSlot4571136)
 
 	client.HeadersBySlot = map[uint64]api.BeaconHeader{
 		4571072: headerAtSlot4571072,
@@ -56,25 +69,205 @@ func TestSyncInterimFinalizedUpdate_WithDataFromAPI(t *testing.T) {
 	}
 	client.BeaconStates = beaconStates
 
+	currentCommittee, _ := stateSyncCommittees(t, "4571072.ssz")
+	writer := newSigningWriter(4563072, currentCommittee, committee.prepared)
+	client.Spec = sepoliaSpec
+
 	h := New(
-		&mock.Writer{
-			LastFinalizedState: state.FinalizedHeader{
-				BeaconBlockRoot:       common.Hash{},
-				BeaconSlot:            4562496,
-				InitialCheckpointRoot: common.Hash{},
-				InitialCheckpointSlot: 0,
-			},
-		},
+		writer,
 		&client,
 		settings,
 		&beaconStore,
 		p,
 		316,
+		nil,
 	)
 
 	// Find a checkpoint for a slot that is just out of the on-chain synced finalized header block roots range
 	_, err = h.syncInterimFinalizedUpdate(context.Background(), 4563072, 4571360)
 	require.NoError(t, err)
+
+	// An update which is not signed by the sync committee on-chain is not submitted
+	writer.LastFinalizedState.BeaconSlot = 4563072
+	writer.SyncCommittees["NextSyncCommittee"] = currentCommittee
+	_, err = h.syncInterimFinalizedUpdate(context.Background(), 4563072, 4571360)
+	require.ErrorIs(t, err, syncer.ErrInvalidSyncCommitteeSignature)
+
+	// Nor is an update signed by a committee which is not known on-chain yet
+	writer.LastFinalizedState.BeaconSlot = 4562496
+	_, err = h.syncInterimFinalizedUpdate(context.Background(), 4563072, 4571360)
+	require.ErrorIs(t, err, ErrSyncCommitteeUnknown)
+}
+
+// Verifies that in light client mode, the interim update is taken from the light client API, so that only the
+// finalized beacon state is downloaded for the block roots proof.
+func TestSyncInterimFinalizedUpdate_WithLightClientAPI(t *testing.T) {
+	settings := config.SpecSettings{
+		SlotsInEpoch:                 32,
+		EpochsPerSyncCommitteePeriod: 256,
+		DenebForkEpoch:               0,
+	}
+	p := protocol.New(settings, MaxRedundancy)
+	client := mock.API{}
+	beaconStore := mock.Store{}
+
+	headerAtSlot4571072, err := testutil.GetHeaderAtSlot(4571072)
+	require.NoError(t, err)
+	headerAtSlot4571136, err := testutil.GetHeaderAtSlot(4571136)
+	require.NoError(t, err)
+	blockAtSlot4571137, err := testutil.GetBlockAtSlot(4571137)
+	require.NoError(t, err)
+
+	client.LatestFinalisedUpdateResponse.Data.AttestedHeader.Beacon = toHeaderResponse(headerAtSlot4571136)
+	client.LatestFinalisedUpdateResponse.Data.FinalizedHeader.Beacon = toHeaderResponse(headerAtSlot4571072)
+	// The fixture block at slot 4571137 does not sign the attested header, so it is signed by a test committee,
+	// which becomes the next sync committee of the period of the last finalized header
+	committee := newTestSyncCommittee()
+	client.LatestFinalisedUpdateResponse.Data.SyncAggregate = committee.sign(t, headerAtSlot4571136)
+	client.LatestFinalisedUpdateResponse.Data.SignatureSlot = "4571137"
+	client.SyncCommitteePeriodUpdateResponse.Data.FinalizedHeader.Beacon = toHeaderResponse(headerAtSlot4571072)
+	client.BlocksAtSlot = map[uint64]api.BeaconBlockResponse{
+		4571137: blockAtSlot4571137,
+	}
+	// The attested beacon state is not available
+	client.BeaconStates = map[uint64]bool{
+		4571072: true,
+	}
+
+	currentCommittee, _ := stateSyncCommittees(t, "4571072.ssz")
+	writer := newSigningWriter(4563072, currentCommittee, committee.prepared)
+	client.Spec = sepoliaSpec
+
+	h := New(
+		writer,
+		&client,
+		settings,
+		&beaconStore,
+		p,
+		316,
+		nil,
+	)
+	h.SetSyncMode(config.SyncModeLightClient)
+
+	update, err := h.syncInterimFinalizedUpdate(context.Background(), 4563072, 4571360)
+	require.NoError(t, err)
+	assert.Equal(t, types.U64(4571072), update.Payload.FinalizedHeader.Slot)
+	assert.False(t, update.Payload.NextSyncCommitteeUpdate.HasValue)
+
+	// No light client update finalizes a header in the range
+	_, err = h.syncInterimFinalizedUpdate(context.Background(), 4571200, 4580000)
+	require.ErrorIs(t, err, syncer.ErrLightClientUpdateUnavailable)
+}
+
+// The fork versions of Sepolia, with which the sync committee signatures are verified
+var sepoliaSpec = map[string]string{
+	"CAPELLA_FORK_VERSION": "0x90000072",
+	"DENEB_FORK_VERSION":   "0x90000073",
+}
+
+var sepoliaValidatorsRoot = common.HexToHash("0xd8ea171f3c94aea21ebc42a1ed61052acf3f9209c00e4efbaaddac09ed9b8078")
+
+// newSigningWriter returns a writer whose last finalized header is at the slot, holding the current and next sync
+// committees with which EthereumBeaconClient verifies updates.
+func newSigningWriter(finalizedSlot uint64, current, next scale.SyncCommitteePrepared) *mock.Writer {
+	return &mock.Writer{
+		LastFinalizedState: state.FinalizedHeader{
+			BeaconSlot: finalizedSlot,
+		},
+		SyncCommittees: map[string]scale.SyncCommitteePrepared{
+			"CurrentSyncCommittee": current,
+			"NextSyncCommittee":    next,
+		},
+		ValidatorsRoot: sepoliaValidatorsRoot,
+	}
+}
+
+// stateSyncCommittees returns the current and next sync committees of a beacon state fixture, in the form in which
+// EthereumBeaconClient stores them
+func stateSyncCommittees(t *testing.T, stateFile string) (scale.SyncCommitteePrepared, scale.SyncCommitteePrepared) {
+	data, err := testutil.LoadFile(stateFile)
+	require.NoError(t, err)
+	beaconState := &state.BeaconStateDenebMainnet{}
+	require.NoError(t, beaconState.UnmarshalSSZ(data))
+	return prepareSyncCommittee(t, beaconState.CurrentSyncCommittee), prepareSyncCommittee(t, beaconState.NextSyncCommittee)
+}
+
+func prepareSyncCommittee(t *testing.T, committee *state.SyncCommittee) scale.SyncCommitteePrepared {
+	root, err := committee.HashTreeRoot()
+	require.NoError(t, err)
+	prepared := scale.SyncCommitteePrepared{Root: types.NewH256(root[:])}
+	for _, pubkey := range committee.PubKeys {
+		uncompressed, err := bls.DecompressPublicKey([48]byte(pubkey))
+		require.NoError(t, err)
+		prepared.Pubkeys = append(prepared.Pubkeys, uncompressed)
+	}
+	prepared.AggregatePubkey, err = bls.DecompressPublicKey(committee.AggregatePubKey)
+	require.NoError(t, err)
+	return prepared
+}
+
+// testSyncCommittee is a sync committee of the secret keys 1 to 512, which signs attested headers whose signing
+// blocks are not among the fixtures
+type testSyncCommittee struct {
+	prepared     scale.SyncCommitteePrepared
+	secretKeySum *big.Int
+}
+
+func newTestSyncCommittee() testSyncCommittee {
+	_, _, generator, _ := bls12381.Generators()
+	committee := testSyncCommittee{
+		prepared:     scale.SyncCommitteePrepared{Root: types.NewH256(common.HexToHash("0x01").Bytes())},
+		secretKeySum: new(big.Int),
+	}
+	var publicKey bls12381.G1Jac
+	var aggregate bls12381.G1Jac
+	for secretKey := int64(1); secretKey <= 512; secretKey++ {
+		publicKey.AddMixed(&generator)
+		aggregate.AddAssign(&publicKey)
+		var point bls12381.G1Affine
+		point.FromJacobian(&publicKey)
+		committee.prepared.Pubkeys = append(committee.prepared.Pubkeys, point.RawBytes())
+		committee.secretKeySum.Add(committee.secretKeySum, big.NewInt(secretKey))
+	}
+	var aggregatePoint bls12381.G1Affine
+	aggregatePoint.FromJacobian(&aggregate)
+	committee.prepared.AggregatePubkey = aggregatePoint.RawBytes()
+	return committee
+}
+
+// sign returns the sync aggregate of the whole committee over a header, signed with the Deneb fork version
+func (c testSyncCommittee) sign(t *testing.T, header api.BeaconHeader) api.SyncAggregateResponse {
+	scaleHeader, err := header.ToScale()
+	require.NoError(t, err)
+	headerRoot, err := scaleHeader.ToSSZ().HashTreeRoot()
+	require.NoError(t, err)
+
+	var versionChunk [32]byte
+	copy(versionChunk[:], common.FromHex(sepoliaSpec["DENEB_FORK_VERSION"]))
+	forkDataRoot := sha256.Sum256(append(versionChunk[:], sepoliaValidatorsRoot[:]...))
+	domain := append([]byte{0x07, 0x00, 0x00, 0x00}, forkDataRoot[:28]...)
+	signingRoot := sha256.Sum256(append(headerRoot[:], domain...))
+
+	message, err := bls12381.HashToG2(signingRoot[:], []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_"))
+	require.NoError(t, err)
+	var signature bls12381.G2Affine
+	signature.ScalarMultiplication(&message, c.secretKeySum)
+	signatureBytes := signature.Bytes()
+
+	return api.SyncAggregateResponse{
+		SyncCommitteeBits:      hexutil.Encode(bytes.Repeat([]byte{0xff}, 64)),
+		SyncCommitteeSignature: hexutil.Encode(signatureBytes[:]),
+	}
+}
+
+func toHeaderResponse(header api.BeaconHeader) api.HeaderResponse {
+	return api.HeaderResponse{
+		Slot:          strconv.FormatUint(header.Slot, 10),
+		ProposerIndex: strconv.FormatUint(header.ProposerIndex, 10),
+		ParentRoot:    header.ParentRoot.Hex(),
+		StateRoot:     header.StateRoot.Hex(),
+		BodyRoot:      header.BodyRoot.Hex(),
+	}
 }
 
 func TestSyncInterimFinalizedUpdate_WithDataFromStore(t *testing.T) {
@@ -95,6 +288,10 @@ func TestSyncInterimFinalizedUpdate_WithDataFromStore(t *testing.T) {
 	require.NoError(t, err)
 	blockAtSlot4571137, err := testutil.GetBlockAtSlot(4571137)
 	require.NoError(t, err)
+	// The fixture block at slot 4571137 does not sign the attested header, so it is signed by a test committee,
+	// which becomes the next sync committee of the period of the last finalized header
+	committee := newTestSyncCommittee()
+	blockAtSlot4571137.Data.Message.Body.SyncAggregate = committee.sign(t, headerAtSlot4571136)
 
 	client.HeadersBySlot = map[uint64]api.BeaconHeader{
 		4571072: headerAtSlot4571072,
@@ -120,20 +317,18 @@ func TestSyncInterimFinalizedUpdate_WithDataFromStore(t *testing.T) {
 		FinalizedBeaconState: finalizedState,
 	}
 
+	currentCommittee, _ := stateSyncCommittees(t, "4571072.ssz")
+	writer := newSigningWriter(4563072, currentCommittee, committee.prepared)
+	client.Spec = sepoliaSpec
+
 	h := New(
-		&mock.Writer{
-			LastFinalizedState: state.FinalizedHeader{
-				BeaconBlockRoot:       common.Hash{},
-				BeaconSlot:            4562496,
-				InitialCheckpointRoot: common.Hash{},
-				InitialCheckpointSlot: 0,
-			},
-		},
+		writer,
 		&client,
 		settings,
 		&beaconStore,
 		p,
 		316,
+		nil,
 	)
 
 	// Find a checkpoint for a slot that is just out of the on-chain synced finalized header block roots range
@@ -186,20 +381,19 @@ func TestSyncInterimFinalizedUpdate_WithDataFromStoreWithDifferentBlocks(t *test
 		FinalizedBeaconState: finalizedState,
 	}
 
+	// The block at slot 4570818 is signed by the sync committee of the period of the last finalized header
+	currentCommittee, nextCommittee := stateSyncCommittees(t, "4570752.ssz")
+	writer := newSigningWriter(4563072, currentCommittee, nextCommittee)
+	client.Spec = sepoliaSpec
+
 	h := New(
-		&mock.Writer{
-			LastFinalizedState: state.FinalizedHeader{
-				BeaconBlockRoot:       common.Hash{},
-				BeaconSlot:            4562496,
-				InitialCheckpointRoot: common.Hash{},
-				InitialCheckpointSlot: 0,
-			},
-		},
+		writer,
 		&client,
 		settings,
 		&beaconStore,
 		p,
 		316,
+		nil,
 	)
 
 	// Find a checkpoint for a slot that is just out of the on-chain synced finalized header block roots range
@@ -246,6 +440,7 @@ func TestSyncInterimFinalizedUpdate_BeaconStateNotAvailableInAPIAndStore(t *test
 		&beaconStore,
 		p,
 		316,
+		nil,
 	)
 
 	// Find a checkpoint for a slot that is just out of the on-chain synced finalized header block roots range
@@ -285,6 +480,7 @@ func TestSyncInterimFinalizedUpdate_NoValidBlocksFound(t *testing.T) {
 		&beaconStore,
 		p,
 		316,
+		nil,
 	)
 
 	// Find a checkpoint for a slot that is just out of the on-chain synced finalized header block roots range
@@ -408,6 +604,7 @@ func TestFindLatestCheckPoint(t *testing.T) {
 		&beaconStore,
 		p,
 		316,
+		nil,
 	)
 
 	// Slot 20 would be usable to prove slot 19
@@ -426,3 +623,76 @@ func TestFindLatestCheckPoint(t *testing.T) {
 	assert.Equal(t, headerIndex4, header.BeaconBlockRoot)
 	assert.Equal(t, uint64(46), header.BeaconSlot)
 }
+
+// Verifies that checkpoints are persisted in the datastore, and only reloaded if they match the finalized headers
+// on-chain.
+func TestLoadCheckpoints(t *testing.T) {
+	settings := config.SpecSettings{
+		SlotsInEpoch:                 32,
+		EpochsPerSyncCommitteePeriod: 256,
+		DenebForkEpoch:               0,
+	}
+	p := protocol.New(settings, MaxRedundancy)
+	client := mock.API{
+		BeaconStates: map[uint64]bool{
+			4571136: true,
+		},
+	}
+	beaconStore := mock.Store{}
+
+	h := New(&mock.Writer{}, &client, settings, &beaconStore, p, 316, nil)
+
+	blockRootsProof, err := h.syncer.GetBlockRoots(4571136)
+	require.NoError(t, err)
+	blockRootsRoot := common.BytesToHash(blockRootsProof.Tree.Hash())
+
+	finalizedBlockRoot := common.HexToHash("0x3e32d3c6fd9e2d4ec48a5e5bd9fd8a6ce2e1c60cfec3ee8d98e2c4f05bcb2d14")
+	h.addCheckpoint(finalizedBlockRoot, blockRootsProof.Tree, 4571136)
+	require.Len(t, beaconStore.Checkpoints, 1)
+	require.Len(t, beaconStore.Checkpoints[4571136].BlockRoots, int(p.SlotsPerHistoricalRoot))
+
+	// A checkpoint whose block roots do not match the on-chain finalized state
+	unfinalizedBlockRoot := common.HexToHash("0x8d2bd0c4b4dc3e0c5f0b3a1d1c0dcbd5a1a3f37d10a46ea31e24cba5e9b8c6f1")
+	err = beaconStore.WriteCheckpoint(store.Checkpoint{
+		Slot:       4571072,
+		BlockRoot:  unfinalizedBlockRoot,
+		BlockRoots: beaconStore.Checkpoints[4571136].BlockRoots,
+	})
+	require.NoError(t, err)
+
+	// After a restart, the cache is populated from the datastore
+	restarted := New(
+		&mock.Writer{
+			FinalizedHeaderStateByBlockRoot: map[types.H256]state.FinalizedHeader{
+				types.H256(finalizedBlockRoot): {
+					BeaconBlockRoot: finalizedBlockRoot,
+					BeaconSlot:      4571136,
+					BlockRootsRoot:  blockRootsRoot,
+				},
+				types.H256(unfinalizedBlockRoot): {
+					BeaconBlockRoot: unfinalizedBlockRoot,
+					BeaconSlot:      4571072,
+					BlockRootsRoot:  common.HexToHash("0x01"),
+				},
+			},
+		},
+		&mock.API{},
+		settings,
+		&beaconStore,
+		p,
+		316,
+		nil,
+	)
+	err = restarted.LoadCheckpoints()
+	require.NoError(t, err)
+
+	checkpoint, err := restarted.cache.GetClosestCheckpoint(4571000)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4571136), checkpoint.Slot)
+	assert.Equal(t, finalizedBlockRoot, checkpoint.FinalizedBlockRoot)
+	assert.Equal(t, blockRootsRoot, common.BytesToHash(checkpoint.BlockRootsTree.Hash()))
+
+	// The checkpoint which does not match is deleted
+	assert.Equal(t, []uint64{4571136}, restarted.cache.Finalized.Checkpoints.Slots)
+	assert.NotContains(t, beaconStore.Checkpoints, uint64(4571072))
+}
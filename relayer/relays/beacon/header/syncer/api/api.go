@@ -1,7 +1,6 @@
 package api
 
 import (
-	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -39,6 +38,8 @@ type BeaconAPI interface {
 	GetSyncCommitteePeriodUpdate(from uint64) (SyncCommitteePeriodUpdateResponse, error)
 	GetLatestFinalizedUpdate() (LatestFinalisedUpdateResponse, error)
 	GetBeaconState(stateIdOrSlot string) ([]byte, error)
+	OpenBeaconState(stateIdOrSlot string) (io.ReadCloser, error)
+	GetSpec() (map[string]string, error)
 }
 
 type BeaconClient struct {
@@ -123,6 +124,46 @@ func (b *BeaconClient) GetGenesis() (Genesis, error) {
 	}, nil
 }
 
+// GetSpec fetches the configuration of the beacon node, such as its preset values and fork schedule
+func (b *BeaconClient) GetSpec() (map[string]string, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/eth/v1/config/spec", b.endpoint), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ConstructRequestErrorMessage, err)
+	}
+
+	req.Header.Set("accept", "application/json")
+	res, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", DoHTTPRequestErrorMessage, err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %d", HTTPStatusNotOKErrorMessage, res.StatusCode)
+	}
+
+	bodyBytes, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ReadResponseBodyErrorMessage, err)
+	}
+
+	var response SpecResponse
+	err = json.Unmarshal(bodyBytes, &response)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", UnmarshalBodyErrorMessage, err)
+	}
+
+	// Skip values which are not strings, such as schedules added in later forks
+	spec := make(map[string]string, len(response.Data))
+	for key, raw := range response.Data {
+		var value string
+		if json.Unmarshal(raw, &value) == nil {
+			spec[key] = value
+		}
+	}
+
+	return spec, nil
+}
+
 func (b *BeaconClient) GetFinalizedCheckpoint() (FinalizedCheckpoint, error) {
 	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/eth/v1/beacon/states/head/finality_checkpoints", b.endpoint), nil)
 	if err != nil {
@@ -405,10 +446,21 @@ func (b *BeaconClient) GetLatestFinalizedUpdate() (LatestFinalisedUpdateResponse
 }
 
 func (b *BeaconClient) GetBeaconState(stateIdOrSlot string) ([]byte, error) {
-	var data []byte
+	body, err := b.OpenBeaconState(stateIdOrSlot)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return io.ReadAll(body)
+}
+
+// OpenBeaconState requests the SSZ encoded beacon state, which is read from the returned response body. The body must
+// be closed by the caller.
+func (b *BeaconClient) OpenBeaconState(stateIdOrSlot string) (io.ReadCloser, error) {
 	req, err := http.NewRequest("GET", fmt.Sprintf("%s/eth/v2/debug/beacon/states/%s", b.stateEndpoint, stateIdOrSlot), nil)
 	if err != nil {
-		return data, err
+		return nil, err
 	}
 
 	req.Header.Add("Accept", "application/octet-stream")
@@ -420,19 +472,17 @@ func (b *BeaconClient) GetBeaconState(stateIdOrSlot string) ([]byte, error) {
 	log.WithFields(log.Fields{"startTime": startTime.Format(time.UnixDate), "endTime": endTime.Format(time.UnixDate), "duration": duration.Seconds()}).Warn("beacon state download time")
 
 	if err != nil {
-		return data, err
+		return nil, err
 	}
 
 	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
 		if res.StatusCode == 404 {
-			return data, ErrNotFound
+			return nil, ErrNotFound
 		}
 
-		return data, fmt.Errorf("%s: %d", DoHTTPRequestErrorMessage, res.StatusCode)
+		return nil, fmt.Errorf("%s: %d", DoHTTPRequestErrorMessage, res.StatusCode)
 	}
 
-	buf := new(bytes.Buffer)
-	buf.ReadFrom(res.Body)
-	data = buf.Bytes()
-	return data, nil
+	return res.Body, nil
 }
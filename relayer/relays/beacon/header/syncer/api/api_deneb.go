@@ -11,7 +11,9 @@ import (
 	"github.com/snowfork/snowbridge/relayer/relays/util"
 )
 
-func DenebExecutionPayloadToScale(e *state.ExecutionPayloadDeneb) (scale.ExecutionPayloadHeaderDeneb, error) {
+// DenebExecutionPayloadToScale converts the payload to a header, hashing the withdrawals with the list
+// limit of the given container, which differs between presets.
+func DenebExecutionPayloadToScale(e *state.ExecutionPayloadDeneb, withdrawalContainer state.WithdrawalsRootContainer) (scale.ExecutionPayloadHeaderDeneb, error) {
 	var payloadHeader scale.ExecutionPayloadHeaderDeneb
 	transactionsContainer := state.TransactionsRootContainer{}
 	transactionsContainer.Transactions = e.Transactions
@@ -23,8 +25,7 @@ func DenebExecutionPayloadToScale(e *state.ExecutionPayloadDeneb) (scale.Executi
 
 	var withdrawalRoot types.H256
 
-	withdrawalContainer := state.WithdrawalsRootContainerMainnet{}
-	withdrawalContainer.Withdrawals = e.Withdrawals
+	withdrawalContainer.SetWithdrawals(e.Withdrawals)
 	withdrawalRoot, err = withdrawalContainer.HashTreeRoot()
 	if err != nil {
 		return payloadHeader, err
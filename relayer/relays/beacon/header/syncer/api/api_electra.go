@@ -0,0 +1,240 @@
+package api
+
+import (
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer/scale"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/state"
+	"github.com/snowfork/snowbridge/relayer/relays/util"
+)
+
+type ExecutionRequestsResponse struct {
+	Deposits       []DepositRequestResponse       `json:"deposits"`
+	Withdrawals    []WithdrawalRequestResponse    `json:"withdrawals"`
+	Consolidations []ConsolidationRequestResponse `json:"consolidations"`
+}
+
+type DepositRequestResponse struct {
+	Pubkey                string `json:"pubkey"`
+	WithdrawalCredentials string `json:"withdrawal_credentials"`
+	Amount                string `json:"amount"`
+	Signature             string `json:"signature"`
+	Index                 string `json:"index"`
+}
+
+type WithdrawalRequestResponse struct {
+	SourceAddress   string `json:"source_address"`
+	ValidatorPubkey string `json:"validator_pubkey"`
+	Amount          string `json:"amount"`
+}
+
+type ConsolidationRequestResponse struct {
+	SourceAddress string `json:"source_address"`
+	SourcePubkey  string `json:"source_pubkey"`
+	TargetPubkey  string `json:"target_pubkey"`
+}
+
+func (e ExecutionRequestsResponse) ToFastSSZ() (*state.ExecutionRequests, error) {
+	deposits := []*state.DepositRequest{}
+	for _, deposit := range e.Deposits {
+		depositSSZ, err := deposit.ToFastSSZ()
+		if err != nil {
+			return nil, err
+		}
+		deposits = append(deposits, depositSSZ)
+	}
+
+	withdrawals := []*state.WithdrawalRequest{}
+	for _, withdrawal := range e.Withdrawals {
+		withdrawalSSZ, err := withdrawal.ToFastSSZ()
+		if err != nil {
+			return nil, err
+		}
+		withdrawals = append(withdrawals, withdrawalSSZ)
+	}
+
+	consolidations := []*state.ConsolidationRequest{}
+	for _, consolidation := range e.Consolidations {
+		consolidationSSZ, err := consolidation.ToFastSSZ()
+		if err != nil {
+			return nil, err
+		}
+		consolidations = append(consolidations, consolidationSSZ)
+	}
+
+	return &state.ExecutionRequests{
+		Deposits:       deposits,
+		Withdrawals:    withdrawals,
+		Consolidations: consolidations,
+	}, nil
+}
+
+func (e ExecutionRequestsResponse) ToScale() (scale.ExecutionRequests, error) {
+	requests, err := e.ToFastSSZ()
+	if err != nil {
+		return scale.ExecutionRequests{}, err
+	}
+
+	deposits := []scale.DepositRequest{}
+	for _, deposit := range requests.Deposits {
+		deposits = append(deposits, scale.DepositRequest{
+			Pubkey:                deposit.Pubkey[:],
+			WithdrawalCredentials: types.NewH256(deposit.WithdrawalCredentials[:]),
+			Amount:                types.NewU64(deposit.Amount),
+			Signature:             deposit.Signature[:],
+			Index:                 types.NewU64(deposit.Index),
+		})
+	}
+
+	withdrawals := []scale.WithdrawalRequest{}
+	for _, withdrawal := range requests.Withdrawals {
+		withdrawals = append(withdrawals, scale.WithdrawalRequest{
+			SourceAddress:   types.NewH160(withdrawal.SourceAddress[:]),
+			ValidatorPubkey: withdrawal.ValidatorPubkey[:],
+			Amount:          types.NewU64(withdrawal.Amount),
+		})
+	}
+
+	consolidations := []scale.ConsolidationRequest{}
+	for _, consolidation := range requests.Consolidations {
+		consolidations = append(consolidations, scale.ConsolidationRequest{
+			SourceAddress: types.NewH160(consolidation.SourceAddress[:]),
+			SourcePubkey:  consolidation.SourcePubkey[:],
+			TargetPubkey:  consolidation.TargetPubkey[:],
+		})
+	}
+
+	return scale.ExecutionRequests{
+		Deposits:       deposits,
+		Withdrawals:    withdrawals,
+		Consolidations: consolidations,
+	}, nil
+}
+
+func (d DepositRequestResponse) ToFastSSZ() (*state.DepositRequest, error) {
+	pubkey, err := util.HexStringToPublicKey(d.Pubkey)
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawalCredentials, err := util.HexStringTo32Bytes(d.WithdrawalCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := util.ToUint64(d.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := util.HexStringTo96Bytes(d.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := util.ToUint64(d.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	return &state.DepositRequest{
+		Pubkey:                pubkey,
+		WithdrawalCredentials: withdrawalCredentials,
+		Amount:                amount,
+		Signature:             signature,
+		Index:                 index,
+	}, nil
+}
+
+func (w WithdrawalRequestResponse) ToFastSSZ() (*state.WithdrawalRequest, error) {
+	sourceAddress, err := util.HexStringTo20Bytes(w.SourceAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	validatorPubkey, err := util.HexStringToPublicKey(w.ValidatorPubkey)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := util.ToUint64(w.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &state.WithdrawalRequest{
+		SourceAddress:   sourceAddress,
+		ValidatorPubkey: validatorPubkey,
+		Amount:          amount,
+	}, nil
+}
+
+func (c ConsolidationRequestResponse) ToFastSSZ() (*state.ConsolidationRequest, error) {
+	sourceAddress, err := util.HexStringTo20Bytes(c.SourceAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	sourcePubkey, err := util.HexStringToPublicKey(c.SourcePubkey)
+	if err != nil {
+		return nil, err
+	}
+
+	targetPubkey, err := util.HexStringToPublicKey(c.TargetPubkey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &state.ConsolidationRequest{
+		SourceAddress: sourceAddress,
+		SourcePubkey:  sourcePubkey,
+		TargetPubkey:  targetPubkey,
+	}, nil
+}
+
+// ToFastSSZElectra converts an attestation which carries the committee bits introduced in Electra
+func (a AttestationResponse) ToFastSSZElectra() (*state.AttestationElectra, error) {
+	data, err := a.Data.ToFastSSZ()
+	if err != nil {
+		return nil, err
+	}
+
+	aggregationBits, err := util.HexStringToByteArray(a.AggregationBits)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := util.HexStringTo96Bytes(a.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	committeeBits, err := util.HexStringToByteArray(a.CommitteeBits)
+	if err != nil {
+		return nil, err
+	}
+
+	return &state.AttestationElectra{
+		AggregationBits: aggregationBits,
+		Data:            data,
+		Signature:       signature,
+		CommitteeBits:   committeeBits,
+	}, nil
+}
+
+func (a AttesterSlashingResponse) ToFastSSZElectra() (*state.AttesterSlashingElectra, error) {
+	attestation1, err := a.Attestation1.ToFastSSZ()
+	if err != nil {
+		return nil, err
+	}
+
+	attestation2, err := a.Attestation2.ToFastSSZ()
+	if err != nil {
+		return nil, err
+	}
+
+	// Indexed attestations only differ from earlier forks in their SSZ list limits
+	return &state.AttesterSlashingElectra{
+		Attestation1: (*state.IndexedAttestationElectra)(attestation1),
+		Attestation2: (*state.IndexedAttestationElectra)(attestation2),
+	}, nil
+}
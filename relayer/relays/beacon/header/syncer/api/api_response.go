@@ -1,12 +1,14 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"strconv"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/config"
 	beaconjson "github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer/json"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer/scale"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/state"
@@ -76,6 +78,7 @@ type BeaconBlockResponseBody struct {
 	} `json:"execution_payload"`
 	BlsToExecutionChanges []SignedBLSToExecutionChangeResponse `json:"bls_to_execution_changes"`
 	BlobKzgCommitments    []string                             `json:"blob_kzg_commitments"`
+	ExecutionRequests     ExecutionRequestsResponse            `json:"execution_requests"`
 }
 
 type BeaconBlockResponse struct {
@@ -150,6 +153,7 @@ type AttestationResponse struct {
 	AggregationBits string                  `json:"aggregation_bits"`
 	Data            AttestationDataResponse `json:"data"`
 	Signature       string                  `json:"signature"`
+	CommitteeBits   string                  `json:"committee_bits,omitempty"`
 }
 
 type SignedVoluntaryExitResponse struct {
@@ -251,6 +255,10 @@ type GenesisResponse struct {
 	} `json:"data"`
 }
 
+type SpecResponse struct {
+	Data map[string]json.RawMessage `json:"data"`
+}
+
 type ErrorMessage struct {
 	StatusCode int    `json:"statusCode"`
 	Error      string `json:"error"`
@@ -460,8 +468,13 @@ func (s SyncAggregateResponse) ToScale() (scale.SyncAggregate, error) {
 // ToFastSSZ can be removed once Lodestar supports returning block data as SSZ instead of JSON only.
 // Because it only returns JSON, we need this interim step where we convert the block JSON to the data
 // types that the FastSSZ lib expects. When Lodestar supports SSZ block response, we can remove all these
-// and directly unmarshal SSZ bytes to state.BeaconBlock.
-func (b BeaconBlockResponse) ToFastSSZ(isDeneb bool) (state.BeaconBlock, error) {
+// and directly unmarshal SSZ bytes to state.BeaconBlock. Blocks of chains running the minimal preset are
+// only supported from Deneb onwards, which the minimal preset activates at genesis.
+func (b BeaconBlockResponse) ToFastSSZ(fork string, minimal bool) (state.BeaconBlock, error) {
+	if minimal && fork != config.Deneb {
+		return nil, fmt.Errorf("%s blocks are not supported with the minimal preset", fork)
+	}
+	isElectra := fork == config.Electra
 	data := b.Data.Message
 
 	slot, err := util.ToUint64(data.Slot)
@@ -522,7 +535,18 @@ func (b BeaconBlockResponse) ToFastSSZ(isDeneb bool) (state.BeaconBlock, error)
 	}
 
 	attesterSlashings := []*state.AttesterSlashing{}
+	attesterSlashingsElectra := []*state.AttesterSlashingElectra{}
 	for _, attesterSlashing := range body.AttesterSlashings {
+		if isElectra {
+			attesterSlashingSSZ, err := attesterSlashing.ToFastSSZElectra()
+			if err != nil {
+				return nil, err
+			}
+
+			attesterSlashingsElectra = append(attesterSlashingsElectra, attesterSlashingSSZ)
+			continue
+		}
+
 		attesterSlashingSSZ, err := attesterSlashing.ToFastSSZ()
 		if err != nil {
 			return nil, err
@@ -532,7 +556,18 @@ func (b BeaconBlockResponse) ToFastSSZ(isDeneb bool) (state.BeaconBlock, error)
 	}
 
 	attestations := []*state.Attestation{}
+	attestationsElectra := []*state.AttestationElectra{}
 	for _, attestation := range body.Attestations {
+		if isElectra {
+			attestationSSZ, err := attestation.ToFastSSZElectra()
+			if err != nil {
+				return nil, err
+			}
+
+			attestationsElectra = append(attestationsElectra, attestationSSZ)
+			continue
+		}
+
 		attestationSSZ, err := attestation.ToFastSSZ()
 		if err != nil {
 			return nil, err
@@ -691,8 +726,61 @@ func (b BeaconBlockResponse) ToFastSSZ(isDeneb bool) (state.BeaconBlock, error)
 		kzgCommitments = append(kzgCommitments, kzgCommitmentSSZ)
 	}
 
-	if isDeneb {
-		return &state.BeaconBlockDenebMainnet{
+	if isElectra {
+		executionRequests, err := body.ExecutionRequests.ToFastSSZ()
+		if err != nil {
+			return nil, err
+		}
+
+		return &state.BeaconBlockElectraMainnet{
+			Slot:          slot,
+			ProposerIndex: proposerIndex,
+			ParentRoot:    parentRoot,
+			StateRoot:     stateRoot,
+			Body: &state.BeaconBlockBodyElectraMainnet{
+				RandaoReveal: randaoReveal,
+				Eth1Data: &state.Eth1Data{
+					DepositRoot:  eth1DepositRoot,
+					DepositCount: eth1DepositCount,
+					BlockHash:    eth1BlockHash,
+				},
+				Graffiti:          graffiti,
+				ProposerSlashings: proposerSlashings,
+				AttesterSlashings: attesterSlashingsElectra, // new for Electra
+				Attestations:      attestationsElectra,      // new for Electra
+				Deposits:          deposits,
+				VoluntaryExits:    voluntaryExits,
+				SyncAggregate: &state.SyncAggregateMainnet{
+					SyncCommitteeBits:      syncCommitteeBits,
+					SyncCommitteeSignature: syncCommitteeSignature,
+				},
+				ExecutionPayload: &state.ExecutionPayloadDeneb{
+					ParentHash:    parentHash,
+					FeeRecipient:  feeRecipient,
+					StateRoot:     executionStateRoot,
+					ReceiptsRoot:  receiptsRoot,
+					LogsBloom:     logsBloom,
+					PrevRandao:    prevRando,
+					BlockNumber:   blockNumber,
+					GasLimit:      gasLimit,
+					GasUsed:       gasUsed,
+					Timestamp:     timestamp,
+					ExtraData:     extraData,
+					BaseFeePerGas: baseFeePerGasBytes,
+					BlockHash:     blockHash,
+					Transactions:  transactions,
+					Withdrawals:   withdrawals,
+					BlobGasUsed:   blobGasUsed,
+					ExcessBlobGas: excessBlobGas,
+				},
+				BlsToExecutionChanges: blsExecutionChanges,
+				BlobKzgCommitments:    kzgCommitments,
+				ExecutionRequests:     executionRequests, // new for Electra
+			},
+		}, nil
+	}
+	if fork == config.Deneb {
+		block := &state.BeaconBlockDenebMainnet{
 			Slot:          slot,
 			ProposerIndex: proposerIndex,
 			ParentRoot:    parentRoot,
@@ -736,7 +824,11 @@ func (b BeaconBlockResponse) ToFastSSZ(isDeneb bool) (state.BeaconBlock, error)
 				BlsToExecutionChanges: blsExecutionChanges,
 				BlobKzgCommitments:    kzgCommitments, // new for Deneb
 			},
-		}, nil
+		}
+		if minimal {
+			return state.NewBeaconBlockDenebMinimal(block), nil
+		}
+		return block, nil
 	}
 	return &state.BeaconBlockCapellaMainnet{
 		Slot:          slot,
@@ -0,0 +1,340 @@
+package api
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/state"
+	"github.com/snowfork/snowbridge/relayer/relays/util"
+)
+
+// BeaconBlockResponseFromSSZ converts a block decoded from SSZ, such as a block read from an era file, into the
+// response of the beacon API, so that it can be used wherever the syncer fetches blocks from a beacon node. It is
+// the inverse of BeaconBlockResponse.ToFastSSZ.
+func BeaconBlockResponseFromSSZ(block state.BeaconBlock) (BeaconBlockResponse, error) {
+	var message BeaconBlockResponseMessage
+	switch b := block.(type) {
+	case *state.BeaconBlockCapellaMainnet:
+		message = blockMessageResponse(b.Slot, b.ProposerIndex, b.ParentRoot, b.StateRoot)
+		body := &message.Body
+		setBlockBodyResponse(body, b.Body.RandaoReveal, b.Body.Eth1Data, b.Body.Graffiti, b.Body.ProposerSlashings, b.Body.Deposits, b.Body.VoluntaryExits, b.Body.BlsToExecutionChanges)
+		body.AttesterSlashings = attesterSlashingsResponse(b.Body.AttesterSlashings)
+		body.Attestations = attestationsResponse(b.Body.Attestations)
+		body.SyncAggregate = syncAggregateResponse(b.Body.SyncAggregate.SyncCommitteeBits, b.Body.SyncAggregate.SyncCommitteeSignature)
+		payload := state.ExecutionPayloadDeneb{
+			ParentHash:    b.Body.ExecutionPayload.ParentHash,
+			FeeRecipient:  b.Body.ExecutionPayload.FeeRecipient,
+			StateRoot:     b.Body.ExecutionPayload.StateRoot,
+			ReceiptsRoot:  b.Body.ExecutionPayload.ReceiptsRoot,
+			LogsBloom:     b.Body.ExecutionPayload.LogsBloom,
+			PrevRandao:    b.Body.ExecutionPayload.PrevRandao,
+			BlockNumber:   b.Body.ExecutionPayload.BlockNumber,
+			GasLimit:      b.Body.ExecutionPayload.GasLimit,
+			GasUsed:       b.Body.ExecutionPayload.GasUsed,
+			Timestamp:     b.Body.ExecutionPayload.Timestamp,
+			ExtraData:     b.Body.ExecutionPayload.ExtraData,
+			BaseFeePerGas: b.Body.ExecutionPayload.BaseFeePerGas,
+			BlockHash:     b.Body.ExecutionPayload.BlockHash,
+			Transactions:  b.Body.ExecutionPayload.Transactions,
+			Withdrawals:   b.Body.ExecutionPayload.Withdrawals,
+		}
+		setExecutionPayloadResponse(body, &payload, false)
+	case *state.BeaconBlockDenebMinimal:
+		return BeaconBlockResponseFromSSZ(&state.BeaconBlockDenebMainnet{
+			Slot:          b.Slot,
+			ProposerIndex: b.ProposerIndex,
+			ParentRoot:    b.ParentRoot,
+			StateRoot:     b.StateRoot,
+			Body: &state.BeaconBlockBodyDenebMainnet{
+				RandaoReveal:          b.Body.RandaoReveal,
+				Eth1Data:              b.Body.Eth1Data,
+				Graffiti:              b.Body.Graffiti,
+				ProposerSlashings:     b.Body.ProposerSlashings,
+				AttesterSlashings:     b.Body.AttesterSlashings,
+				Attestations:          b.Body.Attestations,
+				Deposits:              b.Body.Deposits,
+				VoluntaryExits:        b.Body.VoluntaryExits,
+				SyncAggregate:         (*state.SyncAggregateMainnet)(b.Body.SyncAggregate),
+				ExecutionPayload:      (*state.ExecutionPayloadDeneb)(b.Body.ExecutionPayload),
+				BlsToExecutionChanges: b.Body.BlsToExecutionChanges,
+				BlobKzgCommitments:    b.Body.BlobKzgCommitments,
+			},
+		})
+	case *state.BeaconBlockDenebMainnet:
+		message = blockMessageResponse(b.Slot, b.ProposerIndex, b.ParentRoot, b.StateRoot)
+		body := &message.Body
+		setBlockBodyResponse(body, b.Body.RandaoReveal, b.Body.Eth1Data, b.Body.Graffiti, b.Body.ProposerSlashings, b.Body.Deposits, b.Body.VoluntaryExits, b.Body.BlsToExecutionChanges)
+		body.AttesterSlashings = attesterSlashingsResponse(b.Body.AttesterSlashings)
+		body.Attestations = attestationsResponse(b.Body.Attestations)
+		body.SyncAggregate = syncAggregateResponse(b.Body.SyncAggregate.SyncCommitteeBits, b.Body.SyncAggregate.SyncCommitteeSignature)
+		setExecutionPayloadResponse(body, b.Body.ExecutionPayload, true)
+		body.BlobKzgCommitments = kzgCommitmentsResponse(b.Body.BlobKzgCommitments)
+	case *state.BeaconBlockElectraMainnet:
+		message = blockMessageResponse(b.Slot, b.ProposerIndex, b.ParentRoot, b.StateRoot)
+		body := &message.Body
+		setBlockBodyResponse(body, b.Body.RandaoReveal, b.Body.Eth1Data, b.Body.Graffiti, b.Body.ProposerSlashings, b.Body.Deposits, b.Body.VoluntaryExits, b.Body.BlsToExecutionChanges)
+		body.AttesterSlashings = attesterSlashingsElectraResponse(b.Body.AttesterSlashings)
+		body.Attestations = attestationsElectraResponse(b.Body.Attestations)
+		body.SyncAggregate = syncAggregateResponse(b.Body.SyncAggregate.SyncCommitteeBits, b.Body.SyncAggregate.SyncCommitteeSignature)
+		setExecutionPayloadResponse(body, b.Body.ExecutionPayload, true)
+		body.BlobKzgCommitments = kzgCommitmentsResponse(b.Body.BlobKzgCommitments)
+		body.ExecutionRequests = executionRequestsResponse(b.Body.ExecutionRequests)
+	default:
+		return BeaconBlockResponse{}, fmt.Errorf("unsupported beacon block type %T", block)
+	}
+
+	return BeaconBlockResponse{Data: BeaconBlockResponseData{Message: message}}, nil
+}
+
+func blockMessageResponse(slot, proposerIndex uint64, parentRoot, stateRoot []byte) BeaconBlockResponseMessage {
+	return BeaconBlockResponseMessage{
+		Slot:          strconv.FormatUint(slot, 10),
+		ProposerIndex: strconv.FormatUint(proposerIndex, 10),
+		ParentRoot:    util.BytesToHexString(parentRoot),
+		StateRoot:     util.BytesToHexString(stateRoot),
+	}
+}
+
+// setBlockBodyResponse sets the fields of a block body which are the same in all forks
+func setBlockBodyResponse(
+	body *BeaconBlockResponseBody,
+	randaoReveal []byte,
+	eth1Data *state.Eth1Data,
+	graffiti [32]byte,
+	proposerSlashings []*state.ProposerSlashing,
+	deposits []*state.Deposit,
+	voluntaryExits []*state.SignedVoluntaryExit,
+	blsToExecutionChanges []*state.SignedBLSToExecutionChange,
+) {
+	body.RandaoReveal = util.BytesToHexString(randaoReveal)
+	body.Eth1Data.DepositRoot = util.BytesToHexString(eth1Data.DepositRoot)
+	body.Eth1Data.DepositCount = strconv.FormatUint(eth1Data.DepositCount, 10)
+	body.Eth1Data.BlockHash = util.BytesToHexString(eth1Data.BlockHash)
+	body.Graffiti = util.BytesToHexString(graffiti[:])
+
+	body.ProposerSlashings = []ProposerSlashingResponse{}
+	for _, slashing := range proposerSlashings {
+		body.ProposerSlashings = append(body.ProposerSlashings, ProposerSlashingResponse{
+			SignedHeader1: signedHeaderResponse(slashing.Header1),
+			SignedHeader2: signedHeaderResponse(slashing.Header2),
+		})
+	}
+
+	body.Deposits = []DepositResponse{}
+	for _, deposit := range deposits {
+		proof := []string{}
+		for _, node := range deposit.Proof {
+			proof = append(proof, util.BytesToHexString(node))
+		}
+		body.Deposits = append(body.Deposits, DepositResponse{
+			Proof: proof,
+			Data: DepositDataResponse{
+				Pubkey:                util.BytesToHexString(deposit.Data.Pubkey[:]),
+				WithdrawalCredentials: util.BytesToHexString(deposit.Data.WithdrawalCredentials[:]),
+				Amount:                strconv.FormatUint(deposit.Data.Amount, 10),
+				Signature:             util.BytesToHexString(deposit.Data.Signature),
+			},
+		})
+	}
+
+	body.VoluntaryExits = []SignedVoluntaryExitResponse{}
+	for _, exit := range voluntaryExits {
+		body.VoluntaryExits = append(body.VoluntaryExits, SignedVoluntaryExitResponse{
+			Message: VoluntaryExitResponse{
+				Epoch:          strconv.FormatUint(exit.Exit.Epoch, 10),
+				ValidatorIndex: strconv.FormatUint(exit.Exit.ValidatorIndex, 10),
+			},
+			Signature: util.BytesToHexString(exit.Signature[:]),
+		})
+	}
+
+	body.BlsToExecutionChanges = []SignedBLSToExecutionChangeResponse{}
+	for _, change := range blsToExecutionChanges {
+		body.BlsToExecutionChanges = append(body.BlsToExecutionChanges, SignedBLSToExecutionChangeResponse{
+			Message: &BLSToExecutionChangeResponse{
+				ValidatorIndex:     strconv.FormatUint(change.Message.ValidatorIndex, 10),
+				FromBlsPubkey:      util.BytesToHexString(change.Message.FromBlsPubkey),
+				ToExecutionAddress: util.BytesToHexString(change.Message.ToExecutionAddress),
+			},
+			Signature: util.BytesToHexString(change.Signature),
+		})
+	}
+}
+
+// setExecutionPayloadResponse sets the execution payload of a block body. Payloads before Deneb have no blob gas
+// fields, and are passed as a Deneb payload with deneb false.
+func setExecutionPayloadResponse(body *BeaconBlockResponseBody, payload *state.ExecutionPayloadDeneb, deneb bool) {
+	// FastSSZ holds a little endian byte array, which is copied as ChangeByteOrder reverses it in place
+	baseFeePerGas := payload.BaseFeePerGas
+	baseFee := new(big.Int).SetBytes(util.ChangeByteOrder(baseFeePerGas[:]))
+
+	response := &body.ExecutionPayload
+	response.ParentHash = util.BytesToHexString(payload.ParentHash[:])
+	response.FeeRecipient = util.BytesToHexString(payload.FeeRecipient[:])
+	response.StateRoot = util.BytesToHexString(payload.StateRoot[:])
+	response.ReceiptsRoot = util.BytesToHexString(payload.ReceiptsRoot[:])
+	response.LogsBloom = util.BytesToHexString(payload.LogsBloom[:])
+	response.PrevRandao = util.BytesToHexString(payload.PrevRandao[:])
+	response.BlockNumber = strconv.FormatUint(payload.BlockNumber, 10)
+	response.GasLimit = strconv.FormatUint(payload.GasLimit, 10)
+	response.GasUsed = strconv.FormatUint(payload.GasUsed, 10)
+	response.Timestamp = strconv.FormatUint(payload.Timestamp, 10)
+	response.ExtraData = util.BytesToHexString(payload.ExtraData)
+	response.BaseFeePerGas = baseFee.String()
+	response.BlockHash = util.BytesToHexString(payload.BlockHash[:])
+
+	response.Transactions = []string{}
+	for _, transaction := range payload.Transactions {
+		response.Transactions = append(response.Transactions, util.BytesToHexString(transaction))
+	}
+	response.Withdrawals = []WithdrawalResponse{}
+	for _, withdrawal := range payload.Withdrawals {
+		response.Withdrawals = append(response.Withdrawals, WithdrawalResponse{
+			Index:          strconv.FormatUint(withdrawal.Index, 10),
+			ValidatorIndex: strconv.FormatUint(withdrawal.ValidatorIndex, 10),
+			Address:        util.BytesToHexString(withdrawal.Address[:]),
+			Amount:         strconv.FormatUint(withdrawal.Amount, 10),
+		})
+	}
+
+	if deneb {
+		response.BlobGasUsed = strconv.FormatUint(payload.BlobGasUsed, 10)
+		response.ExcessBlobGas = strconv.FormatUint(payload.ExcessBlobGas, 10)
+	}
+}
+
+func signedHeaderResponse(header *state.SignedBeaconBlockHeader) SignedHeaderResponse {
+	return SignedHeaderResponse{
+		Message: HeaderResponse{
+			Slot:          strconv.FormatUint(header.Header.Slot, 10),
+			ProposerIndex: strconv.FormatUint(header.Header.ProposerIndex, 10),
+			ParentRoot:    util.BytesToHexString(header.Header.ParentRoot),
+			StateRoot:     util.BytesToHexString(header.Header.StateRoot),
+			BodyRoot:      util.BytesToHexString(header.Header.BodyRoot),
+		},
+		Signature: util.BytesToHexString(header.Signature),
+	}
+}
+
+func attestationDataResponse(data *state.AttestationData) AttestationDataResponse {
+	return AttestationDataResponse{
+		Slot:            strconv.FormatUint(uint64(data.Slot), 10),
+		Index:           strconv.FormatUint(data.Index, 10),
+		BeaconBlockRoot: util.BytesToHexString(data.BeaconBlockHash[:]),
+		Source: CheckpointResponse{
+			Epoch: strconv.FormatUint(data.Source.Epoch, 10),
+			Root:  util.BytesToHexString(data.Source.Root),
+		},
+		Target: CheckpointResponse{
+			Epoch: strconv.FormatUint(data.Target.Epoch, 10),
+			Root:  util.BytesToHexString(data.Target.Root),
+		},
+	}
+}
+
+func indexedAttestationResponse(indices []uint64, data *state.AttestationData, signature []byte) IndexedAttestationResponse {
+	attestingIndices := []string{}
+	for _, index := range indices {
+		attestingIndices = append(attestingIndices, strconv.FormatUint(index, 10))
+	}
+	return IndexedAttestationResponse{
+		AttestingIndices: attestingIndices,
+		Data:             attestationDataResponse(data),
+		Signature:        util.BytesToHexString(signature),
+	}
+}
+
+func attesterSlashingsResponse(slashings []*state.AttesterSlashing) []AttesterSlashingResponse {
+	responses := []AttesterSlashingResponse{}
+	for _, slashing := range slashings {
+		responses = append(responses, AttesterSlashingResponse{
+			Attestation1: indexedAttestationResponse(slashing.Attestation1.AttestationIndices, slashing.Attestation1.Data, slashing.Attestation1.Signature),
+			Attestation2: indexedAttestationResponse(slashing.Attestation2.AttestationIndices, slashing.Attestation2.Data, slashing.Attestation2.Signature),
+		})
+	}
+	return responses
+}
+
+func attesterSlashingsElectraResponse(slashings []*state.AttesterSlashingElectra) []AttesterSlashingResponse {
+	responses := []AttesterSlashingResponse{}
+	for _, slashing := range slashings {
+		responses = append(responses, AttesterSlashingResponse{
+			Attestation1: indexedAttestationResponse(slashing.Attestation1.AttestationIndices, slashing.Attestation1.Data, slashing.Attestation1.Signature),
+			Attestation2: indexedAttestationResponse(slashing.Attestation2.AttestationIndices, slashing.Attestation2.Data, slashing.Attestation2.Signature),
+		})
+	}
+	return responses
+}
+
+func attestationsResponse(attestations []*state.Attestation) []AttestationResponse {
+	responses := []AttestationResponse{}
+	for _, attestation := range attestations {
+		responses = append(responses, AttestationResponse{
+			AggregationBits: util.BytesToHexString(attestation.AggregationBits),
+			Data:            attestationDataResponse(attestation.Data),
+			Signature:       util.BytesToHexString(attestation.Signature[:]),
+		})
+	}
+	return responses
+}
+
+func attestationsElectraResponse(attestations []*state.AttestationElectra) []AttestationResponse {
+	responses := []AttestationResponse{}
+	for _, attestation := range attestations {
+		responses = append(responses, AttestationResponse{
+			AggregationBits: util.BytesToHexString(attestation.AggregationBits),
+			Data:            attestationDataResponse(attestation.Data),
+			Signature:       util.BytesToHexString(attestation.Signature[:]),
+			CommitteeBits:   util.BytesToHexString(attestation.CommitteeBits),
+		})
+	}
+	return responses
+}
+
+func syncAggregateResponse(bits []byte, signature [96]byte) SyncAggregateResponse {
+	return SyncAggregateResponse{
+		SyncCommitteeBits:      util.BytesToHexString(bits),
+		SyncCommitteeSignature: util.BytesToHexString(signature[:]),
+	}
+}
+
+func kzgCommitmentsResponse(commitments [][48]byte) []string {
+	responses := []string{}
+	for _, commitment := range commitments {
+		responses = append(responses, util.BytesToHexString(commitment[:]))
+	}
+	return responses
+}
+
+func executionRequestsResponse(requests *state.ExecutionRequests) ExecutionRequestsResponse {
+	response := ExecutionRequestsResponse{
+		Deposits:       []DepositRequestResponse{},
+		Withdrawals:    []WithdrawalRequestResponse{},
+		Consolidations: []ConsolidationRequestResponse{},
+	}
+	for _, deposit := range requests.Deposits {
+		response.Deposits = append(response.Deposits, DepositRequestResponse{
+			Pubkey:                util.BytesToHexString(deposit.Pubkey[:]),
+			WithdrawalCredentials: util.BytesToHexString(deposit.WithdrawalCredentials[:]),
+			Amount:                strconv.FormatUint(deposit.Amount, 10),
+			Signature:             util.BytesToHexString(deposit.Signature[:]),
+			Index:                 strconv.FormatUint(deposit.Index, 10),
+		})
+	}
+	for _, withdrawal := range requests.Withdrawals {
+		response.Withdrawals = append(response.Withdrawals, WithdrawalRequestResponse{
+			SourceAddress:   util.BytesToHexString(withdrawal.SourceAddress[:]),
+			ValidatorPubkey: util.BytesToHexString(withdrawal.ValidatorPubkey[:]),
+			Amount:          strconv.FormatUint(withdrawal.Amount, 10),
+		})
+	}
+	for _, consolidation := range requests.Consolidations {
+		response.Consolidations = append(response.Consolidations, ConsolidationRequestResponse{
+			SourceAddress: util.BytesToHexString(consolidation.SourceAddress[:]),
+			SourcePubkey:  util.BytesToHexString(consolidation.SourcePubkey[:]),
+			TargetPubkey:  util.BytesToHexString(consolidation.TargetPubkey[:]),
+		})
+	}
+	return response
+}
@@ -0,0 +1,58 @@
+package json
+
+type AttestationElectra struct {
+	AggregationBits string          `json:"aggregation_bits"`
+	Data            AttestationData `json:"data"`
+	Signature       string          `json:"signature"`
+	CommitteeBits   string          `json:"committee_bits"`
+}
+
+type ExecutionRequests struct {
+	Deposits       []DepositRequest       `json:"deposits"`
+	Withdrawals    []WithdrawalRequest    `json:"withdrawals"`
+	Consolidations []ConsolidationRequest `json:"consolidations"`
+}
+
+type DepositRequest struct {
+	Pubkey                string `json:"pubkey"`
+	WithdrawalCredentials string `json:"withdrawal_credentials"`
+	Amount                uint64 `json:"amount"`
+	Signature             string `json:"signature"`
+	Index                 uint64 `json:"index"`
+}
+
+type WithdrawalRequest struct {
+	SourceAddress   string `json:"source_address"`
+	ValidatorPubkey string `json:"validator_pubkey"`
+	Amount          uint64 `json:"amount"`
+}
+
+type ConsolidationRequest struct {
+	SourceAddress string `json:"source_address"`
+	SourcePubkey  string `json:"source_pubkey"`
+	TargetPubkey  string `json:"target_pubkey"`
+}
+
+func (a *AttestationElectra) RemoveLeadingZeroHashes() {
+	a.AggregationBits = removeLeadingZeroHash(a.AggregationBits)
+	a.Data.RemoveLeadingZeroHashes()
+	a.Signature = removeLeadingZeroHash(a.Signature)
+	a.CommitteeBits = removeLeadingZeroHash(a.CommitteeBits)
+}
+
+func (e *ExecutionRequests) RemoveLeadingZeroHashes() {
+	for i := range e.Deposits {
+		e.Deposits[i].Pubkey = removeLeadingZeroHash(e.Deposits[i].Pubkey)
+		e.Deposits[i].WithdrawalCredentials = removeLeadingZeroHash(e.Deposits[i].WithdrawalCredentials)
+		e.Deposits[i].Signature = removeLeadingZeroHash(e.Deposits[i].Signature)
+	}
+	for i := range e.Withdrawals {
+		e.Withdrawals[i].SourceAddress = removeLeadingZeroHash(e.Withdrawals[i].SourceAddress)
+		e.Withdrawals[i].ValidatorPubkey = removeLeadingZeroHash(e.Withdrawals[i].ValidatorPubkey)
+	}
+	for i := range e.Consolidations {
+		e.Consolidations[i].SourceAddress = removeLeadingZeroHash(e.Consolidations[i].SourceAddress)
+		e.Consolidations[i].SourcePubkey = removeLeadingZeroHash(e.Consolidations[i].SourcePubkey)
+		e.Consolidations[i].TargetPubkey = removeLeadingZeroHash(e.Consolidations[i].TargetPubkey)
+	}
+}
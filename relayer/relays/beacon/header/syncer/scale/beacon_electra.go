@@ -0,0 +1,96 @@
+package scale
+
+import (
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer/json"
+	"github.com/snowfork/snowbridge/relayer/relays/util"
+)
+
+type AttestationElectra struct {
+	AggregationBits []byte
+	Data            AttestationData
+	Signature       []byte
+	CommitteeBits   []byte
+}
+
+type ExecutionRequests struct {
+	Deposits       []DepositRequest
+	Withdrawals    []WithdrawalRequest
+	Consolidations []ConsolidationRequest
+}
+
+type DepositRequest struct {
+	Pubkey                []byte
+	WithdrawalCredentials types.H256
+	Amount                types.U64
+	Signature             []byte
+	Index                 types.U64
+}
+
+type WithdrawalRequest struct {
+	SourceAddress   types.H160
+	ValidatorPubkey []byte
+	Amount          types.U64
+}
+
+type ConsolidationRequest struct {
+	SourceAddress types.H160
+	SourcePubkey  []byte
+	TargetPubkey  []byte
+}
+
+func (a *AttestationData) ToJSON() json.AttestationData {
+	return json.AttestationData{
+		Slot:            uint64(a.Slot),
+		Index:           uint64(a.Index),
+		BeaconBlockRoot: a.BeaconBlockRoot.Hex(),
+		Source:          json.Checkpoint{Epoch: uint64(a.Source.Epoch), Root: a.Source.Root.Hex()},
+		Target:          json.Checkpoint{Epoch: uint64(a.Target.Epoch), Root: a.Target.Root.Hex()},
+	}
+}
+
+func (a *AttestationElectra) ToJSON() json.AttestationElectra {
+	return json.AttestationElectra{
+		AggregationBits: util.BytesToHexString(a.AggregationBits),
+		Data:            a.Data.ToJSON(),
+		Signature:       util.BytesToHexString(a.Signature),
+		CommitteeBits:   util.BytesToHexString(a.CommitteeBits),
+	}
+}
+
+func (e *ExecutionRequests) ToJSON() json.ExecutionRequests {
+	deposits := []json.DepositRequest{}
+	for _, deposit := range e.Deposits {
+		deposits = append(deposits, json.DepositRequest{
+			Pubkey:                util.BytesToHexString(deposit.Pubkey),
+			WithdrawalCredentials: deposit.WithdrawalCredentials.Hex(),
+			Amount:                uint64(deposit.Amount),
+			Signature:             util.BytesToHexString(deposit.Signature),
+			Index:                 uint64(deposit.Index),
+		})
+	}
+
+	withdrawals := []json.WithdrawalRequest{}
+	for _, withdrawal := range e.Withdrawals {
+		withdrawals = append(withdrawals, json.WithdrawalRequest{
+			SourceAddress:   withdrawal.SourceAddress.Hex(),
+			ValidatorPubkey: util.BytesToHexString(withdrawal.ValidatorPubkey),
+			Amount:          uint64(withdrawal.Amount),
+		})
+	}
+
+	consolidations := []json.ConsolidationRequest{}
+	for _, consolidation := range e.Consolidations {
+		consolidations = append(consolidations, json.ConsolidationRequest{
+			SourceAddress: consolidation.SourceAddress.Hex(),
+			SourcePubkey:  util.BytesToHexString(consolidation.SourcePubkey),
+			TargetPubkey:  util.BytesToHexString(consolidation.TargetPubkey),
+		})
+	}
+
+	return json.ExecutionRequests{
+		Deposits:       deposits,
+		Withdrawals:    withdrawals,
+		Consolidations: consolidations,
+	}
+}
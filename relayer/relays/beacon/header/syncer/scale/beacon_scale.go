@@ -254,6 +254,35 @@ func (s SyncCommittee) Encode(encoder scale.Encoder) error {
 	return nil
 }
 
+// SyncCommitteePrepared is a sync committee as stored by EthereumBeaconClient, with the public keys in their
+// uncompressed form. A committee which is not set has a zero root.
+type SyncCommitteePrepared struct {
+	Root            types.H256
+	Pubkeys         [][96]byte
+	AggregatePubkey [96]byte
+}
+
+// DecodeSyncCommitteePrepared decodes a sync committee of EthereumBeaconClient storage, whose public keys are
+// encoded as a fixed size array of the committee size.
+func DecodeSyncCommitteePrepared(data []byte) (SyncCommitteePrepared, error) {
+	const pubkeySize = 96
+	if len(data) < 32+pubkeySize || (len(data)-32-pubkeySize)%pubkeySize != 0 {
+		return SyncCommitteePrepared{}, fmt.Errorf("invalid sync committee length %d", len(data))
+	}
+
+	committee := SyncCommitteePrepared{
+		Root:    types.NewH256(data[:32]),
+		Pubkeys: make([][96]byte, (len(data)-32-pubkeySize)/pubkeySize),
+	}
+	pubkeys := data[32 : len(data)-pubkeySize]
+	for i := range committee.Pubkeys {
+		copy(committee.Pubkeys[i][:], pubkeys[i*pubkeySize:])
+	}
+	copy(committee.AggregatePubkey[:], data[len(data)-pubkeySize:])
+
+	return committee, nil
+}
+
 type SyncAggregate struct {
 	SyncCommitteeBits      []byte
 	SyncCommitteeSignature [96]byte
@@ -280,6 +309,71 @@ func (s SyncAggregate) Encode(encoder scale.Encoder) error {
 	return nil
 }
 
+// DecodeUpdatePayload decodes an update for a sync committee of the given size, whose public keys and
+// participation bits are encoded as fixed size arrays.
+func DecodeUpdatePayload(decoder scale.Decoder, syncCommitteeSize uint64) (UpdatePayload, error) {
+	var update UpdatePayload
+	err := decoder.Decode(&update.AttestedHeader)
+	if err != nil {
+		return update, fmt.Errorf("decode attested header: %w", err)
+	}
+	update.SyncAggregate.SyncCommitteeBits = make([]byte, syncCommitteeSize/8)
+	err = decoder.Read(update.SyncAggregate.SyncCommitteeBits)
+	if err != nil {
+		return update, fmt.Errorf("decode sync committee bits: %w", err)
+	}
+	err = decoder.Decode(&update.SyncAggregate.SyncCommitteeSignature)
+	if err != nil {
+		return update, fmt.Errorf("decode sync committee signature: %w", err)
+	}
+	err = decoder.Decode(&update.SignatureSlot)
+	if err != nil {
+		return update, fmt.Errorf("decode signature slot: %w", err)
+	}
+
+	err = decoder.Decode(&update.NextSyncCommitteeUpdate.HasValue)
+	if err != nil {
+		return update, fmt.Errorf("decode next sync committee update: %w", err)
+	}
+	if update.NextSyncCommitteeUpdate.HasValue {
+		next := &update.NextSyncCommitteeUpdate.Value
+		next.NextSyncCommittee.Pubkeys = make([][48]byte, syncCommitteeSize)
+		for i := range next.NextSyncCommittee.Pubkeys {
+			err = decoder.Read(next.NextSyncCommittee.Pubkeys[i][:])
+			if err != nil {
+				return update, fmt.Errorf("decode next sync committee: %w", err)
+			}
+		}
+		err = decoder.Read(next.NextSyncCommittee.AggregatePubkey[:])
+		if err != nil {
+			return update, fmt.Errorf("decode next sync committee: %w", err)
+		}
+		err = decoder.Decode(&next.NextSyncCommitteeBranch)
+		if err != nil {
+			return update, fmt.Errorf("decode next sync committee branch: %w", err)
+		}
+	}
+
+	err = decoder.Decode(&update.FinalizedHeader)
+	if err != nil {
+		return update, fmt.Errorf("decode finalized header: %w", err)
+	}
+	err = decoder.Decode(&update.FinalityBranch)
+	if err != nil {
+		return update, fmt.Errorf("decode finality branch: %w", err)
+	}
+	err = decoder.Decode(&update.BlockRootsRoot)
+	if err != nil {
+		return update, fmt.Errorf("decode block roots root: %w", err)
+	}
+	err = decoder.Decode(&update.BlockRootsBranch)
+	if err != nil {
+		return update, fmt.Errorf("decode block roots branch: %w", err)
+	}
+
+	return update, nil
+}
+
 func (b *BeaconHeader) ToSSZ() *state.BeaconBlockHeader {
 	return &state.BeaconBlockHeader{
 		Slot:          uint64(b.Slot),
@@ -323,6 +417,23 @@ func (v VersionedExecutionPayloadHeader) Encode(encoder scale.Encoder) error {
 	return err
 }
 
+func (v *VersionedExecutionPayloadHeader) Decode(decoder scale.Decoder) error {
+	version, err := decoder.ReadOneByte()
+	if err != nil {
+		return err
+	}
+	switch version {
+	case 0:
+		v.Capella = &ExecutionPayloadHeaderCapella{}
+		return decoder.Decode(v.Capella)
+	case 1:
+		v.Deneb = &ExecutionPayloadHeaderDeneb{}
+		return decoder.Decode(v.Deneb)
+	default:
+		return fmt.Errorf("unknown execution payload header version %d", version)
+	}
+}
+
 type CompactExecutionHeader struct {
 	ParentHash   types.H256
 	BlockNumber  types.UCompact
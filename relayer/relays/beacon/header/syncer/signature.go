@@ -0,0 +1,122 @@
+package syncer
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	log "github.com/sirupsen/logrus"
+	"github.com/snowfork/snowbridge/relayer/crypto/bls"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer/scale"
+)
+
+// DOMAIN_SYNC_COMMITTEE of the consensus specs
+var domainSyncCommittee = [4]byte{0x07, 0x00, 0x00, 0x00}
+
+var ErrInvalidSyncCommitteeSignature = errors.New("sync committee signature is invalid")
+
+// signatureVerifier caches the fork versions with which sync committee signatures are verified, which are fetched
+// from the beacon node when they are first needed.
+type signatureVerifier struct {
+	forkVersions map[string][4]byte
+}
+
+func newSignatureVerifier() signatureVerifier {
+	return signatureVerifier{}
+}
+
+// VerifySyncAggregate checks the sync committee signature of an update, the way EthereumBeaconClient does before
+// importing it, so that updates with a bad signature are not submitted. The committee and the genesis validators root
+// are those which EthereumBeaconClient verifies the update with.
+func (s *Syncer) VerifySyncAggregate(update scale.UpdatePayload, committee [][48]byte, genesisValidatorsRoot common.Hash) error {
+	signatureSlot := uint64(update.SignatureSlot)
+
+	bits := update.SyncAggregate.SyncCommitteeBits
+	if len(bits)*8 != len(committee) {
+		return fmt.Errorf("%w: sync committee bits length %d does not match the sync committee size %d", ErrInvalidSyncCommitteeSignature, len(bits)*8, len(committee))
+	}
+	var participants [][48]byte
+	for i, publicKey := range committee {
+		if bits[i/8]>>(i%8)&1 == 1 {
+			participants = append(participants, publicKey)
+		}
+	}
+
+	// The signature is checked with the fork version of the slot before the signature slot
+	forkVersionSlot := signatureSlot
+	if forkVersionSlot > 0 {
+		forkVersionSlot--
+	}
+	domain, err := s.syncCommitteeDomain(s.protocol.ForkAtSlot(forkVersionSlot), genesisValidatorsRoot)
+	if err != nil {
+		return fmt.Errorf("compute sync committee domain: %w", err)
+	}
+
+	headerRoot, err := update.AttestedHeader.ToSSZ().HashTreeRoot()
+	if err != nil {
+		return fmt.Errorf("attested header hash tree root: %w", err)
+	}
+	signingRoot := hashChunks(headerRoot, domain)
+
+	valid, err := bls.FastAggregateVerify(participants, signingRoot[:], update.SyncAggregate.SyncCommitteeSignature)
+	if errors.Is(err, bls.ErrNoPublicKeys) {
+		return fmt.Errorf("%w: no participants", ErrInvalidSyncCommitteeSignature)
+	}
+	if err != nil {
+		return fmt.Errorf("verify sync committee signature: %w", err)
+	}
+	if !valid {
+		log.WithFields(log.Fields{
+			"attested_slot":  update.AttestedHeader.Slot,
+			"signature_slot": signatureSlot,
+			"participants":   len(participants),
+		}).Error("sync committee signature is invalid")
+		return ErrInvalidSyncCommitteeSignature
+	}
+
+	return nil
+}
+
+// syncCommitteeDomain computes the signature domain of sync committees at the fork
+func (s *Syncer) syncCommitteeDomain(fork string, genesisValidatorsRoot common.Hash) ([32]byte, error) {
+	if s.verifier.forkVersions == nil {
+		spec, err := s.Client.GetSpec()
+		if err != nil {
+			return [32]byte{}, fmt.Errorf("fetch spec: %w", err)
+		}
+		forkVersions := make(map[string][4]byte)
+		for key, value := range spec {
+			fork, ok := strings.CutSuffix(key, "_FORK_VERSION")
+			if !ok {
+				continue
+			}
+			version := common.FromHex(value)
+			if len(version) != 4 {
+				return [32]byte{}, fmt.Errorf("%s is not a fork version: %q", key, value)
+			}
+			forkVersions[strings.ToLower(fork)] = [4]byte(version)
+		}
+		s.verifier.forkVersions = forkVersions
+	}
+
+	forkVersion, ok := s.verifier.forkVersions[fork]
+	if !ok {
+		return [32]byte{}, fmt.Errorf("fork version of %s is unknown", fork)
+	}
+
+	var versionChunk [32]byte
+	copy(versionChunk[:], forkVersion[:])
+	forkDataRoot := hashChunks(versionChunk, genesisValidatorsRoot)
+
+	var domain [32]byte
+	copy(domain[:4], domainSyncCommittee[:])
+	copy(domain[4:], forkDataRoot[:28])
+	return domain, nil
+}
+
+// hashChunks returns the hash tree root of a container with two 32 byte fields
+func hashChunks(a, b [32]byte) [32]byte {
+	return sha256.Sum256(append(a[:], b[:]...))
+}
@@ -4,11 +4,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 
 	"github.com/snowfork/go-substrate-rpc-client/v4/types"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/cache"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/config"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer/api"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer/scale"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/protocol"
@@ -30,16 +32,29 @@ const (
 	ExecutionPayloadGeneralizedIndex     = 25
 )
 
+// Electra grows the beacon state beyond 32 fields, which adds a level to the state tree. The
+// execution payload index is unchanged since the block body still has no more than 16 fields.
+const (
+	BlockRootGeneralizedIndexElectra            = 69
+	FinalizedCheckpointGeneralizedIndexElectra  = 169
+	CurrentSyncCommitteeGeneralizedIndexElectra = 86
+	NextSyncCommitteeGeneralizedIndexElectra    = 87
+)
+
 var (
 	ErrCommitteeUpdateHeaderInDifferentSyncPeriod = errors.New("sync committee in different sync period")
 	ErrBeaconStateUnavailable                     = errors.New("beacon state object not available yet")
 	ErrSyncCommitteeNotSuperMajority              = errors.New("update received was not signed by supermajority")
+	ErrLightClientUpdateUnavailable               = errors.New("no light client update finalizes a header in the slot range")
 )
 
 type Syncer struct {
 	Client   api.BeaconAPI
 	store    store.BeaconStore
 	protocol *protocol.Protocol
+	// Build updates from the light client API, only downloading beacon states for block roots proofs
+	lightClientOnly bool
+	verifier        signatureVerifier
 }
 
 func New(client api.BeaconAPI, store store.BeaconStore, protocol *protocol.Protocol) *Syncer {
@@ -47,9 +62,15 @@ func New(client api.BeaconAPI, store store.BeaconStore, protocol *protocol.Proto
 		Client:   client,
 		store:    store,
 		protocol: protocol,
+		verifier: newSignatureVerifier(),
 	}
 }
 
+// SetSyncMode sets how updates are built, the state sync mode being the default
+func (s *Syncer) SetSyncMode(syncMode string) {
+	s.lightClientOnly = syncMode == config.SyncModeLightClient
+}
+
 type finalizedUpdateContainer struct {
 	AttestedSlot        uint64
 	AttestedState       state.BeaconState
@@ -170,7 +191,7 @@ func (s *Syncer) GetCheckpointAtSlot(slot uint64) (scale.BeaconCheckpoint, error
 
 	_ = stateTree.Hash() // necessary to populate the proof tree values
 
-	proof, err := stateTree.Prove(CurrentSyncCommitteeGeneralizedIndex)
+	proof, err := stateTree.Prove(s.currentSyncCommitteeGeneralizedIndex(finalizedState.GetSlot()))
 	if err != nil {
 		return scale.BeaconCheckpoint{}, fmt.Errorf("get block roof proof: %w", err)
 	}
@@ -196,9 +217,12 @@ func (s *Syncer) GetCheckpointAtSlot(slot uint64) (scale.BeaconCheckpoint, error
 // GetSyncCommitteePeriodUpdate fetches a sync committee update from the light client API endpoint. If it fails
 // (typically because it cannot download the finalized header beacon state because the slot does not fall on a 32
 // slot interval, due to a missed block), it will construct an update manually from data download from the beacon
-// API, or if that is unavailable, use a stored beacon state.
+// API, or if that is unavailable, use a stored beacon state. In light client mode, the update is not built manually.
 func (s *Syncer) GetSyncCommitteePeriodUpdate(period uint64, lastFinalizedSlot uint64) (scale.Update, error) {
 	update, err := s.GetSyncCommitteePeriodUpdateFromEndpoint(period)
+	if err != nil && s.lightClientOnly {
+		return update, fmt.Errorf("fetch sync committee update from light client API: %w", err)
+	}
 	if err != nil {
 		log.WithFields(log.Fields{"period": period, "err": err}).Warn("fetch sync committee update period light client failed, trying building update manually")
 		update, err = s.GetFinalizedUpdateWithSyncCommittee(period)
@@ -219,6 +243,21 @@ func (s *Syncer) GetSyncCommitteePeriodUpdateFromEndpoint(from uint64) (scale.Up
 		return scale.Update{}, fmt.Errorf("fetch sync committee period update: %w", err)
 	}
 
+	syncCommitteePeriodUpdate, err := s.syncCommitteePeriodUpdateToScale(committeeUpdateContainer)
+	if err != nil {
+		return scale.Update{}, err
+	}
+
+	finalizedPeriod := s.protocol.ComputeSyncPeriodAtSlot(uint64(syncCommitteePeriodUpdate.Payload.FinalizedHeader.Slot))
+
+	if finalizedPeriod != from {
+		return syncCommitteePeriodUpdate, ErrCommitteeUpdateHeaderInDifferentSyncPeriod
+	}
+
+	return syncCommitteePeriodUpdate, nil
+}
+
+func (s *Syncer) syncCommitteePeriodUpdateToScale(committeeUpdateContainer api.SyncCommitteePeriodUpdateResponse) (scale.Update, error) {
 	committeeUpdate := committeeUpdateContainer.Data
 
 	attestedHeader, err := committeeUpdate.AttestedHeader.Beacon.ToScale()
@@ -277,74 +316,27 @@ func (s *Syncer) GetSyncCommitteePeriodUpdateFromEndpoint(from uint64) (scale.Up
 		BlockRootsTree:           blockRootsProof.Tree,
 	}
 
-	finalizedPeriod := s.protocol.ComputeSyncPeriodAtSlot(uint64(finalizedHeader.Slot))
-
-	if finalizedPeriod != from {
-		return syncCommitteePeriodUpdate, ErrCommitteeUpdateHeaderInDifferentSyncPeriod
-	}
-
 	return syncCommitteePeriodUpdate, nil
 }
 
 func (s *Syncer) GetBlockRoots(slot uint64) (scale.BlockRootProof, error) {
-	var blockRootProof scale.BlockRootProof
-	var beaconState state.BeaconState
-	var blockRootsContainer state.BlockRootsContainer
-
-	data, err := s.getBeaconState(slot)
+	beaconState, err := s.getBeaconStateAtSlot(slot)
 	if err != nil {
-		return blockRootProof, fmt.Errorf("fetch beacon state: %w", err)
+		return scale.BlockRootProof{}, err
 	}
-	isDeneb := s.protocol.DenebForked(slot)
-
-	blockRootsContainer = &state.BlockRootsContainerMainnet{}
-	if isDeneb {
-		beaconState = &state.BeaconStateDenebMainnet{}
-	} else {
-		beaconState = &state.BeaconStateCapellaMainnet{}
-	}
-
-	err = beaconState.UnmarshalSSZ(data)
-	if err != nil {
-		return blockRootProof, fmt.Errorf("unmarshal beacon state: %w", err)
-	}
-
-	stateTree, err := beaconState.GetTree()
-	if err != nil {
-		return blockRootProof, fmt.Errorf("get state tree: %w", err)
-	}
-
-	_ = stateTree.Hash() // necessary to populate the proof tree values
 
-	proof, err := stateTree.Prove(BlockRootGeneralizedIndex)
-	if err != nil {
-		return scale.BlockRootProof{}, fmt.Errorf("get block roof proof: %w", err)
-	}
-
-	scaleBlockRootProof := []types.H256{}
-	for _, proofItem := range proof.Hashes {
-		scaleBlockRootProof = append(scaleBlockRootProof, types.NewH256(proofItem))
-	}
-
-	blockRootsContainer.SetBlockRoots(beaconState.GetBlockRoots())
-
-	tree, err := blockRootsContainer.GetTree()
-	if err != nil {
-		return blockRootProof, fmt.Errorf("convert block roots to tree: %w", err)
-	}
-
-	return scale.BlockRootProof{
-		Leaf:  types.NewH256(proof.Leaf),
-		Proof: scaleBlockRootProof,
-		Tree:  tree,
-	}, nil
+	return s.GetBlockRootsFromState(beaconState)
 }
 
 func (s *Syncer) GetBlockRootsFromState(beaconState state.BeaconState) (scale.BlockRootProof, error) {
 	var blockRootProof scale.BlockRootProof
 	var blockRootsContainer state.BlockRootsContainer
 
-	blockRootsContainer = &state.BlockRootsContainerMainnet{}
+	if s.protocol.Settings.MinimalContainers() {
+		blockRootsContainer = &state.BlockRootsContainerMinimal{}
+	} else {
+		blockRootsContainer = &state.BlockRootsContainerMainnet{}
+	}
 
 	stateTree, err := beaconState.GetTree()
 	if err != nil {
@@ -353,7 +345,7 @@ func (s *Syncer) GetBlockRootsFromState(beaconState state.BeaconState) (scale.Bl
 
 	_ = stateTree.Hash() // necessary to populate the proof tree values
 
-	proof, err := stateTree.Prove(BlockRootGeneralizedIndex)
+	proof, err := stateTree.Prove(s.blockRootGeneralizedIndex(beaconState.GetSlot()))
 	if err != nil {
 		return scale.BlockRootProof{}, fmt.Errorf("get block roof proof: %w", err)
 	}
@@ -397,6 +389,10 @@ func (s *Syncer) GetFinalizedUpdate() (scale.Update, error) {
 		return scale.Update{}, fmt.Errorf("fetch finalized update: %w", err)
 	}
 
+	return s.finalizedUpdateToScale(finalizedUpdate)
+}
+
+func (s *Syncer) finalizedUpdateToScale(finalizedUpdate api.LatestFinalisedUpdateResponse) (scale.Update, error) {
 	attestedHeader, err := finalizedUpdate.Data.AttestedHeader.Beacon.ToScale()
 	if err != nil {
 		return scale.Update{}, fmt.Errorf("convert attested header to scale: %w", err)
@@ -535,7 +531,7 @@ func (s *Syncer) GetHeaderUpdate(blockRoot common.Hash, checkpoint *cache.Proof)
 		return update, err
 	}
 
-	sszBlock, err := blockResponse.ToFastSSZ(s.protocol.DenebForked(slot))
+	sszBlock, err := blockResponse.ToFastSSZ(s.protocol.ForkAtSlot(slot), s.protocol.Settings.MinimalContainers())
 	if err != nil {
 		return update, err
 	}
@@ -556,8 +552,9 @@ func (s *Syncer) GetHeaderUpdate(blockRoot common.Hash, checkpoint *cache.Proof)
 	}
 
 	var versionedExecutionPayloadHeader scale.VersionedExecutionPayloadHeader
+	// The execution payload is unchanged in Electra, so Electra blocks use the Deneb header
 	if s.protocol.DenebForked(slot) {
-		executionPayloadScale, err := api.DenebExecutionPayloadToScale(sszBlock.ExecutionPayloadDeneb())
+		executionPayloadScale, err := api.DenebExecutionPayloadToScale(sszBlock.ExecutionPayloadDeneb(), s.withdrawalsRootContainer())
 		if err != nil {
 			return scale.HeaderUpdatePayload{}, err
 		}
@@ -606,27 +603,55 @@ func (s *Syncer) GetHeaderUpdate(blockRoot common.Hash, checkpoint *cache.Proof)
 	}, nil
 }
 
+// getBeaconStateAtSlot decodes the beacon state at the slot while it is downloaded from the API, or read from the
+// store if the API does not have it, so that the encoded state is never held in memory as a whole.
 func (s *Syncer) getBeaconStateAtSlot(slot uint64) (state.BeaconState, error) {
-	var beaconState state.BeaconState
-	beaconData, err := s.getBeaconState(slot)
+	beaconState, apiErr := s.decodeBeaconState(slot, func() (io.ReadCloser, error) {
+		return s.Client.OpenBeaconState(strconv.FormatUint(slot, 10))
+	})
+	if apiErr == nil {
+		return beaconState, nil
+	}
+
+	beaconState, storeErr := s.decodeBeaconState(slot, func() (io.ReadCloser, error) {
+		return s.store.OpenBeaconState(slot)
+	})
+	if storeErr != nil {
+		log.WithFields(log.Fields{"apiError": apiErr, "storeErr": storeErr}).Warn("fetch beacon state from api and store failed")
+		return nil, fmt.Errorf("fetch beacon state: %w", ErrBeaconStateUnavailable)
+	}
+
+	return beaconState, nil
+}
+
+func (s *Syncer) decodeBeaconState(slot uint64, open func() (io.ReadCloser, error)) (state.BeaconState, error) {
+	beaconState, err := s.newBeaconState(slot)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	err = beaconState.Decode(reader)
 	if err != nil {
-		return beaconState, fmt.Errorf("fetch beacon state: %w", err)
+		return nil, fmt.Errorf("decode beacon state: %w", err)
 	}
 
-	return s.UnmarshalBeaconState(slot, beaconData)
+	return beaconState, nil
 }
 
+// UnmarshalBeaconState decodes the fields of the beacon state which the relay uses, and the roots of the others.
 func (s *Syncer) UnmarshalBeaconState(slot uint64, data []byte) (state.BeaconState, error) {
-	var beaconState state.BeaconState
-	isDeneb := s.protocol.DenebForked(slot)
-
-	if isDeneb {
-		beaconState = &state.BeaconStateDenebMainnet{}
-	} else {
-		beaconState = &state.BeaconStateCapellaMainnet{}
+	beaconState, err := s.newBeaconState(slot)
+	if err != nil {
+		return nil, err
 	}
 
-	err := beaconState.UnmarshalSSZ(data)
+	err = beaconState.UnmarshalSSZ(data)
 	if err != nil {
 		return beaconState, fmt.Errorf("unmarshal beacon state: %w", err)
 	}
@@ -634,6 +659,16 @@ func (s *Syncer) UnmarshalBeaconState(slot uint64, data []byte) (state.BeaconSta
 	return beaconState, nil
 }
 
+func (s *Syncer) newBeaconState(slot uint64) (*state.PartialBeaconState, error) {
+	fork := s.protocol.ForkAtSlot(slot)
+	minimal := s.protocol.Settings.MinimalContainers()
+	if minimal && fork != config.Deneb {
+		return nil, fmt.Errorf("unmarshal beacon state: %s states are not supported with the minimal preset", fork)
+	}
+
+	return state.NewPartialBeaconState(fork, minimal), nil
+}
+
 // FindValidAttestedHeader Find a valid beacon header attested and finalized header pair.
 func (s *Syncer) FindValidAttestedHeader(minSlot, maxSlot uint64) (uint64, error) {
 	var slot uint64
@@ -724,6 +759,20 @@ func (s *Syncer) ValidatePair(finalizedSlot, attestedSlot uint64, attestedState
 	return nil
 }
 
+// FindSyncCommitteeUpdateAttestedSlot finds the attested slot of the update which GetFinalizedUpdateWithSyncCommittee
+// builds for the sync committee period, giving up after maxSlot.
+func (s *Syncer) FindSyncCommitteeUpdateAttestedSlot(syncCommitteePeriod, maxSlot uint64) (uint64, error) {
+	minSlot := syncCommitteePeriod * s.protocol.SlotsPerHistoricalRoot
+
+	attestedSlot, err := s.FindValidAttestedHeader(minSlot, maxSlot)
+	if err != nil {
+		return 0, fmt.Errorf("cannot find blocks at boundaries: %w", err)
+	}
+
+	// GetFinalizedUpdateAtAttestedSlot searches again, from the attested slot
+	return s.FindValidAttestedHeader(attestedSlot, maxSlot)
+}
+
 func (s *Syncer) GetFinalizedUpdateWithSyncCommittee(syncCommitteePeriod uint64) (scale.Update, error) {
 	minSlot := syncCommitteePeriod * s.protocol.SlotsPerHistoricalRoot
 	maxSlot := ((syncCommitteePeriod + 1) * s.protocol.SlotsPerHistoricalRoot) - s.protocol.Settings.SlotsInEpoch // just before the new sync committee boundary
@@ -739,6 +788,10 @@ func (s *Syncer) GetFinalizedUpdateWithSyncCommittee(syncCommitteePeriod uint64)
 func (s *Syncer) GetFinalizedUpdateAtAttestedSlot(minSlot, maxSlot uint64, fetchNextSyncCommittee bool) (scale.Update, error) {
 	var update scale.Update
 
+	if s.lightClientOnly {
+		return s.getFinalizedUpdateFromLightClient(minSlot, maxSlot, fetchNextSyncCommittee)
+	}
+
 	attestedSlot, err := s.FindValidAttestedHeader(minSlot, maxSlot)
 	if err != nil {
 		return scale.Update{}, fmt.Errorf("cannot find blocks at boundaries: %w", err)
@@ -779,14 +832,14 @@ func (s *Syncer) GetFinalizedUpdateAtAttestedSlot(minSlot, maxSlot uint64, fetch
 		return update, fmt.Errorf("get state tree: %w", err)
 	}
 	_ = stateTree.Hash() // necessary to populate the proof tree values
-	finalizedHeaderProof, err := stateTree.Prove(FinalizedCheckpointGeneralizedIndex)
+	finalizedHeaderProof, err := stateTree.Prove(s.finalizedCheckpointGeneralizedIndex(data.AttestedState.GetSlot()))
 	if err != nil {
 		return update, fmt.Errorf("get finalized header proof: %w", err)
 	}
 
 	var nextSyncCommitteeScale scale.OptionNextSyncCommitteeUpdatePayload
 	if fetchNextSyncCommittee {
-		nextSyncCommitteeProof, err := stateTree.Prove(NextSyncCommitteeGeneralizedIndex)
+		nextSyncCommitteeProof, err := stateTree.Prove(s.nextSyncCommitteeGeneralizedIndex(data.AttestedState.GetSlot()))
 		if err != nil {
 			return update, fmt.Errorf("get finalized header proof: %w", err)
 		}
@@ -872,6 +925,124 @@ func (s *Syncer) GetFinalizedUpdateAtAttestedSlot(minSlot, maxSlot uint64, fetch
 	}, nil
 }
 
+// getFinalizedUpdateFromLightClient finds an update finalizing a header between minSlot and maxSlot in the light
+// client API, so that only the finalized beacon state is downloaded, for the block roots proof. The light client API
+// does not serve the block roots of a state, so one beacon state is still downloaded per update, and its bootstrap
+// endpoint is only used for the initial checkpoint. Light client updates cannot be requested at a slot, so the
+// latest finality update and the best updates of the sync committee periods in the range are tried, newest first.
+func (s *Syncer) getFinalizedUpdateFromLightClient(minSlot, maxSlot uint64, fetchNextSyncCommittee bool) (scale.Update, error) {
+	if !fetchNextSyncCommittee {
+		finalizedUpdate, err := s.Client.GetLatestFinalizedUpdate()
+		if err != nil {
+			return scale.Update{}, fmt.Errorf("fetch finalized update: %w", err)
+		}
+
+		finalizedSlot, err := util.ToUint64(finalizedUpdate.Data.FinalizedHeader.Beacon.Slot)
+		if err != nil {
+			return scale.Update{}, fmt.Errorf("parse finalized slot: %w", err)
+		}
+
+		if finalizedSlot >= minSlot && finalizedSlot <= maxSlot {
+			return s.finalizedUpdateToScale(finalizedUpdate)
+		}
+	}
+
+	minPeriod := s.protocol.ComputeSyncPeriodAtSlot(minSlot)
+	for period := s.protocol.ComputeSyncPeriodAtSlot(maxSlot); period >= minPeriod; period-- {
+		update, ok, err := s.getLightClientUpdateInRange(period, minSlot, maxSlot)
+		if err != nil {
+			return scale.Update{}, err
+		}
+		if ok {
+			if !fetchNextSyncCommittee {
+				update.Payload.NextSyncCommitteeUpdate = scale.OptionNextSyncCommitteeUpdatePayload{HasValue: false}
+			}
+			return update, nil
+		}
+		if period == 0 {
+			break
+		}
+	}
+
+	return scale.Update{}, ErrLightClientUpdateUnavailable
+}
+
+// getLightClientUpdateInRange returns the best light client update of the period, if it is signed by a supermajority
+// and finalizes a header between minSlot and maxSlot.
+func (s *Syncer) getLightClientUpdateInRange(period, minSlot, maxSlot uint64) (scale.Update, bool, error) {
+	committeeUpdate, err := s.Client.GetSyncCommitteePeriodUpdate(period)
+	if err != nil {
+		log.WithError(err).WithField("period", period).Warn("fetch sync committee period update failed")
+		return scale.Update{}, false, nil
+	}
+
+	finalizedSlot, err := util.ToUint64(committeeUpdate.Data.FinalizedHeader.Beacon.Slot)
+	if err != nil {
+		return scale.Update{}, false, fmt.Errorf("parse finalized slot: %w", err)
+	}
+	if finalizedSlot < minSlot || finalizedSlot > maxSlot {
+		return scale.Update{}, false, nil
+	}
+
+	superMajority, err := s.protocol.SyncCommitteeSuperMajority(committeeUpdate.Data.SyncAggregate.SyncCommitteeBits)
+	if err != nil {
+		return scale.Update{}, false, fmt.Errorf("compute sync committee supermajority: %w", err)
+	}
+	if !superMajority {
+		return scale.Update{}, false, nil
+	}
+
+	update, err := s.syncCommitteePeriodUpdateToScale(committeeUpdate)
+	if err != nil {
+		return scale.Update{}, false, err
+	}
+
+	return update, true, nil
+}
+
+// The generalized indices of beacon state fields depend on the fork of the state, so that updates
+// keep working across a fork boundary.
+func (s *Syncer) blockRootGeneralizedIndex(slot uint64) int {
+	switch s.protocol.ForkAtSlot(slot) {
+	case config.Electra:
+		return BlockRootGeneralizedIndexElectra
+	default:
+		return BlockRootGeneralizedIndex
+	}
+}
+
+func (s *Syncer) finalizedCheckpointGeneralizedIndex(slot uint64) int {
+	switch s.protocol.ForkAtSlot(slot) {
+	case config.Electra:
+		return FinalizedCheckpointGeneralizedIndexElectra
+	default:
+		return FinalizedCheckpointGeneralizedIndex
+	}
+}
+
+func (s *Syncer) currentSyncCommitteeGeneralizedIndex(slot uint64) int {
+	switch s.protocol.ForkAtSlot(slot) {
+	case config.Electra:
+		return CurrentSyncCommitteeGeneralizedIndexElectra
+	default:
+		return CurrentSyncCommitteeGeneralizedIndex
+	}
+}
+
+func (s *Syncer) nextSyncCommitteeGeneralizedIndex(slot uint64) int {
+	switch s.protocol.ForkAtSlot(slot) {
+	case config.Electra:
+		return NextSyncCommitteeGeneralizedIndexElectra
+	default:
+		return NextSyncCommitteeGeneralizedIndex
+	}
+}
+
+// getBlockHeaderAncestryProof proves the block root in the block_roots of a finalized state. The inbound queue only
+// verifies ancestry against the block_roots root of finalized headers stored by the beacon client, so blocks older than
+// SlotsPerHistoricalRoot slots before a stored finalized header cannot be proven through historical_summaries: the
+// beacon client has no historical summaries to check them against. Such blocks are proven by first importing an
+// interim finalized header, as FetchExecutionProof does.
 func (s *Syncer) getBlockHeaderAncestryProof(slot int, blockRoot common.Hash, blockRootTree *ssz.Node) ([]types.H256, error) {
 	maxSlotsPerHistoricalRoot := int(s.protocol.SlotsPerHistoricalRoot)
 	indexInArray := slot % maxSlotsPerHistoricalRoot
@@ -893,6 +1064,13 @@ func (s *Syncer) getBlockHeaderAncestryProof(slot int, blockRoot common.Hash, bl
 	return util.BytesBranchToScale(proof.Hashes), nil
 }
 
+func (s *Syncer) withdrawalsRootContainer() state.WithdrawalsRootContainer {
+	if s.protocol.Settings.MinimalContainers() {
+		return &state.WithdrawalsRootContainerMinimal{}
+	}
+	return &state.WithdrawalsRootContainerMainnet{}
+}
+
 func (s *Syncer) getExecutionHeaderBranch(block state.BeaconBlock) ([]types.H256, error) {
 	tree, err := block.GetBlockBodyTree()
 	if err != nil {
@@ -966,16 +1144,3 @@ func (s *Syncer) getBestMatchBeaconDataFromStore(minSlot, maxSlot uint64) (final
 
 	return response, nil
 }
-
-func (s *Syncer) getBeaconState(slot uint64) ([]byte, error) {
-	data, apiErr := s.Client.GetBeaconState(strconv.FormatUint(slot, 10))
-	if apiErr != nil {
-		var storeErr error
-		data, storeErr = s.store.GetBeaconStateData(slot)
-		if storeErr != nil {
-			log.WithFields(log.Fields{"apiError": apiErr, "storeErr": storeErr}).Warn("fetch beacon state from api and store failed")
-			return nil, ErrBeaconStateUnavailable
-		}
-	}
-	return data, nil
-}
@@ -3,17 +3,22 @@ package syncer
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/config"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer/api"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer/scale"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/mock"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/protocol"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/state"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/store"
 	"github.com/snowfork/snowbridge/relayer/relays/testutil"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -255,3 +260,252 @@ func TestFindAttestedAndFinalizedHeadersAtBoundary(t *testing.T) {
 	attested, err = syncer.FindValidAttestedHeader(32540, 32768)
 	assert.Error(t, err)
 }
+
+func TestGeneralizedIndicesAcrossElectraFork(t *testing.T) {
+	syncer := New(api.NewBeaconClient(TestUrl, TestUrl), &mock.Store{}, protocol.New(config.SpecSettings{
+		SlotsInEpoch:                 32,
+		EpochsPerSyncCommitteePeriod: 256,
+		DenebForkEpoch:               0,
+		ElectraForkEpoch:             10,
+	}, MaxRedundancy))
+
+	denebSlot := uint64(10*32 - 1)
+	electraSlot := uint64(10 * 32)
+
+	denebState := &state.BeaconStateDenebMainnet{Slot: denebSlot}
+	fillBeaconState(reflect.ValueOf(denebState).Elem())
+	electraState := &state.BeaconStateElectraMainnet{Slot: electraSlot}
+	fillBeaconState(reflect.ValueOf(electraState).Elem())
+
+	for _, beaconState := range []state.BeaconState{denebState, electraState} {
+		tree, err := beaconState.GetTree()
+		require.NoError(t, err)
+		_ = tree.Hash()
+
+		slot := beaconState.GetSlot()
+
+		// The finality branch proves the root of the finalized checkpoint
+		proof, err := tree.Prove(syncer.finalizedCheckpointGeneralizedIndex(slot))
+		require.NoError(t, err)
+		assert.Equal(t, beaconState.GetFinalizedCheckpoint().Root, proof.Leaf)
+
+		currentSyncCommitteeRoot, err := beaconState.GetCurrentSyncCommittee().HashTreeRoot()
+		require.NoError(t, err)
+		proof, err = tree.Prove(syncer.currentSyncCommitteeGeneralizedIndex(slot))
+		require.NoError(t, err)
+		assert.Equal(t, currentSyncCommitteeRoot[:], proof.Leaf)
+
+		nextSyncCommitteeRoot, err := beaconState.GetNextSyncCommittee().HashTreeRoot()
+		require.NoError(t, err)
+		proof, err = tree.Prove(syncer.nextSyncCommitteeGeneralizedIndex(slot))
+		require.NoError(t, err)
+		assert.Equal(t, nextSyncCommitteeRoot[:], proof.Leaf)
+
+		blockRootsContainer := state.BlockRootsContainerMainnet{BlockRoots: beaconState.GetBlockRoots()}
+		blockRootsRoot, err := blockRootsContainer.HashTreeRoot()
+		require.NoError(t, err)
+		proof, err = tree.Prove(syncer.blockRootGeneralizedIndex(slot))
+		require.NoError(t, err)
+		assert.Equal(t, blockRootsRoot[:], proof.Leaf)
+	}
+}
+
+func TestMinimalPresetBeaconState(t *testing.T) {
+	syncer := New(api.NewBeaconClient(TestUrl, TestUrl), &mock.Store{}, protocol.New(config.SpecSettings{
+		Preset: config.Minimal,
+	}, MaxRedundancy))
+
+	minimalState := &state.BeaconStateDenebMinimal{Slot: 64}
+	fillBeaconState(reflect.ValueOf(minimalState).Elem())
+	data, err := minimalState.MarshalSSZ()
+	require.NoError(t, err)
+
+	beaconState, err := syncer.UnmarshalBeaconState(minimalState.Slot, data)
+	require.NoError(t, err)
+	assert.Len(t, beaconState.GetCurrentSyncCommittee().PubKeys, 32)
+	expectedRoot, err := minimalState.HashTreeRoot()
+	require.NoError(t, err)
+	tree, err := beaconState.GetTree()
+	require.NoError(t, err)
+	assert.Equal(t, expectedRoot[:], tree.Hash())
+
+	blockRootsContainer := state.BlockRootsContainerMinimal{BlockRoots: minimalState.BlockRoots}
+	blockRootsRoot, err := blockRootsContainer.HashTreeRoot()
+	require.NoError(t, err)
+	blockRootProof, err := syncer.GetBlockRootsFromState(beaconState)
+	require.NoError(t, err)
+	assert.Equal(t, types.NewH256(blockRootsRoot[:]), blockRootProof.Leaf)
+
+	nextSyncCommitteeRoot, err := minimalState.NextSyncCommittee.HashTreeRoot()
+	require.NoError(t, err)
+	proof, err := tree.Prove(syncer.nextSyncCommitteeGeneralizedIndex(minimalState.Slot))
+	require.NoError(t, err)
+	assert.Equal(t, nextSyncCommitteeRoot[:], proof.Leaf)
+}
+
+// Verifies that the partially decoded beacon state has the same root and field proofs as the full state
+func TestPartialBeaconState(t *testing.T) {
+	syncer := New(api.NewBeaconClient(TestUrl, TestUrl), &mock.Store{}, protocol.New(config.SpecSettings{
+		SlotsInEpoch:                 32,
+		EpochsPerSyncCommitteePeriod: 256,
+		DenebForkEpoch:               0,
+		ElectraForkEpoch:             222464,
+	}, MaxRedundancy))
+
+	var fullStates []interface {
+		state.BeaconState
+		MarshalSSZ() ([]byte, error)
+	}
+	for _, slot := range []uint64{4570752, 4571072, 4644864} {
+		data, err := testutil.LoadFile(fmt.Sprintf("%d.ssz", slot))
+		require.NoError(t, err)
+		denebState := &state.BeaconStateDenebMainnet{}
+		require.NoError(t, denebState.UnmarshalSSZ(data))
+		fullStates = append(fullStates, denebState)
+	}
+	electraState := &state.BeaconStateElectraMainnet{Slot: 222464 * 32}
+	fillBeaconState(reflect.ValueOf(electraState).Elem())
+	fullStates = append(fullStates, electraState)
+
+	for _, fullState := range fullStates {
+		data, err := fullState.MarshalSSZ()
+		require.NoError(t, err)
+
+		slot := fullState.GetSlot()
+		partialState, err := syncer.UnmarshalBeaconState(slot, data)
+		require.NoError(t, err)
+		require.IsType(t, &state.PartialBeaconState{}, partialState)
+
+		assert.Equal(t, slot, partialState.GetSlot())
+		assert.Equal(t, fullState.GetLatestBlockHeader(), partialState.GetLatestBlockHeader())
+		assert.Equal(t, fullState.GetBlockRoots(), partialState.GetBlockRoots())
+		assert.Equal(t, fullState.GetFinalizedCheckpoint(), partialState.GetFinalizedCheckpoint())
+		assert.Equal(t, fullState.GetCurrentSyncCommittee(), partialState.GetCurrentSyncCommittee())
+		assert.Equal(t, fullState.GetNextSyncCommittee(), partialState.GetNextSyncCommittee())
+
+		fullTree, err := fullState.GetTree()
+		require.NoError(t, err)
+		partialTree, err := partialState.GetTree()
+		require.NoError(t, err)
+		assert.Equal(t, fullTree.Hash(), partialTree.Hash())
+
+		for _, index := range []int{
+			syncer.finalizedCheckpointGeneralizedIndex(slot),
+			syncer.currentSyncCommitteeGeneralizedIndex(slot),
+			syncer.nextSyncCommitteeGeneralizedIndex(slot),
+			syncer.blockRootGeneralizedIndex(slot),
+		} {
+			expectedProof, err := fullTree.Prove(index)
+			require.NoError(t, err)
+			proof, err := partialTree.Prove(index)
+			require.NoError(t, err)
+			assert.Equal(t, expectedProof.Leaf, proof.Leaf)
+			assert.Equal(t, expectedProof.Hashes, proof.Hashes)
+		}
+	}
+
+	// A truncated state is rejected rather than hashed
+	data, err := testutil.LoadFile("4571072.ssz")
+	require.NoError(t, err)
+	_, err = syncer.UnmarshalBeaconState(4571072, data[:len(data)/2])
+	assert.Error(t, err)
+}
+
+// Verifies the sync committee signature of an update against the committee of the signature period
+func TestVerifySyncAggregate(t *testing.T) {
+	data, err := testutil.LoadFile("4570752.ssz")
+	require.NoError(t, err)
+	beaconState := &state.BeaconStateDenebMainnet{}
+	require.NoError(t, beaconState.UnmarshalSSZ(data))
+	// The signature slot is in the same period as the state, so it is signed by the current sync committee
+	var committee [][48]byte
+	for _, pubkey := range beaconState.CurrentSyncCommittee.PubKeys {
+		committee = append(committee, [48]byte(pubkey))
+	}
+	validatorsRoot := common.HexToHash("0xd8ea171f3c94aea21ebc42a1ed61052acf3f9209c00e4efbaaddac09ed9b8078")
+
+	client := mock.API{
+		Spec: map[string]string{
+			"CAPELLA_FORK_VERSION": "0x90000072",
+			"DENEB_FORK_VERSION":   "0x90000073",
+		},
+	}
+	syncer := New(&client, &mock.Store{}, protocol.New(config.SpecSettings{Preset: config.Sepolia}, MaxRedundancy))
+
+	attestedHeader, err := testutil.GetHeaderAtSlot(4570816)
+	require.NoError(t, err)
+	signatureBlock, err := testutil.GetBlockAtSlot(4570818)
+	require.NoError(t, err)
+
+	update := scale.UpdatePayload{SignatureSlot: 4570818}
+	update.AttestedHeader, err = attestedHeader.ToScale()
+	require.NoError(t, err)
+	update.SyncAggregate, err = signatureBlock.Data.Message.Body.SyncAggregate.ToScale()
+	require.NoError(t, err)
+
+	require.NoError(t, syncer.VerifySyncAggregate(update, committee, validatorsRoot))
+
+	// A header which the committee did not sign
+	invalidUpdate := update
+	invalidUpdate.AttestedHeader.Slot++
+	assert.ErrorIs(t, syncer.VerifySyncAggregate(invalidUpdate, committee, validatorsRoot), ErrInvalidSyncCommitteeSignature)
+
+	// Participation bits which do not match the signers
+	invalidUpdate = update
+	invalidUpdate.SyncAggregate.SyncCommitteeBits = append([]byte{}, update.SyncAggregate.SyncCommitteeBits...)
+	invalidUpdate.SyncAggregate.SyncCommitteeBits[0] ^= 0x01
+	assert.ErrorIs(t, syncer.VerifySyncAggregate(invalidUpdate, committee, validatorsRoot), ErrInvalidSyncCommitteeSignature)
+
+	// Another committee
+	assert.ErrorIs(t, syncer.VerifySyncAggregate(update, committee[:256], validatorsRoot), ErrInvalidSyncCommitteeSignature)
+
+	// The signature domain depends on the genesis validators root and the fork version
+	assert.ErrorIs(t, syncer.VerifySyncAggregate(update, committee, common.Hash{}), ErrInvalidSyncCommitteeSignature)
+	client.Spec["DENEB_FORK_VERSION"] = "0x90000074"
+	syncer.verifier.forkVersions = nil
+	assert.ErrorIs(t, syncer.VerifySyncAggregate(update, committee, validatorsRoot), ErrInvalidSyncCommitteeSignature)
+}
+
+// fillBeaconState populates the fixed size fields of a beacon state with distinct values, so that
+// proofs of different fields have different leaves.
+func fillBeaconState(v reflect.Value) {
+	counter := byte(0)
+	var fill func(v reflect.Value, tag reflect.StructTag)
+	fill = func(v reflect.Value, tag reflect.StructTag) {
+		counter++
+		switch v.Kind() {
+		case reflect.Ptr:
+			v.Set(reflect.New(v.Type().Elem()))
+			fill(v.Elem(), tag)
+		case reflect.Struct:
+			for i := 0; i < v.NumField(); i++ {
+				fill(v.Field(i), v.Type().Field(i).Tag)
+			}
+		case reflect.Uint64:
+			if v.Uint() == 0 {
+				v.SetUint(uint64(counter))
+			}
+		case reflect.Array:
+			for i := 0; i < v.Len(); i++ {
+				fill(v.Index(i), "")
+			}
+		case reflect.Uint8:
+			v.SetUint(uint64(counter))
+		case reflect.Slice:
+			sizes := strings.Split(tag.Get("ssz-size"), ",")
+			length, err := strconv.Atoi(sizes[0])
+			if err != nil {
+				return
+			}
+			v.Set(reflect.MakeSlice(v.Type(), length, length))
+			for i := 0; i < length; i++ {
+				if len(sizes) > 1 {
+					fill(v.Index(i), reflect.StructTag(fmt.Sprintf(`ssz-size:"%s"`, sizes[1])))
+				} else {
+					fill(v.Index(i), "")
+				}
+			}
+		}
+	}
+	fill(v, "")
+}
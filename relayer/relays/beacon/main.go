@@ -2,11 +2,15 @@ package beacon
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/snowfork/snowbridge/relayer/chain/parachain"
 	"github.com/snowfork/snowbridge/relayer/crypto/sr25519"
+	"github.com/snowfork/snowbridge/relayer/metrics"
+	"github.com/snowfork/snowbridge/relayer/operatingmode"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/config"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/era"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/header"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer/api"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/protocol"
@@ -32,9 +36,6 @@ func NewRelay(
 }
 
 func (r *Relay) Start(ctx context.Context, eg *errgroup.Group) error {
-	specSettings := r.config.Source.Beacon.Spec
-	log.WithField("spec", specSettings).Info("spec settings")
-
 	paraconn := parachain.NewConnection(r.config.Sink.Parachain.Endpoint, r.keypair.AsKeyringPair())
 
 	err := paraconn.ConnectWithHeartBeat(ctx, 30*time.Second)
@@ -47,7 +48,8 @@ func (r *Relay) Start(ctx context.Context, eg *errgroup.Group) error {
 		r.config.Sink.Parachain.MaxWatchedExtrinsics,
 	)
 
-	p := protocol.New(specSettings, r.config.Sink.Parachain.HeaderRedundancy)
+	p := protocol.New(r.config.Source.Beacon.Spec, r.config.Sink.Parachain.HeaderRedundancy)
+	log.WithField("spec", p.Settings).Info("spec settings")
 
 	err = writer.Start(ctx, eg)
 	if err != nil {
@@ -59,16 +61,48 @@ func (r *Relay) Start(ctx context.Context, eg *errgroup.Group) error {
 	if err != nil {
 		return err
 	}
+	var beaconStore store.BeaconStore = &s
+	var archive *era.Archive
+	if r.config.Source.Beacon.DataStore.EraDirectory != "" {
+		archive, err = era.NewArchive(r.config.Source.Beacon.DataStore.EraDirectory, p.SlotsPerHistoricalRoot)
+		if err != nil {
+			return fmt.Errorf("open era archive: %w", err)
+		}
+		beaconStore = era.NewStore(&s, archive)
+	}
+
+	metrics.Start(ctx, eg, r.config.Metrics)
+
+	operatingMode := operatingmode.New(
+		r.config.OperatingMode,
+		nil,
+		nil,
+		nil,
+		paraconn,
+		[]string{operatingmode.EthereumBeaconClient},
+		"",
+	)
+	operatingMode.Start(ctx, eg)
+
+	var beaconAPI api.BeaconAPI = api.NewBeaconClient(r.config.Source.Beacon.Endpoint, r.config.Source.Beacon.StateEndpoint)
+	err = p.CheckBeaconNodeSpec(beaconAPI)
+	if err != nil {
+		return err
+	}
+	if archive != nil {
+		beaconAPI = era.NewAPI(beaconAPI, archive, p)
+	}
 
-	beaconAPI := api.NewBeaconClient(r.config.Source.Beacon.Endpoint, r.config.Source.Beacon.StateEndpoint)
 	headers := header.New(
 		writer,
 		beaconAPI,
-		specSettings,
-		&s,
+		p.Settings,
+		beaconStore,
 		p,
 		r.config.Sink.UpdateSlotInterval,
+		operatingMode,
 	)
+	headers.SetSyncMode(r.config.Source.Beacon.SyncMode)
 
 	return headers.Sync(ctx, eg)
 }
@@ -1,7 +1,10 @@
 package mock
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer/api"
 	"github.com/snowfork/snowbridge/relayer/relays/testutil"
@@ -15,6 +18,8 @@ type API struct {
 	BlocksAtSlot                      map[uint64]api.BeaconBlockResponse
 	Header                            map[common.Hash]api.BeaconHeader
 	BeaconStates                      map[uint64]bool
+	Genesis                           api.Genesis
+	Spec                              map[string]string
 }
 
 func (m *API) GetHeaderAtHead() (api.BeaconHeader, error) {
@@ -26,7 +31,11 @@ func (m *API) GetBootstrap(blockRoot common.Hash) (api.BootstrapResponse, error)
 }
 
 func (m *API) GetGenesis() (api.Genesis, error) {
-	return api.Genesis{}, nil
+	return m.Genesis, nil
+}
+
+func (m *API) GetSpec() (map[string]string, error) {
+	return m.Spec, nil
 }
 
 func (m *API) GetFinalizedCheckpoint() (api.FinalizedCheckpoint, error) {
@@ -86,3 +95,11 @@ func (m *API) GetBeaconState(stateIdOrSlot string) ([]byte, error) {
 	}
 	return data, nil
 }
+
+func (m *API) OpenBeaconState(stateIdOrSlot string) (io.ReadCloser, error) {
+	data, err := m.GetBeaconState(stateIdOrSlot)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
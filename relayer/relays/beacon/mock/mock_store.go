@@ -1,6 +1,10 @@
 package mock
 
 import (
+	"bytes"
+	"io"
+	"sort"
+
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer/api"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/store"
 )
@@ -8,6 +12,7 @@ import (
 type Store struct {
 	StoredBeaconStateData store.StoredBeaconData
 	BeaconStateData       map[uint64][]byte
+	Checkpoints           map[uint64]store.Checkpoint
 }
 
 func (m *Store) FindBeaconStateWithinRange(slot, boundary uint64) (store.StoredBeaconData, error) {
@@ -26,6 +31,38 @@ func (m *Store) GetBeaconStateData(slot uint64) ([]byte, error) {
 	return value, nil
 }
 
+func (m *Store) OpenBeaconState(slot uint64) (io.ReadCloser, error) {
+	data, err := m.GetBeaconStateData(slot)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *Store) WriteCheckpoint(checkpoint store.Checkpoint) error {
+	if m.Checkpoints == nil {
+		m.Checkpoints = make(map[uint64]store.Checkpoint)
+	}
+	m.Checkpoints[checkpoint.Slot] = checkpoint
+	return nil
+}
+
+func (m *Store) ListCheckpoints() ([]store.Checkpoint, error) {
+	var checkpoints []store.Checkpoint
+	for _, checkpoint := range m.Checkpoints {
+		checkpoints = append(checkpoints, checkpoint)
+	}
+	sort.Slice(checkpoints, func(i, j int) bool { return checkpoints[i].Slot < checkpoints[j].Slot })
+	return checkpoints, nil
+}
+
+func (m *Store) DeleteCheckpoints(slots []uint64) error {
+	for _, slot := range slots {
+		delete(m.Checkpoints, slot)
+	}
+	return nil
+}
+
 func (m *Store) Connect() error {
 	return nil
 }
@@ -16,6 +16,9 @@ type Writer struct {
 	LastFinalizedStateIndex         types.U32
 	FinalizedBeaconRootByIndex      map[uint32]types.H256
 	FinalizedHeaderStateByBlockRoot map[types.H256]state.FinalizedHeader
+	// Sync committees by storage item name
+	SyncCommittees map[string]scale.SyncCommitteePrepared
+	ValidatorsRoot common.Hash
 }
 
 func (m *Writer) GetLastExecutionHeaderState() (state.ExecutionHeader, error) {
@@ -75,3 +78,11 @@ func (m *Writer) GetFinalizedHeaderStateByBlockRoot(blockRoot types.H256) (state
 func (m *Writer) FindCheckPointBackward(slot uint64) (state.FinalizedHeader, error) {
 	return state.FinalizedHeader{}, nil
 }
+
+func (m *Writer) GetSyncCommittee(storage string) (scale.SyncCommitteePrepared, error) {
+	return m.SyncCommittees[storage], nil
+}
+
+func (m *Writer) GetValidatorsRoot() (common.Hash, error) {
+	return m.ValidatorsRoot, nil
+}
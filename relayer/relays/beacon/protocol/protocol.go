@@ -2,6 +2,9 @@ package protocol
 
 import (
 	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
 	"strings"
 
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/config"
@@ -13,7 +16,9 @@ type Protocol struct {
 	HeaderRedundancy       uint64
 }
 
+// New creates the protocol for the given settings, after applying their preset
 func New(setting config.SpecSettings, headerRedundancy uint64) *Protocol {
+	setting = setting.WithPreset()
 	return &Protocol{
 		Settings:               setting,
 		SlotsPerHistoricalRoot: setting.SlotsInEpoch * setting.EpochsPerSyncCommitteePeriod,
@@ -44,8 +49,34 @@ func (p *Protocol) CalculateNextCheckpointSlot(slot uint64) uint64 {
 	return (syncPeriod + 1) * p.Settings.SlotsInEpoch * p.Settings.EpochsPerSyncCommitteePeriod
 }
 
+// ForkAtSlot returns the latest fork in the fork schedule which is active at the slot
+func (p *Protocol) ForkAtSlot(slot uint64) string {
+	epoch := p.ComputeEpochAtSlot(slot)
+	active := config.Capella
+	for _, fork := range config.Forks {
+		forkEpoch, ok := p.Settings.ForkEpochs[fork]
+		if ok && epoch >= forkEpoch {
+			active = fork
+		}
+	}
+	return active
+}
+
+// Forked returns whether the fork is active at the slot
+func (p *Protocol) Forked(fork string, slot uint64) bool {
+	forkEpoch, ok := p.Settings.ForkEpochs[fork]
+	if !ok {
+		return fork == config.Capella
+	}
+	return p.ComputeEpochAtSlot(slot) >= forkEpoch
+}
+
 func (p *Protocol) DenebForked(slot uint64) bool {
-	return p.ComputeEpochAtSlot(slot) >= p.Settings.DenebForkEpoch
+	return p.Forked(config.Deneb, slot)
+}
+
+func (p *Protocol) ElectraForked(slot uint64) bool {
+	return p.Forked(config.Electra, slot)
 }
 
 func (p *Protocol) SyncPeriodLength() uint64 {
@@ -76,3 +107,72 @@ func (p *Protocol) SyncCommitteeSuperMajority(syncCommitteeHex string) (bool, er
 	}
 	return true, nil
 }
+
+// SpecSource returns the configuration of a beacon node
+type SpecSource interface {
+	GetSpec() (map[string]string, error)
+}
+
+// CheckBeaconNodeSpec compares the settings with the configuration of the beacon node if the
+// checkSpec setting is enabled.
+func (p *Protocol) CheckBeaconNodeSpec(source SpecSource) error {
+	if !p.Settings.CheckSpec {
+		return nil
+	}
+	spec, err := source.GetSpec()
+	if err != nil {
+		return fmt.Errorf("fetch beacon node spec: %w", err)
+	}
+	err = p.CheckSpec(spec)
+	if err != nil {
+		return fmt.Errorf("check spec settings against beacon node: %w", err)
+	}
+	return nil
+}
+
+// CheckSpec compares the settings with the configuration of the beacon node, as returned by its
+// /eth/v1/config/spec endpoint.
+func (p *Protocol) CheckSpec(spec map[string]string) error {
+	var mismatches []string
+
+	check := func(key string, expected uint64, optional bool) {
+		value, ok := spec[key]
+		if !ok {
+			if !optional {
+				mismatches = append(mismatches, fmt.Sprintf("%s is missing", key))
+			}
+			return
+		}
+		actual, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			mismatches = append(mismatches, fmt.Sprintf("%s is not a number: %q", key, value))
+			return
+		}
+		if actual != expected {
+			mismatches = append(mismatches, fmt.Sprintf("%s is %d, expected %d", key, actual, expected))
+		}
+	}
+
+	check("SLOTS_PER_EPOCH", p.Settings.SlotsInEpoch, false)
+	check("SYNC_COMMITTEE_SIZE", p.Settings.SyncCommitteeSize, false)
+	check("EPOCHS_PER_SYNC_COMMITTEE_PERIOD", p.Settings.EpochsPerSyncCommitteePeriod, false)
+
+	for _, fork := range config.Forks {
+		key := strings.ToUpper(fork) + "_FORK_EPOCH"
+		epoch, ok := p.Settings.ForkEpochs[fork]
+		if !ok {
+			if fork == config.Capella {
+				continue
+			}
+			// Forks which are not scheduled have the far future epoch
+			epoch = math.MaxUint64
+		}
+		check(key, epoch, true)
+	}
+
+	if len(mismatches) > 0 {
+		return fmt.Errorf("spec settings do not match the beacon node: %s", strings.Join(mismatches, ", "))
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -119,3 +120,134 @@ func TestSyncCommitteeBits(t *testing.T) {
 		assert.Equal(t, tt.expected, result, "expected %t but found %t", tt.expected, result)
 	}
 }
+
+func TestElectraForked(t *testing.T) {
+	values := []struct {
+		name     string
+		slot     uint64
+		expected bool
+	}{
+		{
+			name:     "deneb slot",
+			slot:     127,
+			expected: false,
+		},
+		{
+			name:     "first electra slot",
+			slot:     128,
+			expected: true,
+		},
+		{
+			name:     "later electra slot",
+			slot:     300,
+			expected: true,
+		},
+	}
+
+	p := New(config.SpecSettings{
+		SlotsInEpoch:     32,
+		ElectraForkEpoch: 4,
+	}, 0)
+
+	for _, tt := range values {
+		result := p.ElectraForked(tt.slot)
+		assert.Equal(t, tt.expected, result, "expected %t but found %t for slot %d", tt.expected, result, tt.slot)
+	}
+}
+
+func TestForkAtSlot(t *testing.T) {
+	values := []struct {
+		name     string
+		settings config.SpecSettings
+		slot     uint64
+		expected string
+	}{
+		{
+			name:     "mainnet capella",
+			settings: config.SpecSettings{Preset: config.Mainnet},
+			slot:     269568*32 - 1,
+			expected: config.Capella,
+		},
+		{
+			name:     "mainnet deneb",
+			settings: config.SpecSettings{Preset: config.Mainnet},
+			slot:     269568 * 32,
+			expected: config.Deneb,
+		},
+		{
+			name:     "mainnet electra",
+			settings: config.SpecSettings{Preset: config.Mainnet},
+			slot:     364032 * 32,
+			expected: config.Electra,
+		},
+		{
+			name:     "electra not scheduled on minimal",
+			settings: config.SpecSettings{Preset: config.Minimal},
+			slot:     1 << 40,
+			expected: config.Deneb,
+		},
+		{
+			name: "fork epoch overrides preset",
+			settings: config.SpecSettings{
+				Preset:     config.Minimal,
+				ForkEpochs: map[string]uint64{config.Electra: 2},
+			},
+			slot:     16,
+			expected: config.Electra,
+		},
+		{
+			name:     "legacy fork epochs",
+			settings: config.SpecSettings{SlotsInEpoch: 32, DenebForkEpoch: 1, ElectraForkEpoch: 3},
+			slot:     64,
+			expected: config.Deneb,
+		},
+	}
+
+	for _, tt := range values {
+		p := New(tt.settings, 0)
+		assert.Equal(t, tt.expected, p.ForkAtSlot(tt.slot), tt.name)
+	}
+}
+
+func TestCheckSpec(t *testing.T) {
+	p := New(config.SpecSettings{Preset: config.Minimal}, 0)
+
+	spec := map[string]string{
+		"SLOTS_PER_EPOCH":                  "8",
+		"SYNC_COMMITTEE_SIZE":              "32",
+		"EPOCHS_PER_SYNC_COMMITTEE_PERIOD": "8",
+		"CAPELLA_FORK_EPOCH":               "0",
+		"DENEB_FORK_EPOCH":                 "0",
+		"ELECTRA_FORK_EPOCH":               "18446744073709551615",
+	}
+	assert.NoError(t, p.CheckSpec(spec))
+
+	spec["ELECTRA_FORK_EPOCH"] = "10"
+	spec["SLOTS_PER_EPOCH"] = "32"
+	err := p.CheckSpec(spec)
+	assert.ErrorContains(t, err, "SLOTS_PER_EPOCH is 32, expected 8")
+	assert.ErrorContains(t, err, "ELECTRA_FORK_EPOCH is 10, expected 18446744073709551615")
+}
+
+type specSource struct {
+	spec  map[string]string
+	calls int
+}
+
+func (s *specSource) GetSpec() (map[string]string, error) {
+	s.calls++
+	return s.spec, nil
+}
+
+func TestCheckBeaconNodeSpec(t *testing.T) {
+	source := &specSource{spec: map[string]string{"SLOTS_PER_EPOCH": "32"}}
+
+	p := New(config.SpecSettings{Preset: config.Minimal}, 0)
+	assert.NoError(t, p.CheckBeaconNodeSpec(source))
+	assert.Equal(t, 0, source.calls)
+
+	p = New(config.SpecSettings{Preset: config.Minimal, CheckSpec: true}, 0)
+	err := p.CheckBeaconNodeSpec(source)
+	assert.ErrorContains(t, err, "SLOTS_PER_EPOCH is 32, expected 8")
+	assert.Equal(t, 1, source.calls)
+}
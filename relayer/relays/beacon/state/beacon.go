@@ -1,9 +1,15 @@
 package state
 
 import (
+	"encoding/binary"
+	"fmt"
+
 	ssz "github.com/ferranbt/fastssz"
 )
 
+// Offset of the slot in an SSZ encoded beacon state, after the genesis time and genesis validators root
+const beaconStateSlotOffset = 40
+
 type Checkpoint struct {
 	Epoch uint64 `json:"epoch"`
 	Root  []byte `json:"root" ssz-size:"32"`
@@ -182,6 +188,11 @@ type BlockRootsContainer interface {
 	SetBlockRoots(blockRoots [][]byte)
 }
 
+type WithdrawalsRootContainer interface {
+	HashTreeRoot() ([32]byte, error)
+	SetWithdrawals(withdrawals []*Withdrawal)
+}
+
 type BeaconBlock interface {
 	UnmarshalSSZ(buf []byte) error
 	GetBeaconSlot() uint64
@@ -191,6 +202,15 @@ type BeaconBlock interface {
 	GetBlockBodyTree() (*ssz.Node, error)
 }
 
+// BeaconStateSlot reads the slot of an SSZ encoded beacon state, which precedes any fork specific
+// fields, so that the state can be unmarshalled into the container of its fork.
+func BeaconStateSlot(data []byte) (uint64, error) {
+	if len(data) < beaconStateSlotOffset+8 {
+		return 0, fmt.Errorf("beacon state too short: %d bytes", len(data))
+	}
+	return binary.LittleEndian.Uint64(data[beaconStateSlotOffset : beaconStateSlotOffset+8]), nil
+}
+
 func (b *BlockRootsContainerMainnet) SetBlockRoots(blockRoots [][]byte) {
 	b.BlockRoots = blockRoots
 }
@@ -283,6 +303,10 @@ type WithdrawalsRootContainerMainnet struct {
 	Withdrawals []*Withdrawal `ssz-max:"16" json:"withdrawals"`
 }
 
+func (w *WithdrawalsRootContainerMainnet) SetWithdrawals(withdrawals []*Withdrawal) {
+	w.Withdrawals = withdrawals
+}
+
 func (b *BeaconBlockCapellaMainnet) GetBeaconSlot() uint64 {
 	return b.Slot
 }
@@ -1,5 +1,5 @@
 // Code generated by fastssz. DO NOT EDIT.
-// Hash: 03b5096ab94e41e2c740924a4ae7ea8fdd515fe3dd4861032a569e28bcba8bb4
+// Hash: 9c003625f306025cdf99fb40fb76e1070b230c837ca5ef5254c2625d70e9215e
 // Version: 0.1.3
 package state
 
@@ -0,0 +1,176 @@
+package state
+
+import (
+	ssz "github.com/ferranbt/fastssz"
+)
+
+type PendingDeposit struct {
+	Pubkey                [48]byte `json:"pubkey" ssz-size:"48"`
+	WithdrawalCredentials [32]byte `json:"withdrawal_credentials" ssz-size:"32"`
+	Amount                uint64   `json:"amount"`
+	Signature             [96]byte `json:"signature" ssz-size:"96"`
+	Slot                  uint64   `json:"slot"`
+}
+
+type PendingPartialWithdrawal struct {
+	ValidatorIndex    uint64 `json:"validator_index"`
+	Amount            uint64 `json:"amount"`
+	WithdrawableEpoch uint64 `json:"withdrawable_epoch"`
+}
+
+type PendingConsolidation struct {
+	SourceIndex uint64 `json:"source_index"`
+	TargetIndex uint64 `json:"target_index"`
+}
+
+type AttestationElectra struct {
+	AggregationBits []byte           `json:"aggregation_bits" ssz:"bitlist" ssz-max:"131072"`
+	Data            *AttestationData `json:"data"`
+	Signature       [96]byte         `json:"signature" ssz-size:"96"`
+	CommitteeBits   []byte           `json:"committee_bits" ssz-size:"8"`
+}
+
+type IndexedAttestationElectra struct {
+	AttestationIndices []uint64         `json:"attesting_indices" ssz-max:"131072"`
+	Data               *AttestationData `json:"data"`
+	Signature          []byte           `json:"signature" ssz-size:"96"`
+}
+
+type AttesterSlashingElectra struct {
+	Attestation1 *IndexedAttestationElectra `json:"attestation_1"`
+	Attestation2 *IndexedAttestationElectra `json:"attestation_2"`
+}
+
+type DepositRequest struct {
+	Pubkey                [48]byte `json:"pubkey" ssz-size:"48"`
+	WithdrawalCredentials [32]byte `json:"withdrawal_credentials" ssz-size:"32"`
+	Amount                uint64   `json:"amount"`
+	Signature             [96]byte `json:"signature" ssz-size:"96"`
+	Index                 uint64   `json:"index"`
+}
+
+type WithdrawalRequest struct {
+	SourceAddress   [20]byte `json:"source_address" ssz-size:"20"`
+	ValidatorPubkey [48]byte `json:"validator_pubkey" ssz-size:"48"`
+	Amount          uint64   `json:"amount"`
+}
+
+type ConsolidationRequest struct {
+	SourceAddress [20]byte `json:"source_address" ssz-size:"20"`
+	SourcePubkey  [48]byte `json:"source_pubkey" ssz-size:"48"`
+	TargetPubkey  [48]byte `json:"target_pubkey" ssz-size:"48"`
+}
+
+type ExecutionRequests struct {
+	Deposits       []*DepositRequest       `json:"deposits" ssz-max:"8192"`
+	Withdrawals    []*WithdrawalRequest    `json:"withdrawals" ssz-max:"16"`
+	Consolidations []*ConsolidationRequest `json:"consolidations" ssz-max:"2"`
+}
+
+type BeaconBlockElectraMainnet struct {
+	Slot          uint64                         `json:"slot"`
+	ProposerIndex uint64                         `json:"proposer_index"`
+	ParentRoot    []byte                         `json:"parent_root" ssz-size:"32"`
+	StateRoot     []byte                         `json:"state_root" ssz-size:"32"`
+	Body          *BeaconBlockBodyElectraMainnet `json:"body"`
+}
+
+type BeaconBlockBodyElectraMainnet struct {
+	RandaoReveal          []byte                        `json:"randao_reveal" ssz-size:"96"`
+	Eth1Data              *Eth1Data                     `json:"eth1_data"`
+	Graffiti              [32]byte                      `json:"graffiti" ssz-size:"32"`
+	ProposerSlashings     []*ProposerSlashing           `json:"proposer_slashings" ssz-max:"16"`
+	AttesterSlashings     []*AttesterSlashingElectra    `json:"attester_slashings" ssz-max:"1"`
+	Attestations          []*AttestationElectra         `json:"attestations" ssz-max:"8"`
+	Deposits              []*Deposit                    `json:"deposits" ssz-max:"16"`
+	VoluntaryExits        []*SignedVoluntaryExit        `json:"voluntary_exits" ssz-max:"16"`
+	SyncAggregate         *SyncAggregateMainnet         `json:"sync_aggregate"`
+	ExecutionPayload      *ExecutionPayloadDeneb        `json:"execution_payload"`
+	BlsToExecutionChanges []*SignedBLSToExecutionChange `json:"bls_to_execution_changes,omitempty" ssz-max:"16"`
+	BlobKzgCommitments    [][48]byte                    `json:"blob_kzg_commitments,omitempty" ssz-max:"4096" ssz-size:"?,48"`
+	ExecutionRequests     *ExecutionRequests            `json:"execution_requests"`
+}
+
+type BeaconStateElectraMainnet struct {
+	GenesisTime                   uint64                       `json:"genesis_time"`
+	GenesisValidatorsRoot         []byte                       `json:"genesis_validators_root" ssz-size:"32"`
+	Slot                          uint64                       `json:"slot"`
+	Fork                          *Fork                        `json:"fork"`
+	LatestBlockHeader             *BeaconBlockHeader           `json:"latest_block_header"`
+	BlockRoots                    [][]byte                     `json:"block_roots" ssz-size:"8192,32"`
+	StateRoots                    [][]byte                     `json:"state_roots" ssz-size:"8192,32"`
+	HistoricalRoots               [][]byte                     `json:"historical_roots" ssz-max:"16777216" ssz-size:"?,32"`
+	Eth1Data                      *Eth1Data                    `json:"eth1_data"`
+	Eth1DataVotes                 []*Eth1Data                  `json:"eth1_data_votes" ssz-max:"2048"`
+	Eth1DepositIndex              uint64                       `json:"eth1_deposit_index"`
+	Validators                    []*Validator                 `json:"validators" ssz-max:"1099511627776"`
+	Balances                      []uint64                     `json:"balances" ssz-max:"1099511627776"`
+	RandaoMixes                   [][]byte                     `json:"randao_mixes" ssz-size:"65536,32"`
+	Slashings                     []uint64                     `json:"slashings" ssz-size:"8192"`
+	PreviousEpochParticipation    []byte                       `json:"previous_epoch_participation" ssz-max:"1099511627776"`
+	CurrentEpochParticipation     []byte                       `json:"current_epoch_participation" ssz-max:"1099511627776"`
+	JustificationBits             []byte                       `json:"justification_bits" cast-type:"github.com/prysmaticlabs/go-bitfield.Bitvector4" ssz-size:"1"`
+	PreviousJustifiedCheckpoint   *Checkpoint                  `json:"previous_justified_checkpoint"`
+	CurrentJustifiedCheckpoint    *Checkpoint                  `json:"current_justified_checkpoint"`
+	FinalizedCheckpoint           *Checkpoint                  `json:"finalized_checkpoint"`
+	InactivityScores              []uint64                     `json:"inactivity_scores" ssz-max:"1099511627776"`
+	CurrentSyncCommittee          *SyncCommittee               `json:"current_sync_committee"`
+	NextSyncCommittee             *SyncCommittee               `json:"next_sync_committee"`
+	LatestExecutionPayloadHeader  *ExecutionPayloadHeaderDeneb `json:"latest_execution_payload_header"`
+	NextWithdrawalIndex           uint64                       `json:"next_withdrawal_index,omitempty"`
+	NextWithdrawalValidatorIndex  uint64                       `json:"next_withdrawal_validator_index,omitempty"`
+	HistoricalSummaries           []*HistoricalSummary         `json:"historical_summaries,omitempty" ssz-max:"16777216"`
+	DepositRequestsStartIndex     uint64                       `json:"deposit_requests_start_index"`
+	DepositBalanceToConsume       uint64                       `json:"deposit_balance_to_consume"`
+	ExitBalanceToConsume          uint64                       `json:"exit_balance_to_consume"`
+	EarliestExitEpoch             uint64                       `json:"earliest_exit_epoch"`
+	ConsolidationBalanceToConsume uint64                       `json:"consolidation_balance_to_consume"`
+	EarliestConsolidationEpoch    uint64                       `json:"earliest_consolidation_epoch"`
+	PendingDeposits               []*PendingDeposit            `json:"pending_deposits" ssz-max:"134217728"`
+	PendingPartialWithdrawals     []*PendingPartialWithdrawal  `json:"pending_partial_withdrawals" ssz-max:"134217728"`
+	PendingConsolidations         []*PendingConsolidation      `json:"pending_consolidations" ssz-max:"262144"`
+}
+
+func (b *BeaconBlockElectraMainnet) GetBeaconSlot() uint64 {
+	return b.Slot
+}
+
+func (b *BeaconBlockElectraMainnet) GetBlockBodyTree() (*ssz.Node, error) {
+	return b.Body.GetTree()
+}
+
+func (b *BeaconBlockElectraMainnet) ExecutionPayloadCapella() *ExecutionPayloadCapella {
+	return nil
+}
+
+// ExecutionPayloadDeneb returns the execution payload, which is unchanged in Electra
+func (b *BeaconBlockElectraMainnet) ExecutionPayloadDeneb() *ExecutionPayloadDeneb {
+	return b.Body.ExecutionPayload
+}
+
+func (b *BeaconStateElectraMainnet) GetSlot() uint64 {
+	return b.Slot
+}
+
+func (b *BeaconStateElectraMainnet) GetLatestBlockHeader() *BeaconBlockHeader {
+	return b.LatestBlockHeader
+}
+
+func (b *BeaconStateElectraMainnet) GetBlockRoots() [][]byte {
+	return b.BlockRoots
+}
+
+func (b *BeaconStateElectraMainnet) SetBlockRoots(blockRoots [][]byte) {
+	b.BlockRoots = blockRoots
+}
+
+func (b *BeaconStateElectraMainnet) GetFinalizedCheckpoint() *Checkpoint {
+	return b.FinalizedCheckpoint
+}
+
+func (b *BeaconStateElectraMainnet) GetNextSyncCommittee() *SyncCommittee {
+	return b.NextSyncCommittee
+}
+func (b *BeaconStateElectraMainnet) GetCurrentSyncCommittee() *SyncCommittee {
+	return b.CurrentSyncCommittee
+}
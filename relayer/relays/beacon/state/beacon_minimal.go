@@ -0,0 +1,185 @@
+package state
+
+import (
+	ssz "github.com/ferranbt/fastssz"
+)
+
+// Containers of the minimal preset, used by local devnets. They only differ from the mainnet
+// containers in their SSZ limits, so the getters convert them to the mainnet types.
+
+type SyncCommitteeMinimal struct {
+	PubKeys         [][]byte `json:"pubkeys" ssz-size:"32,48"`
+	AggregatePubKey [48]byte `json:"aggregate_pubkey" ssz-size:"48"`
+}
+
+type SyncAggregateMinimal struct {
+	SyncCommitteeBits      []byte   `json:"sync_committee_bits" ssz-size:"4"`
+	SyncCommitteeSignature [96]byte `json:"sync_committee_signature" ssz-size:"96"`
+}
+
+type BlockRootsContainerMinimal struct {
+	BlockRoots [][]byte `json:"block_roots" ssz-size:"64,32"`
+}
+
+type WithdrawalsRootContainerMinimal struct {
+	Withdrawals []*Withdrawal `ssz-max:"4" json:"withdrawals"`
+}
+
+type ExecutionPayloadDenebMinimal struct {
+	ParentHash    [32]byte      `ssz-size:"32" json:"parent_hash"`
+	FeeRecipient  [20]byte      `ssz-size:"20" json:"fee_recipient"`
+	StateRoot     [32]byte      `ssz-size:"32" json:"state_root"`
+	ReceiptsRoot  [32]byte      `ssz-size:"32" json:"receipts_root"`
+	LogsBloom     [256]byte     `ssz-size:"256" json:"logs_bloom"`
+	PrevRandao    [32]byte      `ssz-size:"32" json:"prev_randao"`
+	BlockNumber   uint64        `json:"block_number"`
+	GasLimit      uint64        `json:"gas_limit"`
+	GasUsed       uint64        `json:"gas_used"`
+	Timestamp     uint64        `json:"timestamp"`
+	ExtraData     []byte        `ssz-max:"32" json:"extra_data"`
+	BaseFeePerGas [32]byte      `ssz-size:"32" json:"base_fee_per_gas"`
+	BlockHash     [32]byte      `ssz-size:"32" json:"block_hash"`
+	Transactions  [][]byte      `ssz-max:"1048576,1073741824" ssz-size:"?,?" json:"transactions"`
+	Withdrawals   []*Withdrawal `ssz-max:"4" json:"withdrawals"`
+	BlobGasUsed   uint64        `json:"blob_gas_used,omitempty"`
+	ExcessBlobGas uint64        `json:"excess_blob_gas,omitempty"`
+}
+
+type BeaconBlockDenebMinimal struct {
+	Slot          uint64                       `json:"slot"`
+	ProposerIndex uint64                       `json:"proposer_index"`
+	ParentRoot    []byte                       `json:"parent_root" ssz-size:"32"`
+	StateRoot     []byte                       `json:"state_root" ssz-size:"32"`
+	Body          *BeaconBlockBodyDenebMinimal `json:"body"`
+}
+
+type BeaconBlockBodyDenebMinimal struct {
+	RandaoReveal          []byte                        `json:"randao_reveal" ssz-size:"96"`
+	Eth1Data              *Eth1Data                     `json:"eth1_data"`
+	Graffiti              [32]byte                      `json:"graffiti" ssz-size:"32"`
+	ProposerSlashings     []*ProposerSlashing           `json:"proposer_slashings" ssz-max:"16"`
+	AttesterSlashings     []*AttesterSlashing           `json:"attester_slashings" ssz-max:"2"`
+	Attestations          []*Attestation                `json:"attestations" ssz-max:"128"`
+	Deposits              []*Deposit                    `json:"deposits" ssz-max:"16"`
+	VoluntaryExits        []*SignedVoluntaryExit        `json:"voluntary_exits" ssz-max:"16"`
+	SyncAggregate         *SyncAggregateMinimal         `json:"sync_aggregate"`
+	ExecutionPayload      *ExecutionPayloadDenebMinimal `json:"execution_payload"`
+	BlsToExecutionChanges []*SignedBLSToExecutionChange `json:"bls_to_execution_changes,omitempty" ssz-max:"16"`
+	BlobKzgCommitments    [][48]byte                    `json:"blob_kzg_commitments,omitempty" ssz-max:"32" ssz-size:"?,48"`
+}
+
+type BeaconStateDenebMinimal struct {
+	GenesisTime                  uint64                       `json:"genesis_time"`
+	GenesisValidatorsRoot        []byte                       `json:"genesis_validators_root" ssz-size:"32"`
+	Slot                         uint64                       `json:"slot"`
+	Fork                         *Fork                        `json:"fork"`
+	LatestBlockHeader            *BeaconBlockHeader           `json:"latest_block_header"`
+	BlockRoots                   [][]byte                     `json:"block_roots" ssz-size:"64,32"`
+	StateRoots                   [][]byte                     `json:"state_roots" ssz-size:"64,32"`
+	HistoricalRoots              [][]byte                     `json:"historical_roots" ssz-max:"16777216" ssz-size:"?,32"`
+	Eth1Data                     *Eth1Data                    `json:"eth1_data"`
+	Eth1DataVotes                []*Eth1Data                  `json:"eth1_data_votes" ssz-max:"32"`
+	Eth1DepositIndex             uint64                       `json:"eth1_deposit_index"`
+	Validators                   []*Validator                 `json:"validators" ssz-max:"1099511627776"`
+	Balances                     []uint64                     `json:"balances" ssz-max:"1099511627776"`
+	RandaoMixes                  [][]byte                     `json:"randao_mixes" ssz-size:"64,32"`
+	Slashings                    []uint64                     `json:"slashings" ssz-size:"64"`
+	PreviousEpochParticipation   []byte                       `json:"previous_epoch_participation" ssz-max:"1099511627776"`
+	CurrentEpochParticipation    []byte                       `json:"current_epoch_participation" ssz-max:"1099511627776"`
+	JustificationBits            []byte                       `json:"justification_bits" cast-type:"github.com/prysmaticlabs/go-bitfield.Bitvector4" ssz-size:"1"`
+	PreviousJustifiedCheckpoint  *Checkpoint                  `json:"previous_justified_checkpoint"`
+	CurrentJustifiedCheckpoint   *Checkpoint                  `json:"current_justified_checkpoint"`
+	FinalizedCheckpoint          *Checkpoint                  `json:"finalized_checkpoint"`
+	InactivityScores             []uint64                     `json:"inactivity_scores" ssz-max:"1099511627776"`
+	CurrentSyncCommittee         *SyncCommitteeMinimal        `json:"current_sync_committee"`
+	NextSyncCommittee            *SyncCommitteeMinimal        `json:"next_sync_committee"`
+	LatestExecutionPayloadHeader *ExecutionPayloadHeaderDeneb `json:"latest_execution_payload_header"`
+	NextWithdrawalIndex          uint64                       `json:"next_withdrawal_index,omitempty"`
+	NextWithdrawalValidatorIndex uint64                       `json:"next_withdrawal_validator_index,omitempty"`
+	HistoricalSummaries          []*HistoricalSummary         `json:"historical_summaries,omitempty" ssz-max:"16777216"`
+}
+
+// NewBeaconBlockDenebMinimal converts a block decoded into the mainnet containers, which do not
+// check list limits until the block is hashed, into the containers of the minimal preset.
+func NewBeaconBlockDenebMinimal(b *BeaconBlockDenebMainnet) *BeaconBlockDenebMinimal {
+	body := b.Body
+	return &BeaconBlockDenebMinimal{
+		Slot:          b.Slot,
+		ProposerIndex: b.ProposerIndex,
+		ParentRoot:    b.ParentRoot,
+		StateRoot:     b.StateRoot,
+		Body: &BeaconBlockBodyDenebMinimal{
+			RandaoReveal:          body.RandaoReveal,
+			Eth1Data:              body.Eth1Data,
+			Graffiti:              body.Graffiti,
+			ProposerSlashings:     body.ProposerSlashings,
+			AttesterSlashings:     body.AttesterSlashings,
+			Attestations:          body.Attestations,
+			Deposits:              body.Deposits,
+			VoluntaryExits:        body.VoluntaryExits,
+			SyncAggregate:         (*SyncAggregateMinimal)(body.SyncAggregate),
+			ExecutionPayload:      (*ExecutionPayloadDenebMinimal)(body.ExecutionPayload),
+			BlsToExecutionChanges: body.BlsToExecutionChanges,
+			BlobKzgCommitments:    body.BlobKzgCommitments,
+		},
+	}
+}
+
+func (b *BlockRootsContainerMinimal) SetBlockRoots(blockRoots [][]byte) {
+	b.BlockRoots = blockRoots
+}
+
+func (w *WithdrawalsRootContainerMinimal) SetWithdrawals(withdrawals []*Withdrawal) {
+	w.Withdrawals = withdrawals
+}
+
+func (s *SyncAggregateMinimal) GetSyncAggregateBits() []byte {
+	return s.SyncCommitteeBits
+}
+
+func (s *SyncAggregateMinimal) GetSyncAggregateSignature() [96]byte {
+	return s.SyncCommitteeSignature
+}
+
+func (b *BeaconBlockDenebMinimal) GetBeaconSlot() uint64 {
+	return b.Slot
+}
+
+func (b *BeaconBlockDenebMinimal) GetBlockBodyTree() (*ssz.Node, error) {
+	return b.Body.GetTree()
+}
+
+func (b *BeaconBlockDenebMinimal) ExecutionPayloadCapella() *ExecutionPayloadCapella {
+	return nil
+}
+
+func (b *BeaconBlockDenebMinimal) ExecutionPayloadDeneb() *ExecutionPayloadDeneb {
+	return (*ExecutionPayloadDeneb)(b.Body.ExecutionPayload)
+}
+
+func (b *BeaconStateDenebMinimal) GetSlot() uint64 {
+	return b.Slot
+}
+
+func (b *BeaconStateDenebMinimal) GetLatestBlockHeader() *BeaconBlockHeader {
+	return b.LatestBlockHeader
+}
+
+func (b *BeaconStateDenebMinimal) GetBlockRoots() [][]byte {
+	return b.BlockRoots
+}
+
+func (b *BeaconStateDenebMinimal) SetBlockRoots(blockRoots [][]byte) {
+	b.BlockRoots = blockRoots
+}
+
+func (b *BeaconStateDenebMinimal) GetFinalizedCheckpoint() *Checkpoint {
+	return b.FinalizedCheckpoint
+}
+
+func (b *BeaconStateDenebMinimal) GetNextSyncCommittee() *SyncCommittee {
+	return (*SyncCommittee)(b.NextSyncCommittee)
+}
+func (b *BeaconStateDenebMinimal) GetCurrentSyncCommittee() *SyncCommittee {
+	return (*SyncCommittee)(b.CurrentSyncCommittee)
+}
@@ -0,0 +1,463 @@
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	ssz "github.com/ferranbt/fastssz"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/config"
+)
+
+const (
+	validatorSize                = 121
+	eth1DataSize                 = 72
+	historicalSummarySize        = 64
+	pendingDepositSize           = 192
+	pendingPartialWithdrawalSize = 24
+	pendingConsolidationSize     = 16
+
+	registryLimit              = 1099511627776
+	historicalRootsLimit       = 16777216
+	pendingDepositsLimit       = 134217728
+	pendingWithdrawalsLimit    = 134217728
+	pendingConsolidationsLimit = 262144
+
+	finalizedCheckpointField = 20
+	offsetSize               = 4
+)
+
+// PartialBeaconState decodes the beacon state fields used by the relay, and only hashes the other fields, so that
+// the validator registry and the other large lists are never held in memory. The state is read sequentially, so it
+// can be decoded while it is being downloaded. Its tree only contains the roots of the state fields, which is enough
+// to prove any of them.
+type PartialBeaconState struct {
+	preset presetSizes
+	fork   string
+
+	slot                 uint64
+	latestBlockHeader    *BeaconBlockHeader
+	blockRoots           [][]byte
+	finalizedCheckpoint  *Checkpoint
+	currentSyncCommittee *SyncCommittee
+	nextSyncCommittee    *SyncCommittee
+	fieldRoots           [][32]byte
+}
+
+type presetSizes struct {
+	slotsPerHistoricalRoot    int
+	epochsPerHistoricalVector int
+	epochsPerSlashingsVector  int
+	eth1DataVotesLimit        uint64
+	syncCommitteeSize         int
+}
+
+var (
+	mainnetSizes = presetSizes{
+		slotsPerHistoricalRoot:    8192,
+		epochsPerHistoricalVector: 65536,
+		epochsPerSlashingsVector:  8192,
+		eth1DataVotesLimit:        2048,
+		syncCommitteeSize:         512,
+	}
+	minimalSizes = presetSizes{
+		slotsPerHistoricalRoot:    64,
+		epochsPerHistoricalVector: 64,
+		epochsPerSlashingsVector:  64,
+		eth1DataVotesLimit:        32,
+		syncCommitteeSize:         32,
+	}
+)
+
+// stateField hashes a beacon state field, decoding it if the relay uses it
+type stateField struct {
+	// Size of a fixed size field in bytes, or zero for a variable size field, which is located by its offset
+	size     int
+	fixed    func(buf []byte) ([32]byte, error)
+	variable func(r io.Reader) ([32]byte, error)
+}
+
+func NewPartialBeaconState(fork string, minimal bool) *PartialBeaconState {
+	preset := mainnetSizes
+	if minimal {
+		preset = minimalSizes
+	}
+	return &PartialBeaconState{
+		preset: preset,
+		fork:   fork,
+	}
+}
+
+// UnmarshalSSZ decodes a beacon state held in memory
+func (p *PartialBeaconState) UnmarshalSSZ(buf []byte) error {
+	return p.Decode(bytes.NewReader(buf))
+}
+
+// Decode reads an SSZ encoded beacon state from r until EOF
+func (p *PartialBeaconState) Decode(r io.Reader) error {
+	reader := bufio.NewReader(r)
+	fields := p.fields()
+
+	fixedSize := 0
+	for _, field := range fields {
+		if field.size == 0 {
+			fixedSize += offsetSize
+		} else {
+			fixedSize += field.size
+		}
+	}
+
+	fixed := make([]byte, fixedSize)
+	_, err := io.ReadFull(reader, fixed)
+	if err != nil {
+		return fmt.Errorf("read fixed size fields: %w", err)
+	}
+
+	p.fieldRoots = make([][32]byte, len(fields))
+	var variableFields []int
+	var offsets []uint64
+	position := 0
+	for i, field := range fields {
+		if field.size == 0 {
+			offsets = append(offsets, uint64(binary.LittleEndian.Uint32(fixed[position:])))
+			variableFields = append(variableFields, i)
+			position += offsetSize
+			continue
+		}
+		p.fieldRoots[i], err = field.fixed(fixed[position : position+field.size])
+		if err != nil {
+			return fmt.Errorf("decode field %d: %w", i, err)
+		}
+		position += field.size
+	}
+
+	if len(offsets) > 0 && offsets[0] != uint64(fixedSize) {
+		return ssz.ErrInvalidVariableOffset
+	}
+	for j, i := range variableFields {
+		// The last variable size field extends to the end of the state
+		if j+1 == len(offsets) {
+			p.fieldRoots[i], err = fields[i].variable(reader)
+			if err != nil {
+				return fmt.Errorf("decode field %d: %w", i, err)
+			}
+			continue
+		}
+
+		if offsets[j+1] < offsets[j] {
+			return ssz.ErrOffset
+		}
+		fieldReader := &io.LimitedReader{R: reader, N: int64(offsets[j+1] - offsets[j])}
+		p.fieldRoots[i], err = fields[i].variable(fieldReader)
+		if err != nil {
+			return fmt.Errorf("decode field %d: %w", i, err)
+		}
+		if fieldReader.N != 0 {
+			return fmt.Errorf("decode field %d: %w", i, io.ErrUnexpectedEOF)
+		}
+	}
+
+	return nil
+}
+
+func (p *PartialBeaconState) fields() []stateField {
+	p.latestBlockHeader = &BeaconBlockHeader{}
+	p.finalizedCheckpoint = &Checkpoint{}
+	syncCommitteeSize := p.preset.syncCommitteeSize*48 + 48
+
+	fields := []stateField{
+		uint64Field(nil),                         // genesis_time
+		bytes32Field(),                           // genesis_validators_root
+		uint64Field(&p.slot),                     // slot
+		containerField(16, &Fork{}),              // fork
+		containerField(112, p.latestBlockHeader), // latest_block_header
+		rootVectorField(p.preset.slotsPerHistoricalRoot, &p.blockRoots),            // block_roots
+		rootVectorField(p.preset.slotsPerHistoricalRoot, nil),                      // state_roots
+		rootListField(historicalRootsLimit),                                        // historical_roots
+		containerField(eth1DataSize, &Eth1Data{}),                                  // eth1_data
+		containerListField(eth1DataSize, p.preset.eth1DataVotesLimit, newEth1Data), // eth1_data_votes
+		uint64Field(nil), // eth1_deposit_index
+		containerListField(validatorSize, registryLimit, newValidator),                        // validators
+		packedListField(8, registryLimit),                                                     // balances
+		rootVectorField(p.preset.epochsPerHistoricalVector, nil),                              // randao_mixes
+		packedVectorField(8, p.preset.epochsPerSlashingsVector),                               // slashings
+		packedListField(1, registryLimit),                                                     // previous_epoch_participation
+		packedListField(1, registryLimit),                                                     // current_epoch_participation
+		packedVectorField(1, 1),                                                               // justification_bits
+		containerField(40, &Checkpoint{}),                                                     // previous_justified_checkpoint
+		containerField(40, &Checkpoint{}),                                                     // current_justified_checkpoint
+		containerField(40, p.finalizedCheckpoint),                                             // finalized_checkpoint
+		packedListField(8, registryLimit),                                                     // inactivity_scores
+		p.syncCommitteeField(syncCommitteeSize, &p.currentSyncCommittee),                      // current_sync_committee
+		p.syncCommitteeField(syncCommitteeSize, &p.nextSyncCommittee),                         // next_sync_committee
+		p.executionPayloadHeaderField(),                                                       // latest_execution_payload_header
+		uint64Field(nil),                                                                      // next_withdrawal_index
+		uint64Field(nil),                                                                      // next_withdrawal_validator_index
+		containerListField(historicalSummarySize, historicalRootsLimit, newHistoricalSummary), // historical_summaries
+	}
+
+	if p.fork == config.Electra {
+		fields = append(fields,
+			uint64Field(nil), // deposit_requests_start_index
+			uint64Field(nil), // deposit_balance_to_consume
+			uint64Field(nil), // exit_balance_to_consume
+			uint64Field(nil), // earliest_exit_epoch
+			uint64Field(nil), // consolidation_balance_to_consume
+			uint64Field(nil), // earliest_consolidation_epoch
+			containerListField(pendingDepositSize, pendingDepositsLimit, newPendingDeposit),                        // pending_deposits
+			containerListField(pendingPartialWithdrawalSize, pendingWithdrawalsLimit, newPendingPartialWithdrawal), // pending_partial_withdrawals
+			containerListField(pendingConsolidationSize, pendingConsolidationsLimit, newPendingConsolidation),      // pending_consolidations
+		)
+	}
+
+	return fields
+}
+
+func (p *PartialBeaconState) syncCommitteeField(size int, dst **SyncCommittee) stateField {
+	return stateField{size: size, fixed: func(buf []byte) ([32]byte, error) {
+		// Sync committees of the minimal preset have a different SSZ size, so they are decoded into their own type
+		if p.preset.syncCommitteeSize != mainnetSizes.syncCommitteeSize {
+			syncCommittee := &SyncCommitteeMinimal{}
+			err := syncCommittee.UnmarshalSSZ(buf)
+			if err != nil {
+				return [32]byte{}, err
+			}
+			*dst = (*SyncCommittee)(syncCommittee)
+			return syncCommittee.HashTreeRoot()
+		}
+		syncCommittee := &SyncCommittee{}
+		err := syncCommittee.UnmarshalSSZ(buf)
+		if err != nil {
+			return [32]byte{}, err
+		}
+		*dst = syncCommittee
+		return syncCommittee.HashTreeRoot()
+	}}
+}
+
+func (p *PartialBeaconState) executionPayloadHeaderField() stateField {
+	return stateField{variable: func(r io.Reader) ([32]byte, error) {
+		buf, err := io.ReadAll(r)
+		if err != nil {
+			return [32]byte{}, err
+		}
+		var header interface {
+			UnmarshalSSZ(buf []byte) error
+			HashTreeRoot() ([32]byte, error)
+		}
+		if p.fork == config.Capella {
+			header = &ExecutionPayloadHeaderCapella{}
+		} else {
+			header = &ExecutionPayloadHeaderDeneb{}
+		}
+		err = header.UnmarshalSSZ(buf)
+		if err != nil {
+			return [32]byte{}, err
+		}
+		return header.HashTreeRoot()
+	}}
+}
+
+func uint64Field(dst *uint64) stateField {
+	return stateField{size: 8, fixed: func(buf []byte) ([32]byte, error) {
+		if dst != nil {
+			*dst = binary.LittleEndian.Uint64(buf)
+		}
+		var root [32]byte
+		copy(root[:], buf)
+		return root, nil
+	}}
+}
+
+func bytes32Field() stateField {
+	return stateField{size: 32, fixed: func(buf []byte) ([32]byte, error) {
+		var root [32]byte
+		copy(root[:], buf)
+		return root, nil
+	}}
+}
+
+type sszContainer interface {
+	UnmarshalSSZ(buf []byte) error
+	HashTreeRoot() ([32]byte, error)
+	HashTreeRootWith(hh ssz.HashWalker) error
+}
+
+// containerField decodes a fixed size container into obj
+func containerField(size int, obj sszContainer) stateField {
+	return stateField{size: size, fixed: func(buf []byte) ([32]byte, error) {
+		err := obj.UnmarshalSSZ(buf)
+		if err != nil {
+			return [32]byte{}, err
+		}
+		return obj.HashTreeRoot()
+	}}
+}
+
+// rootVectorField hashes a vector of roots, keeping a copy of the roots if dst is set
+func rootVectorField(length int, dst *[][]byte) stateField {
+	return stateField{size: length * 32, fixed: func(buf []byte) ([32]byte, error) {
+		if dst != nil {
+			roots := make([][]byte, length)
+			for i := range roots {
+				roots[i] = append([]byte{}, buf[i*32:(i+1)*32]...)
+			}
+			*dst = roots
+		}
+		hh := ssz.NewHasher()
+		indx := hh.Index()
+		hh.Append(buf)
+		hh.Merkleize(indx)
+		return hh.HashRoot()
+	}}
+}
+
+func packedVectorField(elementSize, length int) stateField {
+	return stateField{size: elementSize * length, fixed: func(buf []byte) ([32]byte, error) {
+		hh := ssz.NewHasher()
+		indx := hh.Index()
+		hh.Append(buf)
+		hh.FillUpTo32()
+		hh.Merkleize(indx)
+		return hh.HashRoot()
+	}}
+}
+
+func rootListField(limit uint64) stateField {
+	return packedListField(32, limit)
+}
+
+// packedListField hashes a list of basic values, which are packed into chunks
+func packedListField(elementSize int, limit uint64) stateField {
+	return stateField{variable: func(r io.Reader) ([32]byte, error) {
+		hh := ssz.NewHasher()
+		indx := hh.Index()
+		num, err := readElements(r, elementSize, func(element []byte) error {
+			hh.Append(element)
+			return nil
+		})
+		if err != nil {
+			return [32]byte{}, err
+		}
+		if num > limit {
+			return [32]byte{}, ssz.ErrIncorrectListSize
+		}
+		hh.FillUpTo32()
+		hh.MerkleizeWithMixin(indx, num, ssz.CalculateLimit(limit, num, uint64(elementSize)))
+		return hh.HashRoot()
+	}}
+}
+
+// containerListField hashes a list of fixed size containers one element at a time, decoding each into a new element
+func containerListField(elementSize int, limit uint64, newElement func() sszContainer) stateField {
+	return stateField{variable: func(r io.Reader) ([32]byte, error) {
+		hh := ssz.NewHasher()
+		indx := hh.Index()
+		num, err := readElements(r, elementSize, func(buf []byte) error {
+			element := newElement()
+			err := element.UnmarshalSSZ(buf)
+			if err != nil {
+				return err
+			}
+			return element.HashTreeRootWith(hh)
+		})
+		if err != nil {
+			return [32]byte{}, err
+		}
+		if num > limit {
+			return [32]byte{}, ssz.ErrIncorrectListSize
+		}
+		hh.MerkleizeWithMixin(indx, num, limit)
+		return hh.HashRoot()
+	}}
+}
+
+// Constructors of the list elements decoded by containerListField
+func newEth1Data() sszContainer                 { return &Eth1Data{} }
+func newValidator() sszContainer                { return &Validator{} }
+func newHistoricalSummary() sszContainer        { return &HistoricalSummary{} }
+func newPendingDeposit() sszContainer           { return &PendingDeposit{} }
+func newPendingPartialWithdrawal() sszContainer { return &PendingPartialWithdrawal{} }
+func newPendingConsolidation() sszContainer     { return &PendingConsolidation{} }
+
+// readElements reads fixed size elements from r until EOF
+func readElements(r io.Reader, elementSize int, handle func(element []byte) error) (uint64, error) {
+	var num uint64
+	buf := make([]byte, elementSize)
+	for {
+		_, err := io.ReadFull(r, buf)
+		if errors.Is(err, io.EOF) {
+			return num, nil
+		}
+		if err != nil {
+			return num, err
+		}
+		err = handle(buf)
+		if err != nil {
+			return num, err
+		}
+		num++
+	}
+}
+
+func (p *PartialBeaconState) GetTree() (*ssz.Node, error) {
+	leaves := make([]*ssz.Node, len(p.fieldRoots))
+	for i, root := range p.fieldRoots {
+		leaves[i] = ssz.NewNodeWithValue(append([]byte{}, root[:]...))
+	}
+
+	// Expand the finalized checkpoint, since its root is proven rather than the whole checkpoint
+	checkpointTree, err := p.finalizedCheckpoint.GetTree()
+	if err != nil {
+		return nil, err
+	}
+	leaves[finalizedCheckpointField] = checkpointTree
+
+	return ssz.TreeFromNodes(leaves, nextPowerOfTwo(len(leaves)))
+}
+
+// HashTreeRoot returns the root of the beacon state
+func (p *PartialBeaconState) HashTreeRoot() ([32]byte, error) {
+	var root [32]byte
+	tree, err := p.GetTree()
+	if err != nil {
+		return root, err
+	}
+	copy(root[:], tree.Hash())
+	return root, nil
+}
+
+func (p *PartialBeaconState) GetSlot() uint64 {
+	return p.slot
+}
+
+func (p *PartialBeaconState) GetLatestBlockHeader() *BeaconBlockHeader {
+	return p.latestBlockHeader
+}
+
+func (p *PartialBeaconState) GetBlockRoots() [][]byte {
+	return p.blockRoots
+}
+
+func (p *PartialBeaconState) GetFinalizedCheckpoint() *Checkpoint {
+	return p.finalizedCheckpoint
+}
+
+func (p *PartialBeaconState) GetCurrentSyncCommittee() *SyncCommittee {
+	return p.currentSyncCommittee
+}
+
+func (p *PartialBeaconState) GetNextSyncCommittee() *SyncCommittee {
+	return p.nextSyncCommittee
+}
+
+func nextPowerOfTwo(n int) int {
+	power := 1
+	for power < n {
+		power *= 2
+	}
+	return power
+}
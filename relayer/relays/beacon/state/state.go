@@ -16,4 +16,7 @@ type FinalizedHeader struct {
 	BeaconSlot            uint64
 	InitialCheckpointRoot common.Hash
 	InitialCheckpointSlot uint64
+	// Hash tree root of the block roots of the finalized state, which is only set for finalized headers fetched by
+	// block root
+	BlockRootsRoot common.Hash
 }
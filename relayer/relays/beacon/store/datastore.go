@@ -4,10 +4,12 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/protocol"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -15,6 +17,8 @@ import (
 
 const BeaconStateDir = "states"
 const BeaconStateFilename = "beacon_state_%d.ssz"
+const ArchiveStateFilename = "archive_state_%d.ssz"
+const CachedStateFilename = "cached_state_%d.ssz"
 const BeaconStoreName = "beacon-state"
 
 type BeaconStore interface {
@@ -22,7 +26,11 @@ type BeaconStore interface {
 	Close()
 	FindBeaconStateWithinRange(slot, boundary uint64) (StoredBeaconData, error)
 	GetBeaconStateData(slot uint64) ([]byte, error)
+	OpenBeaconState(slot uint64) (io.ReadCloser, error)
 	WriteEntry(attestedSlot, finalizedSlot uint64, attestedStateData, finalizedStateData []byte) error
+	WriteCheckpoint(checkpoint Checkpoint) error
+	ListCheckpoints() ([]Checkpoint, error)
+	DeleteCheckpoints(slots []uint64) error
 }
 
 type BeaconState struct {
@@ -43,6 +51,13 @@ type StoredBeaconData struct {
 	FinalizedBeaconState []byte
 }
 
+// Checkpoint is a finalized header and the block roots of its state, which prove the ancestry of older headers
+type Checkpoint struct {
+	Slot       uint64
+	BlockRoot  common.Hash
+	BlockRoots [][]byte
+}
+
 type Store struct {
 	location   string
 	maxEntries uint64
@@ -123,8 +138,56 @@ func (s *Store) FindBeaconStateWithinRange(minSlot, maxSlot uint64) (StoredBeaco
 	return data, nil
 }
 
+// HasAttestedState checks whether an attested and finalized pair with the attested slot is stored, without reading
+// the state files.
+func (s *Store) HasAttestedState(attestedSlot uint64) (bool, error) {
+	return s.hasEntry("attested_slot", attestedSlot)
+}
+
+// HasFinalizedState checks whether an attested and finalized pair with the finalized slot is stored, without reading
+// the state files.
+func (s *Store) HasFinalizedState(finalizedSlot uint64) (bool, error) {
+	return s.hasEntry("finalized_slot", finalizedSlot)
+}
+
+func (s *Store) hasEntry(slotColumn string, slot uint64) (bool, error) {
+	var count int
+	err := s.db.QueryRow(fmt.Sprintf(`SELECT COUNT(*) FROM beacon_state WHERE %s = ?`, slotColumn), slot).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("count beacon states: %w", err)
+	}
+
+	return count > 0, nil
+}
+
 // GetBeaconStateData finds a beacon state at a slot.
 func (s *Store) GetBeaconStateData(slot uint64) ([]byte, error) {
+	filename, err := s.findStateFile(slot)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.ReadStateFile(filename)
+}
+
+// OpenBeaconState opens the file of the beacon state at a slot, so that the state can be decoded while it is read.
+// The file must be closed by the caller.
+func (s *Store) OpenBeaconState(slot uint64) (io.ReadCloser, error) {
+	filename, err := s.findStateFile(slot)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(s.stateFileLocation(filename))
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+
+	return file, nil
+}
+
+// findStateFile returns the name of the file of the beacon state at a slot
+func (s *Store) findStateFile(slot uint64) (string, error) {
 	query := `SELECT attested_slot, finalized_slot, attested_state_filename, finalized_state_filename FROM beacon_state WHERE attested_slot = ? OR finalized_slot = ? LIMIT 1`
 	var attestedSlot uint64
 	var finalizedSlot uint64
@@ -133,21 +196,20 @@ func (s *Store) GetBeaconStateData(slot uint64) ([]byte, error) {
 	err := s.db.QueryRow(query, slot, slot).Scan(&attestedSlot, &finalizedSlot, &attestedStateFilename, &finalizedStateFilename)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			// No finalized slots found within the range
-			return nil, fmt.Errorf("no match found")
+			return s.findUnpairedStateFile(slot)
 		}
-		return nil, err
+		return "", err
 	}
 
 	if attestedSlot == slot {
-		return s.ReadStateFile(attestedStateFilename)
+		return attestedStateFilename, nil
 	}
 
 	if finalizedSlot == slot {
-		return s.ReadStateFile(finalizedStateFilename)
+		return finalizedStateFilename, nil
 	}
 
-	return nil, fmt.Errorf("no beacon state found")
+	return "", fmt.Errorf("no beacon state found")
 }
 
 func (s *Store) WriteEntry(attestedSlot, finalizedSlot uint64, attestedStateData, finalizedStateData []byte) error {
@@ -166,6 +228,137 @@ func (s *Store) WriteEntry(attestedSlot, finalizedSlot uint64, attestedStateData
 	return s.storeUpdate(attestedSlot, finalizedSlot, attestedSyncPeriod, finalizedSyncPeriod)
 }
 
+// WriteArchiveState stores a beacon state which is not part of an attested and finalized pair, such as a state
+// imported from an era file. Archive states are not pruned.
+func (s *Store) WriteArchiveState(slot uint64, data []byte) error {
+	filename := fmt.Sprintf(ArchiveStateFilename, slot)
+	err := os.WriteFile(s.stateFileLocation(filename), data, 0644)
+	if err != nil {
+		return fmt.Errorf("write to file: %w", err)
+	}
+
+	_, err = s.db.Exec(`INSERT OR REPLACE INTO archive_state (slot, state_filename) VALUES (?, ?)`, slot, filename)
+	if err != nil {
+		return fmt.Errorf("insert archive state: %w", err)
+	}
+
+	return nil
+}
+
+// WriteCachedState stores a beacon state which was downloaded on request, so that it is not downloaded again. Like the
+// attested and finalized pairs, only the most recent maxEntries cached states are kept. The state is copied to a
+// temporary file while it is read, and only stored once it has been read completely.
+func (s *Store) WriteCachedState(slot uint64, state io.Reader) error {
+	filename := fmt.Sprintf(CachedStateFilename, slot)
+	tmp, err := os.CreateTemp(filepath.Dir(s.stateFileLocation(filename)), filename+".*")
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, state)
+	if err != nil {
+		tmp.Close()
+		return fmt.Errorf("write to file: %w", err)
+	}
+	err = tmp.Close()
+	if err != nil {
+		return fmt.Errorf("write to file: %w", err)
+	}
+	err = os.Rename(tmp.Name(), s.stateFileLocation(filename))
+	if err != nil {
+		return fmt.Errorf("write to file: %w", err)
+	}
+
+	_, err = s.db.Exec(`INSERT OR REPLACE INTO cached_state (slot, state_filename) VALUES (?, ?)`, slot, filename)
+	if err != nil {
+		return fmt.Errorf("insert cached state: %w", err)
+	}
+
+	return nil
+}
+
+// findUnpairedStateFile returns the name of the file of an archive or cached beacon state at a slot
+func (s *Store) findUnpairedStateFile(slot uint64) (string, error) {
+	var filename string
+	err := s.db.QueryRow(`SELECT state_filename FROM archive_state WHERE slot = ? UNION ALL SELECT state_filename FROM cached_state WHERE slot = ? LIMIT 1`, slot, slot).Scan(&filename)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("no match found")
+		}
+		return "", err
+	}
+
+	return filename, nil
+}
+
+// WriteCheckpoint stores a checkpoint, replacing a checkpoint which was stored at the same slot
+func (s *Store) WriteCheckpoint(checkpoint Checkpoint) error {
+	blockRoots := make([]byte, 0, len(checkpoint.BlockRoots)*common.HashLength)
+	for i, blockRoot := range checkpoint.BlockRoots {
+		if len(blockRoot) != common.HashLength {
+			return fmt.Errorf("block root %d has length %d", i, len(blockRoot))
+		}
+		blockRoots = append(blockRoots, blockRoot...)
+	}
+
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO checkpoint (slot, block_root, block_roots) VALUES (?, ?, ?)`, checkpoint.Slot, checkpoint.BlockRoot.Hex(), blockRoots)
+	if err != nil {
+		return fmt.Errorf("insert checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// ListCheckpoints returns the stored checkpoints, ordered by slot
+func (s *Store) ListCheckpoints() ([]Checkpoint, error) {
+	rows, err := s.db.Query(`SELECT slot, block_root, block_roots FROM checkpoint ORDER BY slot`)
+	if err != nil {
+		return nil, fmt.Errorf("select checkpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var checkpoints []Checkpoint
+	for rows.Next() {
+		var slot uint64
+		var blockRoot string
+		var blockRoots []byte
+		err := rows.Scan(&slot, &blockRoot, &blockRoots)
+		if err != nil {
+			return nil, fmt.Errorf("scan checkpoint: %w", err)
+		}
+		if len(blockRoots)%common.HashLength != 0 {
+			return nil, fmt.Errorf("block roots of checkpoint at slot %d have length %d", slot, len(blockRoots))
+		}
+
+		checkpoint := Checkpoint{
+			Slot:      slot,
+			BlockRoot: common.HexToHash(blockRoot),
+		}
+		for i := 0; i < len(blockRoots); i += common.HashLength {
+			checkpoint.BlockRoots = append(checkpoint.BlockRoots, blockRoots[i:i+common.HashLength])
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("iterate checkpoints: %w", err)
+	}
+
+	return checkpoints, nil
+}
+
+func (s *Store) DeleteCheckpoints(slots []uint64) error {
+	for _, slot := range slots {
+		_, err := s.db.Exec(`DELETE FROM checkpoint WHERE slot = ?`, slot)
+		if err != nil {
+			return fmt.Errorf("delete checkpoint at slot %d: %w", slot, err)
+		}
+	}
+
+	return nil
+}
+
 func (s *Store) ListBeaconStates() ([]BeaconState, error) {
 	var response []BeaconState
 
@@ -288,7 +481,49 @@ func (s *Store) PruneOldStates() ([]uint64, error) {
 		return nil, fmt.Errorf("failed to prune oldest entries: %w", err)
 	}
 
-	return deleteSlots, nil
+	cachedSlots, err := s.pruneCachedStates()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(deleteSlots, cachedSlots...), nil
+}
+
+// pruneCachedStates deletes the cached states, except for the most recent maxEntries
+func (s *Store) pruneCachedStates() ([]uint64, error) {
+	rows, err := s.db.Query(fmt.Sprintf(`SELECT slot, state_filename FROM cached_state ORDER BY slot DESC LIMIT -1 OFFSET %d`, s.maxEntries))
+	if err != nil {
+		return nil, fmt.Errorf("failed to select oldest cached states: %w", err)
+	}
+	defer rows.Close()
+
+	var slots []uint64
+	var filenames []string
+	for rows.Next() {
+		var slot uint64
+		var filename string
+		if err := rows.Scan(&slot, &filename); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		slots = append(slots, slot)
+		filenames = append(filenames, filename)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	for i, slot := range slots {
+		err := s.DeleteStateFile(filenames[i])
+		if err != nil {
+			return nil, err
+		}
+		_, err = s.db.Exec(`DELETE FROM cached_state WHERE slot = ?`, slot)
+		if err != nil {
+			return nil, fmt.Errorf("delete cached state at slot %d: %w", slot, err)
+		}
+	}
+
+	return slots, nil
 }
 
 func createBeaconStateDir(dirPath string) error {
@@ -308,6 +543,19 @@ func (s *Store) createTable() error {
 		attested_state_filename TEXT NOT NULL,
 		finalized_state_filename TEXT NOT NULL,
 		timestamp INTEGER DEFAULT (strftime('%s', 'now'))
+	);
+	CREATE TABLE IF NOT EXISTS archive_state (
+		slot INTEGER PRIMARY KEY,
+		state_filename TEXT NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS cached_state (
+		slot INTEGER PRIMARY KEY,
+		state_filename TEXT NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS checkpoint (
+		slot INTEGER PRIMARY KEY,
+		block_root TEXT NOT NULL,
+		block_roots BLOB NOT NULL
 	);`
 	_, err := s.db.Exec(sqlStmt)
 	if err != nil {
@@ -2,11 +2,13 @@ package store
 
 import (
 	"fmt"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/config"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/protocol"
 	"github.com/snowfork/snowbridge/relayer/relays/testutil"
 	"github.com/stretchr/testify/require"
 	"os"
+	"strings"
 	"testing"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -207,3 +209,147 @@ func TestFindBeaconStateWithinRange(t *testing.T) {
 	require.Equal(t, int(pair1AttestedSlot), int(beaconData.AttestedSlot))
 	require.Equal(t, int(pair1FinalizedSlot), int(beaconData.FinalizedSlot))
 }
+
+func TestArchiveState(t *testing.T) {
+	_ = os.RemoveAll(TestDataStoreFile + BeaconStateDir)
+	_ = os.Remove(TestDataStoreFile + BeaconStoreName)
+
+	store := New(TestDataStoreFile, 1, *protocol.New(config.SpecSettings{
+		SlotsInEpoch:                 32,
+		EpochsPerSyncCommitteePeriod: 256,
+		DenebForkEpoch:               0,
+	}, MaxRedundancy))
+	err := store.Connect()
+	require.NoError(t, err)
+	defer func() {
+		err := os.RemoveAll(TestDataStoreFile + BeaconStateDir)
+		require.NoError(t, err)
+		err = os.Remove(TestDataStoreFile + BeaconStoreName)
+		require.NoError(t, err)
+		store.Close()
+	}()
+
+	archiveSlot := uint64(4571136)
+	archiveState, err := testutil.LoadFile(fmt.Sprintf("%d.ssz", archiveSlot))
+	require.NoError(t, err)
+
+	err = store.WriteArchiveState(archiveSlot, archiveState)
+	require.NoError(t, err)
+
+	data, err := store.GetBeaconStateData(archiveSlot)
+	require.NoError(t, err)
+	require.Equal(t, archiveState, data)
+
+	// Archive states are neither paired nor pruned
+	_, err = store.FindBeaconStateWithinRange(archiveSlot, archiveSlot)
+	require.Error(t, err)
+
+	for _, pair := range [][2]uint64{{4570752, 4570816}, {4644864, 4644928}} {
+		finalizedState, err := testutil.LoadFile(fmt.Sprintf("%d.ssz", pair[0]))
+		require.NoError(t, err)
+		attestedState, err := testutil.LoadFile(fmt.Sprintf("%d.ssz", pair[1]))
+		require.NoError(t, err)
+		err = store.WriteEntry(pair[1], pair[0], attestedState, finalizedState)
+		require.NoError(t, err)
+	}
+	_, err = store.PruneOldStates()
+	require.NoError(t, err)
+
+	data, err = store.GetBeaconStateData(archiveSlot)
+	require.NoError(t, err)
+	require.Equal(t, archiveState, data)
+}
+
+func TestCachedStates(t *testing.T) {
+	_ = os.RemoveAll(TestDataStoreFile + BeaconStateDir)
+	_ = os.Remove(TestDataStoreFile + BeaconStoreName)
+
+	store := New(TestDataStoreFile, 1, *protocol.New(config.SpecSettings{
+		SlotsInEpoch:                 32,
+		EpochsPerSyncCommitteePeriod: 256,
+		DenebForkEpoch:               0,
+	}, MaxRedundancy))
+	err := store.Connect()
+	require.NoError(t, err)
+	defer func() {
+		err := os.RemoveAll(TestDataStoreFile + BeaconStateDir)
+		require.NoError(t, err)
+		err = os.Remove(TestDataStoreFile + BeaconStoreName)
+		require.NoError(t, err)
+		store.Close()
+	}()
+
+	for _, slot := range []uint64{4571072, 4571136} {
+		err = store.WriteCachedState(slot, strings.NewReader(fmt.Sprintf("beacon state at slot %d", slot)))
+		require.NoError(t, err)
+	}
+
+	data, err := store.GetBeaconStateData(4571072)
+	require.NoError(t, err)
+	require.Equal(t, []byte("beacon state at slot 4571072"), data)
+
+	// Only the most recent cached states are kept
+	deleted, err := store.PruneOldStates()
+	require.NoError(t, err)
+	require.Equal(t, []uint64{4571072}, deleted)
+	require.False(t, store.StateFileExists(fmt.Sprintf(CachedStateFilename, 4571072)))
+	_, err = store.GetBeaconStateData(4571072)
+	require.Error(t, err)
+
+	data, err = store.GetBeaconStateData(4571136)
+	require.NoError(t, err)
+	require.Equal(t, []byte("beacon state at slot 4571136"), data)
+
+	stored, err := store.HasFinalizedState(4571136)
+	require.NoError(t, err)
+	require.False(t, stored)
+}
+
+func TestCheckpoints(t *testing.T) {
+	_ = os.RemoveAll(TestDataStoreFile + BeaconStateDir)
+	_ = os.Remove(TestDataStoreFile + BeaconStoreName)
+
+	store := New(TestDataStoreFile, 100, *protocol.New(config.SpecSettings{
+		SlotsInEpoch:                 32,
+		EpochsPerSyncCommitteePeriod: 256,
+		DenebForkEpoch:               0,
+	}, MaxRedundancy))
+	err := store.Connect()
+	require.NoError(t, err)
+	defer func() {
+		err := os.RemoveAll(TestDataStoreFile + BeaconStateDir)
+		require.NoError(t, err)
+		err = os.Remove(TestDataStoreFile + BeaconStoreName)
+		require.NoError(t, err)
+		store.Close()
+	}()
+
+	checkpoint1 := Checkpoint{
+		Slot:       4571136,
+		BlockRoot:  common.HexToHash("0x3e32d3c6fd9e2d4ec48a5e5bd9fd8a6ce2e1c60cfec3ee8d98e2c4f05bcb2d14"),
+		BlockRoots: [][]byte{common.HexToHash("0x01").Bytes(), common.HexToHash("0x02").Bytes()},
+	}
+	checkpoint2 := Checkpoint{
+		Slot:       4571072,
+		BlockRoot:  common.HexToHash("0x8d2bd0c4b4dc3e0c5f0b3a1d1c0dcbd5a1a3f37d10a46ea31e24cba5e9b8c6f1"),
+		BlockRoots: [][]byte{common.HexToHash("0x03").Bytes(), common.HexToHash("0x04").Bytes()},
+	}
+	require.NoError(t, store.WriteCheckpoint(checkpoint1))
+	require.NoError(t, store.WriteCheckpoint(checkpoint2))
+
+	checkpoints, err := store.ListCheckpoints()
+	require.NoError(t, err)
+	require.Equal(t, []Checkpoint{checkpoint2, checkpoint1}, checkpoints)
+
+	// Writing a checkpoint at the same slot replaces it
+	checkpoint2.BlockRoot = common.HexToHash("0x05")
+	require.NoError(t, store.WriteCheckpoint(checkpoint2))
+	require.NoError(t, store.DeleteCheckpoints([]uint64{checkpoint1.Slot}))
+
+	checkpoints, err = store.ListCheckpoints()
+	require.NoError(t, err)
+	require.Equal(t, []Checkpoint{checkpoint2}, checkpoints)
+
+	err = store.WriteCheckpoint(Checkpoint{Slot: 1, BlockRoots: [][]byte{{0x01}}})
+	require.Error(t, err)
+}
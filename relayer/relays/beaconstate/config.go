@@ -0,0 +1,36 @@
+package beaconstate
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/snowfork/snowbridge/relayer/config"
+	beaconconf "github.com/snowfork/snowbridge/relayer/relays/beacon/config"
+)
+
+type Config struct {
+	Source SourceConfig `mapstructure:"source"`
+	// Address on which beacon states are served, e.g. ":8080"
+	Listen string `mapstructure:"listen"`
+	// Interval (in seconds) between checks for a new finalized update, whose beacon states are prefetched
+	PollInterval uint64 `mapstructure:"pollInterval"`
+	// Maximum number of beacon states which are served at the same time, since each response holds a full state in
+	// memory
+	MaxConcurrentResponses uint64               `mapstructure:"maxConcurrentResponses"`
+	Metrics                config.MetricsConfig `mapstructure:"metrics"`
+}
+
+type SourceConfig struct {
+	Beacon beaconconf.BeaconConfig `mapstructure:"beacon"`
+}
+
+func (c Config) Validate() error {
+	err := c.Source.Beacon.Validate()
+	if err != nil {
+		return fmt.Errorf("beacon config validation: %w", err)
+	}
+	if c.Listen == "" {
+		return errors.New("setting [listen] is not set")
+	}
+	return nil
+}
@@ -0,0 +1,363 @@
+package beaconstate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/snowfork/snowbridge/relayer/metrics"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/era"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer/api"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/protocol"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/store"
+	"github.com/snowfork/snowbridge/relayer/relays/util"
+)
+
+const (
+	statesPath          = "/eth/v2/debug/beacon/states/"
+	defaultPollInterval = 60
+	// Full beacon states are hundreds of megabytes on mainnet, so only a few are served at a time by default
+	defaultMaxConcurrentResponses = 4
+	shutdownTimeout               = 5 * time.Second
+)
+
+// Service owns a beacon state datastore, which it fills with the attested and finalized state pairs of finalized
+// updates, and serves the states to relays which use it as their beacon state endpoint.
+type Service struct {
+	config   *Config
+	client   api.BeaconAPI
+	protocol *protocol.Protocol
+	store    *store.Store
+	syncer   *syncer.Syncer
+	// Where states are served from, which is the datastore and the era archive, if one is configured
+	states store.BeaconStore
+	// Concurrent downloads of the same state, on a store miss, share a single download
+	downloads singleflight.Group
+	// Limits the number of states which are served at the same time
+	responses chan struct{}
+	// Held by the handlers while they open state files, and by prefetch while it writes or prunes them, exclusively
+	// when pruning. An open state file can still be read after it is pruned.
+	statesLock sync.RWMutex
+}
+
+func New(config *Config) *Service {
+	p := protocol.New(config.Source.Beacon.Spec, 0)
+	s := store.New(config.Source.Beacon.DataStore.Location, config.Source.Beacon.DataStore.MaxEntries, *p)
+	client := api.NewBeaconClient(config.Source.Beacon.Endpoint, config.Source.Beacon.StateEndpoint)
+
+	maxConcurrentResponses := config.MaxConcurrentResponses
+	if maxConcurrentResponses == 0 {
+		maxConcurrentResponses = defaultMaxConcurrentResponses
+	}
+
+	return &Service{
+		config:    config,
+		client:    client,
+		protocol:  p,
+		store:     &s,
+		syncer:    syncer.New(client, &s, p),
+		states:    &s,
+		responses: make(chan struct{}, maxConcurrentResponses),
+	}
+}
+
+func (s *Service) Start(ctx context.Context, eg *errgroup.Group) error {
+	log.WithField("spec", s.protocol.Settings).Info("spec settings")
+
+	err := s.protocol.CheckBeaconNodeSpec(s.client)
+	if err != nil {
+		return err
+	}
+
+	err = s.store.Connect()
+	if err != nil {
+		return fmt.Errorf("connect to datastore: %w", err)
+	}
+	if s.config.Source.Beacon.DataStore.EraDirectory != "" {
+		archive, err := era.NewArchive(s.config.Source.Beacon.DataStore.EraDirectory, s.protocol.SlotsPerHistoricalRoot)
+		if err != nil {
+			return fmt.Errorf("open era archive: %w", err)
+		}
+		s.states = era.NewStore(s.store, archive)
+		s.syncer.Client = era.NewAPI(s.client, archive, s.protocol)
+	}
+
+	metrics.Start(ctx, eg, s.config.Metrics)
+
+	mux := http.NewServeMux()
+	mux.Handle(statesPath, s)
+	server := &http.Server{
+		Addr:              s.config.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: shutdownTimeout,
+	}
+
+	eg.Go(func() error {
+		log.WithField("address", s.config.Listen).Info("Serving beacon states")
+		err := server.ListenAndServe()
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("serve beacon states: %w", err)
+	})
+	eg.Go(func() error {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	})
+
+	pollInterval := s.config.PollInterval
+	if pollInterval == 0 {
+		pollInterval = defaultPollInterval
+	}
+	ticker := time.NewTicker(time.Duration(pollInterval) * time.Second)
+
+	eg.Go(func() error {
+		defer ticker.Stop()
+		for {
+			err := s.prefetch()
+			if err != nil {
+				log.WithError(err).Warn("prefetch beacon states of finalized update")
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				continue
+			}
+		}
+	})
+
+	return nil
+}
+
+// prefetch stores the attested and finalized state pairs which the beacon relay looks for in the datastore, when the
+// states are not available from the beacon node anymore: the pair of the latest finalized update, for interim updates,
+// and the pair the syncer builds the sync committee update of the current period from.
+func (s *Service) prefetch() error {
+	update, err := s.client.GetLatestFinalizedUpdate()
+	if err != nil {
+		return fmt.Errorf("fetch latest finalized update: %w", err)
+	}
+	attestedSlot, err := util.ToUint64(update.Data.AttestedHeader.Beacon.Slot)
+	if err != nil {
+		return fmt.Errorf("parse attested slot: %w", err)
+	}
+	finalizedSlot, err := util.ToUint64(update.Data.FinalizedHeader.Beacon.Slot)
+	if err != nil {
+		return fmt.Errorf("parse finalized slot: %w", err)
+	}
+
+	err = s.storePair(attestedSlot, finalizedSlot)
+	if err != nil {
+		return err
+	}
+
+	err = s.prefetchSyncCommitteeUpdate(finalizedSlot)
+	if err != nil {
+		return err
+	}
+
+	s.statesLock.Lock()
+	deletedSlots, err := s.store.PruneOldStates()
+	s.statesLock.Unlock()
+	if err != nil {
+		return fmt.Errorf("prune old beacon states: %w", err)
+	}
+	if len(deletedSlots) > 0 {
+		log.WithField("deletedSlots", deletedSlots).Info("deleted old beacon states")
+	}
+
+	return nil
+}
+
+// prefetchSyncCommitteeUpdate stores the states of the update which the syncer builds for the sync committee period of
+// the finalized slot, once the update can be found among the finalized blocks.
+func (s *Service) prefetchSyncCommitteeUpdate(finalizedSlot uint64) error {
+	period := s.protocol.ComputeSyncPeriodAtSlot(finalizedSlot)
+	attestedSlot, err := s.syncer.FindSyncCommitteeUpdateAttestedSlot(period, finalizedSlot)
+	if err != nil || attestedSlot > finalizedSlot {
+		log.WithError(err).WithField("period", period).Debug("sync committee update of the period is not finalized yet")
+		return nil
+	}
+	stored, err := s.store.HasAttestedState(attestedSlot)
+	if err != nil {
+		return err
+	}
+	if stored {
+		return nil
+	}
+
+	attestedData, err := s.client.GetBeaconState(strconv.FormatUint(attestedSlot, 10))
+	if err != nil {
+		return fmt.Errorf("download attested beacon state at slot %d: %w", attestedSlot, err)
+	}
+	attestedState, err := s.syncer.UnmarshalBeaconState(attestedSlot, attestedData)
+	if err != nil {
+		return fmt.Errorf("unmarshal attested beacon state at slot %d: %w", attestedSlot, err)
+	}
+	// The finalized state is the one of the finalized checkpoint, like the syncer uses
+	finalizedHeader, err := s.client.GetHeaderByBlockRoot(common.BytesToHash(attestedState.GetFinalizedCheckpoint().Root))
+	if err != nil {
+		return fmt.Errorf("fetch finalized header of attested slot %d: %w", attestedSlot, err)
+	}
+
+	return s.writePair(attestedSlot, finalizedHeader.Slot, attestedData)
+}
+
+// storePair downloads and stores the attested and finalized states, unless a pair with the finalized slot is stored
+func (s *Service) storePair(attestedSlot, finalizedSlot uint64) error {
+	stored, err := s.store.HasFinalizedState(finalizedSlot)
+	if err != nil {
+		return err
+	}
+	if stored {
+		log.WithFields(log.Fields{"attestedSlot": attestedSlot, "finalizedSlot": finalizedSlot}).Debug("beacon states already stored")
+		return nil
+	}
+
+	attestedData, err := s.client.GetBeaconState(strconv.FormatUint(attestedSlot, 10))
+	if err != nil {
+		return fmt.Errorf("download attested beacon state at slot %d: %w", attestedSlot, err)
+	}
+
+	return s.writePair(attestedSlot, finalizedSlot, attestedData)
+}
+
+// writePair downloads the finalized state, and stores it with the attested state
+func (s *Service) writePair(attestedSlot, finalizedSlot uint64, attestedData []byte) error {
+	stored, err := s.store.HasFinalizedState(finalizedSlot)
+	if err != nil {
+		return err
+	}
+	if stored {
+		return nil
+	}
+
+	finalizedData, err := s.client.GetBeaconState(strconv.FormatUint(finalizedSlot, 10))
+	if err != nil {
+		return fmt.Errorf("download finalized beacon state at slot %d: %w", finalizedSlot, err)
+	}
+
+	s.statesLock.RLock()
+	err = s.store.WriteEntry(attestedSlot, finalizedSlot, attestedData, finalizedData)
+	s.statesLock.RUnlock()
+	if err != nil {
+		return fmt.Errorf("write beacon store entry: %w", err)
+	}
+	log.WithFields(log.Fields{"attestedSlot": attestedSlot, "finalizedSlot": finalizedSlot}).Info("stored beacon states")
+
+	return nil
+}
+
+// ServeHTTP serves SSZ encoded beacon states like the debug endpoint of the beacon API. States are served from the
+// datastore, and the other states are downloaded from the beacon node and cached. Only slots are supported as state
+// ids.
+func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	stateID := strings.TrimPrefix(r.URL.Path, statesPath)
+	slot, err := strconv.ParseUint(stateID, 10, 64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("state id %q is not a slot", stateID), http.StatusBadRequest)
+		return
+	}
+
+	select {
+	case s.responses <- struct{}{}:
+		defer func() { <-s.responses }()
+	case <-r.Context().Done():
+		return
+	}
+
+	state, err := s.openBeaconState(slot)
+	switch {
+	case errors.Is(err, api.ErrNotFound):
+		http.Error(w, fmt.Sprintf("beacon state at slot %d not found", slot), http.StatusNotFound)
+		return
+	case err != nil:
+		log.WithError(err).WithField("slot", slot).Warn("fetch beacon state from store and api failed")
+		http.Error(w, fmt.Sprintf("beacon state at slot %d is unavailable", slot), http.StatusBadGateway)
+		return
+	}
+
+	defer state.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Eth-Consensus-Version", s.protocol.ForkAtSlot(slot))
+	_, err = io.Copy(w, state)
+	if err != nil {
+		log.WithError(err).WithField("slot", slot).Debug("write beacon state response")
+	}
+}
+
+// openBeaconState opens the state at the slot in the store. On a store miss, the state is downloaded from the beacon
+// node into the cache of the store, with concurrent requests for the state sharing the download, and then opened there.
+// States are streamed rather than held in memory, since they are hundreds of megabytes on mainnet.
+func (s *Service) openBeaconState(slot uint64) (io.ReadCloser, error) {
+	s.statesLock.RLock()
+	state, storeErr := s.states.OpenBeaconState(slot)
+	s.statesLock.RUnlock()
+	if storeErr == nil {
+		return state, nil
+	}
+
+	stateID := strconv.FormatUint(slot, 10)
+	cached, err, _ := s.downloads.Do(stateID, func() (interface{}, error) {
+		return s.cacheBeaconState(slot, stateID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("store: %v, api: %w", storeErr, err)
+	}
+
+	if cached.(bool) {
+		s.statesLock.RLock()
+		state, err = s.store.OpenBeaconState(slot)
+		s.statesLock.RUnlock()
+		if err == nil {
+			return state, nil
+		}
+		log.WithError(err).WithField("slot", slot).Warn("open cached beacon state")
+	}
+
+	// The state could not be cached, so it is streamed from the beacon node
+	state, err = s.client.OpenBeaconState(stateID)
+	if err != nil {
+		return nil, fmt.Errorf("store: %v, api: %w", storeErr, err)
+	}
+	return state, nil
+}
+
+// cacheBeaconState downloads the state at the slot into the cache of the store, and returns whether it was cached. An
+// error is only returned if the state cannot be downloaded.
+func (s *Service) cacheBeaconState(slot uint64, stateID string) (bool, error) {
+	body, err := s.client.OpenBeaconState(stateID)
+	if err != nil {
+		return false, err
+	}
+	defer body.Close()
+
+	// The state is only visible in the store once it is written completely, so the download is not blocking pruning
+	err = s.store.WriteCachedState(slot, body)
+	if err != nil {
+		log.WithError(err).WithField("slot", slot).Warn("cache downloaded beacon state")
+		return false, nil
+	}
+	return true, nil
+}
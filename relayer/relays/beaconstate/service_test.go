@@ -0,0 +1,212 @@
+package beaconstate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/config"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer/api"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/mock"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/protocol"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/state"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/store"
+	"github.com/snowfork/snowbridge/relayer/relays/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T, client *mock.API) *Service {
+	p := protocol.New(config.SpecSettings{
+		SlotsInEpoch:                 32,
+		EpochsPerSyncCommitteePeriod: 256,
+		DenebForkEpoch:               0,
+	}, 0)
+	s := store.New(t.TempDir(), 2, *p)
+	require.NoError(t, s.Connect())
+	t.Cleanup(s.Close)
+
+	return &Service{
+		config:    &Config{},
+		client:    client,
+		protocol:  p,
+		store:     &s,
+		syncer:    syncer.New(client, &s, p),
+		states:    &s,
+		responses: make(chan struct{}, defaultMaxConcurrentResponses),
+	}
+}
+
+func TestPrefetch(t *testing.T) {
+	client := mock.API{
+		BeaconStates: map[uint64]bool{
+			4571072: true,
+			4571136: true,
+		},
+	}
+	client.LatestFinalisedUpdateResponse.Data.AttestedHeader.Beacon.Slot = "4571136"
+	client.LatestFinalisedUpdateResponse.Data.FinalizedHeader.Beacon.Slot = "4571072"
+	service := newTestService(t, &client)
+
+	err := service.prefetch()
+	require.NoError(t, err)
+
+	stored, err := service.store.FindBeaconStateWithinRange(4571072, 4571072)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4571136), stored.AttestedSlot)
+	attestedState, err := testutil.LoadFile("4571136.ssz")
+	require.NoError(t, err)
+	assert.Equal(t, attestedState, stored.AttestedBeaconState)
+
+	// The states of an update are only downloaded once
+	client.BeaconStates = nil
+	err = service.prefetch()
+	require.NoError(t, err)
+
+	// States which cannot be downloaded are not stored
+	client.LatestFinalisedUpdateResponse.Data.AttestedHeader.Beacon.Slot = "4644928"
+	client.LatestFinalisedUpdateResponse.Data.FinalizedHeader.Beacon.Slot = "4644864"
+	err = service.prefetch()
+	require.Error(t, err)
+	_, err = service.store.FindBeaconStateWithinRange(4644864, 4644864)
+	require.Error(t, err)
+}
+
+// Verifies that the pair the syncer builds the sync committee update of the period from is prefetched
+func TestPrefetchSyncCommitteeUpdate(t *testing.T) {
+	superMajority := api.BeaconBlockResponse{Data: api.BeaconBlockResponseData{Message: api.BeaconBlockResponseMessage{Body: api.BeaconBlockResponseBody{SyncAggregate: api.SyncAggregateResponse{
+		SyncCommitteeBits: "0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff00000000000000000000000000000000",
+	}}}}}
+	client := mock.API{
+		HeadersBySlot: map[uint64]api.BeaconHeader{
+			4570688: {Slot: 4570688},
+			4570752: {Slot: 4570752},
+			4570753: {Slot: 4570753},
+			4570816: {Slot: 4570816},
+			4570817: {Slot: 4570817},
+		},
+		BlocksAtSlot: map[uint64]api.BeaconBlockResponse{
+			4570753: superMajority,
+			4570817: superMajority,
+		},
+		BeaconStates: map[uint64]bool{
+			4570752: true,
+			4570816: true,
+			4571072: true,
+			4571136: true,
+		},
+	}
+	client.LatestFinalisedUpdateResponse.Data.AttestedHeader.Beacon.Slot = "4571136"
+	client.LatestFinalisedUpdateResponse.Data.FinalizedHeader.Beacon.Slot = "4571072"
+
+	data, err := testutil.LoadFile("4570816.ssz")
+	require.NoError(t, err)
+	attestedState := state.BeaconStateDenebMainnet{}
+	require.NoError(t, attestedState.UnmarshalSSZ(data))
+	client.Header = map[common.Hash]api.BeaconHeader{
+		common.BytesToHash(attestedState.FinalizedCheckpoint.Root): {Slot: 4570752},
+	}
+	service := newTestService(t, &client)
+
+	err = service.prefetch()
+	require.NoError(t, err)
+
+	// The first valid pair of the period is found from its start, and the syncer searches again from its attested slot
+	stored, err := service.store.FindBeaconStateWithinRange(4570752, 4570752)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4570816), stored.AttestedSlot)
+	assert.Equal(t, data, stored.AttestedBeaconState)
+	stored, err = service.store.FindBeaconStateWithinRange(4571072, 4571072)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4571136), stored.AttestedSlot)
+
+	// The pair is only downloaded once
+	client.BeaconStates = nil
+	err = service.prefetch()
+	require.NoError(t, err)
+}
+
+func TestServeBeaconState(t *testing.T) {
+	client := mock.API{
+		BeaconStates: map[uint64]bool{
+			4644864: true,
+		},
+	}
+	service := newTestService(t, &client)
+
+	attestedState, err := testutil.LoadFile("4571136.ssz")
+	require.NoError(t, err)
+	finalizedState, err := testutil.LoadFile("4571072.ssz")
+	require.NoError(t, err)
+	err = service.store.WriteEntry(4571136, 4571072, attestedState, finalizedState)
+	require.NoError(t, err)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		service.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+
+	// Stored states
+	response := get("/eth/v2/debug/beacon/states/4571136")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "application/octet-stream", response.Header().Get("Content-Type"))
+	assert.Equal(t, "deneb", response.Header().Get("Eth-Consensus-Version"))
+	assert.Equal(t, attestedState, response.Body.Bytes())
+
+	response = get("/eth/v2/debug/beacon/states/4571072")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, finalizedState, response.Body.Bytes())
+
+	// States which are not stored are downloaded from the beacon node
+	response = get("/eth/v2/debug/beacon/states/4644864")
+	require.Equal(t, http.StatusOK, response.Code)
+	downloadedState, err := testutil.LoadFile("4644864.ssz")
+	require.NoError(t, err)
+	assert.Equal(t, downloadedState, response.Body.Bytes())
+
+	// Downloaded states are cached
+	client.BeaconStates = nil
+	response = get("/eth/v2/debug/beacon/states/4644864")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, downloadedState, response.Body.Bytes())
+
+	response = get("/eth/v2/debug/beacon/states/4644865")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = get("/eth/v2/debug/beacon/states/finalized")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	recorder := httptest.NewRecorder()
+	service.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/eth/v2/debug/beacon/states/4571136", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
+
+func TestServeBeaconStateLimit(t *testing.T) {
+	service := newTestService(t, &mock.API{})
+	attestedState, err := testutil.LoadFile("4571136.ssz")
+	require.NoError(t, err)
+	finalizedState, err := testutil.LoadFile("4571072.ssz")
+	require.NoError(t, err)
+	err = service.store.WriteEntry(4571136, 4571072, attestedState, finalizedState)
+	require.NoError(t, err)
+
+	// Requests wait while the maximum number of states are served
+	for i := 0; i < cap(service.responses); i++ {
+		service.responses <- struct{}{}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	recorder := httptest.NewRecorder()
+	service.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/eth/v2/debug/beacon/states/4571136", nil).WithContext(ctx))
+	assert.Empty(t, recorder.Body.Bytes())
+
+	<-service.responses
+	recorder = httptest.NewRecorder()
+	service.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/eth/v2/debug/beacon/states/4571136", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, attestedState, recorder.Body.Bytes())
+}
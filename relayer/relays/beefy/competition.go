@@ -0,0 +1,216 @@
+package beefy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrCommitmentObsolete = errors.New("commitment made obsolete by competing relayer")
+
+// competitionWatcher follows BeefyClient events while a commitment is being submitted, and cancels
+// the submission once a competing relayer has imported a commitment at or beyond our own.
+type competitionWatcher struct {
+	writer      *EthereumWriter
+	address     common.Address
+	blockNumber uint64
+	cancel      context.CancelFunc
+	done        chan struct{}
+
+	mu          sync.Mutex
+	obsoletedBy uint64
+	obsoleteTx  common.Hash
+	// Transactions sent for our own submission, whose events are not competing updates
+	txs []*types.Transaction
+}
+
+// watchCompetition starts watching for competing updates to the BeefyClient. The returned context
+// is cancelled when the commitment for blockNumber becomes obsolete.
+func (wr *EthereumWriter) watchCompetition(ctx context.Context, blockNumber uint64) (context.Context, *competitionWatcher) {
+	ctx, cancel := context.WithCancel(ctx)
+	watcher := competitionWatcher{
+		writer:      wr,
+		address:     wr.conn.Keypair().CommonAddress(),
+		blockNumber: blockNumber,
+		cancel:      cancel,
+		done:        make(chan struct{}),
+	}
+
+	go func() {
+		defer close(watcher.done)
+		err := watcher.run(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.WithError(err).Warn("Stopped watching for competing relayers")
+		}
+	}()
+
+	return ctx, &watcher
+}
+
+func (w *competitionWatcher) run(ctx context.Context) error {
+	headers := make(chan *types.Header, 5)
+
+	sub, err := w.writer.conn.Client().SubscribeNewHead(ctx, headers)
+	if err != nil {
+		return fmt.Errorf("creating ethereum header subscription: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-sub.Err():
+			return fmt.Errorf("header subscription: %w", err)
+		case header := <-headers:
+			blockNumber := header.Number.Uint64()
+			err := w.checkBlock(ctx, blockNumber)
+			if err != nil {
+				return fmt.Errorf("query BeefyClient events in block %v: %w", blockNumber, err)
+			}
+		}
+	}
+}
+
+func (w *competitionWatcher) checkBlock(ctx context.Context, blockNumber uint64) error {
+	filterOpts := bind.FilterOpts{Start: blockNumber, End: &blockNumber, Context: ctx}
+
+	tickets, err := w.writer.contract.FilterNewTicket(&filterOpts)
+	if err != nil {
+		return err
+	}
+	for tickets.Next() {
+		ticket := tickets.Event
+		if ticket.Relayer == w.address || ticket.BlockNumber < w.blockNumber {
+			continue
+		}
+		log.WithFields(log.Fields{
+			"relayer":             ticket.Relayer.Hex(),
+			"beefyBlockNumber":    ticket.BlockNumber,
+			"ourBeefyBlockNumber": w.blockNumber,
+			"ethereumTxHash":      ticket.Raw.TxHash.Hex(),
+		}).Warn("Witnessed a competing ticket")
+	}
+	if tickets.Error() != nil {
+		return tickets.Error()
+	}
+
+	roots, err := w.writer.contract.FilterNewMMRRoot(&filterOpts)
+	if err != nil {
+		return err
+	}
+	for roots.Next() {
+		root := roots.Event
+		// Our own submitFinal can be seen here before the submission has finished watching it
+		if w.isOwnTransaction(root.Raw.TxHash) {
+			continue
+		}
+		if root.BlockNumber >= w.blockNumber {
+			w.markObsolete(root.BlockNumber, root.Raw.TxHash)
+		}
+	}
+	return roots.Error()
+}
+
+// checkLatest queries the BeefyClient directly, covering events missed by the subscription
+func (w *competitionWatcher) checkLatest(ctx context.Context) error {
+	latestBeefyBlock, err := w.writer.contract.LatestBeefyBlock(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return fmt.Errorf("fetch latest beefy block: %w", err)
+	}
+	if latestBeefyBlock >= w.blockNumber {
+		w.markObsolete(latestBeefyBlock, common.Hash{})
+	}
+	if w.obsolete() {
+		return ErrCommitmentObsolete
+	}
+	return nil
+}
+
+func (w *competitionWatcher) markObsolete(blockNumber uint64, txHash common.Hash) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if blockNumber <= w.obsoletedBy {
+		return
+	}
+	w.obsoletedBy = blockNumber
+	w.obsoleteTx = txHash
+
+	log.WithFields(log.Fields{
+		"beefyBlockNumber":    blockNumber,
+		"ourBeefyBlockNumber": w.blockNumber,
+		"ethereumTxHash":      txHash.Hex(),
+	}).Warn("Competing relayer imported a newer commitment, aborting submission")
+	w.cancel()
+}
+
+// track records a transaction sent for our own submission
+func (w *competitionWatcher) track(tx *types.Transaction) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.txs = append(w.txs, tx)
+}
+
+func (w *competitionWatcher) isOwnTransaction(txHash common.Hash) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, tx := range w.txs {
+		if tx.Hash() == txHash {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *competitionWatcher) transactions() []*types.Transaction {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]*types.Transaction{}, w.txs...)
+}
+
+func (w *competitionWatcher) obsolete() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.obsoletedBy != 0
+}
+
+func (w *competitionWatcher) stop() {
+	w.cancel()
+	<-w.done
+}
+
+// reportWastedGas logs the gas spent on transactions of an aborted submission. Transactions
+// which are still pending are listed separately, since they may yet be mined and revert.
+func (wr *EthereumWriter) reportWastedGas(ctx context.Context, blockNumber uint64, txs []*types.Transaction) {
+	gasUsed := uint64(0)
+	cost := new(big.Int)
+	pending := []string{}
+
+	for _, tx := range txs {
+		receipt, err := wr.conn.Client().TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			pending = append(pending, tx.Hash().Hex())
+			continue
+		}
+		gasUsed += receipt.GasUsed
+		if receipt.EffectiveGasPrice != nil {
+			cost.Add(cost, new(big.Int).Mul(receipt.EffectiveGasPrice, new(big.Int).SetUint64(receipt.GasUsed)))
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"beefyBlockNumber": blockNumber,
+		"transactions":     len(txs),
+		"gasUsed":          gasUsed,
+		"wastedWei":        cost.String(),
+		"pendingTxs":       pending,
+	}).Warn("Gas wasted on obsolete commitment")
+}
@@ -0,0 +1,90 @@
+package beefy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/snowfork/snowbridge/relayer/contracts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logBackend serves the given logs to contract event filters
+type logBackend struct {
+	bind.ContractBackend
+	logs []types.Log
+}
+
+func (b *logBackend) FilterLogs(_ context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	logs := []types.Log{}
+	for _, log := range b.logs {
+		if len(query.Topics) > 0 && len(query.Topics[0]) > 0 && log.Topics[0] != query.Topics[0][0] {
+			continue
+		}
+		logs = append(logs, log)
+	}
+	return logs, nil
+}
+
+func newMMRRootLog(t *testing.T, blockNumber uint64, txHash common.Hash) types.Log {
+	contractABI, err := contracts.BeefyClientMetaData.GetAbi()
+	require.NoError(t, err)
+	event := contractABI.Events["NewMMRRoot"]
+	data, err := event.Inputs.Pack([32]byte{1}, blockNumber)
+	require.NoError(t, err)
+	return types.Log{Topics: []common.Hash{event.ID}, Data: data, TxHash: txHash}
+}
+
+func TestCompetitionWatcherMarkObsolete(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	watcher := competitionWatcher{
+		blockNumber: 100,
+		cancel:      cancel,
+	}
+
+	assert.False(t, watcher.obsolete())
+
+	watcher.markObsolete(100, common.Hash{1})
+	assert.True(t, watcher.obsolete())
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+
+	// An older update does not replace the recorded competing update
+	watcher.markObsolete(99, common.Hash{2})
+	assert.Equal(t, uint64(100), watcher.obsoletedBy)
+	assert.Equal(t, common.Hash{1}, watcher.obsoleteTx)
+}
+
+func TestCompetitionWatcherCheckBlockIgnoresOwnRoot(t *testing.T) {
+	ownTx := types.NewTx(&types.LegacyTx{Nonce: 1})
+	backend := logBackend{logs: []types.Log{newMMRRootLog(t, 100, ownTx.Hash())}}
+	contract, err := contracts.NewBeefyClient(common.Address{}, &backend)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	watcher := competitionWatcher{
+		writer:      &EthereumWriter{contract: contract},
+		blockNumber: 100,
+		cancel:      cancel,
+	}
+	watcher.track(ownTx)
+
+	// Our own submitFinal does not make the commitment obsolete
+	err = watcher.checkBlock(ctx, 10)
+	require.NoError(t, err)
+	assert.False(t, watcher.obsolete())
+	assert.NoError(t, ctx.Err())
+
+	// A root imported by a competing relayer does
+	competingTx := common.Hash{2}
+	backend.logs = append(backend.logs, newMMRRootLog(t, 101, competingTx))
+	err = watcher.checkBlock(ctx, 10)
+	require.NoError(t, err)
+	assert.True(t, watcher.obsolete())
+	assert.Equal(t, uint64(101), watcher.obsoletedBy)
+	assert.Equal(t, competingTx, watcher.obsoleteTx)
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+}
@@ -8,6 +8,8 @@ import (
 type Config struct {
 	Source SourceConfig `mapstructure:"source"`
 	Sink   SinkConfig   `mapstructure:"sink"`
+	// Additional sinks which receive the same commitments, e.g. other EVM chains or BeefyClient deployments
+	Sinks []SinkConfig `mapstructure:"sinks"`
 }
 
 type SourceConfig struct {
@@ -19,29 +21,92 @@ type SourceConfig struct {
 }
 
 type SinkConfig struct {
+	// Name of the sink used in logs
+	Name                  string                `mapstructure:"name"`
 	Ethereum              config.EthereumConfig `mapstructure:"ethereum"`
 	DescendantsUntilFinal uint64                `mapstructure:"descendants-until-final"`
 	Contracts             ContractsConfig       `mapstructure:"contracts"`
+	// Key used to sign transactions for this sink. The key passed on the command line is used if unset.
+	PrivateKeyFile string `mapstructure:"private-key-file"`
+	PrivateKeyID   string `mapstructure:"private-key-id"`
 }
 
 type ContractsConfig struct {
 	BeefyClient string `mapstructure:"BeefyClient"`
 }
 
+// AllSinks returns the primary sink followed by any additional sinks, with default names filled in.
+func (c Config) AllSinks() []SinkConfig {
+	sinks := make([]SinkConfig, 0, len(c.Sinks)+1)
+	sinks = append(sinks, c.Sink)
+	if sinks[0].Name == "" {
+		sinks[0].Name = "sink"
+	}
+	for i, sink := range c.Sinks {
+		if sink.Name == "" {
+			sink.Name = fmt.Sprintf("sinks[%d]", i)
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks
+}
+
 func (c Config) Validate() error {
 	err := c.Source.Polkadot.Validate()
 	if err != nil {
 		return fmt.Errorf("source polkadot config: %w", err)
 	}
-	err = c.Sink.Ethereum.Validate()
+	names := make(map[string]bool)
+	for _, sink := range c.AllSinks() {
+		if names[sink.Name] {
+			return fmt.Errorf("sink name [%s] is not unique", sink.Name)
+		}
+		names[sink.Name] = true
+
+		err = sink.Validate()
+		if err != nil {
+			return fmt.Errorf("sink %s: %w", sink.Name, err)
+		}
+	}
+	return nil
+}
+
+func (s SinkConfig) Validate() error {
+	err := s.Ethereum.Validate()
+	if err != nil {
+		return fmt.Errorf("ethereum config: %w", err)
+	}
+	if s.DescendantsUntilFinal == 0 {
+		return fmt.Errorf("setting [descendants-until-final] is not set")
+	}
+	if s.Contracts.BeefyClient == "" {
+		return fmt.Errorf("contracts setting [BeefyClient] is not set")
+	}
+	return nil
+}
+
+type MonitorConfig struct {
+	Polkadot  config.PolkadotConfig `mapstructure:"polkadot"`
+	Ethereum  config.EthereumConfig `mapstructure:"ethereum"`
+	Contracts ContractsConfig       `mapstructure:"contracts"`
+	// Directory in which evidence of invalid or conflicting commitments is written
+	EvidenceDir string `mapstructure:"evidence-dir"`
+}
+
+func (c MonitorConfig) Validate() error {
+	err := c.Polkadot.Validate()
+	if err != nil {
+		return fmt.Errorf("polkadot config: %w", err)
+	}
+	err = c.Ethereum.Validate()
 	if err != nil {
-		return fmt.Errorf("sink ethereum config: %w", err)
+		return fmt.Errorf("ethereum config: %w", err)
 	}
-	if c.Sink.DescendantsUntilFinal == 0 {
-		return fmt.Errorf("sink ethereum setting [descendants-until-final] is not set")
+	if c.Contracts.BeefyClient == "" {
+		return fmt.Errorf("contracts setting [BeefyClient] is not set")
 	}
-	if c.Sink.Contracts.BeefyClient == "" {
-		return fmt.Errorf("sink contracts setting [BeefyClient] is not set")
+	if c.EvidenceDir == "" {
+		return fmt.Errorf("setting [evidence-dir] is not set")
 	}
 	return nil
 }
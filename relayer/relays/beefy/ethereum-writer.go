@@ -3,9 +3,11 @@ package beefy
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
 	"math/rand"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
@@ -39,6 +41,12 @@ func NewEthereumWriter(
 	}
 }
 
+const (
+	// Attempts at submitting a request before the relay stops
+	maxSubmitAttempts = 3
+	submitRetryDelay  = 30 * time.Second
+)
+
 func (wr *EthereumWriter) Start(ctx context.Context, eg *errgroup.Group, requests <-chan Request) error {
 	// launch task processor
 	eg.Go(func() error {
@@ -51,26 +59,27 @@ func (wr *EthereumWriter) Start(ctx context.Context, eg *errgroup.Group, request
 					return nil
 				}
 
-				state, err := wr.queryBeefyClientState(ctx)
-				if err != nil {
-					return fmt.Errorf("query beefy client state: %w", err)
-				}
-
-				if task.SignedCommitment.Commitment.BlockNumber < uint32(state.LatestBeefyBlock) {
-					log.WithFields(logrus.Fields{
+				// A failing sink is retried on its own, so that a transient failure does not stop the other sinks.
+				// The commitment is never skipped, since it may be the only handover to its validator set: once the
+				// attempts are exhausted the relay stops, and is restarted from the on-chain state of the sinks.
+				for attempt := 1; ; attempt++ {
+					err := wr.process(ctx, task)
+					if err == nil || ctx.Err() != nil {
+						break
+					}
+					if attempt == maxSubmitAttempts {
+						return fmt.Errorf("submit request at beefy block %d: %w", task.SignedCommitment.Commitment.BlockNumber, err)
+					}
+					log.WithError(err).WithFields(logrus.Fields{
+						"sink":             wr.config.Name,
 						"beefyBlockNumber": task.SignedCommitment.Commitment.BlockNumber,
-						"latestBeefyBlock": state.LatestBeefyBlock,
-					}).Info("Commitment already synced")
-					continue
-				}
-
-				// Mandatory commitments are always signed by the next validator set recorded in
-				// the beefy light client
-				task.ValidatorsRoot = state.NextValidatorSetRoot
-
-				err = wr.submit(ctx, task)
-				if err != nil {
-					return fmt.Errorf("submit request: %w", err)
+						"attempt":          attempt,
+					}).Error("Failed to submit request to sink")
+					select {
+					case <-ctx.Done():
+						return nil
+					case <-time.After(submitRetryDelay):
+					}
 				}
 			}
 		}
@@ -79,6 +88,40 @@ func (wr *EthereumWriter) Start(ctx context.Context, eg *errgroup.Group, request
 	return nil
 }
 
+// process submits the commitment of a request, unless the BeefyClient has already synced past it
+func (wr *EthereumWriter) process(ctx context.Context, task Request) error {
+	state, err := wr.queryBeefyClientState(ctx)
+	if err != nil {
+		return fmt.Errorf("query beefy client state: %w", err)
+	}
+
+	if task.SignedCommitment.Commitment.BlockNumber < uint32(state.LatestBeefyBlock) {
+		log.WithFields(logrus.Fields{
+			"sink":             wr.config.Name,
+			"beefyBlockNumber": task.SignedCommitment.Commitment.BlockNumber,
+			"latestBeefyBlock": state.LatestBeefyBlock,
+		}).Info("Commitment already synced")
+		return nil
+	}
+
+	// Mandatory commitments are always signed by the next validator set recorded in
+	// the beefy light client
+	task.ValidatorsRoot = state.NextValidatorSetRoot
+
+	err = wr.submit(ctx, task)
+	if errors.Is(err, ErrCommitmentObsolete) {
+		log.WithFields(logrus.Fields{
+			"sink":             wr.config.Name,
+			"beefyBlockNumber": task.SignedCommitment.Commitment.BlockNumber,
+		}).Info("Commitment synced by competing relayer")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("submit request to sink %s: %w", wr.config.Name, err)
+	}
+	return nil
+}
+
 type BeefyClientState struct {
 	LatestBeefyBlock        uint64
 	CurrentValidatorSetID   uint64
@@ -116,12 +159,29 @@ func (wr *EthereumWriter) queryBeefyClientState(ctx context.Context) (*BeefyClie
 }
 
 func (wr *EthereumWriter) submit(ctx context.Context, task Request) error {
+	blockNumber := uint64(task.SignedCommitment.Commitment.BlockNumber)
+
+	// Abort the submission as soon as a competing relayer makes the commitment obsolete
+	watchCtx, watcher := wr.watchCompetition(ctx, blockNumber)
+	defer watcher.stop()
+
+	err := wr.doSubmit(watchCtx, watcher, &task)
+	if err != nil && watcher.obsolete() {
+		wr.reportWastedGas(ctx, blockNumber, watcher.transactions())
+		return fmt.Errorf("submit commitment for block %d: %w", blockNumber, ErrCommitmentObsolete)
+	}
+
+	return err
+}
+
+func (wr *EthereumWriter) doSubmit(ctx context.Context, watcher *competitionWatcher, task *Request) error {
 	// Initial submission
-	tx, initialBitfield, err := wr.doSubmitInitial(ctx, &task)
+	tx, initialBitfield, err := wr.doSubmitInitial(ctx, task)
 	if err != nil {
 		log.WithError(err).Error("Failed to send initial signature commitment")
 		return err
 	}
+	watcher.track(tx)
 
 	// Wait RandaoCommitDelay before submit CommitPrevRandao to prevent attacker from manipulating committee memberships
 	// Details in https://eth2book.info/altair/part3/config/preset/#max_seed_lookahead
@@ -136,12 +196,21 @@ func (wr *EthereumWriter) submit(ctx context.Context, task Request) error {
 		return fmt.Errorf("generate commitment hash: %w", err)
 	}
 
+	err = watcher.checkLatest(ctx)
+	if err != nil {
+		return err
+	}
+
 	// Commit PrevRandao which will be used as seed to randomly select subset of validators
 	// https://github.com/Snowfork/snowbridge/blob/75a475cbf8fc8e13577ad6b773ac452b2bf82fbb/contracts/contracts/BeefyClient.sol#L446-L447
 	tx, err = wr.contract.CommitPrevRandao(
 		wr.conn.MakeTxOpts(ctx),
 		*commitmentHash,
 	)
+	if err != nil {
+		return fmt.Errorf("commit prev randao: %w", ethereum.DecodeRevertError(err))
+	}
+	watcher.track(tx)
 
 	_, err = wr.conn.WatchTransaction(ctx, tx, 1)
 	if err != nil {
@@ -149,12 +218,18 @@ func (wr *EthereumWriter) submit(ctx context.Context, task Request) error {
 		return err
 	}
 
+	err = watcher.checkLatest(ctx)
+	if err != nil {
+		return err
+	}
+
 	// Final submission
-	tx, err = wr.doSubmitFinal(ctx, *commitmentHash, initialBitfield, &task)
+	tx, err = wr.doSubmitFinal(ctx, *commitmentHash, initialBitfield, task)
 	if err != nil {
 		log.WithError(err).Error("Failed to send final signature commitment")
 		return err
 	}
+	watcher.track(tx)
 
 	_, err = wr.conn.WatchTransaction(ctx, tx, 0)
 	if err != nil {
@@ -168,7 +243,6 @@ func (wr *EthereumWriter) submit(ctx context.Context, task Request) error {
 	}).Debug("Transaction SubmitFinal succeeded")
 
 	return nil
-
 }
 
 func (wr *EthereumWriter) doSubmitInitial(ctx context.Context, task *Request) (*types.Transaction, []*big.Int, error) {
@@ -214,7 +288,7 @@ func (wr *EthereumWriter) doSubmitInitial(ctx context.Context, task *Request) (*
 		msg.Proof,
 	)
 	if err != nil {
-		return nil, nil, fmt.Errorf("initial submit: %w", err)
+		return nil, nil, fmt.Errorf("initial submit: %w", ethereum.DecodeRevertError(err))
 	}
 
 	commitmentHash, err := task.CommitmentHash()
@@ -269,7 +343,7 @@ func (wr *EthereumWriter) doSubmitFinal(ctx context.Context, commitmentHash [32]
 		params.LeafProofOrder,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("final submission: %w", err)
+		return nil, fmt.Errorf("final submission: %w", ethereum.DecodeRevertError(err))
 	}
 
 	log.WithField("txHash", tx.Hash().Hex()).
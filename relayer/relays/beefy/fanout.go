@@ -0,0 +1,111 @@
+package beefy
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+)
+
+// Requests queued for a sink which is not keeping up. Requests which are superseded by a newer commitment are
+// dropped, and once the queue is full no more requests are accepted until the sink catches up. A queued commitment may
+// be the only handover to its validator set, so it is never dropped for lack of space.
+const maxQueuedRequests = 16
+
+// fanOut forwards every request to each of count outputs. Each output is backed by its own queue
+// so that a sink which is busy submitting does not hold back the others.
+func fanOut(ctx context.Context, eg *errgroup.Group, requests <-chan Request, count int) []<-chan Request {
+	inputs := make([]chan Request, count)
+	outputs := make([]<-chan Request, count)
+	for i := range inputs {
+		inputs[i] = make(chan Request)
+		output := make(chan Request)
+		outputs[i] = output
+
+		input := inputs[i]
+		eg.Go(func() error {
+			queueRequests(ctx, input, output)
+			return nil
+		})
+	}
+
+	eg.Go(func() error {
+		defer func() {
+			for _, input := range inputs {
+				close(input)
+			}
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case request, ok := <-requests:
+				if !ok {
+					return nil
+				}
+				for _, input := range inputs {
+					select {
+					case <-ctx.Done():
+						return nil
+					case input <- request:
+					}
+				}
+			}
+		}
+	})
+
+	return outputs
+}
+
+// queueRequests buffers requests from in until they can be delivered to out, preserving their order.
+// The output is closed once the input is closed and all queued requests have been delivered.
+func queueRequests(ctx context.Context, in <-chan Request, out chan<- Request) {
+	defer close(out)
+
+	var queue []Request
+	for {
+		var next chan<- Request
+		var head Request
+		if len(queue) > 0 {
+			next = out
+			head = queue[0]
+		} else if in == nil {
+			return
+		}
+		// Apply backpressure while the queue is full
+		input := in
+		if len(queue) >= maxQueuedRequests {
+			input = nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case request, ok := <-input:
+			if !ok {
+				in = nil
+				continue
+			}
+			queue = enqueueRequest(queue, request)
+		case next <- head:
+			queue = queue[1:]
+		}
+	}
+}
+
+// enqueueRequest appends a request to the queue. A queued commitment is superseded by a newer commitment signed
+// by the same validator set, since the BeefyClient accepts the newer commitment in its place.
+func enqueueRequest(queue []Request, request Request) []Request {
+	kept := queue[:0]
+	for _, queued := range queue {
+		if queued.SignedCommitment.Commitment.ValidatorSetID == request.SignedCommitment.Commitment.ValidatorSetID {
+			log.WithFields(log.Fields{
+				"beefyBlockNumber":        queued.SignedCommitment.Commitment.BlockNumber,
+				"supersededByBlockNumber": request.SignedCommitment.Commitment.BlockNumber,
+			}).Debug("Dropping superseded commitment")
+			continue
+		}
+		kept = append(kept, queued)
+	}
+	return append(kept, request)
+}
@@ -0,0 +1,93 @@
+package beefy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sync/errgroup"
+)
+
+func TestFanOut(t *testing.T) {
+	eg, ctx := errgroup.WithContext(context.Background())
+
+	requests := make(chan Request)
+	outputs := fanOut(ctx, eg, requests, 2)
+
+	// Requests are queued for every output, even if none of them are being consumed yet
+	for i := uint32(1); i <= 3; i++ {
+		request := Request{}
+		request.SignedCommitment.Commitment = types.Commitment{BlockNumber: i, ValidatorSetID: uint64(i)}
+		requests <- request
+	}
+	close(requests)
+
+	for _, output := range outputs {
+		var blockNumbers []uint32
+		for request := range output {
+			blockNumbers = append(blockNumbers, request.SignedCommitment.Commitment.BlockNumber)
+		}
+		assert.Equal(t, []uint32{1, 2, 3}, blockNumbers)
+	}
+
+	assert.NoError(t, eg.Wait())
+}
+
+func newRequest(blockNumber uint32, validatorSetID uint64) Request {
+	request := Request{}
+	request.SignedCommitment.Commitment = types.Commitment{BlockNumber: blockNumber, ValidatorSetID: validatorSetID}
+	return request
+}
+
+func blockNumbers(queue []Request) []uint32 {
+	numbers := []uint32{}
+	for _, request := range queue {
+		numbers = append(numbers, request.SignedCommitment.Commitment.BlockNumber)
+	}
+	return numbers
+}
+
+func TestEnqueueRequestDropsSupersededCommitments(t *testing.T) {
+	var queue []Request
+	queue = enqueueRequest(queue, newRequest(10, 1))
+	queue = enqueueRequest(queue, newRequest(20, 2))
+	queue = enqueueRequest(queue, newRequest(30, 2))
+	queue = enqueueRequest(queue, newRequest(40, 3))
+
+	// The commitment at block 20 is superseded by the one at block 30, which is signed by the same validator set
+	assert.Equal(t, []uint32{10, 30, 40}, blockNumbers(queue))
+}
+
+func TestQueueRequestsAppliesBackpressure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan Request)
+	out := make(chan Request)
+	go queueRequests(ctx, in, out)
+
+	for i := uint32(1); i <= maxQueuedRequests; i++ {
+		in <- newRequest(i, uint64(i))
+	}
+
+	// A full queue accepts no more requests until the sink takes one
+	select {
+	case in <- newRequest(maxQueuedRequests+1, maxQueuedRequests+1):
+		t.Fatal("request accepted by a full queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Equal(t, uint32(1), (<-out).SignedCommitment.Commitment.BlockNumber)
+	in <- newRequest(maxQueuedRequests+1, maxQueuedRequests+1)
+	close(in)
+
+	// No commitment is dropped
+	var numbers []uint32
+	for request := range out {
+		numbers = append(numbers, request.SignedCommitment.Commitment.BlockNumber)
+	}
+	assert.Len(t, numbers, maxQueuedRequests)
+	assert.Equal(t, uint32(2), numbers[0])
+	assert.Equal(t, uint32(maxQueuedRequests+1), numbers[len(numbers)-1])
+}
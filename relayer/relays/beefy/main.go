@@ -2,6 +2,7 @@ package beefy
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -20,27 +21,43 @@ type Relay struct {
 	ethereumConn     *ethereum.Connection
 	polkadotListener *PolkadotListener
 	ethereumWriter   *EthereumWriter
+	// Writers for all sinks, starting with the primary sink
+	ethereumWriters []*EthereumWriter
 }
 
 func NewRelay(config *Config, ethereumKeypair *secp256k1.Keypair) (*Relay, error) {
 	relaychainConn := relaychain.NewConnection(config.Source.Polkadot.Endpoint)
-	ethereumConn := ethereum.NewConnection(&config.Sink.Ethereum, ethereumKeypair)
 
 	polkadotListener := NewPolkadotListener(
 		&config.Source,
 		relaychainConn,
 	)
 
-	ethereumWriter := NewEthereumWriter(&config.Sink, ethereumConn)
+	var ethereumWriters []*EthereumWriter
+	for _, sink := range config.AllSinks() {
+		sink := sink
+		keypair := ethereumKeypair
+		if sink.PrivateKeyFile != "" || sink.PrivateKeyID != "" {
+			var err error
+			keypair, err = ethereum.ResolvePrivateKey("", sink.PrivateKeyFile, sink.PrivateKeyID)
+			if err != nil {
+				return nil, fmt.Errorf("resolve private key for sink %s: %w", sink.Name, err)
+			}
+		}
 
-	log.Info("Beefy relay created")
+		ethereumConn := ethereum.NewConnection(&sink.Ethereum, keypair)
+		ethereumWriters = append(ethereumWriters, NewEthereumWriter(&sink, ethereumConn))
+	}
+
+	log.WithField("sinks", len(ethereumWriters)).Info("Beefy relay created")
 
 	return &Relay{
 		config:           config,
 		relaychainConn:   relaychainConn,
-		ethereumConn:     ethereumConn,
+		ethereumConn:     ethereumWriters[0].conn,
 		polkadotListener: polkadotListener,
-		ethereumWriter:   ethereumWriter,
+		ethereumWriter:   ethereumWriters[0],
+		ethereumWriters:  ethereumWriters,
 	}, nil
 }
 
@@ -50,32 +67,52 @@ func (relay *Relay) Start(ctx context.Context, eg *errgroup.Group) error {
 		return fmt.Errorf("create relaychain connection: %w", err)
 	}
 
-	err = relay.ethereumConn.Connect(ctx)
-	if err != nil {
-		return fmt.Errorf("create ethereum connection: %w", err)
-	}
-	err = relay.ethereumWriter.initialize(ctx)
-	if err != nil {
-		return fmt.Errorf("initialize ethereum writer: %w", err)
-	}
+	// Scan from the sink which is furthest behind, the others skip commitments they already have
+	var initialState *BeefyClientState
+	for _, writer := range relay.ethereumWriters {
+		err = writer.conn.Connect(ctx)
+		if err != nil {
+			return fmt.Errorf("create ethereum connection for sink %s: %w", writer.config.Name, err)
+		}
+		err = writer.initialize(ctx)
+		if err != nil {
+			return fmt.Errorf("initialize ethereum writer for sink %s: %w", writer.config.Name, err)
+		}
+
+		state, err := writer.queryBeefyClientState(ctx)
+		if err != nil {
+			return fmt.Errorf("fetch BeefyClient current state for sink %s: %w", writer.config.Name, err)
+		}
+		log.WithFields(log.Fields{
+			"sink":           writer.config.Name,
+			"beefyBlock":     state.LatestBeefyBlock,
+			"validatorSetID": state.CurrentValidatorSetID,
+		}).Info("Retrieved current BeefyClient state")
 
-	initialState, err := relay.ethereumWriter.queryBeefyClientState(ctx)
-	if err != nil {
-		return fmt.Errorf("fetch BeefyClient current state: %w", err)
+		if initialState == nil || state.LatestBeefyBlock < initialState.LatestBeefyBlock {
+			initialState = state
+		}
 	}
-	log.WithFields(log.Fields{
-		"beefyBlock":     initialState.LatestBeefyBlock,
-		"validatorSetID": initialState.CurrentValidatorSetID,
-	}).Info("Retrieved current BeefyClient state")
 
 	requests, err := relay.polkadotListener.Start(ctx, eg, initialState.LatestBeefyBlock, initialState.CurrentValidatorSetID)
 	if err != nil {
 		return fmt.Errorf("initialize polkadot listener: %w", err)
 	}
 
-	err = relay.ethereumWriter.Start(ctx, eg, requests)
-	if err != nil {
-		return fmt.Errorf("start ethereum writer: %w", err)
+	if len(relay.ethereumWriters) == 1 {
+		err = relay.ethereumWriter.Start(ctx, eg, requests)
+		if err != nil {
+			return fmt.Errorf("start ethereum writer: %w", err)
+		}
+		return nil
+	}
+
+	outputs := fanOut(ctx, eg, requests, len(relay.ethereumWriters))
+	for i, writer := range relay.ethereumWriters {
+		err = writer.Start(ctx, eg, outputs[i])
+		if err != nil {
+			return fmt.Errorf("start ethereum writer for sink %s: %w", writer.config.Name, err)
+		}
 	}
 
 	return nil
@@ -145,6 +182,10 @@ func (relay *Relay) OneShotSync(ctx context.Context, blockNumber uint64) error {
 		task.ValidatorsRoot = state.NextValidatorSetRoot
 	}
 	err = relay.ethereumWriter.submit(ctx, task)
+	if errors.Is(err, ErrCommitmentObsolete) {
+		log.WithField("relayBlock", blockNumber).Info("Commitment synced by competing relayer, just ignore")
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("fail to submit beefy update: %w", err)
 	}
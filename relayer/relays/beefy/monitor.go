@@ -0,0 +1,460 @@
+package beefy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/snowfork/snowbridge/relayer/chain/ethereum"
+	"github.com/snowfork/snowbridge/relayer/chain/relaychain"
+	"github.com/snowfork/snowbridge/relayer/contracts"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// The MMR root accepted by the BeefyClient differs from the relay chain's MMR root at that block
+	EvidenceInvalidMMRRoot = "InvalidMMRRoot"
+	// A commitment signed by the validator set conflicts with the relay chain's MMR root at its block
+	EvidenceConflictingCommitment = "ConflictingCommitment"
+)
+
+// ID of the payload item of a commitment which holds the MMR root
+const mmrRootPayloadID = "mh"
+
+// Number of ethereum blocks queried at once when catching up on historical events
+const monitorHistoryBatchSize = 1000
+
+// Evidence of misbehaviour detected by the monitor, written to disk as JSON
+type Evidence struct {
+	Kind                  string              `json:"kind"`
+	Description           string              `json:"description"`
+	DetectedAt            time.Time           `json:"detectedAt"`
+	BeefyBlockNumber      uint64              `json:"beefyBlockNumber"`
+	RelayBlockHash        string              `json:"relayBlockHash"`
+	EthereumBlockNumber   uint64              `json:"ethereumBlockNumber"`
+	EthereumTxHash        string              `json:"ethereumTxHash"`
+	AcceptedMMRRoot       string              `json:"acceptedMMRRoot"`
+	ExpectedMMRRoot       string              `json:"expectedMMRRoot,omitempty"`
+	SubmittedCommitment   *CommitmentEvidence `json:"submittedCommitment,omitempty"`
+	ConflictingCommitment *CommitmentEvidence `json:"conflictingCommitment,omitempty"`
+	TxInput               string              `json:"txInput,omitempty"`
+}
+
+type CommitmentEvidence struct {
+	// Either "ethereum" for commitments submitted to the BeefyClient, or "relaychain" for
+	// commitments found in the BEEFY justifications of the relay chain
+	Source         string                `json:"source"`
+	EthereumTxHash string                `json:"ethereumTxHash,omitempty"`
+	BlockNumber    uint32                `json:"blockNumber"`
+	ValidatorSetID uint64                `json:"validatorSetID"`
+	Payload        []PayloadItemEvidence `json:"payload"`
+	Signatures     []SignatureEvidence   `json:"signatures"`
+}
+
+type PayloadItemEvidence struct {
+	ID   string `json:"id"`
+	Data string `json:"data"`
+}
+
+type SignatureEvidence struct {
+	Index     uint64 `json:"index"`
+	Account   string `json:"account,omitempty"`
+	Signature string `json:"signature"`
+}
+
+// Monitor follows the commitments accepted by the BeefyClient and checks them against the
+// finalized history of the relay chain.
+type Monitor struct {
+	config         *MonitorConfig
+	relaychainConn *relaychain.Connection
+	ethereumConn   *ethereum.Connection
+	contract       *contracts.BeefyClient
+	contractABI    *abi.ABI
+}
+
+func NewMonitor(config *MonitorConfig) (*Monitor, error) {
+	contractABI, err := contracts.BeefyClientMetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("parse BeefyClient ABI: %w", err)
+	}
+
+	return &Monitor{
+		config:         config,
+		relaychainConn: relaychain.NewConnection(config.Polkadot.Endpoint),
+		ethereumConn:   ethereum.NewConnection(&config.Ethereum, nil),
+		contractABI:    contractABI,
+	}, nil
+}
+
+// Start checks the commitments accepted since fromBlock on ethereum, and then follows new ones.
+// If fromBlock is zero only new commitments are checked.
+func (m *Monitor) Start(ctx context.Context, eg *errgroup.Group, fromBlock uint64) error {
+	err := os.MkdirAll(m.config.EvidenceDir, 0o755)
+	if err != nil {
+		return fmt.Errorf("create evidence directory: %w", err)
+	}
+
+	err = m.relaychainConn.ConnectWithHeartBeat(ctx, 30*time.Second)
+	if err != nil {
+		return fmt.Errorf("create relaychain connection: %w", err)
+	}
+
+	err = m.ethereumConn.Connect(ctx)
+	if err != nil {
+		return fmt.Errorf("create ethereum connection: %w", err)
+	}
+
+	address := common.HexToAddress(m.config.Contracts.BeefyClient)
+	m.contract, err = contracts.NewBeefyClient(address, m.ethereumConn.Client())
+	if err != nil {
+		return fmt.Errorf("create beefy client: %w", err)
+	}
+
+	eg.Go(func() error {
+		err := m.follow(ctx, fromBlock)
+		if err != nil && ctx.Err() == nil {
+			return err
+		}
+		return nil
+	})
+
+	return nil
+}
+
+// checkRange checks the commitments accepted in the given range of ethereum blocks, in batches
+func (m *Monitor) checkRange(ctx context.Context, fromBlock, toBlock uint64) error {
+	for start := fromBlock; start <= toBlock; start += monitorHistoryBatchSize {
+		end := min(start+monitorHistoryBatchSize-1, toBlock)
+		err := m.checkBlocks(ctx, start, end)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// follow checks the commitments accepted in new ethereum blocks. The header subscription is
+// started before the history since fromBlock is checked, and every block since the last checked
+// one is checked when a new header arrives, so that no block is skipped in between.
+func (m *Monitor) follow(ctx context.Context, fromBlock uint64) error {
+	headers := make(chan *gethTypes.Header, 5)
+
+	sub, err := m.ethereumConn.Client().SubscribeNewHead(ctx, headers)
+	if err != nil {
+		return fmt.Errorf("creating ethereum header subscription: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	latest, err := m.ethereumConn.Client().BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("fetch latest ethereum block: %w", err)
+	}
+	lastChecked := latest
+	if fromBlock > 0 && fromBlock <= latest {
+		err = m.checkRange(ctx, fromBlock, latest)
+		if err != nil {
+			return err
+		}
+		log.WithFields(log.Fields{
+			"fromBlock": fromBlock,
+			"toBlock":   latest,
+		}).Info("Checked historical BeefyClient commitments")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-sub.Err():
+			return fmt.Errorf("header subscription: %w", err)
+		case header := <-headers:
+			blockNumber := header.Number.Uint64()
+			if blockNumber <= lastChecked {
+				continue
+			}
+			err := m.checkRange(ctx, lastChecked+1, blockNumber)
+			if err != nil {
+				return err
+			}
+			lastChecked = blockNumber
+		}
+	}
+}
+
+func (m *Monitor) checkBlocks(ctx context.Context, start, end uint64) error {
+	iter, err := m.contract.FilterNewMMRRoot(&bind.FilterOpts{Start: start, End: &end, Context: ctx})
+	if err != nil {
+		return fmt.Errorf("query NewMMRRoot events in blocks %d-%d: %w", start, end, err)
+	}
+	defer iter.Close()
+
+	for iter.Next() {
+		err := m.check(ctx, iter.Event)
+		if err != nil {
+			return fmt.Errorf("check NewMMRRoot event in tx %v: %w", iter.Event.Raw.TxHash.Hex(), err)
+		}
+	}
+	return iter.Error()
+}
+
+func (m *Monitor) check(ctx context.Context, event *contracts.BeefyClientNewMMRRoot) error {
+	err := m.waitForFinalized(ctx, event.BlockNumber)
+	if err != nil {
+		return err
+	}
+
+	relayBlockHash, err := m.relaychainConn.API().RPC.Chain.GetBlockHash(event.BlockNumber)
+	if err != nil {
+		return fmt.Errorf("fetch relay chain block hash for block %d: %w", event.BlockNumber, err)
+	}
+
+	expectedRoot, err := m.relaychainConn.GetMMRRootHash(relayBlockHash)
+	if err != nil {
+		return fmt.Errorf("fetch MMR root at block %d: %w", event.BlockNumber, err)
+	}
+
+	submitted, txInput, err := m.fetchSubmittedCommitment(ctx, event.Raw.TxHash, event.BlockNumber)
+	if err != nil {
+		return err
+	}
+
+	newEvidence := func(kind, description string) *Evidence {
+		return &Evidence{
+			Kind:                kind,
+			Description:         description,
+			DetectedAt:          time.Now().UTC(),
+			BeefyBlockNumber:    event.BlockNumber,
+			RelayBlockHash:      relayBlockHash.Hex(),
+			EthereumBlockNumber: event.Raw.BlockNumber,
+			EthereumTxHash:      event.Raw.TxHash.Hex(),
+			AcceptedMMRRoot:     hexutil.Encode(event.MmrRoot[:]),
+			SubmittedCommitment: submitted,
+			TxInput:             txInput,
+		}
+	}
+
+	// The commitment is checked against the MMR root of the relay chain whether or not the block has
+	// a BEEFY justification, which it only has if it ends a session or was requested by a relayer
+	valid := true
+	if submitted != nil && !hasMMRRoot(submitted.Payload, expectedRoot) {
+		valid = false
+		canonical, err := m.fetchRelayChainCommitment(relayBlockHash)
+		if err != nil {
+			return err
+		}
+		evidence := newEvidence(EvidenceConflictingCommitment, "Commitment accepted by the BeefyClient conflicts with the MMR root of the relay chain")
+		evidence.ExpectedMMRRoot = expectedRoot.Hex()
+		evidence.ConflictingCommitment = canonical
+		m.report(evidence)
+	} else if expectedRoot != types.Hash(event.MmrRoot) {
+		valid = false
+		evidence := newEvidence(EvidenceInvalidMMRRoot, "MMR root accepted by the BeefyClient does not match the relay chain")
+		evidence.ExpectedMMRRoot = expectedRoot.Hex()
+		m.report(evidence)
+	}
+
+	if valid {
+		log.WithFields(log.Fields{
+			"beefyBlockNumber":    event.BlockNumber,
+			"mmrRoot":             hexutil.Encode(event.MmrRoot[:]),
+			"ethereumBlockNumber": event.Raw.BlockNumber,
+			"ethereumTxHash":      event.Raw.TxHash.Hex(),
+		}).Info("Verified MMR root accepted by BeefyClient")
+	}
+
+	return nil
+}
+
+// waitForFinalized waits until the relay chain has BEEFY-finalized the given block
+func (m *Monitor) waitForFinalized(ctx context.Context, blockNumber uint64) error {
+	api := m.relaychainConn.API()
+	for {
+		finalizedHash, err := api.RPC.Beefy.GetFinalizedHead()
+		if err != nil {
+			return fmt.Errorf("fetch beefy finalized head: %w", err)
+		}
+		finalizedHeader, err := api.RPC.Chain.GetHeader(finalizedHash)
+		if err != nil {
+			return fmt.Errorf("fetch header for beefy finalized head %v: %w", finalizedHash.Hex(), err)
+		}
+		if uint64(finalizedHeader.Number) >= blockNumber {
+			return nil
+		}
+
+		log.WithFields(log.Fields{
+			"beefyBlockNumber":     blockNumber,
+			"finalizedBlockNumber": finalizedHeader.Number,
+		}).Info("Waiting for relay chain to finalize block accepted by BeefyClient")
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(6 * time.Second):
+		}
+	}
+}
+
+// fetchSubmittedCommitment decodes the commitment from the submitFinal call which imported the MMR
+// root at beefyBlockNumber. The call is either the transaction itself, or is nested in the input of
+// a transaction routed through a multisig or proxy contract. It returns nil if no such call is found.
+func (m *Monitor) fetchSubmittedCommitment(ctx context.Context, txHash common.Hash, beefyBlockNumber uint64) (*CommitmentEvidence, string, error) {
+	tx, _, err := m.ethereumConn.Client().TransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, "", fmt.Errorf("fetch transaction %v: %w", txHash.Hex(), err)
+	}
+	txInput := hexutil.Encode(tx.Data())
+
+	args, ok := findSubmitFinalCall(m.contractABI, tx.Data(), beefyBlockNumber)
+	if !ok {
+		return nil, txInput, nil
+	}
+	commitment := *abi.ConvertType(args[0], new(contracts.BeefyClientCommitment)).(*contracts.BeefyClientCommitment)
+	proofs := *abi.ConvertType(args[2], new([]contracts.BeefyClientValidatorProof)).(*[]contracts.BeefyClientValidatorProof)
+
+	evidence := newCommitmentEvidence("ethereum", &commitment)
+	evidence.EthereumTxHash = txHash.Hex()
+	for _, proof := range proofs {
+		signature := append(append(append([]byte{}, proof.R[:]...), proof.S[:]...), proof.V)
+		evidence.Signatures = append(evidence.Signatures, SignatureEvidence{
+			Index:     proof.Index.Uint64(),
+			Account:   proof.Account.Hex(),
+			Signature: hexutil.Encode(signature),
+		})
+	}
+
+	return evidence, txInput, nil
+}
+
+// findSubmitFinalCall searches the input of a transaction for a submitFinal call with a commitment
+// for beefyBlockNumber, and returns its arguments. ABI encoded calldata which is passed on by a
+// multisig or proxy contract is embedded unchanged in the input of the outer call, so every
+// occurrence of the submitFinal selector is tried.
+func findSubmitFinalCall(contractABI *abi.ABI, input []byte, beefyBlockNumber uint64) ([]interface{}, bool) {
+	method := contractABI.Methods["submitFinal"]
+	for i := 0; i+4 <= len(input); i++ {
+		if !bytes.Equal(input[i:i+4], method.ID) {
+			continue
+		}
+		args, err := method.Inputs.Unpack(input[i+4:])
+		if err != nil {
+			continue
+		}
+		commitment := *abi.ConvertType(args[0], new(contracts.BeefyClientCommitment)).(*contracts.BeefyClientCommitment)
+		if uint64(commitment.BlockNumber) == beefyBlockNumber {
+			return args, true
+		}
+	}
+	return nil, false
+}
+
+// fetchRelayChainCommitment returns the commitment in the BEEFY justification of a relay chain
+// block, to add to the evidence of a conflicting commitment, or nil if the block has no justification.
+func (m *Monitor) fetchRelayChainCommitment(blockHash types.Hash) (*CommitmentEvidence, error) {
+	block, err := m.relaychainConn.API().RPC.Chain.GetBlock(blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("fetch block %v: %w", blockHash.Hex(), err)
+	}
+
+	for _, justification := range block.Justifications {
+		if justification.EngineID() != "BEEF" {
+			continue
+		}
+		sc := types.OptionalSignedCommitment{}
+		err := types.DecodeFromBytes(justification.Payload(), &sc)
+		if err != nil {
+			return nil, fmt.Errorf("decode BEEFY signed commitment: %w", err)
+		}
+		ok, signedCommitment := sc.Unwrap()
+		if !ok {
+			continue
+		}
+
+		evidence := newCommitmentEvidence("relaychain", toBeefyClientCommitment(&signedCommitment.Commitment))
+		for i, signature := range signedCommitment.Signatures {
+			ok, value := signature.Unwrap()
+			if !ok {
+				continue
+			}
+			evidence.Signatures = append(evidence.Signatures, SignatureEvidence{
+				Index:     uint64(i),
+				Signature: hexutil.Encode(value[:]),
+			})
+		}
+		return evidence, nil
+	}
+
+	return nil, nil
+}
+
+func (m *Monitor) report(evidence *Evidence) {
+	path, err := writeEvidence(m.config.EvidenceDir, evidence)
+
+	fields := log.Fields{
+		"severity":            "high",
+		"kind":                evidence.Kind,
+		"beefyBlockNumber":    evidence.BeefyBlockNumber,
+		"ethereumBlockNumber": evidence.EthereumBlockNumber,
+		"ethereumTxHash":      evidence.EthereumTxHash,
+		"acceptedMMRRoot":     evidence.AcceptedMMRRoot,
+		"evidence":            path,
+	}
+	if err != nil {
+		log.WithError(err).WithFields(fields).Error("Failed to write evidence")
+	}
+	log.WithFields(fields).Error(evidence.Description)
+}
+
+func writeEvidence(dir string, evidence *Evidence) (string, error) {
+	data, err := json.MarshalIndent(evidence, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encode evidence: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%d-%s.json", evidence.Kind, evidence.BeefyBlockNumber, evidence.EthereumTxHash)
+	path := filepath.Join(dir, name)
+	err = os.WriteFile(path, data, 0o644)
+	if err != nil {
+		return "", fmt.Errorf("write evidence to %s: %w", path, err)
+	}
+
+	return path, nil
+}
+
+func newCommitmentEvidence(source string, commitment *contracts.BeefyClientCommitment) *CommitmentEvidence {
+	payload := make([]PayloadItemEvidence, len(commitment.Payload))
+	for i, item := range commitment.Payload {
+		payload[i] = PayloadItemEvidence{
+			ID:   string(item.PayloadID[:]),
+			Data: hexutil.Encode(item.Data),
+		}
+	}
+	return &CommitmentEvidence{
+		Source:         source,
+		BlockNumber:    commitment.BlockNumber,
+		ValidatorSetID: commitment.ValidatorSetID,
+		Payload:        payload,
+	}
+}
+
+// hasMMRRoot returns whether the MMR root item of a commitment payload is the given root
+func hasMMRRoot(payload []PayloadItemEvidence, mmrRoot types.Hash) bool {
+	for _, item := range payload {
+		if item.ID == mmrRootPayloadID {
+			return item.Data == hexutil.Encode(mmrRoot[:])
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package beefy
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"github.com/snowfork/snowbridge/relayer/contracts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasMMRRoot(t *testing.T) {
+	mmrRoot := types.NewHash([]byte{1, 2, 3})
+	commitment := contracts.BeefyClientCommitment{
+		BlockNumber:    100,
+		ValidatorSetID: 5,
+		Payload:        []contracts.BeefyClientPayloadItem{{PayloadID: [2]byte{'m', 'h'}, Data: mmrRoot[:]}},
+	}
+	conflicting := contracts.BeefyClientCommitment{
+		BlockNumber:    100,
+		ValidatorSetID: 5,
+		Payload:        []contracts.BeefyClientPayloadItem{{PayloadID: [2]byte{'m', 'h'}, Data: []byte{1, 2, 4}}},
+	}
+	other := contracts.BeefyClientCommitment{
+		BlockNumber:    100,
+		ValidatorSetID: 5,
+		Payload:        []contracts.BeefyClientPayloadItem{{PayloadID: [2]byte{'x', 'y'}, Data: mmrRoot[:]}},
+	}
+
+	a := newCommitmentEvidence("ethereum", &commitment)
+	assert.Equal(t, "mh", a.Payload[0].ID)
+	assert.True(t, hasMMRRoot(a.Payload, mmrRoot))
+	assert.False(t, hasMMRRoot(newCommitmentEvidence("ethereum", &conflicting).Payload, mmrRoot))
+	assert.False(t, hasMMRRoot(newCommitmentEvidence("ethereum", &other).Payload, mmrRoot))
+	assert.False(t, hasMMRRoot(nil, mmrRoot))
+}
+
+func TestWriteEvidence(t *testing.T) {
+	dir := t.TempDir()
+	evidence := Evidence{
+		Kind:             EvidenceInvalidMMRRoot,
+		BeefyBlockNumber: 100,
+		EthereumTxHash:   "0x01",
+		AcceptedMMRRoot:  "0x02",
+		ExpectedMMRRoot:  "0x03",
+	}
+
+	path, err := writeEvidence(dir, &evidence)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var decoded Evidence
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, evidence, decoded)
+}
+
+func TestFindSubmitFinalCall(t *testing.T) {
+	contractABI, err := contracts.BeefyClientMetaData.GetAbi()
+	require.NoError(t, err)
+
+	commitment := contracts.BeefyClientCommitment{
+		BlockNumber:    100,
+		ValidatorSetID: 5,
+		Payload:        []contracts.BeefyClientPayloadItem{{PayloadID: [2]byte{'m', 'h'}, Data: []byte{1, 2, 3}}},
+	}
+	proofs := []contracts.BeefyClientValidatorProof{{V: 27, Index: big.NewInt(1), Proof: [][32]byte{{1}}}}
+	call, err := contractABI.Pack("submitFinal", commitment, []*big.Int{big.NewInt(1)}, proofs, contracts.BeefyClientMMRLeaf{}, [][32]byte{}, big.NewInt(0))
+	require.NoError(t, err)
+
+	// A direct call to the BeefyClient
+	args, ok := findSubmitFinalCall(contractABI, call, 100)
+	require.True(t, ok)
+	assert.Equal(t, commitment, *abi.ConvertType(args[0], new(contracts.BeefyClientCommitment)).(*contracts.BeefyClientCommitment))
+
+	// A call routed through a multisig, e.g. execTransaction(address to, uint256 value, bytes data)
+	addressType, err := abi.NewType("address", "", nil)
+	require.NoError(t, err)
+	uintType, err := abi.NewType("uint256", "", nil)
+	require.NoError(t, err)
+	bytesType, err := abi.NewType("bytes", "", nil)
+	require.NoError(t, err)
+	outer, err := abi.Arguments{{Type: addressType}, {Type: uintType}, {Type: bytesType}}.Pack(common.Address{1}, big.NewInt(0), call)
+	require.NoError(t, err)
+	outer = append([]byte{0x6a, 0x76, 0x12, 0x02}, outer...)
+
+	args, ok = findSubmitFinalCall(contractABI, outer, 100)
+	require.True(t, ok)
+	assert.Equal(t, commitment, *abi.ConvertType(args[0], new(contracts.BeefyClientCommitment)).(*contracts.BeefyClientCommitment))
+
+	// A call for another commitment
+	_, ok = findSubmitFinalCall(contractABI, outer, 101)
+	assert.False(t, ok)
+}
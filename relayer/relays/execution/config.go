@@ -14,6 +14,13 @@ type Config struct {
 	InstantVerification bool              `mapstructure:"instantVerification"`
 	Schedule            ScheduleConfig    `mapstructure:"schedule"`
 	OFAC                config.OFACConfig `mapstructure:"ofac"`
+	// Economic policy for deferring unprofitable messages
+	Profitability config.ProfitabilityConfig `mapstructure:"profitability"`
+	// Claims on nonces exchanged with peers
+	Claims config.ClaimsConfig `mapstructure:"claims"`
+	// Pausing while the Gateway or EthereumInboundQueue is halted
+	OperatingMode config.OperatingModeConfig `mapstructure:"operating-mode"`
+	Metrics       config.MetricsConfig       `mapstructure:"metrics"`
 }
 
 type ScheduleConfig struct {
@@ -23,6 +30,8 @@ type ScheduleConfig struct {
 	TotalRelayerCount uint64 `mapstructure:"totalRelayerCount"`
 	// Sleep interval(in seconds) to check if message(nonce) has already been relayed
 	SleepInterval uint64 `mapstructure:"sleepInterval"`
+	// Source of a dynamic relayer set, replacing ID and TotalRelayerCount once loaded
+	Membership config.MembershipConfig `mapstructure:"membership"`
 }
 
 func (r ScheduleConfig) Validate() error {
@@ -32,6 +41,10 @@ func (r ScheduleConfig) Validate() error {
 	if r.ID >= r.TotalRelayerCount {
 		return errors.New("ID of the Number of relayer is not set")
 	}
+	err := r.Membership.Validate()
+	if err != nil {
+		return fmt.Errorf("membership config: %w", err)
+	}
 	return nil
 }
 
@@ -76,5 +89,13 @@ func (c Config) Validate() error {
 	if err != nil {
 		return fmt.Errorf("ofac config: %w", err)
 	}
+	err = c.Profitability.Validate()
+	if err != nil {
+		return fmt.Errorf("profitability config: %w", err)
+	}
+	err = c.Claims.Validate()
+	if err != nil {
+		return fmt.Errorf("claims config: %w", err)
+	}
 	return nil
 }
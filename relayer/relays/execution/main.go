@@ -8,7 +8,12 @@ import (
 	"sort"
 	"time"
 
+	"github.com/snowfork/snowbridge/relayer/claims"
+	"github.com/snowfork/snowbridge/relayer/metrics"
 	"github.com/snowfork/snowbridge/relayer/ofac"
+	"github.com/snowfork/snowbridge/relayer/operatingmode"
+	"github.com/snowfork/snowbridge/relayer/profitability"
+	"github.com/snowfork/snowbridge/relayer/schedule"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -20,11 +25,12 @@ import (
 	"github.com/snowfork/snowbridge/relayer/chain/parachain"
 	"github.com/snowfork/snowbridge/relayer/contracts"
 	"github.com/snowfork/snowbridge/relayer/crypto/sr25519"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/era"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/header"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer/api"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer/scale"
 	"github.com/snowfork/snowbridge/relayer/relays/beacon/protocol"
-	"github.com/snowfork/snowbridge/relayer/relays/beacon/store"
+	beaconstore "github.com/snowfork/snowbridge/relayer/relays/beacon/store"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -38,7 +44,15 @@ type Relay struct {
 	writer          *parachain.ParachainWriter
 	headerCache     *ethereum.HeaderCache
 	ofac            *ofac.OFAC
+	profitability   *profitability.Policy
+	schedule        *schedule.Schedule
+	claims          *claims.Claims
+	operatingMode   *operatingmode.Watcher
 	chainID         *big.Int
+	// Last parachain block searched for deliveries of messages
+	lastObservedBlock uint64
+	// Number of polls for which each message was deferred to a pending extrinsic of another relayer
+	pendingDeferrals map[uint64]int
 }
 
 func NewRelay(
@@ -92,23 +106,82 @@ func (r *Relay) Start(ctx context.Context, eg *errgroup.Group) error {
 	}
 	r.gatewayContract = contract
 
+	metrics.Start(ctx, eg, r.config.Metrics)
+
+	r.operatingMode = operatingmode.New(
+		r.config.OperatingMode,
+		ethconn,
+		contract,
+		[][32]byte{r.config.Source.ChannelID},
+		paraconn,
+		[]string{operatingmode.EthereumInboundQueue},
+		"",
+	)
+	r.operatingMode.Start(ctx, eg)
+
+	membershipSource, err := schedule.NewSource(r.config.Schedule.Membership, ethconn.Client())
+	if err != nil {
+		return fmt.Errorf("create membership source: %w", err)
+	}
+	self := schedule.Member(types.NewAccountID(r.keypair.AsKeyringPair().PublicKey))
+	r.schedule = schedule.New(
+		r.config.Schedule.ID,
+		r.config.Schedule.TotalRelayerCount,
+		r.config.Schedule.Membership,
+		self,
+		membershipSource,
+	)
+	r.schedule.Start(ctx, eg)
+
+	r.claims = claims.New(r.config.Claims, self.Hex())
+	r.claims.Start(ctx, eg)
+
 	p := protocol.New(r.config.Source.Beacon.Spec, r.config.Sink.Parachain.HeaderRedundancy)
 
 	r.ofac = ofac.New(r.config.OFAC.Enabled, r.config.OFAC.ApiKey)
 
-	store := store.New(r.config.Source.Beacon.DataStore.Location, r.config.Source.Beacon.DataStore.MaxEntries, *p)
+	r.profitability, err = profitability.New(r.config.Profitability)
+	if err != nil {
+		return fmt.Errorf("create profitability policy: %w", err)
+	}
+	defer r.profitability.Close()
+
+	store := beaconstore.New(r.config.Source.Beacon.DataStore.Location, r.config.Source.Beacon.DataStore.MaxEntries, *p)
 	store.Connect()
+	var beaconStore beaconstore.BeaconStore = &store
+	var archive *era.Archive
+	if r.config.Source.Beacon.DataStore.EraDirectory != "" {
+		archive, err = era.NewArchive(r.config.Source.Beacon.DataStore.EraDirectory, p.SlotsPerHistoricalRoot)
+		if err != nil {
+			return fmt.Errorf("open era archive: %w", err)
+		}
+		beaconStore = era.NewStore(&store, archive)
+	}
+
+	var beaconAPI api.BeaconAPI = api.NewBeaconClient(r.config.Source.Beacon.Endpoint, r.config.Source.Beacon.StateEndpoint)
+	err = p.CheckBeaconNodeSpec(beaconAPI)
+	if err != nil {
+		return err
+	}
+	if archive != nil {
+		beaconAPI = era.NewAPI(beaconAPI, archive, p)
+	}
 
-	beaconAPI := api.NewBeaconClient(r.config.Source.Beacon.Endpoint, r.config.Source.Beacon.StateEndpoint)
 	beaconHeader := header.New(
 		r.writer,
 		beaconAPI,
-		r.config.Source.Beacon.Spec,
-		&store,
+		p.Settings,
+		beaconStore,
 		p,
-		0, // setting is not used in the execution relay
+		0,   // setting is not used in the execution relay
+		nil, // headers are not synced by the execution relay
 	)
+	beaconHeader.SetSyncMode(r.config.Source.Beacon.SyncMode)
 	r.beaconHeader = &beaconHeader
+	err = r.beaconHeader.LoadCheckpoints()
+	if err != nil {
+		log.WithError(err).Warn("load checkpoints from datastore")
+	}
 
 	r.chainID, err = r.ethconn.Client().NetworkID(ctx)
 	if err != nil {
@@ -127,6 +200,11 @@ func (r *Relay) Start(ctx context.Context, eg *errgroup.Group) error {
 		case <-ctx.Done():
 			return nil
 		case <-time.After(60 * time.Second):
+			if halted := r.operatingMode.Halted(); len(halted) > 0 {
+				log.WithField("halted", halted).Info("Bridge halted, not relaying messages")
+				continue
+			}
+
 			log.WithFields(log.Fields{
 				"channelId": r.config.Source.ChannelID,
 			}).Info("Polling Nonces")
@@ -148,6 +226,9 @@ func (r *Relay) Start(ctx context.Context, eg *errgroup.Group) error {
 				"instantVerification": r.config.InstantVerification,
 			}).Info("Polled Nonces")
 
+			r.profitability.Prune(r.config.Source.ChannelID, paraNonce)
+			r.observeDeliveries()
+
 			if paraNonce == ethNonce {
 				continue
 			}
@@ -163,10 +244,26 @@ func (r *Relay) Start(ctx context.Context, eg *errgroup.Group) error {
 			}
 
 			for _, ev := range events {
+				if len(r.operatingMode.Halted()) > 0 {
+					// The remaining messages are relayed once the bridge resumes
+					break
+				}
 				err := r.waitAndSend(ctx, ev)
 				if errors.Is(err, header.ErrBeaconHeaderNotFinalized) {
 					log.WithField("nonce", ev.Nonce).Info("beacon header not finalized yet")
 					continue
+				} else if errors.Is(err, ErrMessageDeferred) {
+					// Later messages cannot be delivered before this one
+					log.WithField("nonce", ev.Nonce).Info("message deferred, retrying on the next poll")
+					break
+				} else if errors.Is(err, ErrMessagePending) {
+					// Later messages cannot be delivered before this one
+					log.WithField("nonce", ev.Nonce).Info("message pending, checking again on the next poll")
+					break
+				} else if errors.As(err, new(*parachain.DispatchError)) {
+					// The extrinsic was included but failed, so the message is retried rather than stopping the relay
+					log.WithError(err).WithField("nonce", ev.Nonce).Warn("message failed to dispatch, retrying on the next poll")
+					break
 				} else if err != nil {
 					return fmt.Errorf("submit event: %w", err)
 				}
@@ -372,7 +469,7 @@ func (r *Relay) makeInboundMessage(
 
 func (r *Relay) waitAndSend(ctx context.Context, ev *contracts.GatewayOutboundMessageAccepted) (err error) {
 	ethNonce := ev.Nonce
-	waitingPeriod := (ethNonce + r.config.Schedule.TotalRelayerCount - r.config.Schedule.ID) % r.config.Schedule.TotalRelayerCount
+	waitingPeriod := r.schedule.WaitingPeriod(ethNonce)
 	log.WithFields(logrus.Fields{
 		"waitingPeriod": waitingPeriod,
 	}).Info("relayer waiting period")
@@ -393,14 +490,19 @@ func (r *Relay) waitAndSend(ctx context.Context, ev *contracts.GatewayOutboundMe
 		if err != nil {
 			return fmt.Errorf("check beacon header finalized: %w", err)
 		}
-		if cnt == waitingPeriod {
-			break
+		if cnt >= waitingPeriod {
+			if r.claims.TryClaim(ctx, ev.ChannelID, ev.Nonce) {
+				break
+			}
+			log.WithField("nonce", ev.Nonce).Info("nonce claimed by another relayer, backing off")
 		}
 		log.Info(fmt.Sprintf("sleeping for %d seconds.", time.Duration(r.config.Schedule.SleepInterval)))
 
 		time.Sleep(time.Duration(r.config.Schedule.SleepInterval) * time.Second)
 		cnt++
 	}
+	defer r.claims.Release(ctx, ev.ChannelID, ev.Nonce)
+
 	err = r.doSubmit(ctx, ev)
 	if err != nil {
 		return fmt.Errorf("submit inbound message: %w", err)
@@ -410,6 +512,11 @@ func (r *Relay) waitAndSend(ctx context.Context, ev *contracts.GatewayOutboundMe
 }
 
 func (r *Relay) doSubmit(ctx context.Context, ev *contracts.GatewayOutboundMessageAccepted) error {
+	err := r.checkPending(ev)
+	if err != nil {
+		return err
+	}
+
 	inboundMsg, err := r.makeInboundMessage(ctx, r.headerCache, ev)
 	if err != nil {
 		return fmt.Errorf("make outgoing message: %w", err)
@@ -473,8 +580,17 @@ func (r *Relay) doSubmit(ctx context.Context, ev *contracts.GatewayOutboundMessa
 		return nil
 	}
 
-	err = r.writeToParachain(ctx, proof, inboundMsg)
+	err = r.checkProfitability(ev, proof, inboundMsg)
 	if err != nil {
+		return err
+	}
+
+	err = r.writeToParachain(ctx, proof, inboundMsg)
+	if parachain.IsAlreadyDelivered(err) {
+		// Another relayer won the race for this nonce
+		logger.WithError(err).Info("inbound message already delivered by another relayer")
+		return nil
+	} else if err != nil {
 		return fmt.Errorf("write to parachain: %w", err)
 	}
 
@@ -482,7 +598,8 @@ func (r *Relay) doSubmit(ctx context.Context, ev *contracts.GatewayOutboundMessa
 	if err != nil {
 		return fmt.Errorf("fetch latest parachain nonce: %w", err)
 	}
-	if paraNonce != ev.Nonce {
+	// Later nonces may have been delivered by other relayers in the meantime
+	if paraNonce < ev.Nonce {
 		return fmt.Errorf("inbound message fail to execute")
 	}
 	logger.Info("inbound message executed successfully")
@@ -0,0 +1,58 @@
+package execution
+
+import (
+	"errors"
+
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"github.com/snowfork/snowbridge/relayer/chain/ethereum"
+	"github.com/snowfork/snowbridge/relayer/contracts"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrMessagePending = errors.New("message is being delivered by another relayer")
+
+// Number of polls for which a message is deferred to a pending extrinsic of another relayer before
+// it is delivered anyway, as the pending extrinsic may never be included
+const maxPendingDeferrals = 5
+
+// checkPending returns ErrMessagePending if an extrinsic of another relayer delivering the message
+// is in the transaction pool of the parachain. Messages are identified by their event log, which
+// is the same in the extrinsics of all relayers, whether or not they are batched. The check only
+// needs the event, so it runs before the proofs of the message are built. A message is deferred at
+// most maxPendingDeferrals times, so that an extrinsic stuck in the pool does not hold it back.
+func (r *Relay) checkPending(ev *contracts.GatewayOutboundMessageAccepted) error {
+	signers, err := r.paraconn.PendingExtrinsicSigners(
+		ethereum.MakeEventLog(&ev.Raw),
+		r.config.Source.Beacon.Spec.WithPreset().SyncCommitteeSize,
+	)
+	if err != nil {
+		log.WithError(err).Warn("Failed to fetch pending extrinsics")
+		return nil
+	}
+
+	self := types.NewAccountID(r.keypair.AsKeyringPair().PublicKey)
+	for _, signer := range signers {
+		if signer == self {
+			continue
+		}
+
+		logger := log.WithFields(log.Fields{
+			"nonce":   ev.Nonce,
+			"relayer": types.HexEncodeToString(signer[:]),
+		})
+		if r.pendingDeferrals == nil {
+			r.pendingDeferrals = make(map[uint64]int)
+		}
+		if r.pendingDeferrals[ev.Nonce] >= maxPendingDeferrals {
+			logger.Warn("Pending extrinsic of another relayer is not included, delivering the message")
+			return nil
+		}
+		r.pendingDeferrals[ev.Nonce]++
+		logger.Info("Message is being delivered by another relayer")
+		return ErrMessagePending
+	}
+
+	delete(r.pendingDeferrals, ev.Nonce)
+	return nil
+}
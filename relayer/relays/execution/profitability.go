@@ -0,0 +1,111 @@
+package execution
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"github.com/snowfork/snowbridge/relayer/chain/parachain"
+	"github.com/snowfork/snowbridge/relayer/contracts"
+	"github.com/snowfork/snowbridge/relayer/profitability"
+	"github.com/snowfork/snowbridge/relayer/relays/beacon/header/syncer/scale"
+)
+
+// Encoded length of the signature, address and signed extensions of an extrinsic, in addition
+// to its call
+const extrinsicOverhead = 128
+
+var ErrMessageDeferred = errors.New("message deferred by profitability policy")
+
+// checkProfitability returns ErrMessageDeferred if the message should not be relayed yet
+// according to the profitability policy.
+func (r *Relay) checkProfitability(
+	ev *contracts.GatewayOutboundMessageAccepted,
+	proof scale.ProofPayload,
+	inboundMsg *parachain.Message,
+) error {
+	if !r.profitability.Enabled() {
+		return nil
+	}
+
+	// The message is estimated with the execution proof it is submitted with, without changing
+	// the caller's message
+	msg := *inboundMsg
+	msg.Proof.ExecutionProof = proof.HeaderPayload
+	estimate, err := r.estimateMessage(&msg)
+	if err != nil {
+		return fmt.Errorf("estimate message: %w", err)
+	}
+
+	decision := r.profitability.Decide(ev.ChannelID, ev.Nonce, estimate)
+	if !decision.Relay {
+		return ErrMessageDeferred
+	}
+	return nil
+}
+
+// estimateMessage estimates the reward and cost of submitting a message to the inbound queue. The
+// relayer pays the fee of the submit call, and is refunded the delivery cost of the message from
+// the sovereign account of the channel's parachain. The inbound queue computes the delivery cost
+// from the weight of the call and the encoded length of the message, without the fee multiplier
+// and base fee the actual call fee includes, and adds the local reward.
+func (r *Relay) estimateMessage(inboundMsg *parachain.Message) (profitability.Estimate, error) {
+	call, err := types.NewCall(r.paraconn.Metadata(), "EthereumInboundQueue.submit", inboundMsg)
+	if err != nil {
+		return profitability.Estimate{}, fmt.Errorf("create call: %w", err)
+	}
+	encodedCall, err := types.EncodeToBytes(call)
+	if err != nil {
+		return profitability.Estimate{}, fmt.Errorf("encode call: %w", err)
+	}
+	encodedMsg, err := types.EncodeToBytes(inboundMsg)
+	if err != nil {
+		return profitability.Estimate{}, fmt.Errorf("encode message: %w", err)
+	}
+
+	info, err := r.paraconn.QueryCallInfo(call, uint32(len(encodedCall)+extrinsicOverhead))
+	if err != nil {
+		return profitability.Estimate{}, fmt.Errorf("query call info: %w", err)
+	}
+	weightFee, err := r.paraconn.QueryWeightToFee(info.Weight)
+	if err != nil {
+		return profitability.Estimate{}, fmt.Errorf("query weight fee: %w", err)
+	}
+	lengthFee, err := r.paraconn.QueryLengthToFee(uint32(len(encodedMsg)))
+	if err != nil {
+		return profitability.Estimate{}, fmt.Errorf("query length fee: %w", err)
+	}
+
+	channel, err := r.paraconn.GetChannel(types.H256(r.config.Source.ChannelID))
+	if err != nil {
+		return profitability.Estimate{}, err
+	}
+
+	pricing, err := r.paraconn.GetPricingParameters()
+	if err != nil {
+		return profitability.Estimate{}, err
+	}
+
+	balance, err := r.paraconn.GetFreeBalance(parachain.SiblingSovereignAccount(uint32(channel.ParaID)))
+	if err != nil {
+		return profitability.Estimate{}, err
+	}
+
+	return estimateDelivery(info.PartialFee.Int, weightFee, lengthFee, pricing.Rewards.Local.Int, balance), nil
+}
+
+// estimateDelivery weighs the delivery cost refunded by the inbound queue, which is limited by the
+// balance of the sovereign account paying it, against the fee of the submit call.
+func estimateDelivery(callFee, weightFee, lengthFee, localReward, balance *big.Int) profitability.Estimate {
+	reward := new(big.Int).Add(weightFee, lengthFee)
+	reward.Add(reward, localReward)
+	if balance.Cmp(reward) < 0 {
+		reward = new(big.Int).Set(balance)
+	}
+
+	return profitability.Estimate{
+		Reward: reward,
+		Cost:   callFee,
+	}
+}
@@ -0,0 +1,21 @@
+package execution
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateDelivery(t *testing.T) {
+	// The refunded delivery cost differs from the fee of the call
+	estimate := estimateDelivery(big.NewInt(150), big.NewInt(100), big.NewInt(20), big.NewInt(50), big.NewInt(1000))
+	assert.Equal(t, big.NewInt(170), estimate.Reward)
+	assert.Equal(t, big.NewInt(150), estimate.Cost)
+	assert.Equal(t, big.NewInt(20), estimate.Profit())
+
+	// The refund is limited by the balance of the sovereign account
+	estimate = estimateDelivery(big.NewInt(150), big.NewInt(100), big.NewInt(20), big.NewInt(50), big.NewInt(60))
+	assert.Equal(t, big.NewInt(60), estimate.Reward)
+	assert.Equal(t, big.NewInt(-90), estimate.Profit())
+}
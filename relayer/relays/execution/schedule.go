@@ -0,0 +1,98 @@
+package execution
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"github.com/snowfork/snowbridge/relayer/schedule"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Maximum number of parachain blocks searched for deliveries of messages in each poll
+const deliveryObservationBlocks = 100
+
+// observeDeliveries records which relayers delivered the messages received by the inbound queue
+// since the last observation, so that relayers which miss their turns are left out of the rotation.
+func (r *Relay) observeDeliveries() {
+	if !r.schedule.Dynamic() {
+		return
+	}
+
+	err := r.observeDeliveriesImpl()
+	if err != nil {
+		log.WithError(err).Warn("Failed to observe message deliveries")
+	}
+}
+
+func (r *Relay) observeDeliveriesImpl() error {
+	header, err := r.paraconn.GetFinalizedHeader()
+	if err != nil {
+		return fmt.Errorf("get finalized header: %w", err)
+	}
+	latestBlock := uint64(header.Number)
+
+	start := r.lastObservedBlock + 1
+	if latestBlock >= start+deliveryObservationBlocks {
+		start = latestBlock - deliveryObservationBlocks + 1
+	}
+
+	channelID := types.H256(r.config.Source.ChannelID)
+	for blockNumber := start; blockNumber <= latestBlock; blockNumber++ {
+		blockHash, err := r.paraconn.API().RPC.Chain.GetBlockHash(blockNumber)
+		if err != nil {
+			return fmt.Errorf("get block hash of block %d: %w", blockNumber, err)
+		}
+
+		events, err := r.paraconn.FetchEvents(blockHash)
+		if err != nil {
+			return err
+		}
+
+		var blockTime time.Time
+		for _, event := range events {
+			if event.Pallet != "EthereumInboundQueue" || event.Name != "MessageReceived" || !event.ApplyExtrinsic || len(event.Fields) < 2 {
+				continue
+			}
+
+			var eventChannelID types.H256
+			err = types.DecodeFromBytes(event.Fields[0], &eventChannelID)
+			if err != nil {
+				return fmt.Errorf("decode channel ID of MessageReceived: %w", err)
+			}
+			if eventChannelID != channelID {
+				continue
+			}
+			var nonce types.U64
+			err = types.DecodeFromBytes(event.Fields[1], &nonce)
+			if err != nil {
+				return fmt.Errorf("decode nonce of MessageReceived: %w", err)
+			}
+
+			signer, ok, err := r.paraconn.FetchExtrinsicSigner(blockHash, event.ExtrinsicIndex)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			if blockTime.IsZero() {
+				blockTime, err = r.paraconn.GetBlockTimestamp(blockHash)
+				if err != nil {
+					return err
+				}
+			}
+
+			log.WithFields(log.Fields{
+				"nonce":   nonce,
+				"relayer": types.HexEncodeToString(signer[:]),
+			}).Debug("Observed message delivery")
+			r.schedule.ObserveDelivery(eventChannelID, uint64(nonce), schedule.Member(signer), blockTime)
+		}
+
+		r.lastObservedBlock = blockNumber
+	}
+
+	return nil
+}
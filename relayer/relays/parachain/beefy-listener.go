@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -15,9 +16,15 @@ import (
 	"github.com/snowfork/snowbridge/relayer/chain/ethereum"
 	"github.com/snowfork/snowbridge/relayer/chain/parachain"
 	"github.com/snowfork/snowbridge/relayer/chain/relaychain"
+	"github.com/snowfork/snowbridge/relayer/claims"
+	"github.com/snowfork/snowbridge/relayer/config"
 	"github.com/snowfork/snowbridge/relayer/contracts"
+	"github.com/snowfork/snowbridge/relayer/crypto/keccak"
 	"github.com/snowfork/snowbridge/relayer/crypto/merkle"
 	"github.com/snowfork/snowbridge/relayer/ofac"
+	"github.com/snowfork/snowbridge/relayer/operatingmode"
+	"github.com/snowfork/snowbridge/relayer/profitability"
+	"github.com/snowfork/snowbridge/relayer/schedule"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -25,33 +32,57 @@ import (
 type BeefyListener struct {
 	config              *SourceConfig
 	scheduleConfig      *ScheduleConfig
+	pipelineConfig      *PipelineConfig
 	ethereumConn        *ethereum.Connection
 	beefyClientContract *contracts.BeefyClient
 	relaychainConn      *relaychain.Connection
-	parachainConnection *parachain.Connection
+	parachainConns      []*parachain.Connection
 	ofac                *ofac.OFAC
-	paraID              uint32
+	profitability       *profitability.Policy
+	profitabilityConfig *ProfitabilityConfig
+	claims              *claims.Claims
+	operatingModeConfig *config.OperatingModeConfig
 	tasks               chan<- *Task
-	scanner             *Scanner
+	scanners            map[ChannelID]*Scanner
+	channelIDs          []ChannelID
+	mmrProofs           *mmrProofCache
+	parasHeads          *parasHeadsCache
+	schedule            *schedule.Schedule
+	// Last ethereum block observed for deliveries, by Gateway
+	lastObservedBlocks map[common.Address]uint64
+	// Whether the last scan deferred tasks which are unprofitable until their deadline
+	deferredTasks bool
 }
 
 func NewBeefyListener(
 	config *SourceConfig,
 	scheduleConfig *ScheduleConfig,
+	pipelineConfig *PipelineConfig,
 	ethereumConn *ethereum.Connection,
 	relaychainConn *relaychain.Connection,
-	parachainConnection *parachain.Connection,
+	parachainConns []*parachain.Connection,
 	ofac *ofac.OFAC,
+	profitabilityPolicy *profitability.Policy,
+	profitabilityConfig *ProfitabilityConfig,
+	claims *claims.Claims,
+	operatingModeConfig *config.OperatingModeConfig,
 	tasks chan<- *Task,
 ) *BeefyListener {
 	return &BeefyListener{
 		config:              config,
 		scheduleConfig:      scheduleConfig,
+		pipelineConfig:      pipelineConfig,
 		ethereumConn:        ethereumConn,
 		relaychainConn:      relaychainConn,
-		parachainConnection: parachainConnection,
+		parachainConns:      parachainConns,
 		ofac:                ofac,
+		profitability:       profitabilityPolicy,
+		profitabilityConfig: profitabilityConfig,
+		claims:              claims,
+		operatingModeConfig: operatingModeConfig,
 		tasks:               tasks,
+		mmrProofs:           newMMRProofCache(),
+		parasHeads:          newParasHeadsCache(),
 	}
 }
 
@@ -64,29 +95,89 @@ func (li *BeefyListener) Start(ctx context.Context, eg *errgroup.Group) error {
 	}
 	li.beefyClientContract = beefyClientContract
 
-	// fetch ParaId
-	paraIDKey, err := types.CreateStorageKey(li.parachainConnection.Metadata(), "ParachainInfo", "ParachainId", nil, nil)
-	if err != nil {
-		return err
-	}
-	var paraID uint32
-	ok, err := li.parachainConnection.API().RPC.State.GetStorageLatest(paraIDKey, &paraID)
-	if err != nil {
-		return fmt.Errorf("fetch parachain id: %w", err)
-	}
-	if !ok {
-		return fmt.Errorf("parachain id missing")
+	// Set up a scanner and operating mode watcher for each channel of each source parachain
+	li.scanners = make(map[ChannelID]*Scanner)
+	sources := li.config.sources()
+	for i := range sources {
+		source := &sources[i]
+		paraConn := li.parachainConns[i]
+
+		paraID, err := fetchParaID(paraConn)
+		if err != nil {
+			return fmt.Errorf("source parachain %v: %w", source.Parachain.Endpoint, err)
+		}
+
+		indexer := NewCommitmentIndexer(&source.Indexer, paraConn, source.ChannelIDs)
+		err = indexer.Load()
+		if err != nil {
+			return fmt.Errorf("load commitment index of parachain %v: %w", paraID, err)
+		}
+
+		var deliverTo common.Address
+		if source.Gateway != "" {
+			deliverTo = common.HexToAddress(source.Gateway)
+		}
+
+		gatewayContract, err := contracts.NewGateway(li.config.gateway(*source), li.ethereumConn.Client())
+		if err != nil {
+			return fmt.Errorf("create gateway contract of parachain %v: %w", paraID, err)
+		}
+		var channelIDs [][32]byte
+		for _, channelID := range source.ChannelIDs {
+			channelIDs = append(channelIDs, channelID)
+		}
+		// Commitments already made stay deliverable to Gateway.submitV1 while the Gateway or a channel is halted,
+		// so their operating modes are only surfaced in logs and metrics
+		operatingMode := operatingmode.New(
+			*li.operatingModeConfig,
+			li.ethereumConn,
+			gatewayContract,
+			channelIDs,
+			nil,
+			nil,
+			fmt.Sprint(paraID),
+		)
+		operatingMode.Start(ctx, eg)
+
+		for _, channelID := range source.ChannelIDs {
+			li.scanners[channelID] = &Scanner{
+				config:     li.config,
+				ethConn:    li.ethereumConn,
+				relayConn:  li.relaychainConn,
+				paraConn:   paraConn,
+				paraID:     paraID,
+				channelID:  channelID,
+				gateway:    li.config.gateway(*source),
+				deliverTo:  deliverTo,
+				ofac:       li.ofac,
+				indexer:    indexer,
+				parasHeads: li.parasHeads,
+			}
+			li.channelIDs = append(li.channelIDs, channelID)
+		}
+
+		log.WithFields(log.Fields{
+			"paraID":     paraID,
+			"endpoint":   source.Parachain.Endpoint,
+			"channelIDs": len(source.ChannelIDs),
+			"gateway":    li.config.gateway(*source).Hex(),
+		}).Info("Relaying messages from source parachain")
 	}
-	li.paraID = paraID
 
-	li.scanner = &Scanner{
-		config:    li.config,
-		ethConn:   li.ethereumConn,
-		relayConn: li.relaychainConn,
-		paraConn:  li.parachainConnection,
-		paraID:    paraID,
-		ofac:      li.ofac,
+	li.lastObservedBlocks = make(map[common.Address]uint64)
+
+	membershipSource, err := schedule.NewSource(li.scheduleConfig.Membership, li.ethereumConn.Client())
+	if err != nil {
+		return fmt.Errorf("create membership source: %w", err)
 	}
+	li.schedule = schedule.New(
+		li.scheduleConfig.ID,
+		li.scheduleConfig.TotalRelayerCount,
+		li.scheduleConfig.Membership,
+		schedule.MemberFromAddress(li.ethereumConn.Keypair().CommonAddress()),
+		membershipSource,
+	)
+	li.schedule.Start(ctx, eg)
 
 	eg.Go(func() error {
 		defer close(li.tasks)
@@ -126,12 +217,29 @@ func (li *BeefyListener) subscribeNewMMRRoots(ctx context.Context) error {
 	}
 	defer sub.Unsubscribe()
 
+	// Deferred tasks are reconsidered periodically, since their deadline can pass without a BEEFY update
+	deferredTicker := time.NewTicker(deferredTasksInterval)
+	defer deferredTicker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		case err := <-sub.Err():
 			return fmt.Errorf("header subscription: %w", err)
+		case <-deferredTicker.C:
+			if !li.deferredTasks {
+				continue
+			}
+			beefyBlockNumber, _, err := li.fetchLatestBeefyBlock(ctx)
+			if err != nil {
+				return fmt.Errorf("fetch latest beefy block: %w", err)
+			}
+			log.WithField("beefyBlockNumber", beefyBlockNumber).Info("Reconsidering deferred tasks")
+			err = li.doScan(ctx, beefyBlockNumber)
+			if err != nil {
+				return fmt.Errorf("scan for sync tasks bounded by BEEFY block %v: %w", beefyBlockNumber, err)
+			}
 		case gethheader := <-headers:
 			blockNumber := gethheader.Number.Uint64()
 			contractEvents, err := li.queryBeefyClientEvents(ctx, blockNumber, &blockNumber)
@@ -158,21 +266,85 @@ func (li *BeefyListener) subscribeNewMMRRoots(ctx context.Context) error {
 	}
 }
 
-func (li *BeefyListener) doScan(ctx context.Context, beefyBlockNumber uint64) error {
-	tasks, err := li.scanner.Scan(ctx, beefyBlockNumber)
+// fetchParaID fetches the ID of a parachain from its ParachainInfo pallet
+func fetchParaID(paraConn *parachain.Connection) (uint32, error) {
+	paraIDKey, err := types.CreateStorageKey(paraConn.Metadata(), "ParachainInfo", "ParachainId", nil, nil)
 	if err != nil {
-		return err
+		return 0, err
 	}
-	for _, task := range tasks {
-		paraNonce := (*task.MessageProofs)[0].Message.Nonce
-		waitingPeriod := (paraNonce + li.scheduleConfig.TotalRelayerCount - li.scheduleConfig.ID) % li.scheduleConfig.TotalRelayerCount
-		err = li.waitAndSend(ctx, task, waitingPeriod)
+	var paraID uint32
+	ok, err := paraConn.API().RPC.State.GetStorageLatest(paraIDKey, &paraID)
+	if err != nil {
+		return 0, fmt.Errorf("fetch parachain id: %w", err)
+	}
+	if !ok {
+		return 0, fmt.Errorf("parachain id missing")
+	}
+	return paraID, nil
+}
+
+// doScan scans all channels of all source parachains for tasks, and proves them together so that
+// tasks included in the same relay chain block share the MMR leaf proof and the paras heads tree.
+func (li *BeefyListener) doScan(ctx context.Context, beefyBlockNumber uint64) error {
+	li.observeDeliveries(ctx)
+
+	li.deferredTasks = false
+	var tasks []*Task
+	for _, channelID := range li.channelIDs {
+		scanner := li.scanners[channelID]
+
+		channelTasks, err := scanner.Scan(ctx, beefyBlockNumber)
+		if err != nil {
+			return fmt.Errorf("scan channel %#x of parachain %v: %w", channelID, scanner.paraID, err)
+		}
+
+		channelTasks, err = li.filterProfitableTasks(ctx, channelID, channelTasks)
 		if err != nil {
-			return fmt.Errorf("wait task for nonce %d: %w", paraNonce, err)
+			return fmt.Errorf("filter profitable tasks: %w", err)
 		}
+
+		tasks = append(tasks, channelTasks...)
 	}
 
-	return nil
+	// Tasks of each channel are already ordered by relay chain block, which the stable sort preserves
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].ProofInput.RelayBlockNumber < tasks[j].ProofInput.RelayBlockNumber
+	})
+
+	return li.proveTasks(ctx, tasks)
+}
+
+// proveTasks generates proofs for tasks on a bounded pool of workers and emits them to the
+// ethereum writer in nonce order. Workers may only run ahead of the writer by the size of the
+// pipeline, so a slow writer holds back proof generation.
+func (li *BeefyListener) proveTasks(ctx context.Context, tasks []*Task) error {
+	li.prefetchMMRProofs(ctx, tasks)
+
+	return runOrdered(
+		ctx,
+		len(tasks),
+		li.pipelineConfig.proofWorkers(),
+		li.pipelineConfig.queueSize(),
+		func(ctx context.Context, i int) (bool, error) {
+			task := tasks[i]
+			paraNonce := (*task.MessageProofs)[0].Message.Nonce
+			waitingPeriod := li.schedule.WaitingPeriod(paraNonce)
+			ok, err := li.waitAndProve(ctx, task, waitingPeriod)
+			if err != nil {
+				return false, fmt.Errorf("wait task for nonce %d: %w", paraNonce, err)
+			}
+			return ok, nil
+		},
+		func(ctx context.Context, i int) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case li.tasks <- tasks[i]:
+				log.Info("Beefy Listener emitted new task")
+			}
+			return nil
+		},
+	)
 }
 
 // queryBeefyClientEvents queries ContractNewMMRRoot events from the BeefyClient contract
@@ -240,23 +412,13 @@ func (li *BeefyListener) generateProof(ctx context.Context, input *ProofInput, h
 	}).Info("Generating MMR proof")
 
 	// Generate the MMR proof for the polkadot block.
-	mmrProof, err := li.relaychainConn.GenerateProofForBlock(
+	simplifiedProof, err := li.generateMMRLeafProof(
 		input.RelayBlockNumber+1,
+		latestBeefyBlockNumber,
 		latestBeefyBlockHash,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("generate MMR leaf proof: %w", err)
-	}
-
-	simplifiedProof, err := merkle.ConvertToSimplifiedMMRProof(
-		mmrProof.BlockHash,
-		uint64(mmrProof.Proof.LeafIndex),
-		mmrProof.Leaf,
-		uint64(mmrProof.Proof.LeafCount),
-		mmrProof.Proof.Items,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("simplify MMR leaf proof: %w", err)
+		return nil, err
 	}
 
 	mmrRootHash, err := li.relaychainConn.GetMMRRootHash(latestBeefyBlockHash)
@@ -265,7 +427,7 @@ func (li *BeefyListener) generateProof(ctx context.Context, input *ProofInput, h
 	}
 
 	var merkleProofData *MerkleProofData
-	merkleProofData, input.ParaHeads, err = li.generateAndValidateParasHeadsMerkleProof(input, &mmrProof)
+	merkleProofData, input.ParaHeads, err = li.generateAndValidateParasHeadsMerkleProof(input, &simplifiedProof.Leaf)
 	if err != nil {
 		return nil, err
 	}
@@ -282,28 +444,187 @@ func (li *BeefyListener) generateProof(ctx context.Context, input *ProofInput, h
 	return &output, nil
 }
 
-// Generate a merkle proof for the parachain head with input ParaId and verify with merkle root hash of all parachain heads
-func (li *BeefyListener) generateAndValidateParasHeadsMerkleProof(input *ProofInput, mmrProof *types.GenerateMMRProofResponse) (*MerkleProofData, []relaychain.ParaHead, error) {
+// generateMMRLeafProof returns the simplified MMR proof for a leaf at the given BEEFY block, from
+// the cache if it was generated as part of a batch
+func (li *BeefyListener) generateMMRLeafProof(
+	blockNumber uint64,
+	beefyBlockNumber uint64,
+	beefyBlockHash types.Hash,
+) (merkle.SimplifiedMMRProof, error) {
+	if proof, ok := li.mmrProofs.get(blockNumber, beefyBlockHash); ok {
+		log.WithFields(log.Fields{
+			"beefyBlock":  beefyBlockNumber,
+			"blockNumber": blockNumber,
+		}).Debug("Using cached MMR proof")
+		return proof, nil
+	}
+
+	mmrProof, err := li.relaychainConn.GenerateProofForBlock(blockNumber, beefyBlockHash)
+	if err != nil {
+		return merkle.SimplifiedMMRProof{}, fmt.Errorf("generate MMR leaf proof: %w", err)
+	}
+
+	proof, err := merkle.ConvertToSimplifiedMMRProof(
+		mmrProof.BlockHash,
+		uint64(mmrProof.Proof.LeafIndex),
+		mmrProof.Leaf,
+		uint64(mmrProof.Proof.LeafCount),
+		mmrProof.Proof.Items,
+	)
+	if err != nil {
+		return merkle.SimplifiedMMRProof{}, fmt.Errorf("simplify MMR leaf proof: %w", err)
+	}
+	li.mmrProofs.add(blockNumber, beefyBlockNumber, beefyBlockHash, proof)
+
+	return proof, nil
+}
+
+// prefetchMMRProofs generates the MMR proofs of all tasks against the latest BEEFY block in
+// batches, so that tasks proven before the light client advances do not each need a separate
+// mmr_generateProof call. Failures are not fatal, since proofs missing from the cache are
+// generated individually.
+func (li *BeefyListener) prefetchMMRProofs(ctx context.Context, tasks []*Task) {
+	if len(tasks) < 2 {
+		return
+	}
+
+	beefyBlockNumber, beefyBlockHash, err := li.fetchLatestBeefyBlock(ctx)
+	if err != nil {
+		log.WithError(err).Warn("Failed to fetch latest beefy block for MMR batch proof")
+		return
+	}
+
+	seen := make(map[uint64]bool)
+	var blockNumbers []uint64
+	for _, task := range tasks {
+		blockNumber := task.ProofInput.RelayBlockNumber + 1
+		if seen[blockNumber] {
+			continue
+		}
+		seen[blockNumber] = true
+		if _, ok := li.mmrProofs.get(blockNumber, beefyBlockHash); ok {
+			continue
+		}
+		blockNumbers = append(blockNumbers, blockNumber)
+	}
+	sort.Slice(blockNumbers, func(i, j int) bool { return blockNumbers[i] < blockNumbers[j] })
+
+	mmrRoot, err := li.relaychainConn.GetMMRRootHash(beefyBlockHash)
+	if err != nil {
+		log.WithError(err).Warn("Failed to fetch MMR root for MMR batch proof")
+		return
+	}
+
+	for start := 0; start < len(blockNumbers); start += maxMMRBatchSize {
+		batch := blockNumbers[start:min(start+maxMMRBatchSize, len(blockNumbers))]
+		err := li.prefetchMMRProofBatch(batch, beefyBlockNumber, beefyBlockHash, mmrRoot)
+		if err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"beefyBlock":   beefyBlockNumber,
+				"blockNumbers": batch,
+			}).Warn("Failed to generate MMR batch proof")
+		}
+	}
+}
+
+func (li *BeefyListener) prefetchMMRProofBatch(
+	blockNumbers []uint64,
+	beefyBlockNumber uint64,
+	beefyBlockHash types.Hash,
+	mmrRoot types.Hash,
+) error {
+	response, err := li.relaychainConn.GenerateProofForBlocks(blockNumbers, beefyBlockHash)
+	if err != nil {
+		return fmt.Errorf("generate MMR batch proof: %w", err)
+	}
+	if len(response.Leaves) != len(blockNumbers) {
+		return fmt.Errorf("got %d leaves for %d blocks", len(response.Leaves), len(blockNumbers))
+	}
+
+	mmrLeafIndices := make([]uint64, len(response.Proof.LeafIndices))
+	for i, leafIndex := range response.Proof.LeafIndices {
+		mmrLeafIndices[i] = uint64(leafIndex)
+	}
+
+	proofs, err := merkle.ConvertToSimplifiedMMRProofs(
+		response.BlockHash,
+		mmrLeafIndices,
+		response.Leaves,
+		uint64(response.Proof.LeafCount),
+		response.Proof.Items,
+	)
+	if err != nil {
+		return fmt.Errorf("simplify MMR batch proof: %w", err)
+	}
+
+	for i, proof := range proofs {
+		encodedLeaf, err := types.EncodeToBytes(proof.Leaf)
+		if err != nil {
+			return fmt.Errorf("encode MMR leaf: %w", err)
+		}
+		// Leaves are returned in block order, the leaf of a block commits to its parent
+		if uint64(proof.Leaf.ParentNumberAndHash.ParentNumber)+1 != blockNumbers[i] {
+			return fmt.Errorf("MMR leaf %d has parent block %d, expected the leaf of block %d", mmrLeafIndices[i], proof.Leaf.ParentNumberAndHash.ParentNumber, blockNumbers[i])
+		}
+		leafHash := types.NewH256((&keccak.Keccak256{}).Hash(encodedLeaf))
+		if merkle.CalculateMerkleRoot(&proof, leafHash) != types.H256(mmrRoot) {
+			return fmt.Errorf("simplified proof for leaf %d does not match MMR root %s", mmrLeafIndices[i], mmrRoot.Hex())
+		}
+		li.mmrProofs.add(blockNumbers[i], beefyBlockNumber, beefyBlockHash, proof)
+	}
+
+	log.WithFields(log.Fields{
+		"beefyBlock": beefyBlockNumber,
+		"leafCount":  len(blockNumbers),
+	}).Info("Generated MMR batch proof")
+
+	return nil
+}
+
+// Generate a merkle proof for the parachain head with input ParaId and verify with merkle root hash of all parachain heads.
+// The merkle tree is built once per relay chain block and shared by the tasks of all source parachains.
+func (li *BeefyListener) generateAndValidateParasHeadsMerkleProof(input *ProofInput, mmrLeaf *types.MMRLeaf) (*MerkleProofData, []relaychain.ParaHead, error) {
+	tree, paraHeads, ok := li.parasHeads.tree(input.RelayBlockHash)
+	if !ok {
+		var err error
+		tree, paraHeads, err = li.buildParasHeadsMerkleTree(input, mmrLeaf)
+		if err != nil {
+			return nil, input.ParaHeads, err
+		}
+		li.parasHeads.addTree(input.RelayBlockNumber, input.RelayBlockHash, paraHeads, tree)
+	}
+
+	merkleProofData, err := tree.Prove(input.ParaID)
+	if err != nil {
+		return nil, paraHeads, fmt.Errorf("create parachain header proof: %w", err)
+	}
+	return &merkleProofData, paraHeads, nil
+}
+
+// buildParasHeadsMerkleTree builds the merkle tree over the paras heads of a relay chain block,
+// and checks that its root matches the one in the MMR leaf.
+func (li *BeefyListener) buildParasHeadsMerkleTree(input *ProofInput, mmrLeaf *types.MMRLeaf) (*ParachainMerkleTree, []relaychain.ParaHead, error) {
 	// Polkadot uses the following code to generate merkle root from parachain headers:
 	// https://github.com/paritytech/polkadot-sdk/blob/d66dee3c3da836bcf41a12ca4e1191faee0b6a5b/polkadot/runtime/westend/src/lib.rs#L453-L460
 	// Truncate the ParaHeads to the 1024
 	// https://github.com/paritytech/polkadot-sdk/blob/d66dee3c3da836bcf41a12ca4e1191faee0b6a5b/polkadot/runtime/parachains/src/paras/mod.rs#L1305-L1311
-	paraHeads := input.ParaHeads
+	// The heads may be shared with other tasks, so the tree is built from a copy.
+	paraHeads := append([]relaychain.ParaHead(nil), input.ParaHeads...)
 	numParas := min(MaxParaHeads, len(paraHeads))
-	merkleProofData, err := CreateParachainMerkleProof(paraHeads[:numParas], input.ParaID)
+	tree, err := NewParachainMerkleTree(paraHeads[:numParas])
 	if err != nil {
 		return nil, paraHeads, fmt.Errorf("create parachain header proof: %w", err)
 	}
 
 	// Verify merkle root generated is same as value generated in relaychain and if so exit early
-	if merkleProofData.Root.Hex() == mmrProof.Leaf.ParachainHeads.Hex() {
-		return &merkleProofData, paraHeads, nil
+	if tree.Root().Hex() == mmrLeaf.ParachainHeads.Hex() {
+		return tree, paraHeads, nil
 	}
 
 	// Try a filtering out parathreads
 	log.WithFields(log.Fields{
-		"computedMmr": merkleProofData.Root.Hex(),
-		"mmr":         mmrProof.Leaf.ParachainHeads.Hex(),
+		"computedMmr": tree.Root().Hex(),
+		"mmr":         mmrLeaf.ParachainHeads.Hex(),
 	}).Warn("MMR parachain merkle root does not match calculated merkle root. Trying to filtering out parathreads.")
 
 	paraHeads, err = li.relaychainConn.FilterParachainHeads(paraHeads, input.RelayBlockHash)
@@ -312,49 +633,54 @@ func (li *BeefyListener) generateAndValidateParasHeadsMerkleProof(input *ProofIn
 	}
 
 	numParas = min(MaxParaHeads, len(paraHeads))
-	merkleProofData, err = CreateParachainMerkleProof(paraHeads[:numParas], input.ParaID)
+	tree, err = NewParachainMerkleTree(paraHeads[:numParas])
 	if err != nil {
 		return nil, paraHeads, fmt.Errorf("create parachain header proof: %w", err)
 	}
-	if merkleProofData.Root.Hex() != mmrProof.Leaf.ParachainHeads.Hex() {
+	if tree.Root().Hex() != mmrLeaf.ParachainHeads.Hex() {
 		return nil, paraHeads, fmt.Errorf("MMR parachain merkle root does not match calculated parachain merkle root (mmr: %s, computed: %s)",
-			mmrProof.Leaf.ParachainHeads.Hex(),
-			merkleProofData.Root.String(),
+			mmrLeaf.ParachainHeads.Hex(),
+			tree.Root().String(),
 		)
 	}
-	return &merkleProofData, paraHeads, nil
+	return tree, paraHeads, nil
 }
 
-func (li *BeefyListener) waitAndSend(ctx context.Context, task *Task, waitingPeriod uint64) error {
+// waitAndProve waits for the task to be picked up by another relayer according to the schedule,
+// and otherwise generates its proof. It returns false if the task was relayed by another relayer.
+func (li *BeefyListener) waitAndProve(ctx context.Context, task *Task, waitingPeriod uint64) (bool, error) {
 	paraNonce := (*task.MessageProofs)[0].Message.Nonce
+	channelID := ChannelID((*task.MessageProofs)[0].Message.ChannelID)
 	log.Info(fmt.Sprintf("waiting for nonce %d to be picked up by another relayer", paraNonce))
 	var cnt uint64
 	var err error
 	for {
-		ethInboundNonce, err := li.scanner.findLatestNonce(ctx)
+		ethInboundNonce, err := li.scanners[channelID].findLatestNonce(ctx)
 		if err != nil {
-			return err
+			return false, err
 		}
 		if ethInboundNonce >= paraNonce {
 			log.Info(fmt.Sprintf("nonce %d picked up by another relayer, just skip", paraNonce))
-			return nil
+			return false, nil
 		}
-		if cnt == waitingPeriod {
-			break
+		if cnt >= waitingPeriod {
+			if li.claims.TryClaim(ctx, channelID, paraNonce) {
+				break
+			}
+			log.Info(fmt.Sprintf("nonce %d claimed by another relayer, backing off", paraNonce))
+		}
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(time.Duration(li.scheduleConfig.SleepInterval) * time.Second):
 		}
-		time.Sleep(time.Duration(li.scheduleConfig.SleepInterval) * time.Second)
 		cnt++
 	}
 	log.Info(fmt.Sprintf("nonce %d is not picked up by any one, submit anyway", paraNonce))
 	task.ProofOutput, err = li.generateProof(ctx, task.ProofInput, task.Header)
 	if err != nil {
-		return err
+		li.claims.Release(ctx, channelID, paraNonce)
+		return false, err
 	}
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	case li.tasks <- task:
-		log.Info("Beefy Listener emitted new task")
-	}
-	return nil
+	return true, nil
 }
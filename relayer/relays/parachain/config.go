@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/snowfork/snowbridge/relayer/config"
 )
 
@@ -11,7 +12,14 @@ type Config struct {
 	Source   SourceConfig      `mapstructure:"source"`
 	Sink     SinkConfig        `mapstructure:"sink"`
 	Schedule ScheduleConfig    `mapstructure:"schedule"`
+	Pipeline PipelineConfig    `mapstructure:"pipeline"`
 	OFAC     config.OFACConfig `mapstructure:"ofac"`
+	// Economic policy for deferring unprofitable messages
+	Profitability ProfitabilityConfig `mapstructure:"profitability"`
+	// Claims on nonces exchanged with peers
+	Claims        config.ClaimsConfig        `mapstructure:"claims"`
+	OperatingMode config.OperatingModeConfig `mapstructure:"operating-mode"`
+	Metrics       config.MetricsConfig       `mapstructure:"metrics"`
 }
 
 type SourceConfig struct {
@@ -20,6 +28,107 @@ type SourceConfig struct {
 	Ethereum  config.EthereumConfig  `mapstructure:"ethereum"`
 	Contracts SourceContractsConfig  `mapstructure:"contracts"`
 	ChannelID ChannelID              `mapstructure:"channel-id"`
+	Indexer   IndexerConfig          `mapstructure:"indexer"`
+	// Further source parachains, each with its own endpoint and channels
+	Parachains []ParachainSourceConfig `mapstructure:"parachains"`
+}
+
+type ParachainSourceConfig struct {
+	Parachain  config.ParachainConfig `mapstructure:"parachain"`
+	ChannelIDs []ChannelID            `mapstructure:"channel-ids"`
+	Indexer    IndexerConfig          `mapstructure:"indexer"`
+	// Gateway to which the messages of the parachain are delivered. A Gateway only accepts heads
+	// of the parachain it was deployed for, so each further parachain needs its own Gateway. The
+	// parachain set up by the top-level settings uses the source and sink Gateway.
+	Gateway string `mapstructure:"gateway"`
+}
+
+// sources returns the configured source parachains. The parachain set up by the top-level
+// parachain and channel-id settings comes first, if any.
+func (c SourceConfig) sources() []ParachainSourceConfig {
+	var sources []ParachainSourceConfig
+	if c.Parachain.Endpoint != "" || c.ChannelID != [32]byte{} {
+		sources = append(sources, ParachainSourceConfig{
+			Parachain:  c.Parachain,
+			ChannelIDs: []ChannelID{c.ChannelID},
+			Indexer:    c.Indexer,
+		})
+	}
+	return append(sources, c.Parachains...)
+}
+
+// gateway returns the Gateway to which the messages of a source parachain are delivered
+func (c SourceConfig) gateway(source ParachainSourceConfig) common.Address {
+	if source.Gateway == "" {
+		return common.HexToAddress(c.Contracts.Gateway)
+	}
+	return common.HexToAddress(source.Gateway)
+}
+
+// gatewayChannelIDs returns the channels of the source parachains by the Gateway their messages
+// are delivered to
+func (c SourceConfig) gatewayChannelIDs() map[common.Address][][32]byte {
+	channelIDs := make(map[common.Address][][32]byte)
+	for _, source := range c.sources() {
+		gateway := c.gateway(source)
+		for _, channelID := range source.ChannelIDs {
+			channelIDs[gateway] = append(channelIDs[gateway], channelID)
+		}
+	}
+	return channelIDs
+}
+
+func (c SourceConfig) validateParachains() error {
+	sources := c.sources()
+	if len(sources) == 0 {
+		return fmt.Errorf("no source parachain is configured")
+	}
+
+	channels := make(map[ChannelID]bool)
+	gateways := make(map[common.Address]bool)
+	for i, source := range sources {
+		err := source.Parachain.Validate()
+		if err != nil {
+			return fmt.Errorf("source parachain %d config: %w", i, err)
+		}
+		// Messages of further parachains cannot be verified by the Gateway of the first one
+		if i > 0 && source.Gateway == "" {
+			return fmt.Errorf("source parachain %d setting [gateway] is not set", i)
+		}
+		gateway := c.gateway(source)
+		if gateways[gateway] {
+			return fmt.Errorf("source parachain %d shares its Gateway with another parachain", i)
+		}
+		gateways[gateway] = true
+		if len(source.ChannelIDs) == 0 {
+			return fmt.Errorf("source parachain %d setting [channel-ids] is not set", i)
+		}
+		for _, channelID := range source.ChannelIDs {
+			if channelID == [32]byte{} {
+				return fmt.Errorf("source parachain %d setting [channel-id] is not set", i)
+			}
+			if channels[channelID] {
+				return fmt.Errorf("source channel %#x is configured more than once", channelID)
+			}
+			channels[channelID] = true
+		}
+		if source.Indexer.Path != "" {
+			for _, other := range sources[:i] {
+				if other.Indexer.Path == source.Indexer.Path {
+					return fmt.Errorf("source parachain %d setting [indexer.path] is shared with another parachain", i)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+type IndexerConfig struct {
+	// File in which the commitment index is persisted. The index is only kept in memory if unset.
+	Path string `mapstructure:"path"`
+	// Number of parachain blocks fetched concurrently while indexing, defaults to 16
+	Concurrency uint64 `mapstructure:"concurrency"`
 }
 
 type SourceContractsConfig struct {
@@ -43,6 +152,8 @@ type ScheduleConfig struct {
 	TotalRelayerCount uint64 `mapstructure:"totalRelayerCount"`
 	// Sleep interval(in seconds) to check if message(nonce) has already been relayed
 	SleepInterval uint64 `mapstructure:"sleepInterval"`
+	// Source of a dynamic relayer set, replacing ID and TotalRelayerCount once loaded
+	Membership config.MembershipConfig `mapstructure:"membership"`
 }
 
 func (r ScheduleConfig) Validate() error {
@@ -52,9 +163,42 @@ func (r ScheduleConfig) Validate() error {
 	if r.ID >= r.TotalRelayerCount {
 		return errors.New("ID of the Number of relayer is not set")
 	}
+	err := r.Membership.Validate()
+	if err != nil {
+		return fmt.Errorf("membership config: %w", err)
+	}
 	return nil
 }
 
+type PipelineConfig struct {
+	// Number of tasks for which proofs are generated concurrently, defaults to 1
+	ProofWorkers uint64 `mapstructure:"proof-workers"`
+	// Number of proven tasks which may be queued ahead of the ethereum writer, defaults to 1
+	QueueSize uint64 `mapstructure:"queue-size"`
+	// Number of Gateway.submit transactions which may await inclusion at once, defaults to 1.
+	// Submitting more than one transaction at a time requires a fixed sink gas limit, since
+	// gas cannot be estimated for messages whose predecessors are still pending.
+	MaxPendingSubmissions uint64 `mapstructure:"max-pending-submissions"`
+}
+
+func (p PipelineConfig) proofWorkers() int {
+	return int(max(p.ProofWorkers, 1))
+}
+
+func (p PipelineConfig) queueSize() int {
+	return int(max(p.QueueSize, 1))
+}
+
+func (p PipelineConfig) maxPendingSubmissions() int {
+	return int(max(p.MaxPendingSubmissions, 1))
+}
+
+type ProfitabilityConfig struct {
+	config.ProfitabilityConfig `mapstructure:",squash"`
+	// Gas used by Gateway.submitV1 besides the dispatch gas of a message, defaults to 200000
+	GasOverhead uint64 `mapstructure:"gas-overhead"`
+}
+
 type ChannelID [32]byte
 
 func (c Config) Validate() error {
@@ -63,9 +207,9 @@ func (c Config) Validate() error {
 	if err != nil {
 		return fmt.Errorf("source polkadot config: %w", err)
 	}
-	err = c.Source.Parachain.Validate()
+	err = c.Source.validateParachains()
 	if err != nil {
-		return fmt.Errorf("source parachain config: %w", err)
+		return err
 	}
 	err = c.Source.Ethereum.Validate()
 	if err != nil {
@@ -77,9 +221,6 @@ func (c Config) Validate() error {
 	if c.Source.Contracts.Gateway == "" {
 		return fmt.Errorf("source contracts setting [Gateway] is not set")
 	}
-	if c.Source.ChannelID == [32]byte{} {
-		return fmt.Errorf("source setting [channel-id] is not set")
-	}
 
 	// Sink
 	err = c.Sink.Ethereum.Validate()
@@ -90,6 +231,10 @@ func (c Config) Validate() error {
 		return fmt.Errorf("sink contracts setting [Gateway] is not set")
 	}
 
+	if c.Pipeline.MaxPendingSubmissions > 1 && c.Sink.Ethereum.GasLimit == 0 {
+		return fmt.Errorf("pipeline setting [max-pending-submissions] requires sink ethereum setting [gas-limit]")
+	}
+
 	// Relay
 	err = c.Schedule.Validate()
 	if err != nil {
@@ -99,6 +244,14 @@ func (c Config) Validate() error {
 	if err != nil {
 		return fmt.Errorf("ofac config: %w", err)
 	}
+	err = c.Profitability.Validate()
+	if err != nil {
+		return fmt.Errorf("profitability config: %w", err)
+	}
+	err = c.Claims.Validate()
+	if err != nil {
+		return fmt.Errorf("claims config: %w", err)
+	}
 
 	return nil
 }
@@ -0,0 +1,34 @@
+package parachain
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/snowfork/snowbridge/relayer/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateParachainsGateway(t *testing.T) {
+	parachain := config.ParachainConfig{Endpoint: "ws://127.0.0.1:11144", MaxWatchedExtrinsics: 8}
+	source := SourceConfig{
+		Parachain: parachain,
+		ChannelID: ChannelID{1},
+		Contracts: SourceContractsConfig{Gateway: "0x8f86403a4de0bb5791fa46b8e795c547942fe4cf"},
+		Parachains: []ParachainSourceConfig{
+			{Parachain: parachain, ChannelIDs: []ChannelID{{2}}},
+		},
+	}
+
+	// Further parachains need their own Gateway
+	assert.Error(t, source.validateParachains())
+	source.Parachains[0].Gateway = source.Contracts.Gateway
+	assert.Error(t, source.validateParachains())
+
+	other := "0x00000000000000000000000000000000000000aa"
+	source.Parachains[0].Gateway = other
+	assert.NoError(t, source.validateParachains())
+	assert.Equal(t, map[common.Address][][32]byte{
+		common.HexToAddress(source.Contracts.Gateway): {{1}},
+		common.HexToAddress(other):                    {{2}},
+	}, source.gatewayChannelIDs())
+}
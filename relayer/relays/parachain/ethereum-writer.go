@@ -7,14 +7,17 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 
 	"github.com/snowfork/snowbridge/relayer/chain/ethereum"
+	"github.com/snowfork/snowbridge/relayer/claims"
 	"github.com/snowfork/snowbridge/relayer/contracts"
 	"github.com/snowfork/snowbridge/relayer/crypto/keccak"
 
@@ -23,24 +26,49 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// errStaleProof is returned when the proofs of a message are against an MMR root which the BEEFY
+// light client has since replaced, so that the Gateway would reject them
+var errStaleProof = errors.New("stale proof")
+
 type EthereumWriter struct {
-	config     *SinkConfig
-	conn       *ethereum.Connection
-	gateway    *contracts.Gateway
-	tasks      <-chan *Task
-	gatewayABI abi.ABI
+	config         *SinkConfig
+	pipelineConfig *PipelineConfig
+	conn           *ethereum.Connection
+	gateway        *contracts.Gateway
+	// Gateways of further source parachains, by address
+	gateways           map[common.Address]*contracts.Gateway
+	beefyClientAddress string
+	beefyClient        *contracts.BeefyClient
+	tasks              <-chan *Task
+	claims             *claims.Claims
+	gatewayABI         abi.ABI
+	// Set once the node turns out not to expose pending transactions
+	txpoolUnavailable bool
+	// Messages delivered by pending transactions of other relayers, as of pendingFetchedAt
+	pendingDeliveries map[messageKey]pendingDelivery
+	pendingFetchedAt  time.Time
+	// Number of times each message was deferred to a pending transaction of another relayer
+	pendingDeferrals map[messageKey]int
 }
 
 func NewEthereumWriter(
 	config *SinkConfig,
+	pipelineConfig *PipelineConfig,
 	conn *ethereum.Connection,
+	beefyClientAddress string,
+	nonceClaims *claims.Claims,
 	tasks <-chan *Task,
 ) (*EthereumWriter, error) {
 	return &EthereumWriter{
-		config:  config,
-		conn:    conn,
-		gateway: nil,
-		tasks:   tasks,
+		config:             config,
+		pipelineConfig:     pipelineConfig,
+		conn:               conn,
+		gateway:            nil,
+		gateways:           make(map[common.Address]*contracts.Gateway),
+		beefyClientAddress: beefyClientAddress,
+		beefyClient:        nil,
+		tasks:              tasks,
+		claims:             nonceClaims,
 	}, nil
 }
 
@@ -52,6 +80,12 @@ func (wr *EthereumWriter) Start(ctx context.Context, eg *errgroup.Group) error {
 	}
 	wr.gateway = gateway
 
+	beefyClient, err := contracts.NewBeefyClient(common.HexToAddress(wr.beefyClientAddress), wr.conn.Client())
+	if err != nil {
+		return err
+	}
+	wr.beefyClient = beefyClient
+
 	gatewayABI, err := abi.JSON(strings.NewReader(contracts.GatewayABI))
 	if err != nil {
 		return err
@@ -72,22 +106,87 @@ func (wr *EthereumWriter) Start(ctx context.Context, eg *errgroup.Group) error {
 	return nil
 }
 
+// writeMessagesLoop submits the messages of each task in order. Receipts are checked in
+// submission order by a separate goroutine, so that new tasks keep being accepted while earlier
+// submissions await inclusion. Up to the configured number of submissions may be pending at once.
+// Claims on the nonces of messages are released once their submissions are settled.
 func (wr *EthereumWriter) writeMessagesLoop(ctx context.Context) error {
 	options := wr.conn.MakeTxOpts(ctx)
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case task, ok := <-wr.tasks:
+	maxPending := wr.pipelineConfig.maxPendingSubmissions()
+	// Submitted transactions, in submission order
+	pending := make(chan submission, maxPending)
+	// Acquired before a submission and released once its receipt has been checked
+	slots := make(chan struct{}, maxPending)
+
+	eg, ctx := errgroup.WithContext(ctx)
+
+	eg.Go(func() error {
+		for sub := range pending {
+			err := wr.awaitSubmission(ctx, sub.tx)
+			wr.claims.Release(ctx, sub.message.ChannelID, sub.message.Nonce)
+			if err != nil {
+				return fmt.Errorf("write message: %w", err)
+			}
+			<-slots
+		}
+		return nil
+	})
+
+	eg.Go(func() error {
+		defer close(pending)
+		for {
+			var task *Task
+			var ok bool
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case task, ok = <-wr.tasks:
+			}
 			if !ok {
 				return nil
 			}
-			err := wr.WriteChannels(ctx, options, task)
-			if err != nil {
-				return fmt.Errorf("write message: %w", err)
+
+			for _, proof := range *task.MessageProofs {
+				// Blocks once the maximum number of submissions are pending
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case slots <- struct{}{}:
+				}
+
+				tx, err := wr.submitChannel(ctx, options, task.Gateway, task.ProofInput.ParaID, &proof, task.ProofOutput)
+				if errors.Is(err, errStaleProof) {
+					// The next scan generates the task again with proofs against the latest MMR root
+					log.WithError(err).WithFields(log.Fields{
+						"channelID": Hex(proof.Message.ChannelID[:]),
+						"nonce":     proof.Message.Nonce,
+					}).Info("Dropping task with proofs against an outdated MMR root")
+					wr.claims.Release(ctx, proof.Message.ChannelID, proof.Message.Nonce)
+					<-slots
+					break
+				}
+				if err != nil {
+					wr.claims.Release(ctx, proof.Message.ChannelID, proof.Message.Nonce)
+					return fmt.Errorf("write message: write eth gateway: %w", err)
+				}
+				if tx == nil {
+					// Later messages of the task cannot be delivered before this one
+					wr.claims.Release(ctx, proof.Message.ChannelID, proof.Message.Nonce)
+					<-slots
+					break
+				}
+				pending <- submission{tx: tx, message: proof.Message}
 			}
 		}
-	}
+	})
+
+	return eg.Wait()
+}
+
+// submission is a submitted transaction delivering a message
+type submission struct {
+	tx      *types.Transaction
+	message OutboundQueueMessage
 }
 
 func (wr *EthereumWriter) WriteChannels(
@@ -96,7 +195,7 @@ func (wr *EthereumWriter) WriteChannels(
 	task *Task,
 ) error {
 	for _, proof := range *task.MessageProofs {
-		err := wr.WriteChannel(ctx, options, &proof, task.ProofOutput)
+		err := wr.WriteChannel(ctx, options, task.Gateway, task.ProofInput.ParaID, &proof, task.ProofOutput)
 		if err != nil {
 			return fmt.Errorf("write eth gateway: %w", err)
 		}
@@ -109,14 +208,143 @@ func (wr *EthereumWriter) WriteChannels(
 func (wr *EthereumWriter) WriteChannel(
 	ctx context.Context,
 	options *bind.TransactOpts,
+	gateway common.Address,
+	paraID uint32,
 	commitmentProof *MessageProof,
 	proof *ProofOutput,
 ) error {
+	tx, err := wr.submitChannel(ctx, options, gateway, paraID, commitmentProof, proof)
+	if err != nil {
+		return err
+	}
+	if tx == nil {
+		return nil
+	}
+
+	return wr.awaitSubmission(ctx, tx)
+}
+
+// submitChannel sends a Gateway.submit transaction to the given Gateway, or the sink Gateway if
+// zero, without waiting for it to be included. The proofs are verified before sending, and
+// errStaleProof is returned if the BEEFY light client no longer has the MMR root they were
+// generated for. No transaction is sent, and nil returned, if another relayer's transaction
+// delivering the message is pending.
+func (wr *EthereumWriter) submitChannel(
+	ctx context.Context,
+	options *bind.TransactOpts,
+	gatewayAddress common.Address,
+	paraID uint32,
+	commitmentProof *MessageProof,
+	proof *ProofOutput,
+) (*types.Transaction, error) {
 	message := commitmentProof.Message.IntoInboundMessage()
 
+	verificationProof, err := buildVerificationProof(proof)
+	if err != nil {
+		return nil, err
+	}
+
+	err = wr.verifyMessage(ctx, message, commitmentProof.Proof.InnerHashes, verificationProof, paraID, proof.MMRRootHash)
+	if err != nil {
+		return nil, err
+	}
+
+	gateway, err := wr.gatewayAt(gatewayAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if wr.isMessagePending(ctx, gatewayAddress, message) {
+		return nil, nil
+	}
+
+	tx, err := gateway.SubmitV1(
+		options, message, commitmentProof.Proof.InnerHashes, *verificationProof,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("send transaction Gateway.submit: %w", ethereum.DecodeRevertError(err))
+	}
+
+	hasher := &keccak.Keccak256{}
+	mmrLeafEncoded, err := gsrpcTypes.EncodeToBytes(proof.MMRProof.Leaf)
+	if err != nil {
+		return nil, fmt.Errorf("encode MMRLeaf: %w", err)
+	}
+	log.WithField("txHash", tx.Hash().Hex()).
+		WithField("params", wr.logFieldsForSubmission(message, commitmentProof.Proof.InnerHashes, *verificationProof)).
+		WithFields(log.Fields{
+			"commitmentHash":       commitmentProof.Proof.Root.Hex(),
+			"MMRRoot":              proof.MMRRootHash.Hex(),
+			"MMRLeafHash":          Hex(hasher.Hash(mmrLeafEncoded)),
+			"merkleProofData":      proof.MerkleProofData,
+			"parachainBlockNumber": proof.Header.Number,
+			"beefyBlock":           proof.MMRProof.Blockhash.Hex(),
+			"header":               proof.Header,
+		}).
+		Info("Sent transaction Gateway.submit")
+
+	return tx, nil
+}
+
+// gatewayAt returns the Gateway at an address, or the sink Gateway if the address is zero
+func (wr *EthereumWriter) gatewayAt(address common.Address) (*contracts.Gateway, error) {
+	if address == (common.Address{}) {
+		return wr.gateway, nil
+	}
+	gateway, ok := wr.gateways[address]
+	if ok {
+		return gateway, nil
+	}
+
+	gateway, err := contracts.NewGateway(address, wr.conn.Client())
+	if err != nil {
+		return nil, fmt.Errorf("create gateway contract for address '%v': %w", address, err)
+	}
+	wr.gateways[address] = gateway
+	return gateway, nil
+}
+
+// verifyMessage checks the proofs of a message locally, so that proofs which the Gateway would
+// reject are not submitted. The proofs are checked against the MMR root they were generated for,
+// and errStaleProof is returned if the BEEFY light client has since accepted a newer MMR root.
+func (wr *EthereumWriter) verifyMessage(
+	ctx context.Context,
+	message contracts.InboundMessage,
+	leafProof [][32]byte,
+	verificationProof *contracts.VerificationProof,
+	paraID uint32,
+	mmrRoot gsrpcTypes.Hash,
+) error {
+	err := VerifyMessage(message, leafProof, verificationProof, paraID, gsrpcTypes.H256(mmrRoot))
+	if err != nil {
+		log.WithError(err).WithFields(log.Fields{
+			"channelID": Hex(message.ChannelID[:]),
+			"nonce":     message.Nonce,
+			"MMRRoot":   mmrRoot.Hex(),
+		}).Error("Message proof failed local verification")
+		return fmt.Errorf("verify message proof: %w", err)
+	}
+
+	latestMMRRoot, err := wr.beefyClient.LatestMMRRoot(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return fmt.Errorf("fetch BeefyClient.latestMMRRoot: %w", err)
+	}
+	if gsrpcTypes.Hash(latestMMRRoot) != mmrRoot {
+		return fmt.Errorf(
+			"%w: proof is against MMR root %s, latest MMR root is %s",
+			errStaleProof, mmrRoot.Hex(), Hex(latestMMRRoot[:]),
+		)
+	}
+
+	return nil
+}
+
+// buildVerificationProof converts a parachain header inclusion proof into the form accepted by
+// Gateway.submit
+func buildVerificationProof(proof *ProofOutput) (*contracts.VerificationProof, error) {
 	convertedHeader, err := convertHeader(proof.Header)
 	if err != nil {
-		return fmt.Errorf("convert header: %w", err)
+		return nil, fmt.Errorf("convert header: %w", err)
 	}
 
 	var merkleProofItems [][32]byte
@@ -143,31 +371,11 @@ func (wr *EthereumWriter) WriteChannel(
 		LeafProofOrder: new(big.Int).SetUint64(proof.MMRProof.MerkleProofOrder),
 	}
 
-	tx, err := wr.gateway.SubmitV1(
-		options, message, commitmentProof.Proof.InnerHashes, verificationProof,
-	)
-	if err != nil {
-		return fmt.Errorf("send transaction Gateway.submit: %w", err)
-	}
-
-	hasher := &keccak.Keccak256{}
-	mmrLeafEncoded, err := gsrpcTypes.EncodeToBytes(proof.MMRProof.Leaf)
-	if err != nil {
-		return fmt.Errorf("encode MMRLeaf: %w", err)
-	}
-	log.WithField("txHash", tx.Hash().Hex()).
-		WithField("params", wr.logFieldsForSubmission(message, commitmentProof.Proof.InnerHashes, verificationProof)).
-		WithFields(log.Fields{
-			"commitmentHash":       commitmentProof.Proof.Root.Hex(),
-			"MMRRoot":              proof.MMRRootHash.Hex(),
-			"MMRLeafHash":          Hex(hasher.Hash(mmrLeafEncoded)),
-			"merkleProofData":      proof.MerkleProofData,
-			"parachainBlockNumber": proof.Header.Number,
-			"beefyBlock":           proof.MMRProof.Blockhash.Hex(),
-			"header":               proof.Header,
-		}).
-		Info("Sent transaction Gateway.submit")
+	return &verificationProof, nil
+}
 
+// awaitSubmission waits for a Gateway.submit transaction to be included
+func (wr *EthereumWriter) awaitSubmission(ctx context.Context, tx *types.Transaction) error {
 	receipt, err := wr.conn.WatchTransaction(ctx, tx, 1)
 
 	if err != nil {
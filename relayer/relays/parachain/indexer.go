@@ -0,0 +1,363 @@
+package parachain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/snowfork/go-substrate-rpc-client/v4/scale"
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/snowfork/snowbridge/relayer/chain/parachain"
+)
+
+const defaultIndexerConcurrency = 16
+
+var ErrNonceNotIndexed = errors.New("nonce not indexed")
+
+// CommitmentIndex maps message nonces to the parachain blocks in which they were committed, by
+// channel
+type CommitmentIndex struct {
+	Channels map[string]*ChannelIndex `json:"channels"`
+}
+
+// ChannelIndex maps the nonces of the messages of a channel to the parachain blocks in which they
+// were committed
+type ChannelIndex struct {
+	// Last parachain block which has been indexed for the channel
+	Cursor uint64 `json:"cursor"`
+	// Parachain block in which each message was committed, by nonce
+	Blocks map[uint64]uint64 `json:"blocks"`
+}
+
+// CommitmentIndexer indexes the messages committed by the EthereumOutboundQueue pallet, walking
+// forward from a persisted cursor of each channel. Blocks with commitments are identified by their
+// MessagesCommitted events. Only the messages of the given channels are indexed.
+type CommitmentIndexer struct {
+	config      *IndexerConfig
+	paraConn    *parachain.Connection
+	channels    map[string]bool
+	index       CommitmentIndex
+	messagesKey types.StorageKey
+}
+
+func NewCommitmentIndexer(config *IndexerConfig, paraConn *parachain.Connection, channelIDs []ChannelID) *CommitmentIndexer {
+	channels := make(map[string]bool, len(channelIDs))
+	for _, channelID := range channelIDs {
+		channels[types.H256(channelID).Hex()] = true
+	}
+	return &CommitmentIndexer{
+		config:   config,
+		paraConn: paraConn,
+		channels: channels,
+		index: CommitmentIndex{
+			Channels: make(map[string]*ChannelIndex),
+		},
+	}
+}
+
+// Load restores the index from disk, if it has been persisted before.
+func (ix *CommitmentIndexer) Load() error {
+	messagesKey, err := types.CreateStorageKey(ix.paraConn.Metadata(), "EthereumOutboundQueue", "Messages", nil, nil)
+	if err != nil {
+		return fmt.Errorf("create storage key: %w", err)
+	}
+	ix.messagesKey = messagesKey
+
+	if ix.config.Path == "" {
+		return nil
+	}
+
+	index, err := readCommitmentIndex(ix.config.Path)
+	if err != nil {
+		return err
+	}
+	if index == nil {
+		return nil
+	}
+	ix.index = *index
+
+	// Forget channels which are no longer relayed
+	cursors := make(map[string]uint64)
+	for channel, channelIndex := range ix.index.Channels {
+		if !ix.channels[channel] {
+			delete(ix.index.Channels, channel)
+			continue
+		}
+		cursors[channel] = channelIndex.Cursor
+	}
+
+	log.WithFields(log.Fields{
+		"path":    ix.config.Path,
+		"cursors": cursors,
+	}).Info("Loaded commitment index")
+
+	return nil
+}
+
+// Initialized returns true once the indexer knows where to resume indexing the channel from
+func (ix *CommitmentIndexer) Initialized(channelID types.H256) bool {
+	channelIndex, ok := ix.index.Channels[channelID.Hex()]
+	return ok && channelIndex.Cursor > 0
+}
+
+// Seed initializes the index of a channel with commitments found by other means. All messages of
+// the channel committed up to and including the cursor block must either be included or already
+// delivered.
+func (ix *CommitmentIndexer) Seed(cursor uint64, channelID types.H256, blocks map[uint64]uint64) error {
+	channel := channelID.Hex()
+	if !ix.channels[channel] {
+		return nil
+	}
+	channelIndex := &ChannelIndex{Cursor: cursor, Blocks: make(map[uint64]uint64)}
+	for nonce, blockNumber := range blocks {
+		channelIndex.Blocks[nonce] = blockNumber
+	}
+	ix.index.Channels[channel] = channelIndex
+	return ix.save()
+}
+
+// Lookup returns the parachain block in which the message with the given nonce was committed
+func (ix *CommitmentIndexer) Lookup(channelID types.H256, nonce uint64) (uint64, bool) {
+	channelIndex, ok := ix.index.Channels[channelID.Hex()]
+	if !ok {
+		return 0, false
+	}
+	blockNumber, ok := channelIndex.Blocks[nonce]
+	return blockNumber, ok
+}
+
+// Prune forgets the messages of a channel below the given nonce, which have already been
+// delivered, and persists the index if any were forgotten.
+func (ix *CommitmentIndexer) Prune(channelID types.H256, nonce uint64) error {
+	channelIndex, ok := ix.index.Channels[channelID.Hex()]
+	if !ok {
+		return nil
+	}
+	pruned := false
+	for n := range channelIndex.Blocks {
+		if n < nonce {
+			delete(channelIndex.Blocks, n)
+			pruned = true
+		}
+	}
+	if !pruned {
+		return nil
+	}
+	return ix.save()
+}
+
+type indexedBlock struct {
+	number   uint64
+	messages []OutboundQueueMessage
+}
+
+// Update indexes all parachain blocks after the cursor of the channel up to and including toBlock.
+// Blocks are fetched concurrently and recorded in order, persisting the cursors after each batch.
+// The other channels whose cursors the indexed blocks reach are brought up to date with the same
+// blocks.
+func (ix *CommitmentIndexer) Update(ctx context.Context, channelID types.H256, toBlock uint64) error {
+	channelIndex, ok := ix.index.Channels[channelID.Hex()]
+	if !ok {
+		return fmt.Errorf("%w: channel %s is not indexed", ErrNonceNotIndexed, channelID.Hex())
+	}
+
+	concurrency := ix.config.Concurrency
+	if concurrency == 0 {
+		concurrency = defaultIndexerConcurrency
+	}
+	batchSize := concurrency * 8
+
+	for start := channelIndex.Cursor + 1; start <= toBlock; start += batchSize {
+		end := min(start+batchSize-1, toBlock)
+		blocks := make([]indexedBlock, end-start+1)
+
+		eg, egCtx := errgroup.WithContext(ctx)
+		eg.SetLimit(int(concurrency))
+		for number := start; number <= end; number++ {
+			number := number
+			eg.Go(func() error {
+				if egCtx.Err() != nil {
+					return egCtx.Err()
+				}
+				block, err := ix.fetchBlock(number)
+				if err != nil {
+					return err
+				}
+				blocks[number-start] = *block
+				return nil
+			})
+		}
+		err := eg.Wait()
+		if err != nil {
+			return err
+		}
+
+		ix.recordBlocks(start, end, blocks)
+
+		err = ix.save()
+		if err != nil {
+			return err
+		}
+
+		log.WithFields(log.Fields{
+			"channelID": channelID.Hex(),
+			"cursor":    end,
+			"toBlock":   toBlock,
+		}).Debug("Indexed parachain commitments")
+	}
+
+	return nil
+}
+
+// recordBlocks records the messages committed in the blocks from start to end, for each channel
+// whose cursor is in that range, and moves the cursors of those channels to end
+func (ix *CommitmentIndexer) recordBlocks(start, end uint64, blocks []indexedBlock) {
+	for channel, channelIndex := range ix.index.Channels {
+		if channelIndex.Cursor+1 < start || channelIndex.Cursor >= end {
+			continue
+		}
+		for _, block := range blocks {
+			if block.number <= channelIndex.Cursor {
+				continue
+			}
+			for _, message := range block.messages {
+				if types.H256(message.ChannelID).Hex() == channel {
+					channelIndex.Blocks[message.Nonce] = block.number
+				}
+			}
+		}
+		channelIndex.Cursor = end
+	}
+}
+
+// fetchBlock returns the messages committed in a parachain block
+func (ix *CommitmentIndexer) fetchBlock(blockNumber uint64) (*indexedBlock, error) {
+	blockHash, err := ix.paraConn.API().RPC.Chain.GetBlockHash(blockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("fetch block hash for block %v: %w", blockNumber, err)
+	}
+
+	events, err := ix.paraConn.FetchEvents(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	block := indexedBlock{number: blockNumber}
+	if !hasMessagesCommitted(events) {
+		return &block, nil
+	}
+
+	block.messages, err = fetchCommittedMessages(ix.paraConn, ix.messagesKey, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// hasMessagesCommitted returns whether the events include the MessagesCommitted event of the
+// EthereumOutboundQueue
+func hasMessagesCommitted(events []parachain.EventRecord) bool {
+	for _, event := range events {
+		if event.Pallet == "EthereumOutboundQueue" && event.Name == "MessagesCommitted" {
+			return true
+		}
+	}
+	return false
+}
+
+func (ix *CommitmentIndexer) save() error {
+	if ix.config.Path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(ix.index)
+	if err != nil {
+		return fmt.Errorf("encode commitment index: %w", err)
+	}
+
+	// Write to a temporary file first so that the index is never left partially written
+	tmp, err := os.CreateTemp(filepath.Dir(ix.config.Path), filepath.Base(ix.config.Path)+".*")
+	if err != nil {
+		return fmt.Errorf("create commitment index file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err != nil {
+		tmp.Close()
+		return fmt.Errorf("write commitment index: %w", err)
+	}
+	err = tmp.Close()
+	if err != nil {
+		return fmt.Errorf("write commitment index: %w", err)
+	}
+
+	err = os.Rename(tmp.Name(), ix.config.Path)
+	if err != nil {
+		return fmt.Errorf("write commitment index: %w", err)
+	}
+
+	return nil
+}
+
+func readCommitmentIndex(path string) (*CommitmentIndex, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read commitment index: %w", err)
+	}
+
+	var index CommitmentIndex
+	err = json.Unmarshal(data, &index)
+	if err != nil {
+		return nil, fmt.Errorf("decode commitment index: %w", err)
+	}
+	if index.Channels == nil {
+		index.Channels = make(map[string]*ChannelIndex)
+	}
+	for _, channelIndex := range index.Channels {
+		if channelIndex.Blocks == nil {
+			channelIndex.Blocks = make(map[uint64]uint64)
+		}
+	}
+
+	return &index, nil
+}
+
+// fetchCommittedMessages decodes the messages committed by the EthereumOutboundQueue in a block
+func fetchCommittedMessages(paraConn *parachain.Connection, messagesKey types.StorageKey, blockHash types.Hash) ([]OutboundQueueMessage, error) {
+	raw, err := paraConn.API().RPC.State.GetStorageRaw(messagesKey, blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("fetch committed messages for block %v: %w", blockHash.Hex(), err)
+	}
+	if len(*raw) == 0 {
+		return nil, nil
+	}
+
+	decoder := scale.NewDecoder(bytes.NewReader(*raw))
+	n, err := decoder.DecodeUintCompact()
+	if err != nil {
+		return nil, fmt.Errorf("decode message length error: %w", err)
+	}
+
+	messages := make([]OutboundQueueMessage, 0, n.Uint64())
+	for i := uint64(0); i < n.Uint64(); i++ {
+		m := OutboundQueueMessage{}
+		err = decoder.Decode(&m)
+		if err != nil {
+			return nil, fmt.Errorf("decode message error: %w", err)
+		}
+		messages = append(messages, m)
+	}
+
+	return messages, nil
+}
@@ -0,0 +1,108 @@
+package parachain
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snowfork/snowbridge/relayer/chain/parachain"
+)
+
+func TestCommitmentIndexerPersistence(t *testing.T) {
+	config := IndexerConfig{Path: filepath.Join(t.TempDir(), "index.json")}
+	channelID := types.H256{1}
+	otherChannelID := types.H256{2}
+
+	indexer := NewCommitmentIndexer(&config, nil, []ChannelID{ChannelID(channelID), ChannelID(otherChannelID)})
+	assert.False(t, indexer.Initialized(channelID))
+
+	err := indexer.Seed(100, channelID, map[uint64]uint64{5: 90, 6: 90, 7: 95})
+	require.NoError(t, err)
+	assert.True(t, indexer.Initialized(channelID))
+	// Each channel is seeded separately
+	assert.False(t, indexer.Initialized(otherChannelID))
+
+	// Channels which are not relayed are not indexed
+	err = indexer.Seed(100, types.H256{3}, map[uint64]uint64{6: 90})
+	require.NoError(t, err)
+	_, ok := indexer.Lookup(types.H256{3}, 6)
+	assert.False(t, ok)
+
+	// Pruning is persisted
+	err = indexer.Prune(channelID, 6)
+	require.NoError(t, err)
+	_, ok = indexer.Lookup(channelID, 5)
+	assert.False(t, ok)
+
+	index, err := readCommitmentIndex(config.Path)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), index.Channels[channelID.Hex()].Cursor)
+	assert.Equal(t, map[uint64]uint64{6: 90, 7: 95}, index.Channels[channelID.Hex()].Blocks)
+	assert.NotContains(t, index.Channels, types.H256{3}.Hex())
+}
+
+func TestCommitmentIndexerRecordBlocks(t *testing.T) {
+	channelID := types.H256{1}
+	otherChannelID := types.H256{2}
+	laggingChannelID := types.H256{3}
+
+	indexer := NewCommitmentIndexer(&IndexerConfig{}, nil, []ChannelID{
+		ChannelID(channelID), ChannelID(otherChannelID), ChannelID(laggingChannelID),
+	})
+	require.NoError(t, indexer.Seed(100, channelID, nil))
+	require.NoError(t, indexer.Seed(102, otherChannelID, nil))
+	require.NoError(t, indexer.Seed(50, laggingChannelID, nil))
+
+	blocks := []indexedBlock{
+		{number: 101, messages: []OutboundQueueMessage{
+			{ChannelID: channelID, Nonce: 1},
+			{ChannelID: otherChannelID, Nonce: 1},
+		}},
+		{number: 102},
+		{number: 103, messages: []OutboundQueueMessage{
+			{ChannelID: channelID, Nonce: 2},
+			{ChannelID: otherChannelID, Nonce: 2},
+			{ChannelID: laggingChannelID, Nonce: 1},
+		}},
+	}
+	indexer.recordBlocks(101, 103, blocks)
+
+	blockNumber, ok := indexer.Lookup(channelID, 1)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(101), blockNumber)
+	blockNumber, ok = indexer.Lookup(channelID, 2)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(103), blockNumber)
+
+	// Blocks up to the cursor of a channel were indexed for it before
+	_, ok = indexer.Lookup(otherChannelID, 1)
+	assert.False(t, ok)
+	blockNumber, ok = indexer.Lookup(otherChannelID, 2)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(103), blockNumber)
+	assert.Equal(t, uint64(103), indexer.index.Channels[otherChannelID.Hex()].Cursor)
+
+	// The blocks between the cursor of a lagging channel and the indexed blocks are not indexed yet
+	_, ok = indexer.Lookup(laggingChannelID, 1)
+	assert.False(t, ok)
+	assert.Equal(t, uint64(50), indexer.index.Channels[laggingChannelID.Hex()].Cursor)
+}
+
+func TestReadMissingCommitmentIndex(t *testing.T) {
+	index, err := readCommitmentIndex(filepath.Join(t.TempDir(), "index.json"))
+	require.NoError(t, err)
+	assert.Nil(t, index)
+}
+
+func TestHasMessagesCommitted(t *testing.T) {
+	assert.False(t, hasMessagesCommitted([]parachain.EventRecord{
+		{Pallet: "EthereumOutboundQueue", Name: "MessageQueued"},
+		{Pallet: "System", Name: "ExtrinsicSuccess"},
+	}))
+	assert.True(t, hasMessagesCommitted([]parachain.EventRecord{
+		{Pallet: "EthereumOutboundQueue", Name: "MessagesCommitted"},
+	}))
+}
@@ -10,26 +10,35 @@ import (
 	"github.com/snowfork/snowbridge/relayer/chain/ethereum"
 	"github.com/snowfork/snowbridge/relayer/chain/parachain"
 	"github.com/snowfork/snowbridge/relayer/chain/relaychain"
+	"github.com/snowfork/snowbridge/relayer/claims"
 	"github.com/snowfork/snowbridge/relayer/crypto/secp256k1"
+	"github.com/snowfork/snowbridge/relayer/metrics"
 	"github.com/snowfork/snowbridge/relayer/ofac"
+	"github.com/snowfork/snowbridge/relayer/profitability"
+	"github.com/snowfork/snowbridge/relayer/schedule"
 
 	log "github.com/sirupsen/logrus"
 )
 
 type Relay struct {
 	config                *Config
-	parachainConn         *parachain.Connection
+	parachainConns        []*parachain.Connection
 	relaychainConn        *relaychain.Connection
 	ethereumConnWriter    *ethereum.Connection
 	ethereumConnBeefy     *ethereum.Connection
 	ethereumChannelWriter *EthereumWriter
 	beefyListener         *BeefyListener
+	profitability         *profitability.Policy
+	claims                *claims.Claims
 }
 
 func NewRelay(config *Config, keypair *secp256k1.Keypair) (*Relay, error) {
 	log.Info("Creating worker")
 
-	parachainConn := parachain.NewConnection(config.Source.Parachain.Endpoint, nil)
+	var parachainConns []*parachain.Connection
+	for _, source := range config.Source.sources() {
+		parachainConns = append(parachainConns, parachain.NewConnection(source.Parachain.Endpoint, nil))
+	}
 	relaychainConn := relaychain.NewConnection(config.Source.Polkadot.Endpoint)
 
 	ethereumConnWriter := ethereum.NewConnection(&config.Sink.Ethereum, keypair)
@@ -37,12 +46,22 @@ func NewRelay(config *Config, keypair *secp256k1.Keypair) (*Relay, error) {
 
 	ofacClient := ofac.New(config.OFAC.Enabled, config.OFAC.ApiKey)
 
+	profitabilityPolicy, err := profitability.New(config.Profitability.ProfitabilityConfig)
+	if err != nil {
+		return nil, fmt.Errorf("create profitability policy: %w", err)
+	}
+
+	nonceClaims := claims.New(config.Claims, schedule.MemberFromAddress(keypair.CommonAddress()).Hex())
+
 	// channel for messages from beefy listener to ethereum writer
-	var tasks = make(chan *Task, 1)
+	var tasks = make(chan *Task, config.Pipeline.queueSize())
 
 	ethereumChannelWriter, err := NewEthereumWriter(
 		&config.Sink,
+		&config.Pipeline,
 		ethereumConnWriter,
+		config.Source.Contracts.BeefyClient,
+		nonceClaims,
 		tasks,
 	)
 	if err != nil {
@@ -52,31 +71,40 @@ func NewRelay(config *Config, keypair *secp256k1.Keypair) (*Relay, error) {
 	beefyListener := NewBeefyListener(
 		&config.Source,
 		&config.Schedule,
+		&config.Pipeline,
 		ethereumConnBeefy,
 		relaychainConn,
-		parachainConn,
+		parachainConns,
 		ofacClient,
+		profitabilityPolicy,
+		&config.Profitability,
+		nonceClaims,
+		&config.OperatingMode,
 		tasks,
 	)
 
 	return &Relay{
 		config:                config,
-		parachainConn:         parachainConn,
+		parachainConns:        parachainConns,
 		relaychainConn:        relaychainConn,
 		ethereumConnWriter:    ethereumConnWriter,
 		ethereumConnBeefy:     ethereumConnBeefy,
 		ethereumChannelWriter: ethereumChannelWriter,
 		beefyListener:         beefyListener,
+		profitability:         profitabilityPolicy,
+		claims:                nonceClaims,
 	}, nil
 }
 
 func (relay *Relay) Start(ctx context.Context, eg *errgroup.Group) error {
-	err := relay.parachainConn.ConnectWithHeartBeat(ctx, 30*time.Second)
-	if err != nil {
-		return err
+	for _, parachainConn := range relay.parachainConns {
+		err := parachainConn.ConnectWithHeartBeat(ctx, 30*time.Second)
+		if err != nil {
+			return err
+		}
 	}
 
-	err = relay.ethereumConnWriter.Connect(ctx)
+	err := relay.ethereumConnWriter.Connect(ctx)
 	if err != nil {
 		return fmt.Errorf("unable to connect to ethereum: writer: %w", err)
 	}
@@ -91,6 +119,9 @@ func (relay *Relay) Start(ctx context.Context, eg *errgroup.Group) error {
 		return err
 	}
 
+	relay.claims.Start(ctx, eg)
+	metrics.Start(ctx, eg, relay.config.Metrics)
+
 	log.Info("Starting beefy listener")
 	err = relay.beefyListener.Start(ctx, eg)
 	if err != nil {
@@ -103,6 +134,11 @@ func (relay *Relay) Start(ctx context.Context, eg *errgroup.Group) error {
 		return err
 	}
 
+	eg.Go(func() error {
+		<-ctx.Done()
+		return relay.profitability.Close()
+	})
+
 	log.Info("Current relay's ID:", relay.config.Schedule.ID)
 
 	return nil
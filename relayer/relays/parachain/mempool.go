@@ -0,0 +1,174 @@
+package parachain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/snowfork/snowbridge/relayer/contracts"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// JSON-RPC error code of calls to methods which the node does not expose
+const methodNotFoundErrorCode = -32601
+
+// pendingTransaction is a transaction in the txpool_content response
+type pendingTransaction struct {
+	From         common.Address  `json:"from"`
+	To           *common.Address `json:"to"`
+	Input        hexutil.Bytes   `json:"input"`
+	GasPrice     *hexutil.Big    `json:"gasPrice"`
+	MaxFeePerGas *hexutil.Big    `json:"maxFeePerGas"`
+}
+
+// feeCap returns the highest fee per gas the transaction pays, or nil if it is unknown
+func (tx *pendingTransaction) feeCap() *big.Int {
+	if tx.MaxFeePerGas != nil {
+		return tx.MaxFeePerGas.ToInt()
+	}
+	if tx.GasPrice != nil {
+		return tx.GasPrice.ToInt()
+	}
+	return nil
+}
+
+// Interval at which the pending transactions are fetched again, which is the time between blocks
+const pendingRefreshInterval = 12 * time.Second
+
+// Number of times a message is deferred to a pending transaction of another relayer before it is
+// delivered anyway, as the pending transaction may never be included
+const maxPendingDeferrals = 5
+
+// pendingDelivery is a pending transaction of another relayer delivering a message
+type pendingDelivery struct {
+	relayer common.Address
+	txNonce string
+}
+
+type messageKey struct {
+	gateway   common.Address
+	channelID [32]byte
+	nonce     uint64
+}
+
+// isMessagePending returns whether a transaction of another relayer delivering the message to the
+// given Gateway, or the sink Gateway if zero, is pending. Pending transactions are inspected with txpool_content, and the check is
+// disabled if the node does not expose it. The deliveries found in the pending transactions are
+// cached for a block, so that the pool is not fetched again for each message. A message is deferred
+// at most maxPendingDeferrals times, so that a transaction stuck in the pool does not hold it back.
+func (wr *EthereumWriter) isMessagePending(ctx context.Context, gateway common.Address, message contracts.InboundMessage) bool {
+	if wr.txpoolUnavailable {
+		return false
+	}
+
+	if wr.pendingDeliveries == nil || time.Since(wr.pendingFetchedAt) >= pendingRefreshInterval {
+		deliveries, err := wr.fetchPendingDeliveries(ctx)
+		if err != nil {
+			var rpcErr rpc.Error
+			if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == methodNotFoundErrorCode {
+				log.Info("Ethereum node does not expose pending transactions, skipping mempool checks")
+				wr.txpoolUnavailable = true
+			} else {
+				log.WithError(err).Warn("Failed to fetch pending transactions")
+			}
+			return false
+		}
+		wr.pendingDeliveries = deliveries
+		wr.pendingFetchedAt = time.Now()
+	}
+
+	if gateway == (common.Address{}) {
+		gateway = common.HexToAddress(wr.config.Contracts.Gateway)
+	}
+	key := messageKey{gateway, message.ChannelID, message.Nonce}
+	delivery, ok := wr.pendingDeliveries[key]
+	if !ok {
+		delete(wr.pendingDeferrals, key)
+		return false
+	}
+
+	logger := log.WithFields(log.Fields{
+		"nonce":   message.Nonce,
+		"relayer": delivery.relayer.Hex(),
+		"txNonce": delivery.txNonce,
+	})
+	if wr.pendingDeferrals == nil {
+		wr.pendingDeferrals = make(map[messageKey]int)
+	}
+	if wr.pendingDeferrals[key] >= maxPendingDeferrals {
+		logger.Warn("Pending transaction of another relayer is not included, delivering the message")
+		return false
+	}
+	wr.pendingDeferrals[key]++
+	logger.Info("Message is being delivered by another relayer")
+	return true
+}
+
+// fetchPendingDeliveries returns the messages delivered to Gateways by pending transactions of
+// other relayers. Transactions whose fee is below the base fee of the latest block cannot be
+// included and are ignored.
+func (wr *EthereumWriter) fetchPendingDeliveries(ctx context.Context) (map[messageKey]pendingDelivery, error) {
+	var content struct {
+		Pending map[common.Address]map[string]pendingTransaction `json:"pending"`
+	}
+	err := wr.conn.Client().Client().CallContext(ctx, &content, "txpool_content")
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := wr.conn.Client().HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetch latest header: %w", err)
+	}
+
+	self := wr.conn.Keypair().CommonAddress()
+	deliveries := make(map[messageKey]pendingDelivery)
+	for from, txs := range content.Pending {
+		if from == self {
+			continue
+		}
+		for nonce, tx := range txs {
+			if tx.To == nil {
+				continue
+			}
+			feeCap := tx.feeCap()
+			if head.BaseFee != nil && feeCap != nil && feeCap.Cmp(head.BaseFee) < 0 {
+				continue
+			}
+			pending, err := wr.decodeSubmitV1Message(tx.Input)
+			if err != nil {
+				continue
+			}
+			deliveries[messageKey{*tx.To, pending.ChannelID, pending.Nonce}] = pendingDelivery{
+				relayer: from,
+				txNonce: nonce,
+			}
+		}
+	}
+
+	return deliveries, nil
+}
+
+// decodeSubmitV1Message decodes the message of a call to Gateway.submitV1
+func (wr *EthereumWriter) decodeSubmitV1Message(input []byte) (contracts.InboundMessage, error) {
+	method := wr.gatewayABI.Methods["submitV1"]
+	if len(input) < 4 || !bytes.Equal(input[:4], method.ID) {
+		return contracts.InboundMessage{}, errors.New("not a call to Gateway.submitV1")
+	}
+
+	args, err := method.Inputs.Unpack(input[4:])
+	if err != nil {
+		return contracts.InboundMessage{}, fmt.Errorf("unpack arguments: %w", err)
+	}
+
+	return *abi.ConvertType(args[0], new(contracts.InboundMessage)).(*contracts.InboundMessage), nil
+}
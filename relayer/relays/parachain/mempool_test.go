@@ -0,0 +1,185 @@
+package parachain
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	etypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snowfork/snowbridge/relayer/chain/ethereum"
+	"github.com/snowfork/snowbridge/relayer/config"
+	"github.com/snowfork/snowbridge/relayer/contracts"
+	"github.com/snowfork/snowbridge/relayer/crypto/secp256k1"
+)
+
+const testGateway = "0x8f86403a4de0bb5791fa46b8e795c547942fe4cf"
+
+type testNetService struct{}
+
+func (testNetService) Version() string { return "11155111" }
+
+type testEthService struct{}
+
+func (testEthService) GetBlockByNumber(number string, full bool) *etypes.Header {
+	return &etypes.Header{
+		Number:     big.NewInt(1),
+		Difficulty: big.NewInt(0),
+		BaseFee:    big.NewInt(10),
+	}
+}
+
+type testTxpoolService struct {
+	content map[string]map[string]map[string]pendingTransaction
+}
+
+func (s testTxpoolService) Content() map[string]map[string]map[string]pendingTransaction {
+	return s.content
+}
+
+func newTestEthereumWriter(t *testing.T, txpool *testTxpoolService) *EthereumWriter {
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("net", testNetService{}))
+	require.NoError(t, server.RegisterName("eth", testEthService{}))
+	if txpool != nil {
+		require.NoError(t, server.RegisterName("txpool", txpool))
+	}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	conn := ethereum.NewConnection(&config.EthereumConfig{Endpoint: httpServer.URL}, secp256k1.Alice())
+	require.NoError(t, conn.Connect(context.Background()))
+	t.Cleanup(conn.Close)
+
+	gatewayABI, err := abi.JSON(strings.NewReader(contracts.GatewayABI))
+	require.NoError(t, err)
+
+	return &EthereumWriter{
+		config:     &SinkConfig{Contracts: SinkContractsConfig{Gateway: testGateway}},
+		conn:       conn,
+		gatewayABI: gatewayABI,
+	}
+}
+
+func packSubmitV1(t *testing.T, wr *EthereumWriter, message contracts.InboundMessage) []byte {
+	input, err := wr.gatewayABI.Pack("submitV1", message, [][32]byte{}, contracts.VerificationProof{
+		Header: contracts.VerificationParachainHeader{Number: big.NewInt(1)},
+		HeadProof: contracts.VerificationHeadProof{
+			Pos:   big.NewInt(0),
+			Width: big.NewInt(1),
+		},
+		LeafProofOrder: big.NewInt(0),
+	})
+	require.NoError(t, err)
+	return input
+}
+
+func TestIsMessagePending(t *testing.T) {
+	txpool := &testTxpoolService{}
+	wr := newTestEthereumWriter(t, txpool)
+
+	message := contracts.InboundMessage{
+		ChannelID:    [32]byte{1},
+		Nonce:        5,
+		MaxFeePerGas: big.NewInt(0),
+		Reward:       big.NewInt(0),
+	}
+	gateway := common.HexToAddress(testGateway)
+	other := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+	txpool.content = map[string]map[string]map[string]pendingTransaction{
+		"pending": {
+			other.Hex(): {"7": {From: other, To: &gateway, Input: packSubmitV1(t, wr, message)}},
+		},
+	}
+	assert.True(t, wr.isMessagePending(context.Background(), common.Address{}, message))
+
+	next := message
+	next.Nonce = 6
+	assert.False(t, wr.isMessagePending(context.Background(), common.Address{}, next))
+
+	// Deliveries to the Gateway of another parachain are different messages
+	assert.False(t, wr.isMessagePending(context.Background(), other, message))
+
+	// Pending transactions are cached for a block
+	txpool.content = map[string]map[string]map[string]pendingTransaction{}
+	assert.True(t, wr.isMessagePending(context.Background(), common.Address{}, message))
+
+	// Pending transactions of this relayer are ignored
+	self := wr.conn.Keypair().CommonAddress()
+	txpool.content = map[string]map[string]map[string]pendingTransaction{
+		"pending": {
+			self.Hex(): {"7": {From: self, To: &gateway, Input: packSubmitV1(t, wr, message)}},
+		},
+	}
+	wr.pendingFetchedAt = time.Time{}
+	assert.False(t, wr.isMessagePending(context.Background(), common.Address{}, message))
+}
+
+func TestIsMessagePendingIgnoresTransactionsBelowBaseFee(t *testing.T) {
+	txpool := &testTxpoolService{}
+	wr := newTestEthereumWriter(t, txpool)
+
+	message := contracts.InboundMessage{
+		ChannelID:    [32]byte{1},
+		Nonce:        5,
+		MaxFeePerGas: big.NewInt(0),
+		Reward:       big.NewInt(0),
+	}
+	gateway := common.HexToAddress(testGateway)
+	other := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+	txpool.content = map[string]map[string]map[string]pendingTransaction{
+		"pending": {
+			other.Hex(): {"7": {From: other, To: &gateway, Input: packSubmitV1(t, wr, message), MaxFeePerGas: (*hexutil.Big)(big.NewInt(9))}},
+		},
+	}
+	assert.False(t, wr.isMessagePending(context.Background(), common.Address{}, message))
+
+	txpool.content["pending"][other.Hex()]["7"] = pendingTransaction{
+		From: other, To: &gateway, Input: packSubmitV1(t, wr, message), GasPrice: (*hexutil.Big)(big.NewInt(10)),
+	}
+	wr.pendingFetchedAt = time.Time{}
+	assert.True(t, wr.isMessagePending(context.Background(), common.Address{}, message))
+}
+
+func TestIsMessagePendingIsBounded(t *testing.T) {
+	txpool := &testTxpoolService{}
+	wr := newTestEthereumWriter(t, txpool)
+
+	message := contracts.InboundMessage{
+		ChannelID:    [32]byte{1},
+		Nonce:        5,
+		MaxFeePerGas: big.NewInt(0),
+		Reward:       big.NewInt(0),
+	}
+	gateway := common.HexToAddress(testGateway)
+	other := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	txpool.content = map[string]map[string]map[string]pendingTransaction{
+		"pending": {
+			other.Hex(): {"7": {From: other, To: &gateway, Input: packSubmitV1(t, wr, message)}},
+		},
+	}
+
+	for i := 0; i < maxPendingDeferrals; i++ {
+		assert.True(t, wr.isMessagePending(context.Background(), common.Address{}, message))
+	}
+	// The transaction of the other relayer is stuck, so the message is delivered
+	assert.False(t, wr.isMessagePending(context.Background(), common.Address{}, message))
+}
+
+func TestIsMessagePendingUnavailable(t *testing.T) {
+	wr := newTestEthereumWriter(t, nil)
+
+	assert.False(t, wr.isMessagePending(context.Background(), common.Address{}, contracts.InboundMessage{Nonce: 1}))
+	assert.True(t, wr.txpoolUnavailable)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/snowfork/go-substrate-rpc-client/v4/types"
 	"github.com/snowfork/snowbridge/relayer/chain/relaychain"
+	"github.com/snowfork/snowbridge/relayer/crypto/keccak"
 	"github.com/snowfork/snowbridge/relayer/crypto/merkle"
 )
 
@@ -69,40 +70,81 @@ func (d MerkleProofData) String() string {
 	return string(b)
 }
 
-func CreateParachainMerkleProof(heads []relaychain.ParaHead, paraID uint32) (MerkleProofData, error) {
+// ParachainMerkleTree is the merkle tree over the heads of all parachains at a relay chain block.
+// The heads of several parachains can be proven from one tree.
+type ParachainMerkleTree struct {
+	heads     []relaychain.ParaHead
+	preLeaves [][]byte
+	tree      *merkle.Tree
+}
+
+func NewParachainMerkleTree(heads []relaychain.ParaHead) (*ParachainMerkleTree, error) {
 	// sort slice by para ID
 	sort.Sort(ByParaID(heads))
 
-	// loop headers, convert to pre leaves and find header being proven
+	// convert headers to pre leaves
 	preLeaves := make([][]byte, 0, len(heads))
-	var headerToProve []byte
-	var headerIndex int64
-	for i, head := range heads {
+	for _, head := range heads {
 		preLeaf, err := types.EncodeToBytes(head)
 		if err != nil {
-			return MerkleProofData{}, err
+			return nil, err
 		}
 		preLeaves = append(preLeaves, preLeaf)
-		if head.ParaID == paraID {
-			headerToProve = preLeaf
-			headerIndex = int64(i)
-		}
 	}
 
 	// Reference implementation of MerkleTree in substrate
 	// https://github.com/paritytech/substrate/blob/ea387c634715793f806286abf1e64cabf9b7026f/frame/beefy-mmr/primitives/src/lib.rs#L45-L54
-	leaf, root, proof, err := merkle.GenerateMerkleProof(preLeaves, headerIndex)
+	tree := merkle.NewTree()
+	err := tree.Hash(preLeaves, &keccak.Keccak256{})
 	if err != nil {
-		return MerkleProofData{}, fmt.Errorf("create parachain merkle proof: %w", err)
+		return nil, fmt.Errorf("create parachain merkle tree: %w", err)
+	}
+
+	return &ParachainMerkleTree{
+		heads:     heads,
+		preLeaves: preLeaves,
+		tree:      tree,
+	}, nil
+}
+
+// Root returns the merkle root of the parachain heads
+func (t *ParachainMerkleTree) Root() HexBytes {
+	return t.tree.Root()
+}
+
+// Prove generates the merkle proof for the head of a parachain
+func (t *ParachainMerkleTree) Prove(paraID uint32) (MerkleProofData, error) {
+	index := sort.Search(len(t.heads), func(i int) bool { return t.heads[i].ParaID >= paraID })
+	if index == len(t.heads) || t.heads[index].ParaID != paraID {
+		return MerkleProofData{}, fmt.Errorf("head of parachain %v is not included", paraID)
+	}
+	preLeaf := t.preLeaves[index]
+
+	path := t.tree.MerklePath(preLeaf)
+	if !merkle.Prove(preLeaf, t.tree.Root(), path, &keccak.Keccak256{}) {
+		return MerkleProofData{}, fmt.Errorf("create parachain merkle proof: failed to verify proof")
+	}
+
+	proof := make([][32]byte, len(path))
+	for i, node := range path {
+		copy(proof[i][:], node.Hash)
 	}
 
 	return MerkleProofData{
-		PreLeaves:       preLeaves,
-		NumberOfLeaves:  len(preLeaves),
-		ProvenPreLeaf:   headerToProve,
-		ProvenLeaf:      leaf,
-		ProvenLeafIndex: headerIndex,
-		Root:            root,
+		PreLeaves:       t.preLeaves,
+		NumberOfLeaves:  len(t.preLeaves),
+		ProvenPreLeaf:   preLeaf,
+		ProvenLeaf:      (&keccak.Keccak256{}).Hash(preLeaf),
+		ProvenLeafIndex: int64(index),
+		Root:            t.tree.Root(),
 		Proof:           proof,
 	}, nil
 }
+
+func CreateParachainMerkleProof(heads []relaychain.ParaHead, paraID uint32) (MerkleProofData, error) {
+	tree, err := NewParachainMerkleTree(heads)
+	if err != nil {
+		return MerkleProofData{}, err
+	}
+	return tree.Prove(paraID)
+}
@@ -0,0 +1,65 @@
+package parachain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snowfork/snowbridge/relayer/chain/relaychain"
+	"github.com/snowfork/snowbridge/relayer/crypto/keccak"
+	"github.com/snowfork/snowbridge/relayer/crypto/merkle"
+)
+
+func TestParachainMerkleTreeProvesSeveralParachains(t *testing.T) {
+	heads := []relaychain.ParaHead{
+		{ParaID: 2030, Data: []byte{10, 11, 12}},
+		{ParaID: 1000, Data: []byte{1, 2, 3}},
+		{ParaID: 2004, Data: []byte{7, 8, 9}},
+		{ParaID: 1013, Data: []byte{4, 5, 6}},
+		{ParaID: 2000, Data: []byte{13, 14, 15}},
+	}
+
+	tree, err := NewParachainMerkleTree(append([]relaychain.ParaHead(nil), heads...))
+	require.NoError(t, err)
+
+	for i, paraID := range []uint32{1000, 1013, 2000, 2004, 2030} {
+		proof, err := tree.Prove(paraID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(i), proof.ProvenLeafIndex)
+		assert.Equal(t, tree.Root(), proof.Root)
+
+		path := make([]*merkle.Node, 0, len(proof.Proof))
+		index := proof.ProvenLeafIndex
+		width := int64(proof.NumberOfLeaves)
+		for _, hash := range proof.Proof {
+			// Nodes promoted from the end of an odd level have no sibling
+			for index == width-1 && width%2 == 1 {
+				index, width = index/2, (width+1)/2
+			}
+			position := merkle.PositionRight
+			if index%2 == 1 {
+				position = merkle.PositionLeft
+			}
+			path = append(path, &merkle.Node{Hash: append([]byte(nil), hash[:]...), Position: position})
+			index, width = index/2, (width+1)/2
+		}
+		assert.True(t, merkle.Prove(proof.ProvenPreLeaf, proof.Root, path, &keccak.Keccak256{}))
+
+		// The shared tree yields the same proof as a tree built for a single parachain
+		single, err := CreateParachainMerkleProof(append([]relaychain.ParaHead(nil), heads...), paraID)
+		require.NoError(t, err)
+		assert.Equal(t, single, proof)
+	}
+}
+
+func TestParachainMerkleTreeMissingParachain(t *testing.T) {
+	tree, err := NewParachainMerkleTree([]relaychain.ParaHead{
+		{ParaID: 1000, Data: []byte{1, 2, 3}},
+		{ParaID: 2000, Data: []byte{4, 5, 6}},
+	})
+	require.NoError(t, err)
+
+	_, err = tree.Prove(1013)
+	assert.Error(t, err)
+}
@@ -0,0 +1,77 @@
+package parachain
+
+import (
+	"sync"
+
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"github.com/snowfork/snowbridge/relayer/crypto/merkle"
+)
+
+// Number of BEEFY blocks for which MMR leaf proofs are cached. Proofs are only useful while their
+// BEEFY block is the latest one accepted by the light client.
+const mmrProofCacheBlocks = 2
+
+// Maximum number of leaves proven in a single mmr_generateProof call
+const maxMMRBatchSize = 100
+
+// mmrProofCache caches simplified MMR leaf proofs by relay chain block number and BEEFY block, so that tasks
+// proven against the same BEEFY block can share a batch proof.
+type mmrProofCache struct {
+	mu     sync.Mutex
+	blocks map[types.Hash]*mmrProofCacheEntry
+}
+
+type mmrProofCacheEntry struct {
+	beefyBlockNumber uint64
+	proofs           map[uint64]merkle.SimplifiedMMRProof
+}
+
+func newMMRProofCache() *mmrProofCache {
+	return &mmrProofCache{
+		blocks: make(map[types.Hash]*mmrProofCacheEntry),
+	}
+}
+
+// get returns the cached proof for the leaf of a relay chain block at a BEEFY block
+func (c *mmrProofCache) get(blockNumber uint64, beefyBlockHash types.Hash) (merkle.SimplifiedMMRProof, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.blocks[beefyBlockHash]
+	if !ok {
+		return merkle.SimplifiedMMRProof{}, false
+	}
+	proof, ok := entry.proofs[blockNumber]
+	return proof, ok
+}
+
+// add caches the proof for the leaf of a relay chain block at a BEEFY block. Proofs of the oldest BEEFY block are
+// evicted once proofs for more than mmrProofCacheBlocks blocks are cached.
+func (c *mmrProofCache) add(blockNumber uint64, beefyBlockNumber uint64, beefyBlockHash types.Hash, proof merkle.SimplifiedMMRProof) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.blocks[beefyBlockHash]
+	if !ok {
+		entry = &mmrProofCacheEntry{
+			beefyBlockNumber: beefyBlockNumber,
+			proofs:           make(map[uint64]merkle.SimplifiedMMRProof),
+		}
+		c.blocks[beefyBlockHash] = entry
+		c.evict()
+	}
+	entry.proofs[blockNumber] = proof
+}
+
+func (c *mmrProofCache) evict() {
+	for len(c.blocks) > mmrProofCacheBlocks {
+		var oldestHash types.Hash
+		var oldest *mmrProofCacheEntry
+		for hash, entry := range c.blocks {
+			if oldest == nil || entry.beefyBlockNumber < oldest.beefyBlockNumber {
+				oldestHash, oldest = hash, entry
+			}
+		}
+		delete(c.blocks, oldestHash)
+	}
+}
@@ -0,0 +1,38 @@
+package parachain
+
+import (
+	"testing"
+
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"github.com/snowfork/snowbridge/relayer/crypto/merkle"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMMRProofCache(t *testing.T) {
+	cache := newMMRProofCache()
+	proof := func(order uint64) merkle.SimplifiedMMRProof {
+		return merkle.SimplifiedMMRProof{MerkleProofOrder: order}
+	}
+
+	cache.add(10, 100, types.Hash{1}, proof(1))
+	cache.add(11, 100, types.Hash{1}, proof(2))
+	cache.add(10, 101, types.Hash{2}, proof(3))
+
+	cached, ok := cache.get(10, types.Hash{1})
+	assert.True(t, ok)
+	assert.Equal(t, proof(1), cached)
+	cached, ok = cache.get(10, types.Hash{2})
+	assert.True(t, ok)
+	assert.Equal(t, proof(3), cached)
+	_, ok = cache.get(11, types.Hash{2})
+	assert.False(t, ok)
+
+	// Adding proofs for a third BEEFY block evicts the oldest one
+	cache.add(10, 102, types.Hash{3}, proof(4))
+	_, ok = cache.get(10, types.Hash{1})
+	assert.False(t, ok)
+	_, ok = cache.get(10, types.Hash{2})
+	assert.True(t, ok)
+	_, ok = cache.get(10, types.Hash{3})
+	assert.True(t, ok)
+}
@@ -0,0 +1,98 @@
+package parachain
+
+import (
+	"sync"
+
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"github.com/snowfork/snowbridge/relayer/chain/relaychain"
+)
+
+// Number of relay chain blocks for which paras heads are cached. Messages from all source
+// parachains which were included in recent relay chain blocks are usually proven together.
+const parasHeadsCacheBlocks = 64
+
+// parasHeadsCache caches the paras heads and their merkle tree by relay chain block, so that the
+// heads of all source parachains included in a relay chain block are proven from a single tree.
+type parasHeadsCache struct {
+	mu     sync.Mutex
+	blocks map[types.Hash]*parasHeadsCacheEntry
+}
+
+type parasHeadsCacheEntry struct {
+	relayBlockNumber uint64
+	heads            []relaychain.ParaHead
+	tree             *ParachainMerkleTree
+}
+
+func newParasHeadsCache() *parasHeadsCache {
+	return &parasHeadsCache{
+		blocks: make(map[types.Hash]*parasHeadsCacheEntry),
+	}
+}
+
+// heads returns the paras heads at a relay chain block, fetching them if they are not cached
+func (c *parasHeadsCache) heads(relayConn *relaychain.Connection, relayBlockNumber uint64, relayBlockHash types.Hash) ([]relaychain.ParaHead, error) {
+	c.mu.Lock()
+	entry, ok := c.blocks[relayBlockHash]
+	c.mu.Unlock()
+	if ok {
+		return entry.heads, nil
+	}
+
+	heads, err := relayConn.FetchParasHeads(relayBlockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entry(relayBlockNumber, relayBlockHash).heads = heads
+	return heads, nil
+}
+
+// tree returns the validated merkle tree over the paras heads at a relay chain block, if it
+// has been built before
+func (c *parasHeadsCache) tree(relayBlockHash types.Hash) (*ParachainMerkleTree, []relaychain.ParaHead, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.blocks[relayBlockHash]
+	if !ok || entry.tree == nil {
+		return nil, nil, false
+	}
+	return entry.tree, entry.heads, true
+}
+
+// addTree caches the validated merkle tree over the paras heads at a relay chain block, together
+// with the heads it was built from.
+func (c *parasHeadsCache) addTree(relayBlockNumber uint64, relayBlockHash types.Hash, heads []relaychain.ParaHead, tree *ParachainMerkleTree) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := c.entry(relayBlockNumber, relayBlockHash)
+	entry.heads = heads
+	entry.tree = tree
+}
+
+func (c *parasHeadsCache) entry(relayBlockNumber uint64, relayBlockHash types.Hash) *parasHeadsCacheEntry {
+	entry, ok := c.blocks[relayBlockHash]
+	if !ok {
+		entry = &parasHeadsCacheEntry{relayBlockNumber: relayBlockNumber}
+		c.blocks[relayBlockHash] = entry
+		c.evict()
+	}
+	return entry
+}
+
+func (c *parasHeadsCache) evict() {
+	for len(c.blocks) > parasHeadsCacheBlocks {
+		var oldestHash types.Hash
+		var oldest *parasHeadsCacheEntry
+		for hash, entry := range c.blocks {
+			if oldest == nil || entry.relayBlockNumber < oldest.relayBlockNumber {
+				oldestHash, oldest = hash, entry
+			}
+		}
+		delete(c.blocks, oldestHash)
+	}
+}
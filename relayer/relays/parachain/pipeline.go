@@ -0,0 +1,71 @@
+package parachain
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// runOrdered runs work for items 0..n-1 on a bounded pool of workers, and calls emit for each
+// item for which work returned true, in item order. At most workers+ahead items are in flight
+// between starting their work and being emitted, which applies backpressure from a slow emitter.
+func runOrdered(
+	ctx context.Context,
+	n, workers, ahead int,
+	work func(ctx context.Context, i int) (bool, error),
+	emit func(ctx context.Context, i int) error,
+) error {
+	// Receives the result of the work for an item once it is done successfully
+	done := make([]chan bool, n)
+	for i := range done {
+		done[i] = make(chan bool, 1)
+	}
+	inFlight := make(chan struct{}, workers+ahead)
+
+	eg, ctx := errgroup.WithContext(ctx)
+
+	eg.Go(func() error {
+		for i := 0; i < n; i++ {
+			var ok bool
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case ok = <-done[i]:
+			}
+
+			if ok {
+				err := emit(ctx, i)
+				if err != nil {
+					return err
+				}
+			}
+			<-inFlight
+		}
+		return nil
+	})
+
+	eg.Go(func() error {
+		pool, poolCtx := errgroup.WithContext(ctx)
+		pool.SetLimit(workers)
+		for i := 0; i < n; i++ {
+			select {
+			case <-poolCtx.Done():
+				return pool.Wait()
+			case inFlight <- struct{}{}:
+			}
+
+			i := i
+			pool.Go(func() error {
+				ok, err := work(poolCtx, i)
+				if err != nil {
+					return err
+				}
+				done[i] <- ok
+				return nil
+			})
+		}
+		return pool.Wait()
+	})
+
+	return eg.Wait()
+}
@@ -0,0 +1,62 @@
+package parachain
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunOrdered(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	var emitted []int
+
+	err := runOrdered(context.Background(), 20, 4, 2,
+		func(_ context.Context, i int) (bool, error) {
+			current := running.Add(1)
+			defer running.Add(-1)
+			for {
+				previous := maxRunning.Load()
+				if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+					break
+				}
+			}
+			// Finish later items first to check that emission keeps item order
+			time.Sleep(time.Duration(20-i) * time.Millisecond)
+			return i%5 != 0, nil
+		},
+		func(_ context.Context, i int) error {
+			emitted = append(emitted, i)
+			return nil
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3, 4, 6, 7, 8, 9, 11, 12, 13, 14, 16, 17, 18, 19}, emitted)
+	assert.LessOrEqual(t, maxRunning.Load(), int32(4))
+}
+
+func TestRunOrderedError(t *testing.T) {
+	failure := errors.New("failure")
+	var emitted []int
+
+	err := runOrdered(context.Background(), 10, 2, 1,
+		func(_ context.Context, i int) (bool, error) {
+			if i == 3 {
+				return false, failure
+			}
+			return true, nil
+		},
+		func(_ context.Context, i int) error {
+			emitted = append(emitted, i)
+			return nil
+		},
+	)
+
+	assert.ErrorIs(t, err, failure)
+	assert.NotContains(t, emitted, 3)
+	assert.NotContains(t, emitted, 4)
+}
@@ -0,0 +1,87 @@
+package parachain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/snowfork/snowbridge/relayer/profitability"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Gas used by Gateway.submitV1 besides the dispatch of the message, if not configured
+const defaultSubmitGasOverhead = 200000
+
+// Interval at which deferred tasks are reconsidered when there is no BEEFY update
+const deferredTasksInterval = time.Minute
+
+// estimateMessages estimates the reward and cost of submitting messages to the Gateway at the given
+// gas price. The Gateway refunds the gas used by a message at up to its maximum fee per gas, and
+// pays its reward on top of that.
+func estimateMessages(messages []OutboundQueueMessage, gasPrice *big.Int, gasOverhead uint64) profitability.Estimate {
+	estimate := profitability.Estimate{
+		Reward: new(big.Int),
+		Cost:   new(big.Int),
+	}
+	for _, message := range messages {
+		gas := new(big.Int).SetUint64(gasOverhead + message.MaxDispatchGas)
+
+		refundGasPrice := gasPrice
+		maxFeePerGas := message.MaxFeePerGas.Int
+		if maxFeePerGas != nil && maxFeePerGas.Cmp(gasPrice) < 0 {
+			refundGasPrice = maxFeePerGas
+		}
+
+		estimate.Reward.Add(estimate.Reward, new(big.Int).Mul(gas, refundGasPrice))
+		if message.Reward.Int != nil {
+			estimate.Reward.Add(estimate.Reward, message.Reward.Int)
+		}
+		estimate.Cost.Add(estimate.Cost, new(big.Int).Mul(gas, gasPrice))
+	}
+	return estimate
+}
+
+// filterProfitableTasks returns the tasks of a channel which may be relayed now according to the
+// profitability policy. Messages must be delivered in nonce order, so all tasks after the first
+// deferred task are deferred as well. Deferred tasks are reconsidered on the next BEEFY update, or
+// after deferredTasksInterval, whichever comes first.
+func (li *BeefyListener) filterProfitableTasks(ctx context.Context, channelID ChannelID, tasks []*Task) ([]*Task, error) {
+	if !li.profitability.Enabled() || len(tasks) == 0 {
+		return tasks, nil
+	}
+
+	li.profitability.Prune(channelID, (*tasks[0].MessageProofs)[0].Message.Nonce-1)
+
+	gasPrice, err := li.ethereumConn.Client().SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("suggest gas price: %w", err)
+	}
+
+	gasOverhead := li.profitabilityConfig.GasOverhead
+	if gasOverhead == 0 {
+		gasOverhead = defaultSubmitGasOverhead
+	}
+
+	for i, task := range tasks {
+		messages := make([]OutboundQueueMessage, len(*task.MessageProofs))
+		for j, proof := range *task.MessageProofs {
+			messages[j] = proof.Message
+		}
+		nonce := messages[0].Nonce
+
+		decision := li.profitability.Decide(channelID, nonce, estimateMessages(messages, gasPrice, gasOverhead))
+		if !decision.Relay {
+			log.WithFields(log.Fields{
+				"channelID":     Hex(channelID[:]),
+				"nonce":         nonce,
+				"deferredTasks": len(tasks) - i,
+			}).Info("Deferring unprofitable tasks")
+			li.deferredTasks = true
+			return tasks[:i], nil
+		}
+	}
+
+	return tasks, nil
+}
@@ -0,0 +1,33 @@
+package parachain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateMessages(t *testing.T) {
+	messages := []OutboundQueueMessage{
+		{
+			Nonce:          1,
+			MaxDispatchGas: 100000,
+			MaxFeePerGas:   types.NewU128(*big.NewInt(20)),
+			Reward:         types.NewU128(*big.NewInt(1000)),
+		},
+		{
+			// The refund is capped by the maximum fee per gas
+			Nonce:          2,
+			MaxDispatchGas: 300000,
+			MaxFeePerGas:   types.NewU128(*big.NewInt(5)),
+			Reward:         types.NewU128(*big.NewInt(0)),
+		},
+	}
+
+	estimate := estimateMessages(messages, big.NewInt(10), 200000)
+
+	assert.Equal(t, big.NewInt(300000*10+1000+500000*5), estimate.Reward)
+	assert.Equal(t, big.NewInt(300000*10+500000*10), estimate.Cost)
+	assert.Equal(t, big.NewInt(1000-500000*5), estimate.Profit())
+}
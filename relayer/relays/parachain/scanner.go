@@ -1,7 +1,6 @@
 package parachain
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -10,8 +9,6 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 
-	"github.com/snowfork/go-substrate-rpc-client/v4/scale"
-
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	log "github.com/sirupsen/logrus"
@@ -22,6 +19,7 @@ import (
 	"github.com/snowfork/snowbridge/relayer/chain/relaychain"
 	"github.com/snowfork/snowbridge/relayer/contracts"
 	"github.com/snowfork/snowbridge/relayer/ofac"
+	"golang.org/x/sync/errgroup"
 )
 
 type Scanner struct {
@@ -30,11 +28,18 @@ type Scanner struct {
 	relayConn *relaychain.Connection
 	paraConn  *parachain.Connection
 	paraID    uint32
-	ofac      *ofac.OFAC
-	tasks     chan<- *Task
+	channelID ChannelID
+	// Gateway whose nonces are read, and the Gateway messages are delivered to, which is zero for
+	// the sink Gateway
+	gateway    common.Address
+	deliverTo  common.Address
+	ofac       *ofac.OFAC
+	indexer    *CommitmentIndexer
+	parasHeads *parasHeadsCache
+	tasks      chan<- *Task
 }
 
-// Scans for all parachain message commitments for the configured parachain channelID that need to be relayed and can be
+// Scans for all parachain message commitments for the scanner's parachain channelID that need to be relayed and can be
 // proven using the MMR root at the specified beefyBlockNumber of the relay chain.
 //
 // The algorithm works roughly like this:
@@ -82,13 +87,13 @@ func (s *Scanner) findTasks(
 	ethInboundNonce, err := s.findLatestNonce(ctx)
 	log.WithFields(log.Fields{
 		"nonce":     ethInboundNonce,
-		"channelID": s.config.ChannelID,
+		"channelID": s.channelID,
 	}).Info("Checked latest nonce delivered to ethereum gateway")
 
 	// Fetch latest nonce in parachain outbound queue
-	paraNonceKey, err := types.CreateStorageKey(s.paraConn.Metadata(), "EthereumOutboundQueue", "Nonce", s.config.ChannelID[:], nil)
+	paraNonceKey, err := types.CreateStorageKey(s.paraConn.Metadata(), "EthereumOutboundQueue", "Nonce", s.channelID[:], nil)
 	if err != nil {
-		return nil, fmt.Errorf("create storage key for parachain outbound queue nonce with channelID '%v': %w", s.config.ChannelID, err)
+		return nil, fmt.Errorf("create storage key for parachain outbound queue nonce with channelID '%v': %w", s.channelID, err)
 	}
 	var paraNonce types.U64
 	ok, err := s.paraConn.API().RPC.State.GetStorage(paraNonceKey, &paraNonce, paraHash)
@@ -104,21 +109,38 @@ func (s *Scanner) findTasks(
 	}
 	log.WithFields(log.Fields{
 		"nonce":     uint64(paraNonce),
-		"channelID": s.config.ChannelID,
+		"channelID": s.channelID,
 	}).Info("Checked latest nonce generated by parachain outbound queue")
 
+	channelID := types.H256(s.channelID)
+
 	if !(uint64(paraNonce) > ethInboundNonce) {
+		// All messages committed so far have been delivered, so indexing can start from here
+		if !s.indexer.Initialized(channelID) {
+			err = s.indexer.Seed(paraBlock, channelID, nil)
+			if err != nil {
+				return nil, fmt.Errorf("seed commitment index: %w", err)
+			}
+		}
 		return nil, nil
 	}
 
 	log.Info("Nonces are mismatched, scanning for commitments that need to be relayed")
 
-	tasks, err := s.findTasksImpl(
-		ctx,
-		paraBlock,
-		types.H256(s.config.ChannelID),
-		ethInboundNonce+1,
-	)
+	tasks, err := s.findTasksIndexed(ctx, paraBlock, channelID, ethInboundNonce+1, uint64(paraNonce))
+	if errors.Is(err, ErrNonceNotIndexed) {
+		log.WithError(err).Info("Commitment index incomplete, scanning backwards instead")
+		tasks, err = s.findTasksImpl(
+			ctx,
+			paraBlock,
+			channelID,
+			ethInboundNonce+1,
+		)
+		if err != nil {
+			return nil, err
+		}
+		err = s.seedIndex(paraBlock, channelID, tasks)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -145,11 +167,6 @@ func (s *Scanner) findTasksImpl(
 		"latestBlockNumber": lastParaBlockNumber,
 	}).Debug("Searching backwards from latest block on parachain to find block with nonce")
 
-	messagesKey, err := types.CreateStorageKey(s.paraConn.Metadata(), "EthereumOutboundQueue", "Messages", nil, nil)
-	if err != nil {
-		return nil, fmt.Errorf("create storage key: %w", err)
-	}
-
 	scanOutboundQueueDone := false
 	var tasks []*Task
 
@@ -158,90 +175,193 @@ func (s *Scanner) findTasksImpl(
 			break
 		}
 
-		log.WithFields(log.Fields{
-			"blockNumber": currentBlockNumber,
-		}).Debug("Checking header")
-
-		blockHash, err := s.paraConn.API().RPC.Chain.GetBlockHash(currentBlockNumber)
+		task, scanDone, err := s.findTaskForBlock(currentBlockNumber, channelID, startingNonce)
 		if err != nil {
-			return nil, fmt.Errorf("fetch block hash for block %v: %w", currentBlockNumber, err)
+			return nil, err
 		}
+		scanOutboundQueueDone = scanDone
 
-		header, err := s.paraConn.API().RPC.Chain.GetHeader(blockHash)
-		if err != nil {
-			return nil, fmt.Errorf("fetch header for block hash %v: %w", blockHash.Hex(), err)
+		if task != nil {
+			tasks = append(tasks, task)
 		}
+	}
 
-		commitmentHash, err := ExtractCommitmentFromDigest(header.Digest)
-		if err != nil {
-			return nil, err
-		}
-		if commitmentHash == nil {
-			continue
-		}
+	// Reverse tasks, effectively sorting by ascending block number
+	for i, j := 0, len(tasks)-1; i < j; i, j = i+1, j-1 {
+		tasks[i], tasks[j] = tasks[j], tasks[i]
+	}
 
-		var messages []OutboundQueueMessage
-		raw, err := s.paraConn.API().RPC.State.GetStorageRaw(messagesKey, blockHash)
-		if err != nil {
-			return nil, fmt.Errorf("fetch committed messages for block %v: %w", blockHash.Hex(), err)
+	return tasks, nil
+}
+
+// findTasksIndexed finds the commitments for the nonces in [startingNonce, lastNonce] using the
+// commitment index, fetching the blocks which contain them concurrently.
+func (s *Scanner) findTasksIndexed(
+	ctx context.Context,
+	lastParaBlockNumber uint64,
+	channelID types.H256,
+	startingNonce uint64,
+	lastNonce uint64,
+) ([]*Task, error) {
+	if !s.indexer.Initialized(channelID) {
+		return nil, ErrNonceNotIndexed
+	}
+
+	err := s.indexer.Update(ctx, channelID, lastParaBlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("update commitment index: %w", err)
+	}
+	err = s.indexer.Prune(channelID, startingNonce)
+	if err != nil {
+		return nil, fmt.Errorf("prune commitment index: %w", err)
+	}
+
+	var blockNumbers []uint64
+	for nonce := startingNonce; nonce <= lastNonce; nonce++ {
+		blockNumber, ok := s.indexer.Lookup(channelID, nonce)
+		if !ok {
+			return nil, fmt.Errorf("%w: %d", ErrNonceNotIndexed, nonce)
 		}
-		decoder := scale.NewDecoder(bytes.NewReader(*raw))
-		n, err := decoder.DecodeUintCompact()
-		if err != nil {
-			return nil, fmt.Errorf("decode message length error: %w", err)
+		if len(blockNumbers) == 0 || blockNumbers[len(blockNumbers)-1] != blockNumber {
+			blockNumbers = append(blockNumbers, blockNumber)
 		}
-		for i := uint64(0); i < n.Uint64(); i++ {
-			m := OutboundQueueMessage{}
-			err = decoder.Decode(&m)
-			if err != nil {
-				return nil, fmt.Errorf("decode message error: %w", err)
+	}
+
+	log.WithFields(log.Fields{
+		"channelID":    channelID,
+		"nonce":        startingNonce,
+		"lastNonce":    lastNonce,
+		"blockNumbers": blockNumbers,
+	}).Debug("Found blocks with outstanding commitments in index")
+
+	concurrency := s.indexer.config.Concurrency
+	if concurrency == 0 {
+		concurrency = defaultIndexerConcurrency
+	}
+
+	tasks := make([]*Task, len(blockNumbers))
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(int(concurrency))
+	for i, blockNumber := range blockNumbers {
+		i, blockNumber := i, blockNumber
+		eg.Go(func() error {
+			if egCtx.Err() != nil {
+				return egCtx.Err()
 			}
-			isBanned, err := s.IsBanned(m)
+			task, _, err := s.findTaskForBlock(blockNumber, channelID, startingNonce)
 			if err != nil {
-				log.WithError(err).Fatal("error checking banned address found")
-				return nil, fmt.Errorf("banned check: %w", err)
+				return err
 			}
-			if isBanned {
-				log.Fatal("banned address found")
-				return nil, errors.New("banned address found")
+			if task == nil {
+				return fmt.Errorf("no commitment found in indexed block %v", blockNumber)
 			}
-			messages = append(messages, m)
-		}
+			tasks[i] = task
+			return nil
+		})
+	}
+	err = eg.Wait()
+	if err != nil {
+		return nil, err
+	}
 
-		// For the outbound channel, the commitment hash is the merkle root of the messages
-		// https://github.com/Snowfork/snowbridge/blob/75a475cbf8fc8e13577ad6b773ac452b2bf82fbb/parachain/pallets/basic-channel/src/outbound/mod.rs#L275-L277
-		// To verify it we fetch the message proof from the parachain
-		result, err := scanForOutboundQueueProofs(
-			s.paraConn.API(),
-			blockHash,
-			*commitmentHash,
-			startingNonce,
-			channelID,
-			messages,
-		)
-		if err != nil {
-			return nil, err
+	return tasks, nil
+}
+
+// seedIndex initializes the commitment index with the commitments found by a backwards scan
+func (s *Scanner) seedIndex(lastParaBlockNumber uint64, channelID types.H256, tasks []*Task) error {
+	if s.indexer.Initialized(channelID) {
+		return nil
+	}
+
+	blocks := make(map[uint64]uint64)
+	for _, task := range tasks {
+		for _, proof := range *task.MessageProofs {
+			blocks[proof.Message.Nonce] = uint64(task.Header.Number)
 		}
+	}
 
-		scanOutboundQueueDone = result.scanDone
+	err := s.indexer.Seed(lastParaBlockNumber, channelID, blocks)
+	if err != nil {
+		return fmt.Errorf("seed commitment index: %w", err)
+	}
+	return nil
+}
 
-		if len(result.proofs) > 0 {
-			task := Task{
-				Header:        header,
-				MessageProofs: &result.proofs,
-				ProofInput:    nil,
-				ProofOutput:   nil,
-			}
-			tasks = append(tasks, &task)
+// findTaskForBlock collects the proofs for messages in a parachain block with a nonce of at least
+// startingNonce. It also reports whether no earlier blocks need to be scanned.
+func (s *Scanner) findTaskForBlock(
+	blockNumber uint64,
+	channelID types.H256,
+	startingNonce uint64,
+) (*Task, bool, error) {
+	log.WithFields(log.Fields{
+		"blockNumber": blockNumber,
+	}).Debug("Checking header")
+
+	blockHash, err := s.paraConn.API().RPC.Chain.GetBlockHash(blockNumber)
+	if err != nil {
+		return nil, false, fmt.Errorf("fetch block hash for block %v: %w", blockNumber, err)
+	}
+
+	header, err := s.paraConn.API().RPC.Chain.GetHeader(blockHash)
+	if err != nil {
+		return nil, false, fmt.Errorf("fetch header for block hash %v: %w", blockHash.Hex(), err)
+	}
+
+	commitmentHash, err := ExtractCommitmentFromDigest(header.Digest)
+	if err != nil {
+		return nil, false, err
+	}
+	if commitmentHash == nil {
+		return nil, false, nil
+	}
+
+	messagesKey, err := types.CreateStorageKey(s.paraConn.Metadata(), "EthereumOutboundQueue", "Messages", nil, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("create storage key: %w", err)
+	}
+	messages, err := fetchCommittedMessages(s.paraConn, messagesKey, blockHash)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, m := range messages {
+		isBanned, err := s.IsBanned(m)
+		if err != nil {
+			log.WithError(err).Fatal("error checking banned address found")
+			return nil, false, fmt.Errorf("banned check: %w", err)
+		}
+		if isBanned {
+			log.Fatal("banned address found")
+			return nil, false, errors.New("banned address found")
 		}
 	}
 
-	// Reverse tasks, effectively sorting by ascending block number
-	for i, j := 0, len(tasks)-1; i < j; i, j = i+1, j-1 {
-		tasks[i], tasks[j] = tasks[j], tasks[i]
+	// For the outbound channel, the commitment hash is the merkle root of the messages
+	// https://github.com/Snowfork/snowbridge/blob/75a475cbf8fc8e13577ad6b773ac452b2bf82fbb/parachain/pallets/basic-channel/src/outbound/mod.rs#L275-L277
+	// To verify it we fetch the message proof from the parachain
+	result, err := scanForOutboundQueueProofs(
+		s.paraConn.API(),
+		blockHash,
+		*commitmentHash,
+		startingNonce,
+		channelID,
+		messages,
+	)
+	if err != nil {
+		return nil, false, err
 	}
 
-	return tasks, nil
+	if len(result.proofs) == 0 {
+		return nil, result.scanDone, nil
+	}
+
+	return &Task{
+		Header:        header,
+		Gateway:       s.deliverTo,
+		MessageProofs: &result.proofs,
+		ProofInput:    nil,
+		ProofOutput:   nil,
+	}, result.scanDone, nil
 }
 
 type PersistedValidationData struct {
@@ -272,7 +392,7 @@ func (s *Scanner) gatherProofInputs(
 			return fmt.Errorf("fetch relaychain block hash: %w", err)
 		}
 
-		parachainHeads, err := s.relayConn.FetchParasHeads(relayBlockHash)
+		parachainHeads, err := s.parasHeads.heads(s.relayConn, relayBlockNumber, relayBlockHash)
 		if err != nil {
 			return fmt.Errorf("fetch parachain heads: %w", err)
 		}
@@ -464,7 +584,7 @@ func fetchMessageProof(
 
 func (s *Scanner) findLatestNonce(ctx context.Context) (uint64, error) {
 	// Fetch latest nonce in ethereum gateway
-	gatewayAddress := common.HexToAddress(s.config.Contracts.Gateway)
+	gatewayAddress := s.gateway
 	gatewayContract, err := contracts.NewGateway(
 		gatewayAddress,
 		s.ethConn.Client(),
@@ -477,9 +597,9 @@ func (s *Scanner) findLatestNonce(ctx context.Context) (uint64, error) {
 		Pending: true,
 		Context: ctx,
 	}
-	ethInboundNonce, _, err := gatewayContract.ChannelNoncesOf(&options, s.config.ChannelID)
+	ethInboundNonce, _, err := gatewayContract.ChannelNoncesOf(&options, s.channelID)
 	if err != nil {
-		return 0, fmt.Errorf("fetch nonce from gateway contract for channelID '%v': %w", s.config.ChannelID, err)
+		return 0, fmt.Errorf("fetch nonce from gateway contract for channelID '%v': %w", s.channelID, err)
 	}
 	return ethInboundNonce, err
 }
@@ -0,0 +1,108 @@
+package parachain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"github.com/snowfork/snowbridge/relayer/contracts"
+	"github.com/snowfork/snowbridge/relayer/schedule"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Number of ethereum blocks searched for deliveries of messages when the relayer starts
+const deliveryObservationBlocks = 1000
+
+// observeDeliveries records which relayers delivered the messages dispatched by the Gateways since
+// the last observation, so that relayers which miss their turns are left out of the rotation.
+func (li *BeefyListener) observeDeliveries(ctx context.Context) {
+	if !li.schedule.Dynamic() {
+		return
+	}
+
+	for gateway, channelIDs := range li.config.gatewayChannelIDs() {
+		err := li.observeDeliveriesImpl(ctx, gateway, channelIDs)
+		if err != nil {
+			log.WithError(err).WithField("gateway", gateway.Hex()).Warn("Failed to observe message deliveries")
+		}
+	}
+}
+
+func (li *BeefyListener) observeDeliveriesImpl(ctx context.Context, gateway common.Address, channelIDs [][32]byte) error {
+	client := li.ethereumConn.Client()
+
+	gatewayContract, err := contracts.NewGateway(gateway, client)
+	if err != nil {
+		return fmt.Errorf("create gateway contract: %w", err)
+	}
+
+	latestBlock, err := client.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("get latest block number: %w", err)
+	}
+
+	lastObservedBlock := li.lastObservedBlocks[gateway]
+	start := lastObservedBlock + 1
+	if lastObservedBlock == 0 && latestBlock > deliveryObservationBlocks {
+		start = latestBlock - deliveryObservationBlocks
+	}
+	if start > latestBlock {
+		return nil
+	}
+
+	opts := bind.FilterOpts{
+		Start:   start,
+		End:     &latestBlock,
+		Context: ctx,
+	}
+	iter, err := gatewayContract.FilterInboundMessageDispatched(&opts, channelIDs, nil)
+	if err != nil {
+		return fmt.Errorf("filter InboundMessageDispatched events: %w", err)
+	}
+	defer iter.Close()
+
+	var blockNumber uint64
+	var blockTime time.Time
+	for iter.Next() {
+		event := iter.Event
+		if event.Raw.BlockNumber != blockNumber {
+			// Deliveries in the blocks before this one have all been observed
+			if event.Raw.BlockNumber > start {
+				li.lastObservedBlocks[gateway] = event.Raw.BlockNumber - 1
+			}
+			header, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(event.Raw.BlockNumber))
+			if err != nil {
+				return fmt.Errorf("get header of block %d: %w", event.Raw.BlockNumber, err)
+			}
+			blockNumber = event.Raw.BlockNumber
+			blockTime = time.Unix(int64(header.Time), 0)
+		}
+
+		tx, _, err := client.TransactionByHash(ctx, event.Raw.TxHash)
+		if err != nil {
+			return fmt.Errorf("get transaction %v: %w", event.Raw.TxHash.Hex(), err)
+		}
+		sender, err := client.TransactionSender(ctx, tx, event.Raw.BlockHash, event.Raw.TxIndex)
+		if err != nil {
+			return fmt.Errorf("get sender of transaction %v: %w", event.Raw.TxHash.Hex(), err)
+		}
+
+		log.WithFields(log.Fields{
+			"channelID": types.H256(event.ChannelID).Hex(),
+			"nonce":     event.Nonce,
+			"relayer":   sender.Hex(),
+		}).Debug("Observed message delivery")
+		li.schedule.ObserveDelivery(event.ChannelID, event.Nonce, schedule.MemberFromAddress(sender), blockTime)
+	}
+	if iter.Error() != nil {
+		return fmt.Errorf("iterate InboundMessageDispatched events: %w", iter.Error())
+	}
+
+	li.lastObservedBlocks[gateway] = latestBlock
+	return nil
+}
@@ -3,6 +3,7 @@ package parachain
 import (
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/snowfork/go-substrate-rpc-client/v4/scale"
 	"github.com/snowfork/go-substrate-rpc-client/v4/types"
 	"github.com/snowfork/snowbridge/relayer/chain/relaychain"
@@ -14,6 +15,8 @@ import (
 type Task struct {
 	// Parachain header
 	Header *types.Header
+	// Gateway to which the messages are delivered, the sink Gateway if zero
+	Gateway common.Address
 	// Inputs for MMR proof generation
 	ProofInput *ProofInput
 	// Outputs of MMR proof generation
@@ -0,0 +1,262 @@
+package parachain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/snowfork/go-substrate-rpc-client/v4/scale"
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+
+	"github.com/snowfork/snowbridge/relayer/contracts"
+	"github.com/snowfork/snowbridge/relayer/crypto/merkle"
+)
+
+// ErrInvalidProof is returned when a message proof would be rejected by the Gateway
+var ErrInvalidProof = errors.New("invalid proof")
+
+// IDs of enum variants of DigestItem, as accepted by Verification.sol
+const (
+	digestItemOther                     = 0
+	digestItemConsensus                 = 4
+	digestItemSeal                      = 5
+	digestItemPreRuntime                = 6
+	digestItemRuntimeEnvironmentUpdated = 8
+)
+
+// Enum variant ID for CustomDigestItem::Snowbridge
+const digestItemOtherSnowbridge = 0x00
+
+var inboundMessageArguments = sync.OnceValues(func() (abi.Arguments, error) {
+	parsed, err := contracts.GatewayMetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("parse Gateway ABI: %w", err)
+	}
+	method, ok := parsed.Methods["submitV1"]
+	if !ok {
+		return nil, fmt.Errorf("Gateway ABI has no method submitV1")
+	}
+	return method.Inputs[:1], nil
+})
+
+// VerifyMessage checks a message and its proofs in the same way as Gateway.submitV1, against the
+// given MMR root of the BEEFY light client.
+func VerifyMessage(
+	message contracts.InboundMessage,
+	leafProof [][32]byte,
+	proof *contracts.VerificationProof,
+	paraID uint32,
+	mmrRoot types.H256,
+) error {
+	leafHash, err := MessageLeafHash(message)
+	if err != nil {
+		return err
+	}
+	commitment := ProcessMessageProof(leafHash, leafProof)
+
+	return VerifyCommitment(commitment, proof, paraID, mmrRoot)
+}
+
+// VerifyCommitment mirrors Verification.verifyCommitment. It checks that the message commitment is
+// included in the digest of the parachain header, that the header is included in the parachain heads
+// root, and that the MMR leaf built from that root is included in the MMR with the given root.
+func VerifyCommitment(
+	commitment types.H256,
+	proof *contracts.VerificationProof,
+	paraID uint32,
+	mmrRoot types.H256,
+) error {
+	if !isCommitmentInHeaderDigest(commitment, &proof.Header) {
+		return fmt.Errorf("%w: commitment %s not found in parachain header digest", ErrInvalidProof, commitment.Hex())
+	}
+
+	encodedHead, err := encodeParachainHead(paraID, &proof.Header)
+	if err != nil {
+		return fmt.Errorf("%w: encode parachain header: %v", ErrInvalidProof, err)
+	}
+
+	if proof.HeadProof.Pos.Cmp(proof.HeadProof.Width) >= 0 {
+		return fmt.Errorf(
+			"%w: head proof position %v is not less than width %v",
+			ErrInvalidProof, proof.HeadProof.Pos, proof.HeadProof.Width,
+		)
+	}
+	if !proof.HeadProof.Width.IsUint64() {
+		return fmt.Errorf("%w: head proof width %v is out of range", ErrInvalidProof, proof.HeadProof.Width)
+	}
+
+	parachainHeadsRoot := computeParachainHeadsRoot(
+		types.NewH256(crypto.Keccak256(encodedHead)),
+		proof.HeadProof.Pos.Uint64(),
+		proof.HeadProof.Width.Uint64(),
+		proof.HeadProof.Proof,
+	)
+
+	leafHash := types.NewH256(crypto.Keccak256(encodeMMRLeafPartial(&proof.LeafPartial, parachainHeadsRoot)))
+
+	if !proof.LeafProofOrder.IsUint64() {
+		return fmt.Errorf("%w: leaf proof order %v is out of range", ErrInvalidProof, proof.LeafProofOrder)
+	}
+	leafProof := make([]types.H256, len(proof.LeafProof))
+	for i, item := range proof.LeafProof {
+		leafProof[i] = item
+	}
+	calculatedRoot := merkle.CalculateMerkleRoot(&merkle.SimplifiedMMRProof{
+		MerkleProofItems: leafProof,
+		MerkleProofOrder: proof.LeafProofOrder.Uint64(),
+	}, leafHash)
+
+	if calculatedRoot != mmrRoot {
+		return fmt.Errorf(
+			"%w: MMR leaf %s with parachain heads root %s yields MMR root %s, expected %s",
+			ErrInvalidProof, leafHash.Hex(), parachainHeadsRoot.Hex(), calculatedRoot.Hex(), mmrRoot.Hex(),
+		)
+	}
+
+	return nil
+}
+
+// MessageLeafHash returns the leaf of a message in the message commitment merkle tree, which is the
+// hash of its ABI encoding.
+func MessageLeafHash(message contracts.InboundMessage) (types.H256, error) {
+	arguments, err := inboundMessageArguments()
+	if err != nil {
+		return types.H256{}, err
+	}
+	encoded, err := arguments.Pack(message)
+	if err != nil {
+		return types.H256{}, fmt.Errorf("encode inbound message: %w", err)
+	}
+	return types.NewH256(crypto.Keccak256(encoded)), nil
+}
+
+// ProcessMessageProof computes the message commitment from a message leaf and its merkle proof,
+// hashing sorted pairs like OpenZeppelin's MerkleProof.processProof.
+func ProcessMessageProof(leaf types.H256, proof [][32]byte) types.H256 {
+	computed := [32]byte(leaf)
+	for _, item := range proof {
+		if bytes.Compare(computed[:], item[:]) < 0 {
+			computed = [32]byte(crypto.Keccak256(computed[:], item[:]))
+		} else {
+			computed = [32]byte(crypto.Keccak256(item[:], computed[:]))
+		}
+	}
+	return types.H256(computed)
+}
+
+// computeParachainHeadsRoot mirrors SubstrateMerkleProof.computeRoot
+func computeParachainHeadsRoot(leaf types.H256, position, width uint64, proof [][32]byte) types.H256 {
+	node := leaf[:]
+	for _, item := range proof {
+		if position&1 == 1 || position+1 == width {
+			node = crypto.Keccak256(item[:], node)
+		} else {
+			node = crypto.Keccak256(node, item[:])
+		}
+		position = position >> 1
+		width = ((width - 1) >> 1) + 1
+	}
+	return types.NewH256(node)
+}
+
+func isCommitmentInHeaderDigest(commitment types.H256, header *contracts.VerificationParachainHeader) bool {
+	for _, item := range header.DigestItems {
+		if item.Kind.Cmp(big.NewInt(digestItemOther)) == 0 &&
+			len(item.Data) == 33 &&
+			item.Data[0] == digestItemOtherSnowbridge &&
+			bytes.Equal(commitment[:], item.Data[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeParachainHead SCALE-encodes the parachain head leaf (para ID followed by the length-prefixed
+// header), as done by Verification.createParachainHeader.
+func encodeParachainHead(paraID uint32, header *contracts.VerificationParachainHeader) ([]byte, error) {
+	var encodedHeader bytes.Buffer
+	encodedHeader.Write(header.ParentHash[:])
+	err := encodeCompactU32(&encodedHeader, header.Number)
+	if err != nil {
+		return nil, fmt.Errorf("encode header number: %w", err)
+	}
+	encodedHeader.Write(header.StateRoot[:])
+	encodedHeader.Write(header.ExtrinsicsRoot[:])
+
+	err = encodeCompactU32(&encodedHeader, big.NewInt(int64(len(header.DigestItems))))
+	if err != nil {
+		return nil, fmt.Errorf("encode digest length: %w", err)
+	}
+	for i, item := range header.DigestItems {
+		err = encodeDigestItem(&encodedHeader, &item)
+		if err != nil {
+			return nil, fmt.Errorf("encode digest item %d: %w", i, err)
+		}
+	}
+
+	var encoded bytes.Buffer
+	encoded.Write(binary.LittleEndian.AppendUint32(nil, paraID))
+	err = encodeCompactU32(&encoded, big.NewInt(int64(encodedHeader.Len())))
+	if err != nil {
+		return nil, fmt.Errorf("encode header length: %w", err)
+	}
+	encoded.Write(encodedHeader.Bytes())
+
+	return encoded.Bytes(), nil
+}
+
+func encodeDigestItem(buf *bytes.Buffer, item *contracts.VerificationDigestItem) error {
+	if !item.Kind.IsUint64() {
+		return fmt.Errorf("unsupported digest item kind %v", item.Kind)
+	}
+
+	switch kind := item.Kind.Uint64(); kind {
+	case digestItemPreRuntime, digestItemConsensus, digestItemSeal:
+		buf.WriteByte(byte(kind))
+		buf.Write(item.ConsensusEngineID[:])
+		err := encodeCompactU32(buf, big.NewInt(int64(len(item.Data))))
+		if err != nil {
+			return err
+		}
+		buf.Write(item.Data)
+	case digestItemOther:
+		buf.WriteByte(byte(kind))
+		err := encodeCompactU32(buf, big.NewInt(int64(len(item.Data))))
+		if err != nil {
+			return err
+		}
+		buf.Write(item.Data)
+	case digestItemRuntimeEnvironmentUpdated:
+		buf.WriteByte(byte(kind))
+	default:
+		return fmt.Errorf("unsupported digest item kind %d", kind)
+	}
+
+	return nil
+}
+
+// encodeMMRLeafPartial SCALE-encodes an MMR leaf, as done by Verification.createMMRLeaf
+func encodeMMRLeafPartial(leaf *contracts.VerificationMMRLeafPartial, parachainHeadsRoot types.H256) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(leaf.Version)
+	buf.Write(binary.LittleEndian.AppendUint32(nil, leaf.ParentNumber))
+	buf.Write(leaf.ParentHash[:])
+	buf.Write(binary.LittleEndian.AppendUint64(nil, leaf.NextAuthoritySetID))
+	buf.Write(binary.LittleEndian.AppendUint32(nil, leaf.NextAuthoritySetLen))
+	buf.Write(leaf.NextAuthoritySetRoot[:])
+	buf.Write(parachainHeadsRoot[:])
+	return buf.Bytes()
+}
+
+func encodeCompactU32(buf *bytes.Buffer, value *big.Int) error {
+	if value.Sign() < 0 || value.Cmp(big.NewInt(math.MaxUint32)) > 0 {
+		return fmt.Errorf("value %v does not fit into u32", value)
+	}
+	return scale.NewEncoder(buf).EncodeUintCompact(*value)
+}
@@ -0,0 +1,229 @@
+package parachain
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/snowfork/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snowfork/snowbridge/relayer/chain/ethereum"
+	"github.com/snowfork/snowbridge/relayer/chain/relaychain"
+	"github.com/snowfork/snowbridge/relayer/config"
+	"github.com/snowfork/snowbridge/relayer/contracts"
+	"github.com/snowfork/snowbridge/relayer/crypto/merkle"
+	"github.com/snowfork/snowbridge/relayer/crypto/secp256k1"
+)
+
+const testParaID = 1013
+
+type verificationFixture struct {
+	message   contracts.InboundMessage
+	leafProof [][32]byte
+	proof     *contracts.VerificationProof
+	mmrRoot   types.H256
+}
+
+// newVerificationFixture builds a valid chain of proofs for a message, using the substrate encodings
+// of the header and MMR leaf so that the encodings in verification.go are checked against them.
+func newVerificationFixture(t *testing.T) verificationFixture {
+	message := contracts.InboundMessage{
+		ChannelID:      [32]byte{0xc1},
+		Nonce:          7,
+		Command:        2,
+		Params:         []byte{1, 2, 3},
+		MaxDispatchGas: 200000,
+		MaxFeePerGas:   big.NewInt(1000000000),
+		Reward:         big.NewInt(1000),
+		Id:             [32]byte{0x1d},
+	}
+	leafHash, err := MessageLeafHash(message)
+	require.NoError(t, err)
+
+	// A second message in the same commitment, hashed as a sorted pair
+	sibling := types.NewH256(crypto.Keccak256([]byte("sibling")))
+	pair := [][]byte{leafHash[:], sibling[:]}
+	if bytes.Compare(pair[0], pair[1]) > 0 {
+		pair[0], pair[1] = pair[1], pair[0]
+	}
+	commitment := types.NewH256(crypto.Keccak256(pair...))
+
+	header := types.Header{
+		ParentHash:     types.NewHash(crypto.Keccak256([]byte("parent"))),
+		Number:         4242,
+		StateRoot:      types.NewHash(crypto.Keccak256([]byte("state"))),
+		ExtrinsicsRoot: types.NewHash(crypto.Keccak256([]byte("extrinsics"))),
+		Digest: types.Digest{
+			{
+				IsPreRuntime: true,
+				AsPreRuntime: types.PreRuntime{ConsensusEngineID: 0x61727561, Bytes: []byte{9, 8, 7, 6}},
+			},
+			{
+				IsOther: true,
+				AsOther: append([]byte{0}, commitment[:]...),
+			},
+			{
+				IsSeal: true,
+				AsSeal: types.Seal{ConsensusEngineID: 0x61727561, Bytes: bytes.Repeat([]byte{5}, 64)},
+			},
+		},
+	}
+	encodedHeader, err := types.EncodeToBytes(header)
+	require.NoError(t, err)
+
+	heads := []relaychain.ParaHead{
+		{ParaID: 1000, Data: []byte{1, 2, 3}},
+		{ParaID: testParaID, Data: encodedHeader},
+		{ParaID: 2000, Data: []byte{4, 5, 6}},
+		{ParaID: 2004, Data: []byte{7, 8, 9}},
+		{ParaID: 2030, Data: []byte{10, 11, 12}},
+	}
+	merkleProofData, err := CreateParachainMerkleProof(heads, testParaID)
+	require.NoError(t, err)
+
+	leaf := types.MMRLeaf{
+		Version: 0,
+		ParentNumberAndHash: types.ParentNumberAndHash{
+			ParentNumber: 100,
+			Hash:         types.NewHash(crypto.Keccak256([]byte("relay parent"))),
+		},
+		BeefyNextAuthoritySet: types.BeefyNextAuthoritySet{
+			ID:   5,
+			Len:  300,
+			Root: types.NewH256(crypto.Keccak256([]byte("authorities"))),
+		},
+		ParachainHeads: types.NewH256(merkleProofData.Root),
+	}
+	encodedLeaf, err := types.EncodeToBytes(leaf)
+	require.NoError(t, err)
+
+	mmrProof := merkle.SimplifiedMMRProof{
+		MerkleProofItems: []types.H256{
+			types.NewH256(crypto.Keccak256([]byte("peak 1"))),
+			types.NewH256(crypto.Keccak256([]byte("peak 2"))),
+		},
+		MerkleProofOrder: 2,
+		Leaf:             leaf,
+	}
+	mmrRoot := merkle.CalculateMerkleRoot(&mmrProof, types.NewH256(crypto.Keccak256(encodedLeaf)))
+
+	proof, err := buildVerificationProof(&ProofOutput{
+		MMRProof:        mmrProof,
+		MMRRootHash:     types.Hash(mmrRoot),
+		Header:          header,
+		MerkleProofData: merkleProofData,
+	})
+	require.NoError(t, err)
+
+	return verificationFixture{
+		message:   message,
+		leafProof: [][32]byte{sibling},
+		proof:     proof,
+		mmrRoot:   mmrRoot,
+	}
+}
+
+func TestVerifyMessage(t *testing.T) {
+	f := newVerificationFixture(t)
+
+	err := VerifyMessage(f.message, f.leafProof, f.proof, testParaID, f.mmrRoot)
+	assert.NoError(t, err)
+}
+
+func TestVerifyMessageInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(f *verificationFixture, paraID *uint32)
+	}{
+		{"message", func(f *verificationFixture, _ *uint32) { f.message.Nonce++ }},
+		{"leaf proof", func(f *verificationFixture, _ *uint32) { f.leafProof[0][0] ^= 1 }},
+		{"para ID", func(_ *verificationFixture, paraID *uint32) { *paraID = 1002 }},
+		{"header", func(f *verificationFixture, _ *uint32) { f.proof.Header.StateRoot[0] ^= 1 }},
+		{"head proof position", func(f *verificationFixture, _ *uint32) { f.proof.HeadProof.Pos = big.NewInt(0) }},
+		{"head proof width", func(f *verificationFixture, _ *uint32) { f.proof.HeadProof.Width = big.NewInt(1) }},
+		{"leaf partial", func(f *verificationFixture, _ *uint32) { f.proof.LeafPartial.NextAuthoritySetID++ }},
+		{"leaf proof order", func(f *verificationFixture, _ *uint32) { f.proof.LeafProofOrder = big.NewInt(1) }},
+		{"MMR root", func(f *verificationFixture, _ *uint32) { f.mmrRoot[0] ^= 1 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newVerificationFixture(t)
+			paraID := uint32(testParaID)
+			tt.mutate(&f, &paraID)
+
+			err := VerifyMessage(f.message, f.leafProof, f.proof, paraID, f.mmrRoot)
+			assert.ErrorIs(t, err, ErrInvalidProof)
+		})
+	}
+}
+
+func TestVerifyCommitmentUnsupportedDigestItem(t *testing.T) {
+	f := newVerificationFixture(t)
+	leafHash, err := MessageLeafHash(f.message)
+	require.NoError(t, err)
+
+	f.proof.Header.DigestItems = append(f.proof.Header.DigestItems, contracts.VerificationDigestItem{
+		Kind: big.NewInt(7),
+	})
+
+	err = VerifyCommitment(ProcessMessageProof(leafHash, f.leafProof), f.proof, testParaID, f.mmrRoot)
+	assert.ErrorIs(t, err, ErrInvalidProof)
+	assert.ErrorContains(t, err, "unsupported digest item kind 7")
+}
+
+// testBeefyClientService answers every eth_call with the latest MMR root of the BEEFY light client
+type testBeefyClientService struct {
+	latestMMRRoot types.H256
+}
+
+func (s testBeefyClientService) Call(args map[string]interface{}, block string) hexutil.Bytes {
+	return s.latestMMRRoot[:]
+}
+
+func newTestVerifyingWriter(t *testing.T, latestMMRRoot types.H256) *EthereumWriter {
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("net", testNetService{}))
+	require.NoError(t, server.RegisterName("eth", testBeefyClientService{latestMMRRoot: latestMMRRoot}))
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	conn := ethereum.NewConnection(&config.EthereumConfig{Endpoint: httpServer.URL}, secp256k1.Alice())
+	require.NoError(t, conn.Connect(context.Background()))
+	t.Cleanup(conn.Close)
+
+	beefyClient, err := contracts.NewBeefyClient(common.Address{}, conn.Client())
+	require.NoError(t, err)
+
+	return &EthereumWriter{conn: conn, beefyClient: beefyClient}
+}
+
+func TestEthereumWriterVerifyMessage(t *testing.T) {
+	f := newVerificationFixture(t)
+	mmrRoot := types.Hash(f.mmrRoot)
+
+	wr := newTestVerifyingWriter(t, f.mmrRoot)
+	err := wr.verifyMessage(context.Background(), f.message, f.leafProof, f.proof, testParaID, mmrRoot)
+	assert.NoError(t, err)
+
+	// The light client has accepted a newer MMR root since the proofs were generated
+	newerMMRRoot := f.mmrRoot
+	newerMMRRoot[0] ^= 1
+	wr = newTestVerifyingWriter(t, newerMMRRoot)
+	err = wr.verifyMessage(context.Background(), f.message, f.leafProof, f.proof, testParaID, mmrRoot)
+	assert.ErrorIs(t, err, errStaleProof)
+	assert.NotErrorIs(t, err, ErrInvalidProof)
+
+	// Proofs which do not match the MMR root they were generated for are invalid, whatever the latest root
+	f.message.Nonce++
+	err = wr.verifyMessage(context.Background(), f.message, f.leafProof, f.proof, testParaID, mmrRoot)
+	assert.ErrorIs(t, err, ErrInvalidProof)
+}
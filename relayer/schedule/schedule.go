@@ -0,0 +1,253 @@
+package schedule
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/snowfork/snowbridge/relayer/config"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultRefreshInterval = 60
+	defaultMaxMissedTurns  = 3
+	// Number of past rotations kept to find the relayer whose turn it was when a nonce was delivered
+	maxRotationHistory = 64
+)
+
+// Schedule assigns nonces to relayers in rotation. Each relayer waits for the relayers before it
+// in the rotation to deliver a nonce, before delivering the nonce itself.
+//
+// Without a membership source, the rotation is given by the static ID and relayer count. With a
+// membership source, the rotation is the sorted set of members, leaving out members which
+// repeatedly missed their turns. A member rejoins the rotation once it delivers a nonce again.
+type Schedule struct {
+	id                uint64
+	totalRelayerCount uint64
+	self              Member
+	source            Source
+	refreshInterval   time.Duration
+	maxMissedTurns    uint64
+
+	mu        sync.Mutex
+	members   []Member
+	missed    map[Member]uint64
+	history   []rotationRecord
+	delivered map[[32]byte]uint64
+	now       func() time.Time
+}
+
+// rotationRecord is a rotation and the time from which it was in force
+type rotationRecord struct {
+	since    time.Time
+	rotation []Member
+}
+
+// New creates a schedule for the relayer self. The static ID and relayer count are used while
+// no relayer set has been loaded from the source, or if source is nil.
+func New(id, totalRelayerCount uint64, membership config.MembershipConfig, self Member, source Source) *Schedule {
+	refreshInterval := membership.RefreshInterval
+	if refreshInterval == 0 {
+		refreshInterval = defaultRefreshInterval
+	}
+	maxMissedTurns := membership.MaxMissedTurns
+	if maxMissedTurns == 0 {
+		maxMissedTurns = defaultMaxMissedTurns
+	}
+
+	return &Schedule{
+		id:                id,
+		totalRelayerCount: totalRelayerCount,
+		self:              self,
+		source:            source,
+		refreshInterval:   time.Duration(refreshInterval) * time.Second,
+		maxMissedTurns:    maxMissedTurns,
+		missed:            make(map[Member]uint64),
+		delivered:         make(map[[32]byte]uint64),
+		now:               time.Now,
+	}
+}
+
+// Dynamic returns whether the relayer set is loaded from a membership source
+func (s *Schedule) Dynamic() bool {
+	return s.source != nil
+}
+
+// Start loads the relayer set and refreshes it periodically. Failing to load the relayer set is
+// not fatal, the previous relayer set or the static schedule remains in use.
+func (s *Schedule) Start(ctx context.Context, eg *errgroup.Group) {
+	if s.source == nil {
+		return
+	}
+
+	s.refresh(ctx)
+
+	eg.Go(func() error {
+		ticker := time.NewTicker(s.refreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				s.refresh(ctx)
+			}
+		}
+	})
+}
+
+func (s *Schedule) refresh(ctx context.Context) {
+	members, err := s.source.Members(ctx)
+	if err != nil {
+		log.WithError(err).Warn("Failed to refresh relayer set")
+		return
+	}
+	s.SetMembers(members)
+}
+
+// SetMembers replaces the relayer set
+func (s *Schedule) SetMembers(members []Member) {
+	sorted := make([]Member, 0, len(members))
+	seen := make(map[Member]bool, len(members))
+	for _, member := range members {
+		if !seen[member] {
+			seen[member] = true
+			sorted = append(sorted, member)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i][:], sorted[j][:]) < 0 })
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for member := range s.missed {
+		if !seen[member] {
+			delete(s.missed, member)
+		}
+	}
+	if !seen[s.self] {
+		log.WithField("self", s.self.Hex()).Warn("Relayer is not a member of the relayer set, delivering after all members")
+	}
+	if !equalMembers(sorted, s.members) {
+		log.WithField("members", len(sorted)).Info("Relayer set changed")
+	}
+	s.members = sorted
+	s.recordRotation()
+}
+
+// WaitingPeriod returns the number of sleep intervals the relayer waits for other relayers to
+// deliver a nonce, before delivering it itself.
+func (s *Schedule) WaitingPeriod(nonce uint64) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.members) == 0 {
+		return (nonce + s.totalRelayerCount - s.id) % s.totalRelayerCount
+	}
+
+	rotation := s.rotation()
+	count := uint64(len(rotation))
+	for i, member := range rotation {
+		if member == s.self {
+			return (nonce + count - uint64(i)) % count
+		}
+	}
+	// Relayers outside the relayer set deliver after all members
+	return count
+}
+
+// ObserveDelivery records which relayer delivered a nonce on a channel at the given time. The
+// relayer whose turn it was in the rotation in force at that time misses its turn if another
+// relayer delivered the nonce. Deliveries of nonces which were already observed are ignored.
+func (s *Schedule) ObserveDelivery(channelID [32]byte, nonce uint64, deliverer Member, deliveredAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.members) == 0 {
+		return
+	}
+
+	if last, ok := s.delivered[channelID]; ok && nonce <= last {
+		return
+	}
+	s.delivered[channelID] = nonce
+
+	rotation := s.rotationAt(deliveredAt)
+	if len(rotation) == 0 {
+		delete(s.missed, deliverer)
+		s.recordRotation()
+		return
+	}
+	designated := rotation[nonce%uint64(len(rotation))]
+	if designated != deliverer && designated != s.self {
+		s.missed[designated]++
+		if s.missed[designated] == s.maxMissedTurns {
+			log.WithFields(log.Fields{
+				"relayer": designated.Hex(),
+				"nonce":   nonce,
+			}).Warn("Relayer missed its turns, leaving it out of the rotation")
+		}
+	}
+
+	if s.missed[deliverer] >= s.maxMissedTurns {
+		log.WithField("relayer", deliverer.Hex()).Info("Relayer delivered again, returning it to the rotation")
+	}
+	delete(s.missed, deliverer)
+	s.recordRotation()
+}
+
+// recordRotation appends the current rotation to the rotation history if it changed
+func (s *Schedule) recordRotation() {
+	rotation := s.rotation()
+	if len(s.history) > 0 && equalMembers(s.history[len(s.history)-1].rotation, rotation) {
+		return
+	}
+	s.history = append(s.history, rotationRecord{since: s.now(), rotation: rotation})
+	if len(s.history) > maxRotationHistory {
+		s.history = s.history[len(s.history)-maxRotationHistory:]
+	}
+}
+
+// rotationAt returns the rotation in force at the given time. Deliveries older than the rotation
+// history are attributed to the oldest known rotation.
+func (s *Schedule) rotationAt(at time.Time) []Member {
+	if len(s.history) == 0 {
+		return s.rotation()
+	}
+	for i := len(s.history) - 1; i > 0; i-- {
+		if !s.history[i].since.After(at) {
+			return s.history[i].rotation
+		}
+	}
+	return s.history[0].rotation
+}
+
+// rotation returns the members which take turns delivering nonces. The relayer itself always
+// takes its turns.
+func (s *Schedule) rotation() []Member {
+	rotation := make([]Member, 0, len(s.members))
+	for _, member := range s.members {
+		if member == s.self || s.missed[member] < s.maxMissedTurns {
+			rotation = append(rotation, member)
+		}
+	}
+	return rotation
+}
+
+func equalMembers(a, b []Member) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+package schedule
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/snowfork/snowbridge/relayer/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	alice = Member{1}
+	bob   = Member{2}
+	carol = Member{3}
+
+	channel = [32]byte{1}
+)
+
+// clock is a fake time source which advances by a second whenever it is read
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	c.t = c.t.Add(time.Second)
+	return c.t
+}
+
+func TestWaitingPeriodStatic(t *testing.T) {
+	s := New(1, 3, config.MembershipConfig{}, alice, nil)
+
+	assert.Equal(t, uint64(0), s.WaitingPeriod(1))
+	assert.Equal(t, uint64(1), s.WaitingPeriod(2))
+	assert.Equal(t, uint64(2), s.WaitingPeriod(3))
+
+	// Deliveries do not change the static schedule
+	s.ObserveDelivery(channel, 1, bob, time.Now())
+	assert.Equal(t, uint64(0), s.WaitingPeriod(4))
+}
+
+func TestWaitingPeriodMembers(t *testing.T) {
+	s := New(0, 1, config.MembershipConfig{MaxMissedTurns: 2}, bob, &FileSource{})
+	s.SetMembers([]Member{carol, alice, bob, alice})
+
+	// The rotation is alice, bob, carol
+	assert.Equal(t, uint64(2), s.WaitingPeriod(0))
+	assert.Equal(t, uint64(0), s.WaitingPeriod(1))
+	assert.Equal(t, uint64(1), s.WaitingPeriod(2))
+
+	// alice misses its turns on nonces 3 and 6, and is left out of the rotation
+	s.ObserveDelivery(channel, 3, bob, time.Now())
+	assert.Equal(t, uint64(2), s.WaitingPeriod(3))
+	s.ObserveDelivery(channel, 6, carol, time.Now())
+
+	// The rotation is bob, carol
+	assert.Equal(t, uint64(0), s.WaitingPeriod(6))
+	assert.Equal(t, uint64(1), s.WaitingPeriod(7))
+
+	// alice rejoins once it delivers again
+	s.ObserveDelivery(channel, 7, alice, time.Now())
+	assert.Equal(t, uint64(1), s.WaitingPeriod(8))
+}
+
+func TestObserveDeliveryIgnoresObservedNonces(t *testing.T) {
+	s := New(0, 1, config.MembershipConfig{MaxMissedTurns: 2}, bob, &FileSource{})
+	s.SetMembers([]Member{alice, bob, carol})
+
+	// alice misses its turn on nonce 3 once, however often the delivery is observed
+	s.ObserveDelivery(channel, 3, bob, time.Now())
+	s.ObserveDelivery(channel, 3, bob, time.Now())
+	s.ObserveDelivery(channel, 2, bob, time.Now())
+	assert.Equal(t, uint64(2), s.WaitingPeriod(0))
+
+	// Nonces are tracked per channel
+	s.ObserveDelivery([32]byte{2}, 3, bob, time.Now())
+	assert.Equal(t, uint64(0), s.WaitingPeriod(0))
+}
+
+func TestObserveDeliveryUsesRotationAtDeliveryTime(t *testing.T) {
+	c := &clock{t: time.Unix(0, 0)}
+	s := New(0, 1, config.MembershipConfig{MaxMissedTurns: 1}, bob, &FileSource{})
+	s.now = c.now
+
+	// The rotation is alice, bob, carol
+	s.SetMembers([]Member{alice, bob, carol})
+	deliveredAt := c.now()
+
+	// The rotation is bob, carol
+	s.SetMembers([]Member{bob, carol})
+
+	// Nonce 3 was alice's turn when it was delivered, so carol does not miss its turn
+	s.ObserveDelivery(channel, 3, bob, deliveredAt)
+	assert.Equal(t, uint64(1), s.WaitingPeriod(1))
+
+	// Nonce 5 is carol's turn in the current rotation
+	s.ObserveDelivery(channel, 5, bob, c.now())
+	assert.Equal(t, uint64(0), s.WaitingPeriod(1))
+}
+
+func TestWaitingPeriodNotMember(t *testing.T) {
+	s := New(0, 1, config.MembershipConfig{}, carol, &FileSource{})
+	s.SetMembers([]Member{alice, bob})
+
+	assert.Equal(t, uint64(2), s.WaitingPeriod(0))
+	assert.Equal(t, uint64(2), s.WaitingPeriod(1))
+}
+
+func TestFileSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "relayers")
+	err := os.WriteFile(path, []byte(`
+# relayers
+0x0100000000000000000000000000000000000000000000000000000000000000
+0x00000000000000000000000000000000000000aa
+`), 0644)
+	require.NoError(t, err)
+
+	members, err := (&FileSource{path: path}).Members(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Member{alice, MemberFromAddress(common.HexToAddress("0xaa"))}, members)
+}
+
+func TestHTTPSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`["0x0200000000000000000000000000000000000000000000000000000000000000"]`))
+	}))
+	defer server.Close()
+
+	source, err := NewSource(config.MembershipConfig{Source: "http", URL: server.URL}, nil)
+	require.NoError(t, err)
+
+	members, err := source.Members(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Member{bob}, members)
+}
@@ -0,0 +1,177 @@
+package schedule
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/snowfork/snowbridge/relayer/config"
+)
+
+// A Member is the account of a relayer. Ethereum addresses are left-padded with zeros.
+type Member [32]byte
+
+func (m Member) Hex() string {
+	return hexutil.Encode(m[:])
+}
+
+// ParseMember parses a member from a hex encoded 32 byte account or 20 byte address
+func ParseMember(s string) (Member, error) {
+	b, err := hexutil.Decode(strings.TrimSpace(s))
+	if err != nil {
+		return Member{}, fmt.Errorf("decode member %q: %w", s, err)
+	}
+	var member Member
+	switch len(b) {
+	case 20:
+		copy(member[12:], b)
+	case 32:
+		copy(member[:], b)
+	default:
+		return Member{}, fmt.Errorf("member %q is neither an address nor an account", s)
+	}
+	return member, nil
+}
+
+// MemberFromAddress returns the member for an Ethereum address
+func MemberFromAddress(address common.Address) Member {
+	var member Member
+	copy(member[12:], address[:])
+	return member
+}
+
+// A Source provides the current set of relayers
+type Source interface {
+	Members(ctx context.Context) ([]Member, error)
+}
+
+// NewSource creates the membership source given by the config. It returns nil if no source is
+// configured. The contract caller is only used by the contract source.
+func NewSource(config config.MembershipConfig, caller bind.ContractCaller) (Source, error) {
+	switch config.Source {
+	case "":
+		return nil, nil
+	case "file":
+		return &FileSource{path: config.File}, nil
+	case "http":
+		return &HTTPSource{url: config.URL, client: &http.Client{Timeout: 10 * time.Second}}, nil
+	case "contract":
+		return NewContractSource(common.HexToAddress(config.Contract), caller)
+	default:
+		return nil, fmt.Errorf("unknown membership source %q", config.Source)
+	}
+}
+
+// FileSource reads the relayer set from a file listing one member per line. Empty lines and
+// lines starting with '#' are ignored.
+type FileSource struct {
+	path string
+}
+
+func (f *FileSource) Members(_ context.Context) ([]Member, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("read membership file: %w", err)
+	}
+
+	var members []Member
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		member, err := ParseMember(line)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, scanner.Err()
+}
+
+// HTTPSource fetches the relayer set from a registry returning a JSON array of members
+type HTTPSource struct {
+	url    string
+	client *http.Client
+}
+
+func (h *HTTPSource) Members(ctx context.Context) ([]Member, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Accept", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch relayer set: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch relayer set: status %d: %s", resp.StatusCode, body)
+	}
+
+	var accounts []string
+	err = json.Unmarshal(body, &accounts)
+	if err != nil {
+		return nil, fmt.Errorf("decode relayer set: %w", err)
+	}
+
+	members := make([]Member, len(accounts))
+	for i, account := range accounts {
+		members[i], err = ParseMember(account)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return members, nil
+}
+
+const allowlistABI = `[{"type":"function","name":"relayers","inputs":[],"outputs":[{"name":"","type":"bytes32[]"}],"stateMutability":"view"}]`
+
+// ContractSource reads the relayer set from an allowlist contract on Ethereum
+type ContractSource struct {
+	contract *bind.BoundContract
+}
+
+func NewContractSource(address common.Address, caller bind.ContractCaller) (*ContractSource, error) {
+	parsed, err := abi.JSON(strings.NewReader(allowlistABI))
+	if err != nil {
+		return nil, err
+	}
+	return &ContractSource{
+		contract: bind.NewBoundContract(address, parsed, caller, nil, nil),
+	}, nil
+}
+
+func (c *ContractSource) Members(ctx context.Context) ([]Member, error) {
+	var out []interface{}
+	err := c.contract.Call(&bind.CallOpts{Context: ctx}, &out, "relayers")
+	if err != nil {
+		return nil, fmt.Errorf("call relayers: %w", err)
+	}
+
+	accounts := *abi.ConvertType(out[0], new([][32]byte)).(*[][32]byte)
+	members := make([]Member, len(accounts))
+	for i, account := range accounts {
+		members[i] = Member(account)
+	}
+	return members, nil
+}
@@ -134,16 +134,25 @@ config_relayer() {
         config/parachain-relay.json >$output_dir/parachain-relay-penpal.json
 
     # Configure beacon relay
+    local capella_forked_epoch=56832
     local deneb_forked_epoch=132608
+    local electra_forked_epoch=222464
     if [ "$eth_fast_mode" == "true" ]; then
+        capella_forked_epoch=0
         deneb_forked_epoch=0
+        # The local beacon node does not schedule Electra
+        electra_forked_epoch=0
     fi
     jq \
         --arg beacon_endpoint_http $beacon_endpoint_http \
+        --argjson capella_forked_epoch $capella_forked_epoch \
         --argjson deneb_forked_epoch $deneb_forked_epoch \
+        --argjson electra_forked_epoch $electra_forked_epoch \
         '
       .source.beacon.endpoint = $beacon_endpoint_http
+    | .source.beacon.spec.capellaForkedEpoch = $capella_forked_epoch
     | .source.beacon.spec.denebForkedEpoch = $deneb_forked_epoch
+    | .source.beacon.spec.electraForkedEpoch = $electra_forked_epoch
     ' \
         config/beacon-relay.json >$output_dir/beacon-relay.json
 